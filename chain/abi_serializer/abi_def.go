@@ -93,7 +93,7 @@ type AbiDef struct {
 	RicardianClauses []ClausePair       `json:"ricardian_clauses,omitempty"`
 	ErrorMessages    []ErrorMessage     `json:"error_messages,omitempty"`
 	Extensions       []*types.Extension `json:"abi_extensions,omitempty"`
-	Variants         []VariantDef       `json:"variants,omitempty"` // TODO may not exit
+	Variants         []VariantDef       `json:"variants,omitempty" eos:"may_not_exist"`
 }
 
 func NewABI(r io.Reader) (*AbiDef, error) {
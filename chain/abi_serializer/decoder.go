@@ -257,7 +257,7 @@ func (a *AbiDef) read(binaryDecoder *rlp.Decoder, fieldName string, fieldType st
 		}
 	case "symbol":
 		s := common.Symbol{}
-		err := binaryDecoder.Decode(&s)
+		err = binaryDecoder.Decode(&s)
 		if err == nil {
 			value = fmt.Sprintf("%d,%s", s.Precision, s.Symbol)
 		}
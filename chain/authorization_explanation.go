@@ -0,0 +1,150 @@
+package chain
+
+import (
+	"github.com/eosspark/eos-go/chain/types"
+	. "github.com/eosspark/eos-go/chain/types/generated_containers"
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto/ecc"
+	"github.com/eosspark/eos-go/entity"
+	. "github.com/eosspark/eos-go/exception"
+	. "github.com/eosspark/eos-go/exception/try"
+)
+
+// DeclaredAuthorizationExplanation explains how one authorization declared by an action is checked
+type DeclaredAuthorizationExplanation struct {
+	Account       common.AccountName     `json:"account"`
+	Name          common.ActionName      `json:"name"`
+	Authorization common.PermissionLevel `json:"authorization"`
+
+	// the native actions which cannot be linked check their authorization against their own data
+	Special           bool                   `json:"special"`
+	LinkedPermission  *common.PermissionName `json:"linked_permission"`
+	MinimumPermission *common.PermissionName `json:"minimum_permission"` // nil if any permission is relevant
+	Relevant          bool                   `json:"relevant"`
+
+	Satisfied   bool                        `json:"satisfied"`
+	Explanation types.PermissionExplanation `json:"explanation"`
+	Error       string                      `json:"error,omitempty"`
+}
+
+type AuthorizationExplanation struct {
+	Authorized     bool                               `json:"authorized"`
+	Error          string                             `json:"error,omitempty"`
+	ProvidedDelay  common.Microseconds                `json:"provided_delay"`
+	EffectiveDelay common.Microseconds                `json:"effective_delay"` // the delay the wait weights are checked against
+	MaxDepth       uint16                             `json:"max_depth"`
+	UnusedKeys     []ecc.PublicKey                    `json:"unused_keys"`
+	Authorizations []DeclaredAuthorizationExplanation `json:"authorizations"`
+}
+
+/**
+ *  ExplainAuthorization tells why CheckAuthorization accepts or rejects the actions.
+ *
+ *  Authorized and Error are the outcome of CheckAuthorization itself, unused keys included. For each declared
+ *  authorization, the minimum permission of the action and the full evaluation tree of the declared permission
+ *  are reported, so that the failing part can be found without reading the chain state.
+ */
+func (a *AuthorizationManager) ExplainAuthorization(actions []*types.Action,
+	providedKeys *PublicKeySet,
+	providedPermissions *PermissionLevelSet,
+	providedDelay common.Microseconds,
+) *AuthorizationExplanation {
+	config := a.control.GetGlobalProperties().Configuration
+	delayMaxLimit := common.Seconds(int64(config.MaxTrxDelay))
+	var effectiveProvidedDelay common.Microseconds
+	if providedDelay >= delayMaxLimit {
+		effectiveProvidedDelay = common.MaxMicroseconds()
+	} else {
+		effectiveProvidedDelay = providedDelay
+	}
+
+	result := &AuthorizationExplanation{
+		ProvidedDelay:  providedDelay,
+		EffectiveDelay: effectiveProvidedDelay,
+		MaxDepth:       config.MaxAuthorityDepth,
+		UnusedKeys:     make([]ecc.PublicKey, 0),
+		Authorizations: make([]DeclaredAuthorizationExplanation, 0),
+	}
+
+	checkTime := func() {}
+	Try(func() {
+		a.CheckAuthorization(actions, providedKeys, providedPermissions, providedDelay, &checkTime, false)
+		result.Authorized = true
+	}).Catch(func(e Exception) {
+		result.Error = e.DetailMessage()
+	}).End()
+
+	checker := types.MakeAuthChecker(func(p *common.PermissionLevel) types.SharedAuthority {
+		perm := a.GetPermission(p)
+		if perm != nil {
+			return perm.Auth
+		} else {
+			return types.SharedAuthority{}
+		}
+	},
+		config.MaxAuthorityDepth,
+		providedKeys,
+		providedPermissions,
+		effectiveProvidedDelay,
+		&checkTime,
+	)
+
+	permissionIndex, err := a.db.GetIndex("id", entity.PermissionObject{})
+	EosAssert(err == nil, &DatabaseException{}, "failed to get the permission index: %v", err)
+
+	for _, act := range actions {
+		special := false
+		specialError := ""
+		Try(func() {
+			special = a.checkSpecialAuthorization(act)
+		}).Catch(func(e Exception) {
+			special = true
+			specialError = e.DetailMessage()
+		}).End()
+
+		for _, declaredAuth := range act.Authorization {
+			explanation := DeclaredAuthorizationExplanation{
+				Account:       act.Account,
+				Name:          act.Name,
+				Authorization: declaredAuth,
+				Special:       special,
+				Relevant:      specialError == "",
+				Error:         specialError,
+			}
+
+			if !special {
+				explanation.LinkedPermission = a.LookupLinkedPermission(declaredAuth.Actor, act.Account, act.Name)
+				explanation.MinimumPermission = a.LookupMinimumPermission(declaredAuth.Actor, act.Account, act.Name)
+				if explanation.MinimumPermission != nil {
+					Try(func() {
+						minPermission := a.GetPermission(&common.PermissionLevel{Actor: declaredAuth.Actor, Permission: *explanation.MinimumPermission})
+						explanation.Relevant = a.GetPermission(&declaredAuth).Satisfies(*minPermission, permissionIndex)
+						if !explanation.Relevant {
+							explanation.Error = "declared authority is not the minimum permission or one of its parents"
+						}
+					}).Catch(func(e Exception) {
+						explanation.Relevant = false
+						explanation.Error = e.DetailMessage()
+					}).End()
+				}
+			}
+
+			Try(func() {
+				explanation.Satisfied = checker.SatisfiedLoc(&declaredAuth, effectiveProvidedDelay, nil)
+			}).Catch(func(e Exception) {
+				explanation.Error = e.DetailMessage()
+			}).End()
+			explanation.Explanation = checker.Explain(&declaredAuth)
+
+			result.Authorizations = append(result.Authorizations, explanation)
+		}
+	}
+
+	unusedKeys := checker.GetUnusedKeys()
+	itr := unusedKeys.Iterator()
+	for itr.Next() {
+		result.UnusedKeys = append(result.UnusedKeys, itr.Value())
+	}
+
+	return result
+}
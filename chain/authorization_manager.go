@@ -4,6 +4,7 @@ import (
 	"github.com/eosspark/eos-go/chain/types"
 	. "github.com/eosspark/eos-go/chain/types/generated_containers"
 	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto/ecc"
 	"github.com/eosspark/eos-go/crypto/rlp"
 	"github.com/eosspark/eos-go/database"
 	"github.com/eosspark/eos-go/entity"
@@ -58,6 +59,7 @@ func (a *AuthorizationManager) CreatePermission(account common.AccountName,
 	if err != nil {
 		log.Error("CreatePermission is error: %s", err)
 	}
+	a.addPermissionAuthorizers(&perm)
 	return &perm
 }
 
@@ -69,6 +71,8 @@ func (a *AuthorizationManager) ModifyPermission(permission *entity.PermissionObj
 	if err != nil {
 		log.Error("ModifyPermission is error: %s", err)
 	}
+	a.removePermissionAuthorizers(permission)
+	a.addPermissionAuthorizers(permission)
 }
 
 func (a *AuthorizationManager) RemovePermission(permission *entity.PermissionObject) {
@@ -90,12 +94,119 @@ func (a *AuthorizationManager) RemovePermission(permission *entity.PermissionObj
 	if err != nil {
 		log.Error("RemovePermission is error: %s", err)
 	}
+	a.removePermissionAuthorizers(permission)
 	err = a.db.Remove(permission)
 	if err != nil {
 		log.Error("RemovePermission is error: %s", err)
 	}
 }
 
+func (a *AuthorizationManager) addPermissionAuthorizers(permission *entity.PermissionObject) {
+	auth := &permission.Auth
+	for _, k := range auth.Keys {
+		pao := entity.PermissionAuthorizerObject{
+			PermissionId: permission.ID,
+			Owner:        permission.Owner,
+			Name:         permission.Name,
+			Key:          k.Key,
+			Weight:       k.Weight,
+			Threshold:    auth.Threshold,
+		}
+		err := a.db.Insert(&pao)
+		if err != nil {
+			log.Error("addPermissionAuthorizers is error: %s", err)
+		}
+	}
+	for _, p := range auth.Accounts {
+		pao := entity.PermissionAuthorizerObject{
+			PermissionId:         permission.ID,
+			Owner:                permission.Owner,
+			Name:                 permission.Name,
+			AuthorizerActor:      p.Permission.Actor,
+			AuthorizerPermission: p.Permission.Permission,
+			Weight:               p.Weight,
+			Threshold:            auth.Threshold,
+		}
+		err := a.db.Insert(&pao)
+		if err != nil {
+			log.Error("addPermissionAuthorizers is error: %s", err)
+		}
+	}
+}
+
+func (a *AuthorizationManager) removePermissionAuthorizers(permission *entity.PermissionObject) {
+	index, err := a.db.GetIndex("byPermission", entity.PermissionAuthorizerObject{})
+	if err != nil {
+		log.Error("removePermissionAuthorizers is error: %s", err)
+		return
+	}
+
+	authorizers := make([]entity.PermissionAuthorizerObject, 0)
+	itr, err := index.LowerBound(entity.PermissionAuthorizerObject{PermissionId: permission.ID})
+	if err != nil {
+		log.Error("removePermissionAuthorizers is error: %s", err)
+		return
+	}
+	for !index.CompareEnd(itr) {
+		pao := entity.PermissionAuthorizerObject{}
+		err = itr.Data(&pao)
+		if err != nil || pao.PermissionId != permission.ID {
+			break
+		}
+		authorizers = append(authorizers, pao)
+		itr.Next()
+	}
+
+	for i := range authorizers {
+		err = a.db.Remove(&authorizers[i])
+		if err != nil {
+			log.Error("removePermissionAuthorizers is error: %s", err)
+		}
+	}
+}
+
+// GetPermissionsByKey returns the authorizer entries of every permission whose authority contains key
+func (a *AuthorizationManager) GetPermissionsByKey(key *ecc.PublicKey) []entity.PermissionAuthorizerObject {
+	index, err := a.db.GetIndex("byKey", entity.PermissionAuthorizerObject{})
+	EosAssert(err == nil, &PermissionQueryException{}, "Failed to retrieve permissions by key: %s", err)
+
+	result := make([]entity.PermissionAuthorizerObject, 0)
+	itr, err := index.LowerBound(entity.PermissionAuthorizerObject{Key: *key})
+	EosAssert(err == nil, &PermissionQueryException{}, "Failed to retrieve permissions by key: %s", err)
+	for !index.CompareEnd(itr) {
+		pao := entity.PermissionAuthorizerObject{}
+		if err = itr.Data(&pao); err != nil || !pao.IsKey() || !pao.Key.Compare(*key) {
+			break
+		}
+		result = append(result, pao)
+		itr.Next()
+	}
+	return result
+}
+
+// GetPermissionsByAuthorizer returns the authorizer entries of every permission whose authority
+// contains level. An empty level.Permission matches any permission of level.Actor.
+func (a *AuthorizationManager) GetPermissionsByAuthorizer(level *common.PermissionLevel) []entity.PermissionAuthorizerObject {
+	index, err := a.db.GetIndex("byAuthorizer", entity.PermissionAuthorizerObject{})
+	EosAssert(err == nil, &PermissionQueryException{}, "Failed to retrieve permissions by authorizer: %s", err)
+
+	result := make([]entity.PermissionAuthorizerObject, 0)
+	itr, err := index.LowerBound(entity.PermissionAuthorizerObject{AuthorizerActor: level.Actor, AuthorizerPermission: level.Permission})
+	EosAssert(err == nil, &PermissionQueryException{}, "Failed to retrieve permissions by authorizer: %s", err)
+	for !index.CompareEnd(itr) {
+		pao := entity.PermissionAuthorizerObject{}
+		if err = itr.Data(&pao); err != nil || pao.AuthorizerActor != level.Actor {
+			break
+		}
+		if !level.Permission.Empty() && pao.AuthorizerPermission != level.Permission {
+			break
+		}
+		result = append(result, pao)
+		itr.Next()
+	}
+	return result
+}
+
 func (a *AuthorizationManager) UpdatePermissionUsage(permission *entity.PermissionObject) {
 	puo := entity.PermissionUsageObject{}
 	puo.ID = permission.UsageId
@@ -324,6 +435,45 @@ func (a *AuthorizationManager) CheckCancelDelayAuthorization(cancel CancelDelay,
 	return common.Milliseconds(int64(generatedTrx.DelayUntil) - int64(generatedTrx.Published))
 }
 
+// checkSpecialAuthorization checks the declared authorization of the native actions which cannot be linked,
+// it returns false for the other actions
+func (a *AuthorizationManager) checkSpecialAuthorization(act *types.Action) bool {
+	if act.Account != common.DefaultConfig.SystemAccountName {
+		return false
+	}
+
+	switch act.Name {
+	case UpdateAuth{}.GetName():
+		UpdateAuth := UpdateAuth{}
+		rlp.DecodeBytes(act.Data, &UpdateAuth)
+		a.CheckUpdateAuthAuthorization(UpdateAuth, act.Authorization)
+
+	case DeleteAuth{}.GetName():
+		DeleteAuth := DeleteAuth{}
+		rlp.DecodeBytes(act.Data, &DeleteAuth)
+		a.CheckDeleteAuthAuthorization(DeleteAuth, act.Authorization)
+
+	case LinkAuth{}.GetName():
+		LinkAuth := LinkAuth{}
+		rlp.DecodeBytes(act.Data, &LinkAuth)
+		a.CheckLinkAuthAuthorization(LinkAuth, act.Authorization)
+
+	case UnLinkAuth{}.GetName():
+		UnLinkAuth := UnLinkAuth{}
+		rlp.DecodeBytes(act.Data, &UnLinkAuth)
+		a.CheckUnLinkAuthAuthorization(UnLinkAuth, act.Authorization)
+
+	case CancelDelay{}.GetName():
+		CancelDelay := CancelDelay{}
+		rlp.DecodeBytes(act.Data, &CancelDelay)
+		a.CheckCancelDelayAuthorization(CancelDelay, act.Authorization)
+
+	default:
+		return false
+	}
+	return true
+}
+
 func (a *AuthorizationManager) CheckAuthorization(actions []*types.Action,
 	providedKeys *PublicKeySet,
 	providedPermissions *PermissionLevelSet,
@@ -355,42 +505,9 @@ func (a *AuthorizationManager) CheckAuthorization(actions []*types.Action,
 	permissionToSatisfy := make(map[common.PermissionLevel]common.Microseconds)
 
 	for _, act := range actions {
-		specialCase := false
+		specialCase := a.checkSpecialAuthorization(act)
 		delay := effectiveProvidedDelay
 
-		if act.Account == common.DefaultConfig.SystemAccountName {
-			specialCase = true
-			switch act.Name {
-			case UpdateAuth{}.GetName():
-				UpdateAuth := UpdateAuth{}
-				rlp.DecodeBytes(act.Data, &UpdateAuth)
-				a.CheckUpdateAuthAuthorization(UpdateAuth, act.Authorization)
-
-			case DeleteAuth{}.GetName():
-				DeleteAuth := DeleteAuth{}
-				rlp.DecodeBytes(act.Data, &DeleteAuth)
-				a.CheckDeleteAuthAuthorization(DeleteAuth, act.Authorization)
-
-			case LinkAuth{}.GetName():
-				LinkAuth := LinkAuth{}
-				rlp.DecodeBytes(act.Data, &LinkAuth)
-				a.CheckLinkAuthAuthorization(LinkAuth, act.Authorization)
-
-			case UnLinkAuth{}.GetName():
-				UnLinkAuth := UnLinkAuth{}
-				rlp.DecodeBytes(act.Data, &UnLinkAuth)
-				a.CheckUnLinkAuthAuthorization(UnLinkAuth, act.Authorization)
-
-			case CancelDelay{}.GetName():
-				CancelDelay := CancelDelay{}
-				rlp.DecodeBytes(act.Data, &CancelDelay)
-				a.CheckCancelDelayAuthorization(CancelDelay, act.Authorization)
-
-			default:
-				specialCase = false
-			}
-		}
-
 		for _, declaredAuth := range act.Authorization {
 			(*checkTime)()
 			if !specialCase {
@@ -78,7 +78,7 @@ func NewBlockLog(dataDir string) *BlockLog {
 
 	_, err := os.Stat(dataDir)
 	if err != nil {
-		os.Mkdir(dataDir, os.ModePerm)
+		os.MkdirAll(dataDir, os.ModePerm)
 	}
 
 	blockLog.blockFile = dataDir + "/blocks.log"
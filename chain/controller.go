@@ -63,6 +63,7 @@ type ValidationMode int8
 const (
 	FULL = ValidationMode(iota)
 	LIGHT
+	HEADER_ONLY
 )
 
 func (v ValidationMode) String() string {
@@ -71,6 +72,8 @@ func (v ValidationMode) String() string {
 		return "full"
 	case LIGHT:
 		return "light"
+	case HEADER_ONLY:
+		return "header-only"
 	default:
 		return ""
 	}
@@ -82,6 +85,8 @@ func ValidationModeFromString(s string) (ValidationMode, bool) {
 		return FULL, true
 	case "LIGHT", "light":
 		return LIGHT, true
+	case "HEADER_ONLY", "header-only":
+		return HEADER_ONLY, true
 	default:
 		return -1, false
 	}
@@ -98,6 +103,7 @@ type Config struct {
 	TrustedProducers        AccountNameSet
 	BlocksDir               string
 	StateDir                string
+	InMemoryState           bool // state and reversible blocks databases are kept in memory and lost on close
 	StateSize               uint64
 	StateGuardSize          uint64
 	ReversibleCacheSize     uint64
@@ -109,7 +115,10 @@ type Config struct {
 	ContractsConsole        bool
 	AllowRamBillingInNotify bool
 	Genesis                 *types.GenesisState
-	VmType                  wasmgo.WasmGo
+	VmType                  wasmgo.VmType
+	ContractDebugAccounts   AccountNameSet // contracts executed in the wasmgo debug mode
+	ContractTraceDir        string         // instruction traces of the debug mode are written here, none if empty
+	ActionReceiptsRetention uint32         // number of the last irreversible blocks whose action receipts are kept, none if 0
 	ReadMode                DBReadMode
 	BlockValidationMode     ValidationMode
 }
@@ -132,6 +141,7 @@ func NewConfig() *Config {
 		DisableReplayOpts:       false,
 		ContractsConsole:        false,
 		AllowRamBillingInNotify: false,
+		VmType:                  wasmgo.Interpreter,
 		ReadMode:                SPECULATIVE,
 		BlockValidationMode:     FULL,
 		Genesis:                 types.NewGenesisState(),
@@ -144,6 +154,8 @@ func NewConfig() *Config {
 		KeyBlacklist:      *NewPublicKeySet(),
 		ResourceGreylist:  *NewAccountNameSet(),
 		TrustedProducers:  *NewAccountNameSet(),
+
+		ContractDebugAccounts: *NewAccountNameSet(),
 	}
 }
 
@@ -153,6 +165,7 @@ type Controller struct {
 	DB                             database.DataBase
 	UndoSession                    database.Session
 	ReversibleBlocks               database.DataBase
+	ActionReceipts                 database.DataBase
 	Blog                           *BlockLog
 	Pending                        *PendingState
 	Head                           *types.BlockState
@@ -180,27 +193,51 @@ type Controller struct {
 	BadAlloc                       include.Signal
 }
 
-func NewController(cfg *Config) *Controller {
-	db, err := database.NewDataBase(cfg.StateDir)
-	reversibleDB, err := database.NewDataBase(cfg.BlocksDir + "/" + common.DefaultConfig.DefaultReversibleBlocksDirName)
+// openDataBases opens the chain state, reversible blocks and action receipts databases, in memory when cfg.InMemoryState is set
+func openDataBases(cfg *Config) (db database.DataBase, reversibleDB database.DataBase, receiptsDB database.DataBase, err error) {
+	if cfg.InMemoryState {
+		if db, err = database.NewMemDataBase(); err != nil {
+			return
+		}
+		if reversibleDB, err = database.NewMemDataBase(); err != nil {
+			return
+		}
+		receiptsDB, err = database.NewMemDataBase()
+		return
+	}
 
+	if db, err = database.NewDataBase(cfg.StateDir); err != nil {
+		return
+	}
+	if reversibleDB, err = database.NewDataBase(cfg.BlocksDir + "/" + common.DefaultConfig.DefaultReversibleBlocksDirName); err != nil {
+		return
+	}
+	receiptsDB, err = database.NewDataBase(cfg.BlocksDir + "/" + common.DefaultConfig.DefaultActionReceiptsDirName)
+	return
+}
+
+func NewController(cfg *Config) *Controller {
+	db, reversibleDB, receiptsDB, err := openDataBases(cfg)
 	if err != nil {
 		log.Error("newController create database is error :%s", err)
-		return nil
+		Throw(err)
 	}
 	con := &Controller{InTrxRequiringChecks: false, RePlaying: false, TrustedProducerLightValidation: false}
 	con.DB = db
 	con.ReversibleBlocks = reversibleDB
+	con.ActionReceipts = receiptsDB
 
 	con.Blog = NewBlockLog(cfg.BlocksDir)
 
-	con.ForkDB = NewForkDatabase(cfg.StateDir)
+	con.ForkDB = NewForkDatabase(cfg.StateDir, !cfg.InMemoryState)
 
 	con.ChainID = cfg.Genesis.ComputeChainID()
 
 	con.ReadMode = cfg.ReadMode
 	con.ApplyHandlers = make(map[string]v)
 	con.WasmIf = wasmgo.NewWasmGo()
+	con.WasmIf.SetVmType(cfg.VmType)
+	con.WasmIf.EnableDebug(cfg.ContractDebugAccounts.Values(), cfg.ContractTraceDir)
 
 	con.Config = *cfg
 
@@ -231,12 +268,21 @@ func NewController(cfg *Config) *Controller {
 
 func (c *Controller) Startup() {
 	//TODO c.AddIndices()
+	c.checkGeneratedTransactionLayout()
+	c.checkPermissionAuthorizers()
+
+	if c.ForkDB.Head == nil && c.DB.Revision() > 0 {
+		c.rebuildForkDB()
+	}
 
 	c.Head = c.ForkDB.Head
 	if c.Head == nil {
 		log.Warn("No head block in fork db, perhaps we need to replay")
+	} else if c.ReadMode != IRREVERSIBLE && c.Head.BlockNum < uint32(c.DB.Revision()) {
+		c.Head = c.catchUpForkDB()
 	}
 	c.initialize()
+	c.ForkDB.Checkpoint()
 }
 
 func (c *Controller) PopBlock() {
@@ -264,6 +310,17 @@ func (c *Controller) PopBlock() {
 	}
 	c.Head = prev
 	c.DB.Undo()
+	c.flushDataBases()
+}
+
+// flushDataBases makes the state, reversible blocks and action receipts written so far durable, a crash after it restarts from here
+func (c *Controller) flushDataBases() {
+	err := c.DB.Flush()
+	EosAssert(err == nil, &DatabaseException{}, "flush state database failed: %s", err)
+	err = c.ReversibleBlocks.Flush()
+	EosAssert(err == nil, &DatabaseException{}, "flush reversible blocks database failed: %s", err)
+	err = c.ActionReceipts.Flush()
+	EosAssert(err == nil, &DatabaseException{}, "flush action receipts database failed: %s", err)
 }
 
 func (c *Controller) SetApplayHandler(receiver common.AccountName, contract common.AccountName, action common.ActionName, handler func(a *ApplyContext)) {
@@ -290,6 +347,7 @@ func (c *Controller) OnIrreversible(s *types.BlockState) {
 	lhBlockNum := logHead.BlockNumber()
 	c.DB.Commit(int64(s.BlockNum))
 	if s.BlockNum <= lhBlockNum {
+		c.keepActionReceipts(s)
 		return
 	}
 	EosAssert(s.BlockNum-1 == lhBlockNum, &UnlinkableBlockException{}, "unlinkable block:%d,%d", s.BlockNum, lhBlockNum)
@@ -321,9 +379,78 @@ func (c *Controller) OnIrreversible(s *types.BlockState) {
 		c.ForkDB.SetValidity(s, true)
 		c.Head = s
 	}
+	c.keepActionReceipts(s)
 	c.IrreversibleBlock.Emit(s)
 }
 
+/**
+ *  keepActionReceipts stores the action receipts of an irreversible block, they leave the fork database with it.
+ *  Only the receipts of the last Config.ActionReceiptsRetention irreversible blocks are kept, older ones are removed.
+ */
+func (c *Controller) keepActionReceipts(s *types.BlockState) {
+	if c.Config.ActionReceiptsRetention == 0 {
+		return
+	}
+
+	if len(s.ActionReceipts) != 0 {
+		obj := entity.ActionReceiptsObject{BlockNum: s.BlockNum}
+		if c.ActionReceipts.Find("byNum", obj, &obj) != nil {
+			obj.FirstSequence = s.ActionReceipts[0].GlobalSequence
+			obj.SetReceipts(s.ActionReceipts)
+			if err := c.ActionReceipts.Insert(&obj); err != nil {
+				log.Error("Controller keepActionReceipts is error: %s", err)
+			}
+		}
+	}
+
+	if s.BlockNum <= c.Config.ActionReceiptsRetention {
+		return
+	}
+	oldest := s.BlockNum - c.Config.ActionReceiptsRetention
+	idx, err := c.ActionReceipts.GetIndex("byNum", &entity.ActionReceiptsObject{})
+	if err != nil {
+		EosThrow(&DatabaseGuardException{}, err.Error())
+	}
+	for itr := idx.Begin(); !idx.CompareEnd(itr); itr = idx.Begin() {
+		obj := entity.ActionReceiptsObject{}
+		itr.Data(&obj)
+		if obj.BlockNum > oldest {
+			break
+		}
+		if err := c.ActionReceipts.Remove(&obj); err != nil {
+			log.Error("Controller keepActionReceipts is error: %s", err)
+			break
+		}
+	}
+}
+
+// FetchActionReceipts returns the action receipts kept for an irreversible block, nil if it has none
+func (c *Controller) FetchActionReceipts(blockNum uint32) []types.ActionReceipt {
+	obj := entity.ActionReceiptsObject{BlockNum: blockNum}
+	if c.ActionReceipts.Find("byNum", obj, &obj) != nil {
+		return nil
+	}
+	return obj.GetReceipts()
+}
+
+// FindActionReceiptsBlock returns the number of the irreversible block whose kept receipts may hold the action
+// of globalSequence, the block with the greatest first sequence not above it, 0 if there is none
+func (c *Controller) FindActionReceiptsBlock(globalSequence uint64) uint32 {
+	obj := entity.ActionReceiptsObject{FirstSequence: globalSequence}
+	idx, err := c.ActionReceipts.GetIndex("bySequence", &obj)
+	if err != nil {
+		return 0
+	}
+	itr, _ := idx.UpperBound(&obj)
+	if idx.CompareBegin(itr) {
+		return 0
+	}
+	itr.Prev()
+	found := entity.ActionReceiptsObject{}
+	itr.Data(&found)
+	return found.BlockNum
+}
+
 func (c *Controller) AbortBlock() {
 	if c.Pending != nil {
 		if c.ReadMode == SPECULATIVE {
@@ -343,6 +470,7 @@ func (c *Controller) StartBlock(when types.BlockTimeStamp, confirmBlockCount uin
 }
 func (c *Controller) startBlock(when types.BlockTimeStamp, confirmBlockCount uint16, s types.BlockStatus, producerBlockId *common.BlockIdType) {
 	EosAssert(c.Pending == nil, &BlockValidateException{}, "pending block already exists")
+	EosAssert(c.Config.BlockValidationMode != HEADER_ONLY, &BlockValidateException{}, "blocks are not executed in header-only validation mode")
 	defer func() {
 		if c.Pending != nil && c.Pending.PendingValid {
 			c.Pending = c.Pending.Reset()
@@ -610,6 +738,7 @@ func (c *Controller) Close() {
 	c.ForkDB.Close()
 	c.DB.Close()
 	c.ReversibleBlocks.Close()
+	c.ActionReceipts.Close()
 	c = nil
 }
 
@@ -647,10 +776,9 @@ func (c *Controller) GetScheduledTransactions() []common.TransactionIdType {
 	} else {
 		itr.Data(&gto)
 	}
-	for itr != idx.End() {
-		if gto.DelayUntil <= c.PendingBlockTime() {
-			result = append(result, gto.TrxId)
-		}
+	// byDelay is ordered by (DelayUntil, Id), the transactions after the first one not due yet are not due either
+	for itr != idx.End() && gto.DelayUntil <= c.PendingBlockTime() {
+		result = append(result, gto.TrxId)
 		if !itr.Next() {
 			break
 		}
@@ -894,14 +1022,19 @@ func (c *Controller) setActionMerkle() {
 		actionDigests = append(actionDigests, a.Digest())
 	}
 	c.Pending.PendingBlockState.Header.ActionMRoot = types.Merkle(actionDigests)
+	c.Pending.PendingBlockState.ActionReceipts = c.Pending.Actions
 }
 
 func (c *Controller) setTrxMerkle() {
-	trxDigests := make([]crypto.Sha256, 0, len(c.Pending.PendingBlockState.SignedBlock.Transactions))
-	for _, b := range c.Pending.PendingBlockState.SignedBlock.Transactions {
+	c.Pending.PendingBlockState.Header.TransactionMRoot = trxMerkle(c.Pending.PendingBlockState.SignedBlock.Transactions)
+}
+
+func trxMerkle(receipts []types.TransactionReceipt) common.DigestType {
+	trxDigests := make([]crypto.Sha256, 0, len(receipts))
+	for _, b := range receipts {
 		trxDigests = append(trxDigests, b.Digest())
 	}
-	c.Pending.PendingBlockState.Header.TransactionMRoot = types.Merkle(trxDigests)
+	return types.Merkle(trxDigests)
 }
 
 func (c *Controller) FinalizeBlock() {
@@ -1016,6 +1149,9 @@ func (c *Controller) CommitBlock(addToForkDb bool) {
 			c.AcceptedBlockHeader.Emit(c.Pending.PendingBlockState)
 			c.Head = c.ForkDB.Header()
 			EosAssert(newBsp == c.Head, &ForkDatabaseException{}, "committed block did not become the new head in fork database")
+		} else if bs := c.ForkDB.GetBlock(&c.Pending.PendingBlockState.BlockId); bs != nil {
+			/* the validated block is already in fork database, keep its action receipts for get_action_proof */
+			bs.ActionReceipts = c.Pending.PendingBlockState.ActionReceipts
 		}
 
 		if !c.RePlaying {
@@ -1033,9 +1169,21 @@ func (c *Controller) CommitBlock(addToForkDb bool) {
 	}).End()
 	c.Pending.Push()
 	c.Pending.PendingValid = true
+	c.flushDataBases()
+	if addToForkDb {
+		// blocks from the network are checkpointed by PushBlock once it is done with the fork database
+		c.checkpointForkDB()
+	}
 	//log.Info("commitBlock success!")
 }
 
+// checkpointForkDB writes the fork database once per block, a replay only writes it when it is done
+func (c *Controller) checkpointForkDB() {
+	if !c.RePlaying {
+		c.ForkDB.Checkpoint()
+	}
+}
+
 func (c *Controller) PushBlock(b *types.SignedBlock, s types.BlockStatus) {
 	EosAssert(c.Pending == nil, &BlockValidateException{}, "it is not valid to push a block when there is a pending block")
 	defer func() {
@@ -1045,6 +1193,11 @@ func (c *Controller) PushBlock(b *types.SignedBlock, s types.BlockStatus) {
 	Try(func() {
 		EosAssert(b != nil, &BlockValidateException{}, "trying to push empty block")
 		EosAssert(s != types.Incomplete, &BlockLogException{}, "invalid block status for a completed block")
+		if c.Config.BlockValidationMode == HEADER_ONLY {
+			/* the transactions are not applied, their receipts must still be the ones the producer signed */
+			EosAssert(trxMerkle(b.Transactions) == b.TransactionMRoot, &BlockValidateException{},
+				"transaction merkle root of block %s does not match its transactions", b.BlockID())
+		}
 		c.PreAcceptedBlock.Emit(b)
 		trust := !c.Config.ForceAllChecks && (s == types.Irreversible || s == types.Validated)
 
@@ -1059,20 +1212,31 @@ func (c *Controller) PushBlock(b *types.SignedBlock, s types.BlockStatus) {
 		if s == types.Irreversible {
 			c.IrreversibleBlock.Emit(newHeaderState)
 		}
+		c.checkpointForkDB()
 	}).FcLogAndRethrow().End()
 
 }
 
 func (c *Controller) PushConfirmation(hc *types.HeaderConfirmation) {
 	EosAssert(c.Pending == nil, &BlockValidateException{}, "it is not valid to push a confirmation when there is a pending block")
+	bftIrreversibleBlocknum := c.ForkDB.Head.BftIrreversibleBlocknum
 	c.ForkDB.AddConfirmation(hc)
 	c.AcceptedConfirmation.Emit(hc)
-	if c.ReadMode != IRREVERSIBLE {
+	if c.ReadMode != IRREVERSIBLE && c.ForkDB.Head.BlockId != c.Head.BlockId {
 		c.maybeSwitchForks(types.Complete)
 	}
+	if c.ForkDB.Head.BftIrreversibleBlocknum > bftIrreversibleBlocknum {
+		// the other confirmations are written with the next block
+		c.checkpointForkDB()
+	}
 }
 
 func (c *Controller) maybeSwitchForks(s types.BlockStatus) {
+	if c.Config.BlockValidationMode == HEADER_ONLY {
+		c.switchHeaders()
+		return
+	}
+
 	newHead := c.ForkDB.Head
 	if newHead.Header.Previous == c.Head.BlockId {
 		Try(func() {
@@ -1135,6 +1299,35 @@ func (c *Controller) maybeSwitchForks(s types.BlockStatus) {
 
 }
 
+/**
+ *  In header-only validation mode the head follows the fork database head without applying
+ *  any transaction. The fork database verified the producer signature and the schedule of each
+ *  header when it was added, so switching forks only moves the blocks in and out of the current chain.
+ */
+func (c *Controller) switchHeaders() {
+	newHead := c.ForkDB.Head
+	if newHead.BlockId == c.Head.BlockId {
+		return
+	}
+
+	if newHead.Header.Previous != c.Head.BlockId {
+		log.Info("switching forks from: %v (block number %v) to %v (block number %v)", c.Head.BlockId, c.Head.BlockNum, newHead.BlockId, newHead.BlockNum)
+		branches := c.ForkDB.FetchBranchFrom(&newHead.BlockId, &c.Head.BlockId)
+
+		for _, old := range branches.second {
+			c.ForkDB.MarkInCurrentChain(old, false)
+		}
+		for i := len(branches.first) - 1; i >= 0; i-- {
+			c.ForkDB.MarkInCurrentChain(branches.first[i], true)
+			c.ForkDB.SetValidity(branches.first[i], true)
+		}
+	}
+
+	c.ForkDB.MarkInCurrentChain(newHead, true)
+	c.ForkDB.SetValidity(newHead, true)
+	c.Head = newHead
+}
+
 func (c *Controller) DataBase() database.DataBase {
 	return c.DB
 }
@@ -1556,7 +1749,13 @@ func (c *Controller) CreateNativeAccount(name common.AccountName, owner types.Au
 }
 
 func (c *Controller) initializeForkDB() {
+	c.Head = c.genesisBlockState()
+	c.ForkDB.SetHead(c.Head)
+	c.DB.SetRevision(int64(c.Head.BlockNum))
+	c.initializeDatabase()
+}
 
+func (c *Controller) genesisBlockState() *types.BlockState {
 	gs := c.Config.Genesis
 	pst := types.ProducerScheduleType{0, []types.ProducerKey{
 		{common.DefaultConfig.SystemAccountName, gs.InitialKey}}}
@@ -1570,13 +1769,109 @@ func (c *Controller) initializeForkDB() {
 	genHeader.BlockNum = genHeader.Header.BlockNumber()
 	genHeader.ProducerToLastProduced = *NewAccountNameUint32Map()
 	genHeader.ProducerToLastImpliedIrb = *NewAccountNameUint32Map()
-	c.Head = types.NewBlockState(&genHeader)
+	genesis := types.NewBlockState(&genHeader)
+	genesis.SignedBlock = types.NewSignedBlock1(&genHeader.Header)
+	return genesis
+}
+
+/**
+ *  checkGeneratedTransactionLayout refuses a state database written while the Id of the generated transactions
+ *  was their first field, their rows and index keys are laid out differently since it follows the fields of the
+ *  composite indices. The TrxId read from such a row is not found by the byTrxId index.
+ */
+func (c *Controller) checkGeneratedTransactionLayout() {
+	idx, err := c.DB.GetIndex("id", &entity.GeneratedTransactionObject{})
+	EosAssert(err == nil, &DatabaseException{}, "check generated transaction layout GetIndex is error: %s", err)
+	itr := idx.Begin()
+	if idx.CompareEnd(itr) {
+		return
+	}
+
+	gto, check := entity.GeneratedTransactionObject{}, entity.GeneratedTransactionObject{}
+	current := itr.Data(&gto) == nil &&
+		c.DB.Find("byTrxId", entity.GeneratedTransactionObject{TrxId: gto.TrxId}, &check) == nil && check.Id == gto.Id
+	EosAssert(current, &DatabaseException{},
+		"the generated transactions of the state database use a former layout, replay the blockchain with --replay-blockchain")
+}
+
+/**
+ *  checkPermissionAuthorizers refuses a state database written before the permission authorizers were indexed,
+ *  get_accounts_by_authorizers would find nothing there. The index is not rebuilt in place, the rows inserted
+ *  now would be recorded in the undo session of the last reversible block.
+ */
+func (c *Controller) checkPermissionAuthorizers() {
+	permissions, err := c.DB.GetIndex("id", &entity.PermissionObject{})
+	EosAssert(err == nil, &DatabaseException{}, "check permission authorizers GetIndex is error: %s", err)
+	authorizers, err := c.DB.GetIndex("id", &entity.PermissionAuthorizerObject{})
+	EosAssert(err == nil, &DatabaseException{}, "check permission authorizers GetIndex is error: %s", err)
+
+	// every chain has permissions controlled by keys or accounts, starting with the ones of eosio
+	indexed := permissions.CompareEnd(permissions.Begin()) || !authorizers.CompareEnd(authorizers.Begin())
+	EosAssert(indexed, &DatabaseException{},
+		"the permission authorizers of the state database are not indexed, replay the blockchain with --replay-blockchain")
+}
+
+/**
+ *  rebuildForkDB recovers a fork database that was lost or corrupted, along with its previous checkpoint,
+ *  while the state database survived. The header state of the last irreversible block is recomputed from
+ *  the whole block log without applying any transaction, Startup then catches up with the state database.
+ */
+func (c *Controller) rebuildForkDB() {
+	log.Warn("rebuilding the fork database from the block log")
+
+	lib := c.genesisBlockState()
+	if end := c.Blog.ReadHead(); end != nil {
+		for n := lib.BlockNum + 1; n <= end.BlockNumber(); n++ {
+			lib = types.NewBlockState3(&lib.BlockHeaderState, c.Blog.ReadBlockByNum(n), true)
+		}
+	}
+	// the confirmations that made it irreversible are not in the block log
+	lib.BftIrreversibleBlocknum = lib.BlockNum
+	lib.InCurrentChain = true
+	lib.Validated = true
+	c.ForkDB.SetHead(lib)
+	log.Info("fork database rebuilt, last irreversible block %d", lib.BlockNum)
+}
+
+/**
+ *  catchUpForkDB adds the blocks the state database applied after the fork database was written, a crash
+ *  between the flush of the state database and the checkpoint of the fork database leaves it a block behind.
+ *  The blocks come from the block log or the reversible blocks database, their transactions are not applied
+ *  again. It returns the block state of the state database revision.
+ */
+func (c *Controller) catchUpForkDB() *types.BlockState {
+	revision := uint32(c.DB.Revision())
+	head := c.ForkDB.Index.GetByBlockNum().Begin().Value()
+	log.Warn("fork database is behind the state database, catching up from block %d to %d", head.BlockNum, revision)
+
+	for n := head.BlockNum + 1; n <= revision; n++ {
+		var b *types.SignedBlock
+		if end := c.Blog.ReadHead(); end != nil && n <= end.BlockNumber() {
+			b = c.Blog.ReadBlockByNum(n)
+		} else {
+			r := entity.ReversibleBlockObject{BlockNum: n}
+			err := c.ReversibleBlocks.Find("byNum", r, &r)
+			EosAssert(err == nil, &ForkDatabaseException{},
+				"block %d of the state database is neither in the block log nor in the reversible blocks database, replay blockchain", n)
+			b = r.GetBlock()
+		}
 
-	c.Head.SignedBlock = types.NewSignedBlock1(&genHeader.Header)
+		id := b.BlockID()
+		s := c.ForkDB.GetBlock(&id)
+		if s == nil {
+			s = c.ForkDB.AddSignedBlock(b, true)
+		}
+		if old := c.ForkDB.GetBlockInCurrentChainByNum(n); old != nil && old != s {
+			c.ForkDB.MarkInCurrentChain(old, false)
+		}
+		c.ForkDB.MarkInCurrentChain(s, true)
+		c.ForkDB.SetValidity(s, true)
+		head = s
+	}
 
-	c.ForkDB.SetHead(c.Head)
-	c.DB.SetRevision(int64(c.Head.BlockNum))
-	c.initializeDatabase()
+	EosAssert(head.BlockNum == revision, &ForkDatabaseException{},
+		"fork database is inconsistent with shared memory %d,%d", revision, head.BlockNum)
+	return head
 }
 
 func (c *Controller) initializeDatabase() {
@@ -1701,6 +1996,7 @@ func (c *Controller) initialize() {
 	for uint32(c.DB.Revision()) > c.Head.BlockNum {
 		c.DB.Undo()
 	}
+	c.flushDataBases()
 }
 
 func (c *Controller) clearExpiredInputTransactions() {
@@ -1791,18 +2087,19 @@ func (c *Controller) initConfig() *Controller {
 		DisableReplayOpts:       false,
 		ContractsConsole:        false,
 		AllowRamBillingInNotify: false,
-		//vmType:              common.DefaultConfig.DefaultWasmRuntime, //TODO
-		ReadMode:            SPECULATIVE,
-		BlockValidationMode: FULL,
-		Genesis:             types.NewGenesisState(),
-		ActorWhitelist:      *NewAccountNameSet(),
-		ActorBlacklist:      *NewAccountNameSet(),
-		ContractWhitelist:   *NewAccountNameSet(),
-		ContractBlacklist:   *NewAccountNameSet(),
-		ActionBlacklist:     *NewNamePairSet(),
-		KeyBlacklist:        *NewPublicKeySet(),
-		ResourceGreylist:    *NewAccountNameSet(),
-		TrustedProducers:    *NewAccountNameSet(),
+		VmType:                  wasmgo.Interpreter,
+		ReadMode:                SPECULATIVE,
+		BlockValidationMode:     FULL,
+		Genesis:                 types.NewGenesisState(),
+		ActorWhitelist:          *NewAccountNameSet(),
+		ActorBlacklist:          *NewAccountNameSet(),
+		ContractWhitelist:       *NewAccountNameSet(),
+		ContractBlacklist:       *NewAccountNameSet(),
+		ActionBlacklist:         *NewNamePairSet(),
+		KeyBlacklist:            *NewPublicKeySet(),
+		ResourceGreylist:        *NewAccountNameSet(),
+		TrustedProducers:        *NewAccountNameSet(),
+		ContractDebugAccounts:   *NewAccountNameSet(),
 	}
 	return c
 }
@@ -0,0 +1,65 @@
+package chain
+
+import (
+	"github.com/eosspark/eos-go/chain/types"
+	. "github.com/eosspark/eos-go/chain/types/generated_containers"
+	"github.com/eosspark/eos-go/common"
+	. "github.com/eosspark/eos-go/exception"
+	. "github.com/eosspark/eos-go/exception/try"
+)
+
+/**
+ *  DryRunTransaction executes trx in an undo session which is always rolled back, so that its trace,
+ *  resource usage and console output can be inspected without broadcasting it. The transaction runs on
+ *  the head state, plus the transactions of the pending block when there is one; otherwise a throwaway
+ *  pending block is started on the head. Nothing is recorded in the pending block and no signal is emitted.
+ *
+ *  @param skipSignatures - do not check the authorization of the transaction, so that view-like actions
+ *  can be called without signing them
+ *  @return the trace, its Except is set when the transaction failed
+ */
+func (c *Controller) DryRunTransaction(trx *types.TransactionMetadata, deadline common.TimePoint, skipSignatures bool) *types.TransactionTrace {
+	EosAssert(trx != nil && !trx.Implicit && !trx.Scheduled, &TransactionTypeException{}, "Implicit/Scheduled transaction not allowed")
+
+	if c.Pending == nil {
+		c.Pending = NewPendingState(c.DB)
+		c.Pending.PendingBlockState = types.NewBlockState2(&c.Head.BlockHeaderState, c.Head.Header.Timestamp.Next())
+		defer func() {
+			c.Pending = c.Pending.Reset()
+		}()
+	}
+
+	trxContext := NewTransactionContext(c, trx.Trx, trx.ID, common.Now())
+	defer trxContext.Undo()
+	trxContext.Deadline = deadline
+	trace := trxContext.Trace
+
+	Try(func() {
+		trxContext.InitForInputTrx(uint64(trx.PackedTrx.GetUnprunableSize()), uint64(trx.PackedTrx.GetPrunableSize()),
+			uint32(len(trx.Trx.Signatures)), false)
+		trxContext.Delay = common.Seconds(int64(trx.Trx.DelaySec))
+		if !skipSignatures && !c.SkipAuthCheck() {
+			checkTime := func() {}
+			c.Authorization.CheckAuthorization(trx.Trx.Actions,
+				trx.RecoverKeys(&c.ChainID),
+				NewPermissionLevelSet(),
+				trxContext.Delay,
+				&checkTime,
+				false)
+		}
+		trxContext.Exec()
+		trxContext.Finalize()
+
+		trace.Receipt.Status = types.TransactionStatusExecuted
+		if trxContext.Delay != common.Microseconds(0) {
+			trace.Receipt.Status = types.TransactionStatusDelayed
+		}
+		trace.Receipt.CpuUsageUs = uint32(trxContext.BilledCpuTimeUs)
+		trace.Receipt.NetUsageWords = common.Vuint32(trace.NetUsage / 8)
+	}).Catch(func(ex Exception) {
+		trace.Except = ex
+		trace.ExceptPtr = ex
+	}).End()
+
+	return trace
+}
@@ -192,7 +192,8 @@ func applyEosioSetcode(context *ApplyContext) {
 	var codeId *crypto.Sha256
 	if len(act.Code) > 0 {
 		codeId = crypto.Hash256(act.Code)
-		wasmgo.Validate(context.Control.IsProducingBlock(), act.Code)
+		err := wasmgo.Validate(!context.Control.IsProducingBlock(), act.Code)
+		EosAssert(err == nil, &WasmSerializationError{}, "%v", err)
 	}
 
 	accountObject := entity.AccountObject{Name: act.Account}
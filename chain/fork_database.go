@@ -5,9 +5,11 @@ import (
 	"github.com/eosspark/eos-go/chain/types"
 	"github.com/eosspark/eos-go/chain/types/generated_containers/forkdb_multi_index"
 	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto"
 	"github.com/eosspark/eos-go/crypto/rlp"
 	. "github.com/eosspark/eos-go/exception"
 	. "github.com/eosspark/eos-go/exception/try"
+	"github.com/eosspark/eos-go/log"
 	"github.com/eosspark/eos-go/plugins/appbase/app/include"
 	"io/ioutil"
 	"os"
@@ -19,54 +21,102 @@ type ForkDatabase struct {
 	Head       *types.BlockState `json:"head"`
 	dataDir    string
 	fileStream *os.File
+	checkpoint bool
 
 	Irreversible include.Signal
 }
 
-func NewForkDatabase(dataDir string) *ForkDatabase {
+const forkDbVersion = uint32(1)
+
+/**
+ *  forkDbFile is the layout of the fork database file, the payload holds the number of block states,
+ *  the block states and the head block id. The checksum is the hash of the payload.
+ */
+type forkDbFile struct {
+	Version  uint32
+	Checksum crypto.Sha256
+	Payload  []byte
+}
+
+/**
+ *  @param dataDir - the directory of the fork database file
+ *  @param checkpoint - when set, Checkpoint() writes the fork database to disk so that it survives a crash,
+ *  the controller calls it once per block. Otherwise it is only written by Close().
+ */
+func NewForkDatabase(dataDir string, checkpoint bool) *ForkDatabase {
 	f := &ForkDatabase{Index: forkdb_multi_index.NewMultiIndex()}
 	f.dataDir = dataDir
+	f.checkpoint = checkpoint
 
 	if !common.FileExist(dataDir) {
 		os.MkdirAll(dataDir, os.ModePerm)
 	}
 
+	// the previous checkpoint is only one block behind, the controller catches up from it
 	forkDbDat := f.dataDir + "/" + common.DefaultConfig.ForkDbName
-	if common.FileExist(forkDbDat) {
-		content, err := ioutil.ReadFile(forkDbDat)
-		Throw(err)
-
-		decode := rlp.NewDecoder(content)
-		var size uint
-		decode.Decode(&size)
-
-		for i := uint(0); i < size; i++ {
-			s := types.BlockState{}
-			decode.Decode(&s)
-			f.SetHead(&s)
+	for _, file := range []string{forkDbDat, forkDbDat + ".prev"} {
+		if f.Head != nil || !common.FileExist(file) {
+			continue
 		}
+		Try(func() {
+			f.load(file)
+		}).Catch(func(e Exception) {
+			log.Error("fork database %s is corrupted: %s", file, e.DetailMessage())
+			f.Index.Clear()
+			f.Head = nil
+			Throw(os.Rename(file, file+".corrupted"))
+		}).End()
+	}
 
-		headId := common.BlockIdType{}
-		decode.Decode(&headId)
-
-		f.Head = f.GetBlock(&headId)
-		err = os.Remove(forkDbDat)
-		Throw(err)
+	if !f.checkpoint {
+		for _, file := range []string{forkDbDat, forkDbDat + ".prev"} {
+			if common.FileExist(file) {
+				Throw(os.Remove(file))
+			}
+		}
 	}
 
 	return f
 }
 
-func (f *ForkDatabase) Close() {
-	if f.Index.Size() == 0 {
-		return
+func (f *ForkDatabase) load(forkDbDat string) {
+	content, err := ioutil.ReadFile(forkDbDat)
+	Throw(err)
+
+	file := forkDbFile{}
+	err = rlp.DecodeBytes(content, &file)
+	EosAssert(err == nil, &ForkDatabaseException{}, "unable to decode fork database file: %s", err)
+	EosAssert(file.Version == forkDbVersion, &ForkDatabaseException{}, "unsupported fork database version %d", file.Version)
+	EosAssert(*crypto.Hash256(file.Payload) == file.Checksum, &ForkDatabaseException{}, "fork database checksum mismatch")
+
+	decode := rlp.NewDecoder(file.Payload)
+	var size uint
+	err = decode.Decode(&size)
+	EosAssert(err == nil, &ForkDatabaseException{}, "unable to decode fork database size: %s", err)
+
+	for i := uint(0); i < size; i++ {
+		s := types.BlockState{}
+		err = decode.Decode(&s)
+		EosAssert(err == nil, &ForkDatabaseException{}, "unable to decode block state %d: %s", i, err)
+		f.SetHead(&s)
 	}
 
-	forkDbDat := f.dataDir + "/" + common.DefaultConfig.ForkDbName
-	file, err := os.OpenFile(forkDbDat, os.O_RDWR|os.O_CREATE|os.O_TRUNC, os.ModePerm)
-	Throw(err)
+	headId := common.BlockIdType{}
+	err = decode.Decode(&headId)
+	EosAssert(err == nil, &ForkDatabaseException{}, "unable to decode fork database head: %s", err)
 
-	out := rlp.NewEncoder(file)
+	f.Head = f.GetBlock(&headId)
+	EosAssert(f.Head != nil, &ForkDatabaseException{}, "fork database head %s is not in the fork database", headId)
+}
+
+/**
+ *  write the fork database to a temporary file which then replaces the fork database file,
+ *  a crash leaves either the previous or the new file on disk but never a partial one.
+ *  The previous file is kept as well, in case the new one gets corrupted.
+ */
+func (f *ForkDatabase) write() {
+	payload := bytes.NewBuffer(nil)
+	out := rlp.NewEncoder(payload)
 
 	numBlockInForkDB := uint(f.Index.Size())
 	out.Encode(numBlockInForkDB)
@@ -81,6 +131,44 @@ func (f *ForkDatabase) Close() {
 		out.Encode(common.BlockIdType{})
 	}
 
+	file := forkDbFile{Version: forkDbVersion, Payload: payload.Bytes()}
+	file.Checksum = *crypto.Hash256(file.Payload)
+	content, err := rlp.EncodeToBytes(&file)
+	Throw(err)
+
+	forkDbDat := f.dataDir + "/" + common.DefaultConfig.ForkDbName
+	tmp, err := os.OpenFile(forkDbDat+".tmp", os.O_RDWR|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	Throw(err)
+	_, err = tmp.Write(content)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	tmp.Close()
+	Throw(err)
+
+	if common.FileExist(forkDbDat) {
+		Throw(os.Rename(forkDbDat, forkDbDat+".prev"))
+	}
+	Throw(os.Rename(forkDbDat+".tmp", forkDbDat))
+}
+
+/**
+ *  Checkpoint writes the fork database to disk when checkpoints are enabled. It is called once the
+ *  changes of a block are complete, writing each change would write the whole database many times per block.
+ */
+func (f *ForkDatabase) Checkpoint() {
+	if f.checkpoint && f.Index.Size() > 0 {
+		f.write()
+	}
+}
+
+func (f *ForkDatabase) Close() {
+	if f.Index.Size() == 0 {
+		return
+	}
+
+	f.write()
+
 	// we don't normally indicate the head block as irreversible
 	// we cannot normally prune the lib if it is the head block because
 	// the next block needs to build off of the head block. We are exiting
@@ -0,0 +1,53 @@
+package chain
+
+import (
+	. "github.com/eosspark/eos-go/chain/types/generated_containers"
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/database"
+	"github.com/eosspark/eos-go/entity"
+	. "github.com/eosspark/eos-go/exception"
+	. "github.com/eosspark/eos-go/exception/try"
+)
+
+// StateView is a read only view of the chain state pinned at a block, it does not change while
+// blocks and transactions are applied, so it can be queried from another goroutine
+type StateView struct {
+	DB             database.DataBase
+	BlockNum       uint32
+	BlockTime      common.TimePoint
+	ResourceLimits *ResourceLimitsManager
+	greylist       *AccountNameSet
+}
+
+// NewStateView pins the state at the head block, or at the last irreversible one.
+// It must be called from the main thread, the view has to be closed after use
+func (c *Controller) NewStateView(irreversible bool) *StateView {
+	blockNum, blockTime := c.HeadBlockNum(), c.HeadBlockTime()
+	if irreversible {
+		blockNum = c.LastIrreversibleBlockNum()
+		if block := c.FetchBlockByNumber(blockNum); block != nil {
+			blockTime = block.Timestamp.ToTimePoint()
+		}
+	}
+
+	db, err := c.DB.ReadView(int64(blockNum))
+	EosAssert(err == nil, &DatabaseException{}, "state view of block %d: %s", blockNum, err)
+
+	return &StateView{DB: db, BlockNum: blockNum, BlockTime: blockTime, ResourceLimits: &ResourceLimitsManager{db: db},
+		greylist: CopyFromAccountNameSet(&c.Config.ResourceGreylist)}
+}
+
+func (v *StateView) GetAccount(name common.AccountName) *entity.AccountObject {
+	accountObj := entity.AccountObject{Name: name}
+	err := v.DB.Find("byName", accountObj, &accountObj)
+	EosAssert(err == nil, &AccountQueryException{}, "Fail to retrieve account for %s", name)
+	return &accountObj
+}
+
+func (v *StateView) IsResourceGreylisted(name common.AccountName) bool {
+	return v.greylist.Contains(name)
+}
+
+func (v *StateView) Close() {
+	v.DB.Close()
+}
@@ -0,0 +1,137 @@
+package types
+
+import (
+	. "github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto/ecc"
+	. "github.com/eosspark/eos-go/exception"
+	. "github.com/eosspark/eos-go/exception/try"
+)
+
+// how the weight of a permission was obtained while explaining it
+const (
+	PermissionProvided       = "provided"        // satisfied by a provided permission, not evaluated
+	PermissionEvaluated      = "evaluated"       // its authority was evaluated
+	PermissionNotFound       = "not_found"       // it does not exist, it never counts
+	PermissionDepthExceeded  = "depth_exceeded"  // it is deeper than the recursion depth limit, it never counts
+	PermissionBeingEvaluated = "being_evaluated" // it is one of its own authorizers, the cycle never counts
+)
+
+type KeyWeightExplanation struct {
+	Key       ecc.PublicKey `json:"key"`
+	Weight    WeightType    `json:"weight"`
+	Satisfied bool          `json:"satisfied"`
+}
+
+type WaitWeightExplanation struct {
+	WaitSec   uint32     `json:"wait_sec"`
+	Weight    WeightType `json:"weight"`
+	Satisfied bool       `json:"satisfied"`
+}
+
+type PermissionLevelWeightExplanation struct {
+	Weight WeightType `json:"weight"`
+	PermissionExplanation
+}
+
+/**
+ * A PermissionExplanation is the evaluation tree of a permission by an AuthorityChecker.
+ *
+ * Unlike the checker, which stops as soon as the threshold is reached, every weight of the
+ * authority is evaluated, so TotalWeight is the weight of everything that was satisfied.
+ */
+type PermissionExplanation struct {
+	Permission  PermissionLevel                    `json:"permission"`
+	Status      string                             `json:"status"`
+	Depth       uint16                             `json:"depth"`
+	Threshold   uint32                             `json:"threshold"`
+	TotalWeight uint32                             `json:"total_weight"`
+	Satisfied   bool                               `json:"satisfied"`
+	Keys        []KeyWeightExplanation             `json:"keys"`
+	Accounts    []PermissionLevelWeightExplanation `json:"accounts"`
+	Waits       []WaitWeightExplanation            `json:"waits"`
+}
+
+/**
+ * explain how permission is evaluated against the provided keys, permissions and delay of the checker.
+ * The checker is left untouched, in particular no key is marked as used.
+ */
+func (ac *AuthorityChecker) Explain(permission *PermissionLevel) PermissionExplanation {
+	cachedPermissions := make(PermissionCacheType)
+	ac.initializePermissionCache(&cachedPermissions)
+	return ac.explainPermission(permission, cachedPermissions, 0)
+}
+
+func (ac *AuthorityChecker) explainPermission(permission *PermissionLevel, cachedPerms PermissionCacheType, depth uint16) PermissionExplanation {
+	explanation := PermissionExplanation{
+		Permission: *permission,
+		Depth:      depth,
+		Keys:       make([]KeyWeightExplanation, 0),
+		Accounts:   make([]PermissionLevelWeightExplanation, 0),
+		Waits:      make([]WaitWeightExplanation, 0),
+	}
+
+	switch ac.PermissionStatusInCache(cachedPerms, permission) {
+	case PermissionSatisfied:
+		explanation.Status = PermissionProvided
+		explanation.Satisfied = true
+		return explanation
+	case BeingEvaluated:
+		explanation.Status = PermissionBeingEvaluated
+		return explanation
+	}
+
+	if depth >= ac.RecursionDepthLimit {
+		explanation.Status = PermissionDepthExceeded
+		return explanation
+	}
+
+	var auth SharedAuthority
+	Try(func() {
+		auth = ac.permissionToAuthority(permission)
+	}).Catch(func(e *PermissionQueryException) {
+		auth = SharedAuthority{}
+	}).End()
+	if auth.Threshold == 0 {
+		explanation.Status = PermissionNotFound
+		return explanation
+	}
+
+	explanation.Status = PermissionEvaluated
+	explanation.Threshold = auth.Threshold
+
+	cachedPerms[*permission] = BeingEvaluated
+	defer delete(cachedPerms, *permission)
+
+	for _, kw := range auth.Keys {
+		satisfied := false
+		for _, key := range ac.ProvidedKeys {
+			if key.Compare(kw.Key) {
+				satisfied = true
+				break
+			}
+		}
+		if satisfied {
+			explanation.TotalWeight += uint32(kw.Weight)
+		}
+		explanation.Keys = append(explanation.Keys, KeyWeightExplanation{kw.Key, kw.Weight, satisfied})
+	}
+
+	for _, plw := range auth.Accounts {
+		sub := ac.explainPermission(&plw.Permission, cachedPerms, depth+1)
+		if sub.Satisfied {
+			explanation.TotalWeight += uint32(plw.Weight)
+		}
+		explanation.Accounts = append(explanation.Accounts, PermissionLevelWeightExplanation{plw.Weight, sub})
+	}
+
+	for _, ww := range auth.Waits {
+		satisfied := ac.ProvidedDelay >= Seconds(int64(ww.WaitSec))
+		if satisfied {
+			explanation.TotalWeight += uint32(ww.Weight)
+		}
+		explanation.Waits = append(explanation.Waits, WaitWeightExplanation{ww.WaitSec, ww.Weight, satisfied})
+	}
+
+	explanation.Satisfied = explanation.TotalWeight >= explanation.Threshold
+	return explanation
+}
@@ -1,7 +1,6 @@
 package types
 
 import (
-	"encoding/binary"
 	"encoding/json"
 	"github.com/eosspark/eos-go/common"
 	"github.com/eosspark/eos-go/crypto"
@@ -222,10 +221,7 @@ func (t *TransactionReceipt) Digest() common.DigestType {
 	enc := crypto.NewSha256()
 	status, _ := rlp.EncodeToBytes(t.Status)
 	cpuUsageUs, _ := rlp.EncodeToBytes(t.CpuUsageUs)
-	//netUsageWords, _ := rlp.EncodeToBytes(t.NetUsageWords)
-	buf := make([]byte, 8) //TODO t.NetUsageWords is a vuint32!!
-	l := binary.PutUvarint(buf, uint64(25))
-	netUsageWords := buf[:l]
+	netUsageWords, _ := rlp.EncodeToBytes(t.NetUsageWords)
 
 	enc.Write(status)
 	enc.Write(cpuUsageUs)
@@ -57,6 +57,10 @@ type HeaderConfirmation struct {
 	ProducerSignature ecc.Signature      `json:"producers_signature"`
 }
 
+func (h *HeaderConfirmation) BlockNum() uint32 {
+	return NumFromID(&h.BlockId)
+}
+
 func (b BlockHeader) IsEmpty() bool {
 	return b.Timestamp == 0 && b.Producer.IsEmpty() && b.Confirmed == 0 && b.Previous.IsEmpty() &&
 		b.TransactionMRoot.IsEmpty() && b.ActionMRoot.IsEmpty() && b.ScheduleVersion == 0 && b.NewProducers == nil &&
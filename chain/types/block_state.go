@@ -12,11 +12,12 @@ type BlockState struct {
 	Validated        bool         `json:"validated"`
 	InCurrentChain   bool         `json:"in_current_chain"`
 	Trxs             []*TransactionMetadata
+	ActionReceipts   []ActionReceipt `json:"-" eos:"-"`
 }
 
 func NewBlockState(cur *BlockHeaderState) *BlockState {
 	return &BlockState{*cur, &SignedBlock{},
-		false, false, make([]*TransactionMetadata, 0)}
+		false, false, make([]*TransactionMetadata, 0), nil}
 }
 
 func NewBlockState2(prev *BlockHeaderState, when BlockTimeStamp) *BlockState {
@@ -6,8 +6,8 @@ import (
 )
 
 type IncrementalMerkle struct {
-	NodeCount   uint64       `json:"node_count"`
 	ActiveNodes []DigestType `json:"active_nodes"`
+	NodeCount   uint64       `json:"node_count"`
 }
 
 /**
@@ -0,0 +1,238 @@
+package types
+
+import (
+	. "github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto"
+	"github.com/eosspark/eos-go/crypto/rlp"
+	. "github.com/eosspark/eos-go/exception"
+	. "github.com/eosspark/eos-go/exception/try"
+)
+
+/**
+ * A MerkleProof shows that Leaf is one of the digests a merkle root was computed from.
+ *
+ * Path holds the sibling of every node from the leaf up to the root. Each sibling is
+ * stored canonicalized for the side it is hashed on, so the proof carries no index:
+ * a canonical left sibling is hashed before the node, a canonical right one after it.
+ */
+type MerkleProof struct {
+	Leaf DigestType   `json:"leaf"`
+	Path []DigestType `json:"path"`
+}
+
+/**
+ * build the proof of ids[index] against Merkle(ids)
+ *
+ * @param ids - the leaves of the tree, left untouched
+ * @param index - the position of the proven leaf
+ * @return the proof
+ */
+func NewMerkleProof(ids []DigestType, index int) MerkleProof {
+	EosAssert(index >= 0 && index < len(ids), &OutOfRangeException{}, "leaf %d is out of the range of %d leaves", index, len(ids))
+
+	proof := MerkleProof{Leaf: ids[index], Path: make([]DigestType, 0, calculateMaxDepth(uint64(len(ids))))}
+	level := make([]DigestType, len(ids))
+	copy(level, ids)
+
+	for len(level) > 1 {
+		if len(level)%2 > 0 {
+			level = append(level, level[len(level)-1])
+		}
+
+		if index%2 == 0 {
+			proof.Path = append(proof.Path, makeCanonicalRight(level[index+1]))
+		} else {
+			proof.Path = append(proof.Path, makeCanonicalLeft(level[index-1]))
+		}
+
+		for i := 0; i < len(level)/2; i++ {
+			level[i] = *crypto.Hash256(makeCanonicalPair(level[2*i], level[(2*i)+1]))
+		}
+
+		level = level[:len(level)/2]
+		index /= 2
+	}
+
+	return proof
+}
+
+/**
+ * return the merkle root implied by the leaf and the path of the proof
+ */
+func (p MerkleProof) Root() DigestType {
+	node := p.Leaf
+	for _, sibling := range p.Path {
+		if isCanonicalLeft(sibling) {
+			node = *crypto.Hash256(makeCanonicalPair(sibling, node))
+		} else {
+			node = *crypto.Hash256(makeCanonicalPair(node, sibling))
+		}
+	}
+	return node
+}
+
+func (p MerkleProof) Verify(root DigestType) bool {
+	return p.Root() == root
+}
+
+// VerifyTransactionProof tells whether proof shows a transaction receipt digest is in the block of header
+func VerifyTransactionProof(header *BlockHeader, proof MerkleProof) bool {
+	return proof.Verify(header.TransactionMRoot)
+}
+
+// VerifyActionProof tells whether proof shows an action receipt digest is in the block of header
+func VerifyActionProof(header *BlockHeader, proof MerkleProof) bool {
+	return proof.Verify(header.ActionMRoot)
+}
+
+// VerifyBlockProof tells whether proof shows blockID is one of the blocks preceding the block of state,
+// whose blockroot_merkle is signed by its producer
+func VerifyBlockProof(state *BlockHeaderState, blockID BlockIdType, proof MerkleProof) bool {
+	return proof.Leaf == blockID && proof.Verify(state.BlockrootMerkle.GetRoot())
+}
+
+/**
+ * build the proof of leaf index against the merkle root of a tree of count leaves, without the tree
+ *
+ * @param count - the number of leaves of the tree
+ * @param index - the position of the proven leaf
+ * @param node - the root of the subtree of a level whose leftmost leaf is index<<level, as Merkle
+ *        computes it over the leaves of that subtree which are part of the tree
+ * @return the proof
+ */
+func NewMerkleProofFromNodes(count, index uint64, node func(level uint, index uint64) DigestType) MerkleProof {
+	EosAssert(index < count, &OutOfRangeException{}, "leaf %d is out of the range of %d leaves", index, count)
+
+	proof := MerkleProof{Leaf: node(0, index), Path: make([]DigestType, 0, calculateMaxDepth(count))}
+	current := proof.Leaf
+
+	for level, width := uint(0), count; width > 1; level, width = level+1, (width+1)/2 {
+		i := index >> level
+		if i%2 == 0 {
+			sibling := current
+			if i+1 < width {
+				sibling = node(level, i+1)
+			}
+			proof.Path = append(proof.Path, makeCanonicalRight(sibling))
+			current = MerkleParent(current, sibling)
+		} else {
+			sibling := node(level, i-1)
+			proof.Path = append(proof.Path, makeCanonicalLeft(sibling))
+			current = MerkleParent(sibling, current)
+		}
+	}
+
+	return proof
+}
+
+// MerkleParent is the node a merkle tree puts above left and right
+func MerkleParent(left, right DigestType) DigestType {
+	return *crypto.Hash256(makeCanonicalPair(left, right))
+}
+
+/**
+ * MerkleNodes keeps the roots of the complete subtrees of a growing merkle tree, from the subtrees of
+ * 1<<base leaves up. The leaves of the subtree being filled are kept until it is complete.
+ */
+type MerkleNodes struct {
+	base    uint
+	count   uint64
+	partial []DigestType
+	levels  [][]DigestType // levels[l][i] is the root of the leaves [i<<(base+l), (i+1)<<(base+l))
+}
+
+func NewMerkleNodes(base uint) *MerkleNodes {
+	return &MerkleNodes{base: base}
+}
+
+// merkleNodesLayout is how MerkleNodes are serialized
+type merkleNodesLayout struct {
+	Base    uint32
+	Count   uint64
+	Partial []DigestType
+	Levels  [][]DigestType
+}
+
+func (m MerkleNodes) Pack() ([]byte, error) {
+	return rlp.EncodeToBytes(merkleNodesLayout{Base: uint32(m.base), Count: m.count, Partial: m.partial, Levels: m.levels})
+}
+
+func (m *MerkleNodes) Unpack(in []byte) (int, error) {
+	layout := merkleNodesLayout{}
+	decoder := rlp.NewDecoder(in)
+	if err := decoder.Decode(&layout); err != nil {
+		return 0, err
+	}
+	m.base, m.count, m.partial, m.levels = uint(layout.Base), layout.Count, layout.Partial, layout.Levels
+	return decoder.GetPos(), nil
+}
+
+// Count is the number of leaves appended
+func (m *MerkleNodes) Count() uint64 {
+	return m.count
+}
+
+func (m *MerkleNodes) Append(leaf DigestType) {
+	m.partial = append(m.partial, leaf)
+	m.count++
+	if len(m.partial) < 1<<m.base {
+		return
+	}
+
+	node := Merkle(m.partial)
+	m.partial = m.partial[:0]
+	for l := 0; ; l++ {
+		if l == len(m.levels) {
+			m.levels = append(m.levels, nil)
+		}
+		m.levels[l] = append(m.levels[l], node)
+		n := len(m.levels[l])
+		if n%2 > 0 {
+			return
+		}
+		node = MerkleParent(m.levels[l][n-2], m.levels[l][n-1])
+	}
+}
+
+// Leaf returns an appended leaf if it belongs to the subtree being filled
+func (m *MerkleNodes) Leaf(index uint64) (DigestType, bool) {
+	first := m.count - uint64(len(m.partial))
+	if index < first || index >= m.count {
+		return DigestType{}, false
+	}
+	return m.partial[index-first], true
+}
+
+// Node returns the root of the complete subtree of a level whose leftmost leaf is index<<level, if it is kept
+func (m *MerkleNodes) Node(level uint, index uint64) (DigestType, bool) {
+	if level < m.base || int(level-m.base) >= len(m.levels) || index >= uint64(len(m.levels[level-m.base])) {
+		return DigestType{}, false
+	}
+	return m.levels[level-m.base][index], true
+}
+
+/**
+ * return the root of the subtree of a level whose leftmost leaf is index<<level, in the tree of the
+ * first count leaves, count may go past the appended ones
+ *
+ * @param leaf - the leaves which are neither appended nor part of a kept node
+ */
+func (m *MerkleNodes) Subtree(count uint64, level uint, index uint64, leaf func(index uint64) DigestType) DigestType {
+	if (index+1)<<level <= count {
+		if node, ok := m.Node(level, index); ok {
+			return node
+		}
+	}
+	if level == 0 {
+		if node, ok := m.Leaf(index); ok {
+			return node
+		}
+		return leaf(index)
+	}
+
+	left := m.Subtree(count, level-1, 2*index, leaf)
+	if (2*index+1)<<(level-1) >= count {
+		return MerkleParent(left, left)
+	}
+	return MerkleParent(left, m.Subtree(count, level-1, 2*index+1, leaf))
+}
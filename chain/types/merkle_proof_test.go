@@ -0,0 +1,128 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto"
+	"github.com/eosspark/eos-go/crypto/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func leaves(n int) []DigestType {
+	ids := make([]DigestType, n)
+	for i := range ids {
+		ids[i] = *crypto.Hash256(fmt.Sprintf("leaf-%d", i))
+	}
+	return ids
+}
+
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n <= 33; n++ {
+		ids := leaves(n)
+		root := Merkle(leaves(n))
+
+		for i := 0; i < n; i++ {
+			proof := NewMerkleProof(ids, i)
+			assert.Equal(t, ids[i], proof.Leaf)
+			assert.Equal(t, calculateMaxDepth(uint64(n))-1, len(proof.Path), "%d leaves", n)
+			assert.True(t, proof.Verify(root), "leaf %d of %d", i, n)
+		}
+		assert.Equal(t, leaves(n), ids, "the leaves must be left untouched")
+	}
+}
+
+func TestMerkleProofRejected(t *testing.T) {
+	ids := leaves(7)
+	root := Merkle(leaves(7))
+	proof := NewMerkleProof(ids, 3)
+
+	forged := proof
+	forged.Leaf = ids[4]
+	assert.False(t, forged.Verify(root))
+
+	/* flipping the side of a sibling changes the root */
+	forged = MerkleProof{Leaf: proof.Leaf, Path: append([]DigestType{}, proof.Path...)}
+	forged.Path[1] = makeCanonicalRight(forged.Path[1])
+	assert.False(t, forged.Verify(root))
+
+	forged = MerkleProof{Leaf: proof.Leaf, Path: proof.Path[:2]}
+	assert.False(t, forged.Verify(root))
+}
+
+func TestMerkleProofHeader(t *testing.T) {
+	trxs, actions := leaves(5), leaves(9)
+	header := BlockHeader{TransactionMRoot: Merkle(leaves(5)), ActionMRoot: Merkle(leaves(9))}
+
+	assert.True(t, VerifyTransactionProof(&header, NewMerkleProof(trxs, 2)))
+	assert.True(t, VerifyActionProof(&header, NewMerkleProof(actions, 8)))
+	assert.False(t, VerifyActionProof(&header, NewMerkleProof(trxs, 2)))
+}
+
+func TestMerkleProofBlockroot(t *testing.T) {
+	ids := leaves(20)
+	state := BlockHeaderState{}
+	for _, id := range ids {
+		state.BlockrootMerkle.Append(id)
+	}
+
+	for i, id := range ids {
+		assert.True(t, VerifyBlockProof(&state, BlockIdType(id), NewMerkleProof(ids, i)))
+	}
+	assert.False(t, VerifyBlockProof(&state, BlockIdType(ids[1]), NewMerkleProof(ids, 0)))
+}
+
+func TestMerkleProofFromNodes(t *testing.T) {
+	const base = 2
+	for n := 1; n <= 40; n++ {
+		ids := leaves(n + 5)
+
+		/* trees of fewer, as many and more leaves than the nodes were appended from */
+		for _, appended := range []int{n - n/3, n, n + 5} {
+			nodes := NewMerkleNodes(base)
+			for _, id := range ids[:appended] {
+				nodes.Append(id)
+			}
+			assert.Equal(t, uint64(appended), nodes.Count())
+
+			for i := 0; i < n; i++ {
+				reads := 0
+				leaf := func(index uint64) DigestType {
+					reads++
+					return ids[index]
+				}
+				proof := NewMerkleProofFromNodes(uint64(n), uint64(i), func(level uint, index uint64) DigestType {
+					return nodes.Subtree(uint64(n), level, index, leaf)
+				})
+
+				assert.Equal(t, NewMerkleProof(ids[:n], i), proof, "leaf %d of %d, %d appended", i, n, appended)
+				/* the subtree of the leaf and the one cut by the end of the tree at most */
+				if appended >= n {
+					assert.True(t, reads <= 2<<base, "leaf %d of %d read %d leaves", i, n, reads)
+				}
+			}
+		}
+	}
+}
+
+func TestMerkleNodesPack(t *testing.T) {
+	ids := leaves(27)
+	nodes := NewMerkleNodes(2)
+	for _, id := range ids[:22] {
+		nodes.Append(id)
+	}
+
+	data, err := rlp.EncodeToBytes(nodes)
+	assert.NoError(t, err)
+	unpacked := MerkleNodes{}
+	assert.NoError(t, rlp.DecodeBytes(data, &unpacked))
+	assert.Equal(t, nodes.Count(), unpacked.Count())
+
+	/* the unpacked nodes keep growing as the packed ones */
+	for _, id := range ids[22:] {
+		nodes.Append(id)
+		unpacked.Append(id)
+	}
+	assert.Equal(t, *nodes, unpacked)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/eosspark/eos-go/crypto/rlp"
 	. "github.com/eosspark/eos-go/exception"
 	. "github.com/eosspark/eos-go/exception/try"
+	"io"
 	"io/ioutil"
 )
 
@@ -307,8 +308,9 @@ func (p *PackedTransaction) SetTransactionWithCFD(t *SignedTransaction, cfd *[]c
 		default:
 			EosThrow(&UnknownTransactionCompression{}, "Unknown transaction compression algorithm")
 		}
-	}).FcCaptureAndRethrow(compression, t).End()
+	}).FcCaptureAndRethrow("compression:%s", compression).End()
 
+	p.UnpackedTrx = nil
 	p.Compression = compression
 }
 func (p *PackedTransaction) GetUnprunableSize() (size uint32) {
@@ -351,11 +353,11 @@ func (p *PackedTransaction) GetRawTransaction() common.HexBytes {
 		case CompressionNone:
 			out = p.PackedTrx
 		case CompressionZlib:
-			out = zlibDecompress(&p.PackedTrx)
+			out = zlibDecompress(&p.PackedTrx, common.DefaultConfig.MaxDecompressedTrxSize)
 		default:
 			EosThrow(&UnknownTransactionCompression{}, "Unknown transaction compression algorithm")
 		}
-	}).FcCaptureAndRethrow(p.Compression, p.PackedTrx).End()
+	}).FcCaptureAndRethrow("compression:%s", p.Compression).End()
 	return out
 }
 
@@ -370,7 +372,7 @@ func (p *PackedTransaction) GetContextFreeData() []common.HexBytes {
 		default:
 			EosThrow(&UnknownTransactionCompression{}, "Unknown transaction compression algorithm")
 		}
-	}).FcCaptureAndRethrow(p.Compression, p.PackedContextFreeData).End()
+	}).FcCaptureAndRethrow("compression:%s", p.Compression).End()
 	return out
 }
 
@@ -385,10 +387,7 @@ func (p *PackedTransaction) ID() common.TransactionIdType {
 }
 
 func (p *PackedTransaction) GetUncachedID() common.TransactionIdType {
-	raw := p.GetRawTransaction()
-	tx := Transaction{}
-	rlp.DecodeBytes([]byte(raw), &tx)
-	return tx.ID()
+	return unpackTransaction(p.GetRawTransaction()).ID()
 }
 
 func (p *PackedTransaction) localUnpack() {
@@ -402,7 +401,7 @@ func (p *PackedTransaction) localUnpack() {
 			default:
 				EosThrow(&UnknownTransactionCompression{}, "Unknown transaction compression algorithm")
 			}
-		}).FcCaptureAndRethrow(p.Compression, p.PackedTrx).End()
+		}).FcCaptureAndRethrow("compression:%s", p.Compression).End()
 	}
 }
 
@@ -421,7 +420,7 @@ func (p *PackedTransaction) GetSignedTransaction() (signedTrx *SignedTransaction
 		default:
 			EosThrow(&UnknownTransactionCompression{}, "Unknown transaction compression algorithm")
 		}
-	}).FcCaptureAndRethrow(p.Compression, p.PackedTrx, p.PackedContextFreeData).End()
+	}).FcCaptureAndRethrow("compression:%s", p.Compression).End()
 	return
 }
 
@@ -435,9 +434,10 @@ func (p *PackedTransaction) SetTransaction(t *Transaction, compression Compressi
 		default:
 			EosThrow(&UnknownTransactionCompression{}, "Unknown transaction compression algorithm")
 		}
-	}).FcCaptureAndRethrow(compression, t).End()
+	}).FcCaptureAndRethrow("compression:%s", compression).End()
 
-	p.PackedContextFreeData = nil //TODO clear()
+	p.PackedContextFreeData = p.PackedContextFreeData[:0]
+	p.UnpackedTrx = nil
 	p.Compression = compression
 }
 
@@ -446,21 +446,40 @@ func unpackContextFreeData(data *common.HexBytes) []common.HexBytes {
 	if len(*data) == 0 {
 		return out
 	}
-	rlp.DecodeBytes([]byte(*data), &out) //todo err?
+	err := rlp.DecodeBytes([]byte(*data), &out)
+	EosAssert(err == nil, &UnpackException{}, "Unable to unpack context free data: %s", err)
 	return out
 }
 func unpackTransaction(data common.HexBytes) *Transaction {
 	tx := Transaction{}
-	rlp.DecodeBytes(data, &tx)
+	err := rlp.DecodeBytes(data, &tx)
+	EosAssert(err == nil, &UnpackException{}, "Unable to unpack transaction: %s", err)
 	return &tx
 }
 
-func zlibDecompress(data *common.HexBytes) common.HexBytes { //TODO
-	in := bytes.NewReader(*data)
-	r, err := zlib.NewReader(in)
-	Throw(err)
-	result, _ := ioutil.ReadAll(r)
-	r.Close()
+// CheckDecompressedSize refuses a zlib compressed transaction or context free data which inflates past limit bytes.
+// It applies the local limit to the transactions received from the api or the peers, while the transactions of
+// the blocks are unpacked within the fixed DefaultConfig.MaxDecompressedTrxSize so that every node agrees on
+// the validity of a block.
+func (p *PackedTransaction) CheckDecompressedSize(limit uint64) {
+	if p.Compression != CompressionZlib {
+		return
+	}
+	zlibDecompress(&p.PackedTrx, limit)
+	if len(p.PackedContextFreeData) > 0 {
+		zlibDecompress(&p.PackedContextFreeData, limit)
+	}
+}
+
+// zlibDecompress inflates data, refusing to produce more than limit bytes
+func zlibDecompress(data *common.HexBytes, limit uint64) common.HexBytes {
+	r, err := zlib.NewReader(bytes.NewReader(*data))
+	EosAssert(err == nil, &TxDecompressionError{}, "Error decompressing transaction: %s", err)
+	defer r.Close()
+
+	result, err := ioutil.ReadAll(io.LimitReader(r, int64(limit)+1))
+	EosAssert(err == nil, &TxDecompressionError{}, "Error decompressing transaction: %s", err)
+	EosAssert(uint64(len(result)) <= limit, &TxDecompressionError{}, "Exceeded maximum decompressed transaction size")
 	return result
 }
 
@@ -468,12 +487,12 @@ func zlibDecompressContextFreeData(data *common.HexBytes) []common.HexBytes {
 	if len(*data) == 0 {
 		return []common.HexBytes{}
 	}
-	packedData := zlibDecompress(data)
+	packedData := zlibDecompress(data, common.DefaultConfig.MaxDecompressedTrxSize)
 	return unpackContextFreeData(&packedData)
 }
 
 func zlibDecompressTransaction(data *common.HexBytes) *Transaction {
-	packedTrax := zlibDecompress(data)
+	packedTrax := zlibDecompress(data, common.DefaultConfig.MaxDecompressedTrxSize)
 	return unpackTransaction(packedTrax)
 }
 
@@ -72,7 +72,7 @@ func TestTransactionID(t *testing.T) {
 	transactions := signedBlock.Transactions
 	for _, TrxReceipt := range transactions {
 
-		if TrxReceipt.Trx.TransactionID == common.TransactionIdType(*crypto.NewSha256Nil()) {
+		if TrxReceipt.Trx.TransactionID == common.TransactionIdType(crypto.NewSha256Nil()) {
 			packedTrx := TrxReceipt.Trx.PackedTransaction
 
 			//enc, _ := rlp.EncodeToBytes(packedTrx)
@@ -42,6 +42,7 @@ func init() {
 	}
 
 	DefaultConfig.FixedNetOverheadOfPackedTrx = 16
+	DefaultConfig.MaxDecompressedTrxSize = 1024 * 1024 // 1 MB, same as the read_limiter of the reference implementation
 
 	DefaultConfig.BlockIntervalMs = 500
 	DefaultConfig.BlockIntervalUs = 1000 * DefaultConfig.BlockIntervalMs
@@ -90,11 +91,13 @@ func init() {
 	DefaultConfig.HashingChecktimeBlockSize = 10 * 1024
 
 	DefaultConfig.ForkDbName = "forkdb.dat"
+	DefaultConfig.BlockMerkleName = "block_merkle.dat"
 	DefaultConfig.DBFileName = "shared_memory.bin"
 	DefaultConfig.ReversibleFileName = "shared_memory_tmp.bin" //wait db modify
 	DefaultConfig.BlockFileName = "blog.log"
 	DefaultConfig.DefaultBlocksDirName = "blocks"
 	DefaultConfig.DefaultReversibleBlocksDirName = "reversible"
+	DefaultConfig.DefaultActionReceiptsDirName = "action_receipts"
 	DefaultConfig.DefaultStateDirName = "state"
 
 	DefaultConfig.DefaultStateSize = 1 * 1024 * 1024 * 1024
@@ -137,6 +140,7 @@ type Config struct {
 	MaxProducers        int
 
 	FixedNetOverheadOfPackedTrx       uint32 //TODO: C++ default value 16 and is this reasonable?
+	MaxDecompressedTrxSize            uint64 ///< upper bound on the inflated size of zlib compressed packed_trx and packed_context_free_data, part of the consensus
 	FixedOverheadSharedVectorRamBytes uint32
 	OverheadPerRowPerIndexRamBytes    uint32 ///< overhead accounts for basic tracking structures in a row per index
 	OverheadPerAccountRamBytes        uint32 //= 2*1024; ///< overhead accounts for basic account storage and pre-pays features like account recovery
@@ -184,11 +188,13 @@ type Config struct {
 	/**************************chain_config end****************************/
 
 	ForkDbName                     string
+	BlockMerkleName                string
 	DBFileName                     string
 	ReversibleFileName             string
 	BlockFileName                  string
 	DefaultBlocksDirName           string
 	DefaultReversibleBlocksDirName string
+	DefaultActionReceiptsDirName   string
 	DefaultStateDirName            string
 	DefaultStateSize               uint64
 	DefaultStateGuardSize          uint64
@@ -5,26 +5,35 @@ const (
 )
 
 const (
-	ChainFuncBase           string = "/v1/chain"
-	GetInfoFunc             string = ChainFuncBase + "/get_info"
-	PushTxnFunc             string = ChainFuncBase + "/push_transaction"
-	PushTxnsFunc            string = ChainFuncBase + "/push_transactions"
-	JsonToBinFunc           string = ChainFuncBase + "/abi_json_to_bin"
-	GetBlockFunc            string = ChainFuncBase + "/get_block"
-	GetBlockHeaderStateFunc string = ChainFuncBase + "/get_block_header_state"
-	GetAccountFunc          string = ChainFuncBase + "/get_account"
-	GetTableFunc            string = ChainFuncBase + "/get_table_rows"
-	GetTableByScopeFunc     string = ChainFuncBase + "/get_table_by_scope"
-	GetCodeFunc             string = ChainFuncBase + "/get_code"
-	GetCodeHashFunc         string = ChainFuncBase + "/get_code_hash"
-	GetAbiFunc              string = ChainFuncBase + "/get_abi"
-	GetRawAbiFunc           string = ChainFuncBase + "/get_raw_abi"
-	GetRawCodeAndAbiFunc    string = ChainFuncBase + "/get_raw_code_and_abi"
-	GetCurrencyBalanceFunc  string = ChainFuncBase + "/get_currency_balance"
-	GetCurrencyStatsFunc    string = ChainFuncBase + "/get_currency_stats"
-	GetProducersFunc        string = ChainFuncBase + "/get_producers"
-	GetScheduleFunc         string = ChainFuncBase + "/get_producer_schedule"
-	GetRequiredKeys         string = ChainFuncBase + "/get_required_keys"
+	ChainFuncBase                string = "/v1/chain"
+	GetInfoFunc                  string = ChainFuncBase + "/get_info"
+	PushTxnFunc                  string = ChainFuncBase + "/push_transaction"
+	PushTxnsFunc                 string = ChainFuncBase + "/push_transactions"
+	SendTxnsFunc                 string = ChainFuncBase + "/send_transactions"
+	JsonToBinFunc                string = ChainFuncBase + "/abi_json_to_bin"
+	GetBlockFunc                 string = ChainFuncBase + "/get_block"
+	GetBlockHeaderStateFunc      string = ChainFuncBase + "/get_block_header_state"
+	GetBlockHeaderFunc           string = ChainFuncBase + "/get_block_header"
+	GetAccountFunc               string = ChainFuncBase + "/get_account"
+	GetTableFunc                 string = ChainFuncBase + "/get_table_rows"
+	GetTableByScopeFunc          string = ChainFuncBase + "/get_table_by_scope"
+	GetCodeFunc                  string = ChainFuncBase + "/get_code"
+	GetCodeHashFunc              string = ChainFuncBase + "/get_code_hash"
+	GetAbiFunc                   string = ChainFuncBase + "/get_abi"
+	GetRawAbiFunc                string = ChainFuncBase + "/get_raw_abi"
+	GetRawCodeAndAbiFunc         string = ChainFuncBase + "/get_raw_code_and_abi"
+	GetCurrencyBalanceFunc       string = ChainFuncBase + "/get_currency_balance"
+	GetCurrencyStatsFunc         string = ChainFuncBase + "/get_currency_stats"
+	GetProducersFunc             string = ChainFuncBase + "/get_producers"
+	GetScheduleFunc              string = ChainFuncBase + "/get_producer_schedule"
+	GetScheduledTransactionsFunc string = ChainFuncBase + "/get_scheduled_transactions"
+	GetRequiredKeys              string = ChainFuncBase + "/get_required_keys"
+	GetAccountsByAuthorizersFunc string = ChainFuncBase + "/get_accounts_by_authorizers"
+	GetTransactionProofFunc      string = ChainFuncBase + "/get_transaction_proof"
+	GetActionProofFunc           string = ChainFuncBase + "/get_action_proof"
+	GetBlockProofFunc            string = ChainFuncBase + "/get_block_proof"
+	DryRunTransactionFunc        string = ChainFuncBase + "/dry_run_transaction"
+	ExplainAuthorizationFunc     string = ChainFuncBase + "/explain_authorization"
 
 	HistoryFuncBase           string = "/v1/history"
 	GetActionsFunc            string = HistoryFuncBase + "/get_actions"
@@ -40,6 +49,9 @@ const (
 	NetDisconnect  string = NetFuncBase + "/disconnect"
 	NetStatus      string = NetFuncBase + "/status"
 	NetConnections string = NetFuncBase + "/connections"
+	NetBans        string = NetFuncBase + "/bans"
+	NetUnban       string = NetFuncBase + "/unban"
+	NetAddresses   string = NetFuncBase + "/addresses"
 
 	WalletFuncBase   string = "/v1/wallet"
 	WalletCreate     string = WalletFuncBase + "/create"
@@ -70,4 +82,8 @@ const (
 	ProducerSetWhitelistBlacklist  string = ProducerFuncBase + "/set_whitelist_blacklist"
 	ProducerGetIntegrityHash       string = ProducerFuncBase + "/get_integrity_hash"
 	ProducerCreateSnapshot         string = ProducerFuncBase + "/create_snapshot"
+	ProducerStartContractProfiler  string = ProducerFuncBase + "/start_contract_profiler"
+	ProducerStopContractProfiler   string = ProducerFuncBase + "/stop_contract_profiler"
+	ProducerGetContractProfile     string = ProducerFuncBase + "/get_contract_profile"
+	ProducerGetContractPprof       string = ProducerFuncBase + "/get_contract_pprof"
 )
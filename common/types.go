@@ -106,7 +106,7 @@ func (v Vuint32) Pack() ([]byte, error) {
 func (v *Vuint32) Unpack(in []byte) (l int, err error) {
 	re, l, err := ReadUvarint64(in)
 	if err != nil {
-		return 0, nil
+		return 0, err
 	}
 	*v = Vuint32(re)
 	return l, nil
@@ -120,7 +120,7 @@ func (v Vint32) Pack() ([]byte, error) {
 func (v *Vint32) Unpack(in []byte) (l int, err error) {
 	re, l, err := ReadVarint64(in)
 	if err != nil {
-		return 0, nil
+		return 0, err
 	}
 	*v = Vint32(re)
 	return l, nil
@@ -126,20 +126,22 @@ func WriteInt64(i int64) []byte {
 //	return e.toWriter(b)
 //}
 func WriteUVarInt(v int) []byte {
-	buf := make([]byte, 8)
+	buf := make([]byte, binary.MaxVarintLen64)
 	l := binary.PutUvarint(buf, uint64(v))
 	return buf[:l]
 }
 func WriteVarInt(v int) []byte {
-	buf := make([]byte, 8)
+	buf := make([]byte, binary.MaxVarintLen64)
 	l := binary.PutVarint(buf, int64(v))
 	return buf[:l]
 }
 
 func ReadUvarint64(in []byte) (uint64, int, error) {
 	l, read := binary.Uvarint(in)
-	if read < 0 {
+	if read == 0 {
 		return l, 0, fmt.Errorf("too short")
+	} else if read < 0 {
+		return l, 0, fmt.Errorf("overflow")
 	}
 
 	return l, read, nil
@@ -147,8 +149,10 @@ func ReadUvarint64(in []byte) (uint64, int, error) {
 
 func ReadVarint64(in []byte) (int64, int, error) {
 	l, read := binary.Varint(in)
-	if read < 0 {
+	if read == 0 {
 		return l, 0, fmt.Errorf("too short")
+	} else if read < 0 {
+		return l, 0, fmt.Errorf("overflow")
 	}
 
 	return l, read, nil
@@ -17,6 +17,7 @@ var (
 	ErrElemTooLarge     = errors.New("rlp: element is larger than containing list")
 	ErrValueTooLarge    = errors.New("rlp: value size exceeds available input length")
 	ErrVarIntBufferSize = errors.New("rlp: invalid buffer size")
+	ErrVarIntOverflow   = errors.New("rlp: varint overflows 64 bits")
 )
 
 var TypeSize = struct {
@@ -236,6 +237,9 @@ func (d *Decoder) Decode(v interface{}) (err error) {
 			return
 		}
 		rlplog.Warn("decode slice: length is %d, type: %s", l, rv.String())
+		if l > uint64(MAX_NUM_ARRAY_ELEMENT) || l > uint64(d.remaining()) {
+			return ErrElemTooLarge
+		}
 
 		rv.Set(reflect.MakeSlice(t, int(l), int(l)))
 		for i := 0; i < int(l); i++ {
@@ -285,15 +289,25 @@ func (d *Decoder) decodeStruct(v interface{}, t reflect.Type, rv reflect.Value)
 		case "-", "SVTag":
 			continue
 		case "optional":
-			isPresent, _ := d.ReadByte()
+			isPresent, err := d.ReadByte()
+			if err != nil {
+				return err
+			}
 			if isPresent == 0 {
 				//rlplog.Warn("Skipping optional OptionalProducerSchedule")
 				v = nil
 				continue
 			}
+		case "may_not_exist":
+			// binary extension, data packed by older versions ends before this field
+			if d.remaining() == 0 {
+				continue
+			}
 
 		case "trxID":
-			d.destaticVariantTag, _ = d.ReadByte()
+			if d.destaticVariantTag, err = d.ReadByte(); err != nil {
+				return err
+			}
 		case "tag0":
 			if d.destaticVariantTag != 1 {
 				continue
@@ -318,16 +332,20 @@ func (d *Decoder) decodeStruct(v interface{}, t reflect.Type, rv reflect.Value)
 
 func (d *Decoder) ReadUvarint64() (uint64, error) {
 	l, read := binary.Uvarint(d.data[d.pos:])
-	if read < 0 {
+	if read == 0 {
 		return l, ErrVarIntBufferSize
+	} else if read < 0 {
+		return l, ErrVarIntOverflow
 	}
 	d.pos += read
 	return l, nil
 }
 func (d *Decoder) ReadVarint64() (out int64, err error) {
 	l, read := binary.Varint(d.data[d.pos:])
-	if read < 0 {
+	if read == 0 {
 		return l, ErrVarIntBufferSize
+	} else if read < 0 {
+		return l, ErrVarIntOverflow
 	}
 	d.pos += read
 	return l, nil
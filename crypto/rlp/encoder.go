@@ -15,8 +15,6 @@ type Pack interface {
 	Pack() ([]byte, error)
 }
 
-var trxIsID bool
-
 const (
 	MAX_NUM_ARRAY_ELEMENT   = int(1024 * 1024)
 	MAX_SIZE_OF_BYTE_ARRAYS = int(20 * 1024 * 1024)
@@ -26,8 +24,9 @@ const (
 // Encoder implements the EOS packing, similar to FC_BUFFER
 // --------------------------------------------------------------
 type Encoder struct {
-	output io.Writer
-	count  int
+	output  io.Writer
+	count   int
+	trxIsID bool // static_variant<transaction_id_type, packed_transaction> holds the id
 }
 
 func NewEncoder(w io.Writer) *Encoder {
@@ -146,15 +145,15 @@ func (e *Encoder) Encode(v interface{}) (err error) {
 				e.writeBool(true)
 
 			case "tag0":
-				trxIsID = false
+				e.trxIsID = false
 				if rv.Field(i).IsNil() {
 					e.writeUint8(0)
-					trxIsID = true
+					e.trxIsID = true
 					continue
 				}
 				e.writeUint8(1)
 			case "tag1":
-				if !trxIsID {
+				if !e.trxIsID {
 					continue
 				}
 			}
@@ -200,12 +199,12 @@ func (e *Encoder) writeByteArray(b []byte) error {
 }
 
 func (e *Encoder) WriteUVarInt(v int) (err error) {
-	buf := make([]byte, 8)
+	buf := make([]byte, binary.MaxVarintLen64)
 	l := binary.PutUvarint(buf, uint64(v))
 	return e.toWriter(buf[:l])
 }
 func (e *Encoder) WriteVarInt(v int) (err error) {
-	buf := make([]byte, 8)
+	buf := make([]byte, binary.MaxVarintLen64)
 	l := binary.PutVarint(buf, int64(v))
 	return e.toWriter(buf[:l])
 }
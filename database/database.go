@@ -21,7 +21,7 @@ const (
 )
 
 type LDataBase struct {
-	db        *leveldb.DB
+	db        kvStore
 	stack     *deque
 	path      string
 	reversion int64
@@ -31,6 +31,15 @@ type LDataBase struct {
 	batch     *leveldb.Batch
 	count     int64
 	isClosed	bool
+	dirty     bool
+	readOnly  bool
+	flushed   flushedState
+}
+
+// flushedState is what the last Flush left in the store, read views of a committed snapshot start from it
+type flushedState struct {
+	reversion int64
+	stack     [][]byte
 }
 
 /*
@@ -54,16 +63,30 @@ func NewDataBase(path string, flag ...bool) (DataBase, error) {
 	if err != nil {
 		return nil, err
 	}
+	store, err := newLevelStore(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	/* a database which was not closed cleanly can not be trusted */
+	if readDirtyFromDb(store) {
+		store.Close()
+		return nil, ErrDirty
+	}
 	/*	read every type increment	*/
-	nextId, err := readIncrementFromDb(db)
+	nextId, err := readIncrementFromDb(store)
 	if err != nil {
 		log.Error("database init failed : %s", err.Error())
 		panic("open database file failed : " + err.Error())
 	}
 	/* read reversion */
-	reversion := readReversionFromDb(db)
+	reversion := readReversionFromDb(store)
 	/* read stack */
-	stack:=readUndoStackFromDb(db)
+	stack, stored := readUndoStackFromDb(store)
+	if last, ok := stack.Last().(*undoContainer); ok && last.Reversion != reversion {
+		store.Close()
+		return nil, ErrRevisionMismatch
+	}
 	logFlag := false
 	if len(flag) > 0 {
 		logFlag = flag[0]
@@ -76,63 +99,65 @@ func NewDataBase(path string, flag ...bool) (DataBase, error) {
 	} else {
 		dbLog.SetHandler(log.DiscardHandler())
 	}
-	return &LDataBase{db: db, stack:stack , path: path, nextId: nextId, logFlag: logFlag, log: dbLog, batch: new(leveldb.Batch),reversion:reversion}, nil
+	return &LDataBase{db: store, stack: stack, path: path, nextId: nextId, logFlag: logFlag, log: dbLog, batch: new(leveldb.Batch), reversion: reversion, flushed: flushedState{reversion, stored}}, nil
+}
+
+func readDirtyFromDb(db kvStore) bool {
+	_, err := db.Get([]byte(dbDirty))
+	return err == nil
 }
-func readUndoStackFromDb(db*leveldb.DB)(*deque){
+
+func readUndoStackFromDb(db kvStore) (*deque, [][]byte) {
 	key := []byte(undoKey)
-	val, err := db.Get(key, nil)
+	val, err := db.Get(key)
 	if err != nil && err != leveldb.ErrNotFound {
 		//throw
 
 	}
 	dq := newDeque()
-	if err == leveldb.ErrNotFound{
-		return dq
+	if err == leveldb.ErrNotFound {
+		return dq, nil
 	}
 	values := [][]byte{}
 	err = DecodeBytes(val, &values)
-	if err != nil{
+	if err != nil {
 		// throw
 	}
-	cons := []undoContainer{}
 
-	for index,_ := range values{
-		con := undoContainer{}
-		err = DecodeBytes(values[index],&con)
-		if err != nil{
+	/* stored from the oldest session to the newest */
+	for index, _ := range values {
+		con := storedUndoContainer{}
+		err = DecodeBytes(values[index], &con)
+		if err != nil {
 			//throw
 		}
-		cons = append(cons, con)
+		dq.Append(con.toContainer())
 	}
 
-	for index,_:= range cons{
-		dq.Append(&cons[index])
-	}
-
-	return  dq
+	return dq, values
 }
-func readReversionFromDb(db *leveldb.DB) (int64 ) {
+func readReversionFromDb(db kvStore) int64 {
 	key := []byte(dbReversion)
-	val, err := db.Get(key, nil)
+	val, err := db.Get(key)
 	if err != nil && err != leveldb.ErrNotFound {
 		//throw
 
 	}
-	if err == leveldb.ErrNotFound{
+	if err == leveldb.ErrNotFound {
 		return 0
 	}
 	var reversion int64
 	err = DecodeBytes(val, &reversion)
-	if err != nil{
+	if err != nil {
 		// throw
 	}
 	return reversion
 }
-func readIncrementFromDb(db *leveldb.DB) (map[string]int64, error) {
+func readIncrementFromDb(db kvStore) (map[string]int64, error) {
 	nextId := make(map[string]int64)
 
 	key := []byte(dbIncrement)
-	val, err := db.Get(key, nil)
+	val, err := db.Get(key)
 	if err != nil && err != leveldb.ErrNotFound {
 		return nil, err
 	}
@@ -142,20 +167,27 @@ func readIncrementFromDb(db *leveldb.DB) (map[string]int64, error) {
 			panic("database init failed : " + err.Error())
 		}
 	}
-	err = db.Delete(key, nil)
-	if err != nil {
-		panic("database init failed : " + err.Error())
-	}
 	return nextId, nil
 }
 
 func (ldb *LDataBase) Close() {
-	if ldb.isClosed{
+	if ldb.isClosed {
+		return
+	}
+	if ldb.readOnly {
+		ldb.db.Close()
+		ldb.isClosed = true
 		return
 	}
 	err := ldb.writeIncrementToDb()
 	if err != nil {
 		ldb.log.Error("database close failed : %s", err.Error())
+	} else {
+		/* everything is written, clear the dirty flag in the same commit */
+		err = ldb.writeDirty(false)
+		if err != nil {
+			ldb.log.Error("database close failed : %s", err.Error())
+		}
 	}
 	err = ldb.db.Close()
 	if err != nil {
@@ -167,65 +199,88 @@ func (ldb *LDataBase) Close() {
 	ldb.isClosed = true
 }
 
-func (ldb *LDataBase) writeIncrementToDb() error {
+/*
+*	Flush commits every change since the last Flush
+*	together with the increments, the reversion and the undo stack, as one atomic write.
+*	After a crash the database is found exactly as it was at the last Flush
+ */
 
-	if len(ldb.nextId) == 0 {
-		return nil
+func (ldb *LDataBase) Flush() error {
+	err := ldb.writeIncrementToDb()
+	if err != nil {
+		return err
 	}
+	return ldb.db.Commit()
+}
+
+func (ldb *LDataBase) writeDirty(dirty bool) error {
+	if dirty {
+		err := ldb.db.Put([]byte(dbDirty), []byte{1})
+		if err != nil {
+			return err
+		}
+	} else {
+		batch := new(leveldb.Batch)
+		batch.Delete([]byte(dbDirty))
+		err := ldb.db.Write(batch)
+		if err != nil {
+			return err
+		}
+	}
+	ldb.dirty = dirty
+	return nil
+}
+
+func (ldb *LDataBase) writeIncrementToDb() error {
 	// write next id
 	val, err := EncodeToBytes(ldb.nextId)
 	if err != nil {
 		ldb.log.Error("WriteIncrement rlp EncodeToBytes failed is : %s", err.Error())
 		return err
 	}
-	err = ldb.db.Put([]byte(dbIncrement), val, nil)
+	err = ldb.db.Put([]byte(dbIncrement), val)
 	if err != nil {
 		ldb.log.Error("WriteIncrement saveKey failed is : %s", err.Error())
 		return err
 	}
 	// write reversion
-	val,err = EncodeToBytes(ldb.reversion)
-	if err != nil{
+	val, err = EncodeToBytes(ldb.reversion)
+	if err != nil {
 		return err
 	}
-	err = ldb.db.Put([]byte(dbReversion),val,nil)
-	if err != nil{
+	err = ldb.db.Put([]byte(dbReversion), val)
+	if err != nil {
 		return err
 	}
 	// write undo stack
-	ldb.writeUndoStack()
-	return nil
+	return ldb.writeUndoStack()
 }
 
-
-func (ldb *LDataBase) undoStackToByte() [] byte{
-		u := ldb.getStack()
-		uv := undoContainer{}
-		uv = *u
-		val, err := EncodeToBytes(uv)
-		if err != nil{
-			return nil
-		}
-		return val
-}
-func (ldb*LDataBase)writeUndoStack(){
+func (ldb *LDataBase) writeUndoStack() error {
 	values := [][]byte{}
-	for ldb.stack.Size() > 0{
-		val := ldb.undoStackToByte()
-		values = append(values,val)
-		ldb.stack.Pop()
-	}
-	if len(values)	!= 0{
-		val ,err:= EncodeToBytes(values)
-		if err != nil{
-			// throw
-		}
-		err = ldb.db.Put([]byte(undoKey),val,nil)
-		if err != nil{
-			//throw
+	ldb.stack.RLock()
+	for e := ldb.stack.container.Front(); e != nil; e = e.Next() {
+		val, err := EncodeToBytes(e.Value.(*undoContainer).toStored())
+		if err != nil {
+			ldb.stack.RUnlock()
+			return err
 		}
+		values = append(values, val)
 	}
+	ldb.stack.RUnlock()
+
+	val, err := EncodeToBytes(values)
+	if err != nil {
+		return err
+	}
+	err = ldb.db.Put([]byte(undoKey), val)
+	if err != nil {
+		return err
+	}
+	ldb.flushed = flushedState{ldb.reversion, values}
+	return nil
 }
+
 func (ldb *LDataBase) Revision() int64 {
 	ldb.log.Info("ldb reversion is : %d", ldb.reversion)
 	return ldb.reversion
@@ -299,6 +354,16 @@ func (ldb *LDataBase) Squash() {
 }
 
 func (ldb *LDataBase) StartSession() *Session {
+	if !ldb.dirty {
+		/* mark the database before anything of the session can reach the disk */
+		err := ldb.writeDirty(true)
+		if err == nil {
+			err = ldb.Flush()
+		}
+		if err != nil {
+			ldb.log.Error("database set dirty flag failed : %s", err.Error())
+		}
+	}
 	ldb.reversion++
 	state := newUndoContainer(ldb.reversion, ldb.nextId)
 	ldb.stack.Append(state)
@@ -615,7 +680,7 @@ func (ldb *LDataBase) find(tagName string, value interface{}, to interface{}, sk
 	key = append(key, suffix...)
 
 	//ldb.log.Info("key is : %v", key)
-	it := ldb.db.NewIterator(util.BytesPrefix(key), nil)
+	it := ldb.db.NewIterator(util.BytesPrefix(key))
 
 	//fmt.Println("ErrorNotFound key is : %v", key)
 	//fmt.Println(it.Key(),"  ",it.Value())
@@ -627,7 +692,7 @@ func (ldb *LDataBase) find(tagName string, value interface{}, to interface{}, sk
 
 func (ldb *LDataBase) getAllKv(key []byte) {
 
-	it := ldb.db.NewIterator(nil, nil)
+	it := ldb.db.NewIterator(nil)
 	for it.Next() {
 		ldb.log.Info("key %v", it.Key(), "  value %v", it.Value())
 	}
@@ -693,7 +758,7 @@ func (ldb *LDataBase) GetMutableIndex(fieldName string, in interface{}) (*MultiI
 func (ldb *LDataBase) LowerBound(begin, end, fieldName []byte, data interface{}, skip ...SkipSuffix) (*DbIterator, error) {
 	key, typeName := ldb.dbPrefix(begin, fieldName, data, skip...)
 	//return ldb.dbIterator(key,begin,end,typeName,false)
-	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end}, nil)
+	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end})
 	if !it.Next() {
 		return nil, ErrNotFound
 	}
@@ -721,7 +786,7 @@ func (ldb *LDataBase) UpperBound(begin, end, fieldName []byte, data interface{},
 }
 
 func (ldb *LDataBase) dbIterator(key, begin, end, typeName []byte, upper bool) (*DbIterator, error) {
-	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end}, nil)
+	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end})
 	if !it.Next() {
 		return nil, ErrNotFound
 	}
@@ -745,7 +810,7 @@ func (ldb *LDataBase) EndIterator(begin, end, typeName []byte) (*DbIterator, err
 
 	ldb.log.Info("begin : %v, end : %v, typeName: %v", begin, end, typeName)
 
-	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end}, nil)
+	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end})
 	if !it.Next(){
 		// not found  --> iterator is nil  == end
 		itr := &DbIterator{it: nil, db: ldb.db, first: false, typeName: typeName, currentStatus: itEND}
@@ -790,7 +855,7 @@ func (ldb *LDataBase) dbPrefix(begin_, fieldName []byte, data interface{}, skip
 func (ldb *LDataBase) Empty(begin, end, fieldName []byte) bool {
 
 	ldb.log.Info("begin : %v, end : %v, fieldName: %v ", begin, end, fieldName)
-	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end}, nil)
+	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end})
 	defer it.Release()
 	if it.Next() {
 		return false
@@ -818,7 +883,7 @@ func (ldb *LDataBase) IteratorTo(begin, end, fieldName []byte, data interface{},
 	key := []byte{}
 	key = append(begin, prefix...)
 
-	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end}, nil)
+	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end})
 	if !it.Seek(key) {
 		ldb.log.Error("seek failed key is %v", key)
 		return nil, ErrNotFound
@@ -839,7 +904,7 @@ func (ldb *LDataBase) BeginIterator(begin, end, typeName []byte) (*DbIterator, e
 
 	ldb.log.Info("begin : %v, end : %v, typeName: %v ", begin, end, typeName)
 
-	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end}, nil)
+	it := ldb.db.NewIterator(&util.Range{Start: begin, Limit: end})
 	if !it.Next() {
 		ldb.log.Error("Next Failed")
 		return nil, ErrNotFound
@@ -885,7 +950,7 @@ func (ldb *LDataBase) deleteBatch(dbKV *dbKeyValue) {
 
 func (ldb *LDataBase) writeBatch() error {
 	if ldb.batch.Len() > 0 {
-		err := ldb.db.Write(ldb.batch, nil)
+		err := ldb.db.Write(ldb.batch)
 		if err != nil {
 			return err
 		}
@@ -971,8 +1036,8 @@ func (ldb *LDataBase) getStack() *undoContainer {
 	return nil
 }
 
-func getDbKey(key []byte, db *leveldb.DB) ([]byte, error) {
-	val, err := db.Get(key, nil)
+func getDbKey(key []byte, db kvStore) ([]byte, error) {
+	val, err := db.Get(key)
 	if err != nil {
 		return nil, err
 	}
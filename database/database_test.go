@@ -12,6 +12,19 @@ import (
 var logFlag = false
 //var logFlag = true
 
+// memBackend makes openDb return the in memory backend instead of leveldb
+var memBackend = false
+
+// TestMain runs the tests of the package against leveldb, then against the in memory backend, and fails if either run fails
+func TestMain(m *testing.M) {
+	code := m.Run()
+	memBackend = true
+	if memCode := m.Run(); code == 0 {
+		code = memCode
+	}
+	os.Exit(code)
+}
+
 func Test_rawDb(t *testing.T) {
 	cpuf, err := os.Create("cpu_profile")
 	if err != nil {
@@ -1548,7 +1561,13 @@ func openDb() (DataBase, func()) {
 		reFn()
 	}
 
-	db, err := NewDataBase(fileName, logFlag)
+	var db DataBase
+	var err error
+	if memBackend {
+		db, err = NewMemDataBase(logFlag)
+	} else {
+		db, err = NewDataBase(fileName, logFlag)
+	}
 	if err != nil {
 
 		log.Fatalln("new database failed : ", err)
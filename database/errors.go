@@ -21,4 +21,12 @@ var (
 	ErrPtrNeeded = errors.New("database : provided target must be a pointer to a valid variable")
 
 	ErrNotFound = errors.New("database not found")
+
+	ErrDirty = errors.New("database dirty flag set (likely due to unclean shutdown)")
+
+	ErrRevisionMismatch = errors.New("database revision does not match its undo stack")
+
+	ErrReadOnly = errors.New("database : read only view")
+
+	ErrRevisionNotAvailable = errors.New("database : revision is not kept by the undo stack")
 )
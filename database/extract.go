@@ -16,6 +16,7 @@ const (
 	dbIncrement  = "db_increment"
  undoKey = "undo_stack"
 	dbReversion = "db_reversion"
+	dbDirty     = "db_dirty"
 )
 
 /*
@@ -3,6 +3,10 @@ package database
 type DataBase interface {
 	Close()
 
+	Flush() error
+
+	ReadView(revision int64) (DataBase, error)
+
 	Insert(in interface{}) error
 
 	Find(tagName string, in interface{}, out interface{}, skip ...SkipSuffix) error
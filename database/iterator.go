@@ -1,7 +1,6 @@
 package database
 
 import (
-	"github.com/syndtr/goleveldb/leveldb"
 	"reflect"
 	"fmt"
 )
@@ -47,13 +46,13 @@ type DbIterator struct {
 	begin         []byte
 	currentStatus string
 	typeName      []byte
-	db            *leveldb.DB // TODO interface
+	db            kvStore
 	it            iterator
 	first         bool
 }
 
 //Do not use the functions in this file
-func newDbIterator(typeName []byte, it iterator, db *leveldb.DB) (*DbIterator, error) {
+func newDbIterator(typeName []byte, it iterator, db kvStore) (*DbIterator, error) {
 
 	idx := &DbIterator{typeName: typeName, it: it, db: db}
 
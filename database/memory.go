@@ -0,0 +1,218 @@
+package database
+
+import (
+	"sync"
+
+	"github.com/eosspark/eos-go/log"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	lvlIterator "github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const memInitialCapacity = 4 * 1024 * 1024
+
+var memCompactMinSize = 64 * 1024 * 1024 // buffer size below which dead entries are never reclaimed
+
+/*
+*	Create a database which lives in memory only
+*	it shares indices, iterators and sessions with the leveldb backed database
+*	nothing is kept once it is closed
+ */
+
+func NewMemDataBase(flag ...bool) (DataBase, error) {
+	logFlag := false
+	if len(flag) > 0 {
+		logFlag = flag[0]
+	}
+
+	dbLog := log.New("db")
+	if logFlag {
+		dbLog.SetHandler(log.TerminalHandler)
+	} else {
+		dbLog.SetHandler(log.DiscardHandler())
+	}
+
+	store := &memStore{db: memdb.New(comparer.DefaultComparer, memInitialCapacity), snaps: make(map[*memSnapshot]struct{})}
+	return &LDataBase{db: store, stack: newDeque(), nextId: make(map[string]int64), logFlag: logFlag, log: dbLog, batch: new(leveldb.Batch)}, nil
+}
+
+/*
+*	goleveldb skip list in memory
+*	snapshots are copy on write, before a key is written its previous value is handed to every open snapshot
+ */
+
+type memStore struct {
+	db    *memdb.DB
+	mu    sync.RWMutex // held for writing while the store changes, snapshots read under it
+	snaps map[*memSnapshot]struct{}
+}
+
+func (s *memStore) Get(key []byte) ([]byte, error) {
+	val, err := s.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return cloneByte(val), nil
+}
+
+func (s *memStore) Put(key, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.preserve(key)
+	return s.db.Put(key, value)
+}
+
+func (s *memStore) Write(batch *leveldb.Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.snaps) > 0 {
+		if err := batch.Replay(memPreserve{s}); err != nil {
+			return err
+		}
+	}
+	err := batch.Replay(memReplay{s.db})
+	if err != nil {
+		return err
+	}
+	s.compact()
+	return nil
+}
+
+// preserve hands the current value of key to the open snapshots which have not kept one yet
+func (s *memStore) preserve(key []byte) {
+	if len(s.snaps) == 0 {
+		return
+	}
+	old, err := s.db.Get(key)
+	for snap := range s.snaps {
+		if snap.prior.Contains(key) {
+			continue
+		}
+		if err == nil {
+			snap.prior.Put(key, append([]byte{overlayValue}, old...))
+		} else {
+			snap.prior.Put(key, []byte{overlayDeleted})
+		}
+	}
+}
+
+func (s *memStore) NewIterator(slice *util.Range) iterator {
+	return s.db.NewIterator(slice)
+}
+
+func (s *memStore) Commit() error {
+	return nil
+}
+
+// Snapshot sees the store as it is now, nothing is ever committed in memory
+func (s *memStore) Snapshot() (storeSnapshot, error) {
+	snap := &memSnapshot{store: s, prior: memdb.New(comparer.DefaultComparer, 0)}
+	s.mu.Lock()
+	s.snaps[snap] = struct{}{}
+	s.mu.Unlock()
+	return snap, nil
+}
+
+func (s *memStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.db.Reset()
+	return nil
+}
+
+/*
+*	memdb is append only, removed and modified entries keep their space.
+*	Once more than half of the buffer is dead the live entries are copied into a fresh one,
+*	iterators which are still open keep reading the old buffer.
+ */
+
+func (s *memStore) compact() {
+	used := s.db.Capacity() - s.db.Free()
+	if used < memCompactMinSize || s.db.Size() > used/2 {
+		return
+	}
+
+	fresh := memdb.New(comparer.DefaultComparer, s.db.Size()*2)
+	it := s.db.NewIterator(nil)
+	for it.Next() {
+		fresh.Put(it.Key(), it.Value())
+	}
+	it.Release()
+	s.db = fresh
+}
+
+// memSnapshot reads the store through the values preserved for it since it was taken
+type memSnapshot struct {
+	store *memStore
+	prior *memdb.DB
+}
+
+func (s *memSnapshot) Get(key []byte) ([]byte, error) {
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	val, err := s.prior.Get(key)
+	if err == nil {
+		if val[0] == overlayDeleted {
+			return nil, leveldb.ErrNotFound
+		}
+		return cloneByte(val[1:]), nil
+	}
+	val, err = s.store.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return cloneByte(val), nil
+}
+
+// NewIterator copies the entries of slice, so that the store may change while it is walked
+func (s *memSnapshot) NewIterator(slice *util.Range) lvlIterator.Iterator {
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	copied := memdb.New(comparer.DefaultComparer, 0)
+	it := &overlayIterator{base: s.store.db.NewIterator(slice), over: s.prior.NewIterator(slice)}
+	for it.Next() {
+		copied.Put(it.Key(), it.Value())
+	}
+	it.Release()
+	return copied.NewIterator(nil)
+}
+
+func (s *memSnapshot) live() bool {
+	return true
+}
+
+func (s *memSnapshot) Release() {
+	s.store.mu.Lock()
+	delete(s.store.snaps, s)
+	s.store.mu.Unlock()
+	s.prior.Reset()
+}
+
+type memPreserve struct {
+	store *memStore
+}
+
+func (r memPreserve) Put(key, value []byte) {
+	r.store.preserve(key)
+}
+
+func (r memPreserve) Delete(key []byte) {
+	r.store.preserve(key)
+}
+
+type memReplay struct {
+	db *memdb.DB
+}
+
+func (r memReplay) Put(key, value []byte) {
+	r.db.Put(key, value)
+}
+
+func (r memReplay) Delete(key []byte) {
+	r.db.Delete(key)
+}
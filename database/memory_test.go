@@ -0,0 +1,157 @@
+package database
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
+	"testing"
+)
+
+func Test_memFind(t *testing.T) {
+	db, err := NewMemDataBase()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	objs, houses := Objects()
+	objs_, houses_ := saveObjs(objs, houses, db)
+
+	findObjs(objs_, houses_, db)
+	findInLineFieldObjs(objs_, houses_, db)
+	getLessObjs(objs_, houses_, db)
+}
+
+func Test_memUndo(t *testing.T) {
+	db, err := NewMemDataBase()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	objs, _ := Objects()
+	db.SetRevision(10)
+
+	session := db.StartSession()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, db.Insert(&objs[i]))
+	}
+	assert.Equal(t, int64(11), db.Revision())
+
+	session.Undo()
+	assert.Equal(t, int64(10), db.Revision())
+
+	idx, err := db.GetIndex("Code", DbTableIdObject{})
+	assert.NoError(t, err)
+	assert.True(t, idx.Empty())
+
+	session = db.StartSession()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, db.Insert(&objs[i]))
+	}
+	inner := db.StartSession()
+	assert.NoError(t, db.Modify(&objs[0], func(obj *DbTableIdObject) {
+		obj.Count = 100
+	}))
+	assert.NoError(t, db.Remove(&objs[1]))
+	inner.Squash()
+
+	tmp := DbTableIdObject{}
+	assert.NoError(t, db.Find("id", DbTableIdObject{ID: objs[0].ID}, &tmp))
+	assert.Equal(t, uint32(100), tmp.Count)
+	assert.Equal(t, ErrNotFound, db.Find("id", DbTableIdObject{ID: objs[1].ID}, &tmp))
+
+	session.Undo()
+	assert.True(t, idx.Empty())
+}
+
+func Test_memRemoveWhileIterating(t *testing.T) {
+	db, err := NewMemDataBase()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	objs, houses := Objects()
+	saveObjs(objs, houses, db)
+
+	idx, err := db.GetIndex("Code", DbTableIdObject{})
+	assert.NoError(t, err)
+
+	count := 0
+	it := idx.Begin()
+	for !idx.CompareEnd(it) {
+		tmp := DbTableIdObject{}
+		assert.NoError(t, it.Data(&tmp))
+		it.Next()
+		assert.NoError(t, db.Remove(&tmp))
+		count++
+	}
+	assert.Equal(t, len(objs), count)
+	assert.True(t, idx.Empty())
+}
+
+func Test_memCompact(t *testing.T) {
+	minSize := memCompactMinSize
+	memCompactMinSize = 0
+	defer func() { memCompactMinSize = minSize }()
+
+	db, err := NewMemDataBase()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	objs, houses := Objects()
+	objs_, houses_ := saveObjs(objs, houses, db)
+
+	store := db.(*LDataBase).db.(*memStore)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, db.Modify(&objs_[0], func(obj *DbTableIdObject) {
+			obj.Count++
+		}))
+	}
+	used := store.db.Capacity() - store.db.Free()
+	assert.True(t, used <= 2*store.db.Size())
+
+	findObjs(objs_, houses_, db)
+	tmp := DbTableIdObject{}
+	assert.NoError(t, db.Find("id", DbTableIdObject{ID: objs_[0].ID}, &tmp))
+	assert.Equal(t, objs[0].Count+100, tmp.Count)
+}
+
+func Test_memSnapshot(t *testing.T) {
+	db, err := NewMemDataBase()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := db.(*LDataBase).db.(*memStore)
+	assert.NoError(t, store.Put([]byte("a"), []byte("1")))
+	assert.NoError(t, store.Put([]byte("b"), []byte("2")))
+
+	snap, err := store.Snapshot()
+	assert.NoError(t, err)
+
+	// the snapshot keeps the values it was taken with, nothing is copied up front
+	batch := new(leveldb.Batch)
+	batch.Put([]byte("a"), []byte("3"))
+	batch.Delete([]byte("b"))
+	batch.Put([]byte("c"), []byte("4"))
+	assert.NoError(t, store.Write(batch))
+	assert.NoError(t, store.Put([]byte("a"), []byte("5")))
+
+	val, err := snap.Get([]byte("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("1"), val)
+	val, err = snap.Get([]byte("b"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("2"), val)
+	_, err = snap.Get([]byte("c"))
+	assert.Equal(t, leveldb.ErrNotFound, err)
+
+	it := snap.NewIterator(nil)
+	assert.NoError(t, store.Put([]byte("d"), []byte("6")))
+	kvs := []string{}
+	for it.Next() {
+		kvs = append(kvs, string(it.Key())+"="+string(it.Value()))
+	}
+	it.Release()
+	assert.Equal(t, []string{"a=1", "b=2"}, kvs)
+
+	snap.Release()
+	assert.Equal(t, 0, len(store.snaps))
+	val, err = store.Get([]byte("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("5"), val)
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	lvlIterator "github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// kvStore is the ordered key value storage LDataBase keeps its objects and indices in.
+// Get returns leveldb.ErrNotFound for a missing key.
+type kvStore interface {
+	Get(key []byte) ([]byte, error)
+
+	Put(key, value []byte) error
+
+	Write(batch *leveldb.Batch) error
+
+	NewIterator(slice *util.Range) iterator
+
+	// Commit makes everything written since the last Commit durable as one atomic unit
+	Commit() error
+
+	Snapshot() (storeSnapshot, error)
+
+	Close() error
+}
+
+/*
+*	goleveldb on disk
+*	writes are gathered in a batch and kept readable in a memory overlay,
+*	nothing reaches the files until Commit writes the batch, a crash in between leaves the previous commit intact
+ */
+
+type levelStore struct {
+	db      *leveldb.DB
+	batch   *leveldb.Batch
+	pending *memdb.DB // the writes of batch, tagged like the overlays of the read views
+}
+
+func newLevelStore(db *leveldb.DB) (*levelStore, error) {
+	return &levelStore{db: db, batch: new(leveldb.Batch), pending: memdb.New(comparer.DefaultComparer, 0)}, nil
+}
+
+func (s *levelStore) Get(key []byte) ([]byte, error) {
+	val, err := s.pending.Get(key)
+	if err == nil {
+		if val[0] == overlayDeleted {
+			return nil, leveldb.ErrNotFound
+		}
+		return cloneByte(val[1:]), nil
+	}
+	return s.db.Get(key, nil)
+}
+
+func (s *levelStore) Put(key, value []byte) error {
+	s.batch.Put(key, value)
+	return s.pending.Put(key, append([]byte{overlayValue}, value...))
+}
+
+func (s *levelStore) Write(batch *leveldb.Batch) error {
+	if err := batch.Replay(s.batch); err != nil {
+		return err
+	}
+	return batch.Replay(overlayReplay{s.pending})
+}
+
+func (s *levelStore) NewIterator(slice *util.Range) iterator {
+	return &overlayIterator{base: s.db.NewIterator(slice, nil), over: s.pending.NewIterator(slice)}
+}
+
+func (s *levelStore) Commit() error {
+	if s.batch.Len() == 0 {
+		return nil
+	}
+	err := s.db.Write(s.batch, nil)
+	if err != nil {
+		return err
+	}
+	/* iterators still open keep the overlay they were created on */
+	s.batch = new(leveldb.Batch)
+	s.pending = memdb.New(comparer.DefaultComparer, 0)
+	return nil
+}
+
+// Snapshot sees the store as of the last Commit
+func (s *levelStore) Snapshot() (storeSnapshot, error) {
+	snap, err := s.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return levelSnapshot{snap}, nil
+}
+
+func (s *levelStore) Close() error {
+	err := s.Commit()
+	if err != nil {
+		s.db.Close()
+		return err
+	}
+	return s.db.Close()
+}
+
+type levelSnapshot struct {
+	snap *leveldb.Snapshot
+}
+
+func (s levelSnapshot) Get(key []byte) ([]byte, error) {
+	return s.snap.Get(key, nil)
+}
+
+func (s levelSnapshot) NewIterator(slice *util.Range) lvlIterator.Iterator {
+	return s.snap.NewIterator(slice, nil)
+}
+
+func (s levelSnapshot) live() bool {
+	return false
+}
+
+func (s levelSnapshot) Release() {
+	s.snap.Release()
+}
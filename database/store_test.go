@@ -0,0 +1,164 @@
+package database
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
+	"os"
+	"testing"
+)
+
+const crashDbPath = "./crash"
+
+func openCrashDb(t *testing.T) DataBase {
+	db, err := NewDataBase(crashDbPath)
+	assert.NoError(t, err)
+	return db
+}
+
+// crash drops everything which was not flushed and closes the files without a clean Close
+func crash(db DataBase) {
+	db.(*LDataBase).db.(*levelStore).db.Close()
+}
+
+func Test_flushAtomic(t *testing.T) {
+	os.RemoveAll(crashDbPath)
+	defer os.RemoveAll(crashDbPath)
+
+	objs, _ := Objects()
+	db := openCrashDb(t)
+	db.SetRevision(5)
+	assert.NoError(t, db.Insert(&objs[0]))
+	assert.NoError(t, db.Insert(&objs[1]))
+	assert.NoError(t, db.Flush())
+
+	assert.NoError(t, db.Insert(&objs[2]))
+	crash(db)
+
+	db = openCrashDb(t)
+	defer db.Close()
+	assert.Equal(t, int64(5), db.Revision())
+
+	tmp := DbTableIdObject{}
+	assert.NoError(t, db.Find("id", DbTableIdObject{ID: objs[1].ID}, &tmp))
+	assert.Equal(t, objs[1], tmp)
+	assert.Equal(t, ErrNotFound, db.Find("id", DbTableIdObject{ID: objs[2].ID}, &tmp))
+
+	// the increment was flushed with the objects, new ids do not collide
+	obj := objs[2]
+	obj.ID = 0
+	assert.NoError(t, db.Insert(&obj))
+	assert.Equal(t, objs[2].ID, obj.ID)
+}
+
+func Test_dirtyFlag(t *testing.T) {
+	os.RemoveAll(crashDbPath)
+	defer os.RemoveAll(crashDbPath)
+
+	objs, _ := Objects()
+	db := openCrashDb(t)
+	db.StartSession()
+	assert.NoError(t, db.Insert(&objs[0]))
+	assert.NoError(t, db.Flush())
+	crash(db)
+
+	_, err := NewDataBase(crashDbPath)
+	assert.Equal(t, ErrDirty, err)
+}
+
+func Test_undoStackReopen(t *testing.T) {
+	os.RemoveAll(crashDbPath)
+	defer os.RemoveAll(crashDbPath)
+
+	objs, _ := Objects()
+	db := openCrashDb(t)
+	db.SetRevision(10)
+	for i := 0; i < 3; i++ {
+		db.StartSession().Push()
+		assert.NoError(t, db.Insert(&objs[i]))
+	}
+	assert.NoError(t, db.Flush())
+	db.Close()
+
+	db = openCrashDb(t)
+	defer db.Close()
+	assert.Equal(t, int64(13), db.Revision())
+
+	// sessions come back in order, the newest is undone first
+	tmp := DbTableIdObject{}
+	db.Undo()
+	assert.Equal(t, int64(12), db.Revision())
+	assert.Equal(t, ErrNotFound, db.Find("id", DbTableIdObject{ID: objs[2].ID}, &tmp))
+	assert.NoError(t, db.Find("id", DbTableIdObject{ID: objs[1].ID}, &tmp))
+
+	db.UndoAll()
+	assert.Equal(t, int64(10), db.Revision())
+	assert.Equal(t, ErrNotFound, db.Find("id", DbTableIdObject{ID: objs[0].ID}, &tmp))
+}
+
+func Test_levelStorePending(t *testing.T) {
+	os.RemoveAll(crashDbPath)
+	defer os.RemoveAll(crashDbPath)
+
+	db, err := leveldb.OpenFile(crashDbPath, nil)
+	assert.NoError(t, err)
+	store, _ := newLevelStore(db)
+	defer store.Close()
+
+	assert.NoError(t, store.Put([]byte("a"), []byte("1")))
+	assert.NoError(t, store.Put([]byte("c"), []byte("3")))
+	assert.NoError(t, store.Commit())
+
+	batch := new(leveldb.Batch)
+	batch.Put([]byte("b"), []byte("2"))
+	batch.Delete([]byte("c"))
+	assert.NoError(t, store.Write(batch))
+
+	// the pending writes are read back before they are committed
+	val, err := store.Get([]byte("b"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("2"), val)
+	_, err = store.Get([]byte("c"))
+	assert.Equal(t, leveldb.ErrNotFound, err)
+
+	keys := func(it iterator) (keys string) {
+		for it.Next() {
+			keys += string(it.Key())
+		}
+		it.Release()
+		return
+	}
+	assert.Equal(t, "ab", keys(store.NewIterator(nil)))
+
+	// but they do not reach the files
+	_, err = db.Get([]byte("b"), nil)
+	assert.Equal(t, leveldb.ErrNotFound, err)
+	_, err = db.Get([]byte("c"), nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Commit())
+	assert.Equal(t, "ab", keys(db.NewIterator(nil, nil)))
+	assert.Equal(t, "ab", keys(store.NewIterator(nil)))
+}
+
+func Test_revisionMismatch(t *testing.T) {
+	os.RemoveAll(crashDbPath)
+	defer os.RemoveAll(crashDbPath)
+
+	objs, _ := Objects()
+	db := openCrashDb(t)
+	db.SetRevision(10)
+	db.StartSession().Push()
+	assert.NoError(t, db.Insert(&objs[0]))
+	db.Close()
+
+	// a reversion which is not the one of the newest undo state
+	db, err := NewDataBase(crashDbPath)
+	assert.NoError(t, err)
+	store := db.(*LDataBase).db
+	val, _ := EncodeToBytes(int64(10))
+	assert.NoError(t, store.Put([]byte(dbReversion), val))
+	assert.NoError(t, store.Close())
+
+	_, err = NewDataBase(crashDbPath)
+	assert.Equal(t, ErrRevisionMismatch, err)
+}
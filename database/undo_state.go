@@ -114,3 +114,98 @@ func (stack *undoState) undoStackModify(value *modifyValue) {
 	}
 	stack.OldValue[id] = value
 }
+
+/*
+*	The undo stack is kept in the database between runs,
+*	key values have no exported fields so they are stored through the types below
+ */
+
+type storedKv struct {
+	Key   []byte
+	Value []byte
+}
+
+type storedKeyValue struct {
+	Idk      storedKv
+	Index    []storedKv
+	TypeName []byte
+	Id       int64
+}
+
+type storedModifyValue struct {
+	Id    int64
+	OldKv storedKeyValue
+	NewKv storedKeyValue
+}
+
+type storedUndoState struct {
+	NewValue    map[int64]storedModifyValue
+	RemoveValue map[int64]storedModifyValue
+	OldValue    map[int64]storedModifyValue
+}
+
+type storedUndoContainer struct {
+	Undo      map[string]storedUndoState
+	OldIds    map[string]int64
+	Reversion int64
+}
+
+func (container *undoContainer) toStored() storedUndoContainer {
+	stored := storedUndoContainer{Undo: make(map[string]storedUndoState), OldIds: container.OldIds, Reversion: container.Reversion}
+	for typeName, undo := range container.Undo {
+		stored.Undo[typeName] = storedUndoState{
+			NewValue:    storeModifyValues(undo.NewValue),
+			RemoveValue: storeModifyValues(undo.RemoveValue),
+			OldValue:    storeModifyValues(undo.OldValue),
+		}
+	}
+	return stored
+}
+
+func (stored *storedUndoContainer) toContainer() *undoContainer {
+	oldIds := stored.OldIds
+	if oldIds == nil {
+		oldIds = make(map[string]int64)
+	}
+	container := &undoContainer{Undo: make(map[string]*undoState), OldIds: oldIds, Reversion: stored.Reversion}
+	for typeName, undo := range stored.Undo {
+		container.Undo[typeName] = &undoState{
+			NewValue:    restoreModifyValues(undo.NewValue),
+			RemoveValue: restoreModifyValues(undo.RemoveValue),
+			OldValue:    restoreModifyValues(undo.OldValue),
+		}
+	}
+	return container
+}
+
+func storeModifyValues(values map[int64]*modifyValue) map[int64]storedModifyValue {
+	stored := make(map[int64]storedModifyValue)
+	for id, value := range values {
+		stored[id] = storedModifyValue{Id: value.Id, OldKv: storeKeyValue(value.OldKv), NewKv: storeKeyValue(value.NewKv)}
+	}
+	return stored
+}
+
+func restoreModifyValues(stored map[int64]storedModifyValue) map[int64]*modifyValue {
+	values := make(map[int64]*modifyValue)
+	for id, value := range stored {
+		values[id] = &modifyValue{Id: value.Id, OldKv: value.OldKv.restore(), NewKv: value.NewKv.restore()}
+	}
+	return values
+}
+
+func storeKeyValue(dbKV *dbKeyValue) storedKeyValue {
+	stored := storedKeyValue{Idk: storedKv{Key: dbKV.idk.key, Value: dbKV.idk.value}, TypeName: dbKV.typeName, Id: dbKV.id}
+	for _, v := range dbKV.index {
+		stored.Index = append(stored.Index, storedKv{Key: v.key, Value: v.value})
+	}
+	return stored
+}
+
+func (stored *storedKeyValue) restore() *dbKeyValue {
+	dbKV := &dbKeyValue{idk: kv{key: stored.Idk.Key, value: stored.Idk.Value}, typeName: stored.TypeName, id: stored.Id}
+	for _, v := range stored.Index {
+		dbKV.index = append(dbKV.index, kv{key: v.Key, value: v.Value})
+	}
+	return dbKV
+}
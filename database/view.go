@@ -0,0 +1,338 @@
+package database
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	lvlIterator "github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+/*
+*	A read view is the database as it was at a revision.
+*	It reads a snapshot of the store and lays the undo records of every newer revision over it,
+*	so it never changes while the database goes on, and it may be read from another goroutine.
+*	Writes to a view fail with ErrReadOnly.
+ */
+
+// storeSnapshot is a frozen kvStore
+type storeSnapshot interface {
+	Get(key []byte) ([]byte, error)
+
+	NewIterator(slice *util.Range) lvlIterator.Iterator
+
+	// live reports whether the snapshot holds writes which were not committed yet
+	live() bool
+
+	Release()
+}
+
+/*
+*	ReadView returns a view of the database at revision, which may be any revision still kept by the undo stack.
+*	It must be called from the goroutine writing the database, the view has to be closed after use
+ */
+
+func (ldb *LDataBase) ReadView(revision int64) (DataBase, error) {
+	snap, err := ldb.db.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	reversion, undos, err := ldb.undosAfter(revision, snap.live())
+	if err != nil {
+		snap.Release()
+		return nil, err
+	}
+	if revision > reversion || (len(undos) > 0 && undos[len(undos)-1].Reversion != revision+1) {
+		snap.Release()
+		return nil, ErrRevisionNotAvailable
+	}
+
+	/* newest first, exactly as Undo would run */
+	overlay := memdb.New(comparer.DefaultComparer, 0)
+	batch := new(leveldb.Batch)
+	for _, undo := range undos {
+		undoBatch(undo, batch)
+	}
+	batch.Replay(overlayReplay{overlay})
+
+	nextId := make(map[string]int64)
+	if len(undos) > 0 {
+		for k, v := range undos[len(undos)-1].OldIds {
+			nextId[k] = v
+		}
+	}
+	store := &viewStore{snap: snap, overlay: overlay}
+	return &LDataBase{db: store, stack: newDeque(), path: ldb.path, nextId: nextId, log: ldb.log, batch: new(leveldb.Batch), reversion: revision, readOnly: true}, nil
+}
+
+// undosAfter collects the undo records newer than revision, newest first.
+// A live snapshot is read with the undo stack in memory, otherwise with the one of the last Flush
+func (ldb *LDataBase) undosAfter(revision int64, live bool) (int64, []*undoContainer, error) {
+	undos := []*undoContainer{}
+	if live {
+		ldb.stack.RLock()
+		defer ldb.stack.RUnlock()
+		for e := ldb.stack.container.Back(); e != nil; e = e.Prev() {
+			undo := e.Value.(*undoContainer)
+			if undo.Reversion <= revision {
+				break
+			}
+			undos = append(undos, undo)
+		}
+		return ldb.reversion, undos, nil
+	}
+
+	for i := len(ldb.flushed.stack) - 1; i >= 0; i-- {
+		stored := storedUndoContainer{}
+		if err := DecodeBytes(ldb.flushed.stack[i], &stored); err != nil {
+			return 0, nil, err
+		}
+		if stored.Reversion <= revision {
+			break
+		}
+		undos = append(undos, stored.toContainer())
+	}
+	return ldb.flushed.reversion, undos, nil
+}
+
+// undoBatch adds to batch the writes Undo makes for one session
+func undoBatch(undo *undoContainer, batch *leveldb.Batch) {
+	for _, state := range undo.Undo {
+		for _, value := range state.OldValue {
+			deleteKv(batch, value.NewKv)
+			putKv(batch, value.OldKv)
+		}
+		for _, value := range state.NewValue {
+			deleteKv(batch, value.NewKv)
+		}
+		for _, value := range state.RemoveValue {
+			putKv(batch, value.NewKv)
+		}
+	}
+}
+
+func putKv(batch *leveldb.Batch, dbKV *dbKeyValue) {
+	for _, v := range dbKV.index {
+		batch.Put(v.key, v.value)
+	}
+	batch.Put(dbKV.idk.key, dbKV.idk.value)
+}
+
+func deleteKv(batch *leveldb.Batch, dbKV *dbKeyValue) {
+	for _, v := range dbKV.index {
+		batch.Delete(v.key)
+	}
+	batch.Delete(dbKV.idk.key)
+}
+
+/* overlay values are tagged, a deleted key is kept as a tombstone hiding the snapshot */
+
+const (
+	overlayDeleted = byte(0)
+	overlayValue   = byte(1)
+)
+
+type overlayReplay struct {
+	db *memdb.DB
+}
+
+func (r overlayReplay) Put(key, value []byte) {
+	r.db.Put(key, append([]byte{overlayValue}, value...))
+}
+
+func (r overlayReplay) Delete(key []byte) {
+	r.db.Put(key, []byte{overlayDeleted})
+}
+
+type viewStore struct {
+	snap    storeSnapshot
+	overlay *memdb.DB
+}
+
+func (s *viewStore) Get(key []byte) ([]byte, error) {
+	val, err := s.overlay.Get(key)
+	if err == nil {
+		if val[0] == overlayDeleted {
+			return nil, leveldb.ErrNotFound
+		}
+		return cloneByte(val[1:]), nil
+	}
+	return s.snap.Get(key)
+}
+
+func (s *viewStore) Put(key, value []byte) error {
+	return ErrReadOnly
+}
+
+func (s *viewStore) Write(batch *leveldb.Batch) error {
+	return ErrReadOnly
+}
+
+func (s *viewStore) NewIterator(slice *util.Range) iterator {
+	return &overlayIterator{base: s.snap.NewIterator(slice), over: s.overlay.NewIterator(slice)}
+}
+
+func (s *viewStore) Snapshot() (storeSnapshot, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *viewStore) Commit() error {
+	return nil
+}
+
+func (s *viewStore) Close() error {
+	s.snap.Release()
+	s.overlay.Reset()
+	return nil
+}
+
+/*
+*	overlayIterator walks the snapshot and the overlay together,
+*	an overlay entry wins over the snapshot entry of the same key and tombstones are skipped
+ */
+
+type overlayIterator struct {
+	base       lvlIterator.Iterator
+	over       lvlIterator.Iterator
+	positioned bool
+	forward    bool
+	cur        lvlIterator.Iterator
+}
+
+func (it *overlayIterator) First() bool {
+	it.base.First()
+	it.over.First()
+	return it.settle(true)
+}
+
+func (it *overlayIterator) Last() bool {
+	it.base.Last()
+	it.over.Last()
+	return it.settle(false)
+}
+
+func (it *overlayIterator) Seek(key []byte) bool {
+	it.base.Seek(key)
+	it.over.Seek(key)
+	return it.settle(true)
+}
+
+func (it *overlayIterator) Next() bool {
+	/* like leveldb iterators, walking off the start turns around and a fresh iterator starts at the first key */
+	if it.cur == nil {
+		if !it.positioned || !it.forward {
+			return it.First()
+		}
+		return false
+	}
+	key := cloneByte(it.cur.Key())
+	if !it.forward {
+		/* both below the current key, bring them to the first key after it */
+		it.base.Seek(key)
+		it.over.Seek(key)
+	}
+	if it.base.Valid() && comparer.DefaultComparer.Compare(it.base.Key(), key) == 0 {
+		it.base.Next()
+	}
+	if it.over.Valid() && comparer.DefaultComparer.Compare(it.over.Key(), key) == 0 {
+		it.over.Next()
+	}
+	return it.settle(true)
+}
+
+func (it *overlayIterator) Prev() bool {
+	if it.cur == nil {
+		if !it.positioned || it.forward {
+			return it.Last()
+		}
+		return false
+	}
+	key := cloneByte(it.cur.Key())
+	if it.forward {
+		/* both at or above the current key, bring them to the last key before it */
+		for _, i := range []lvlIterator.Iterator{it.base, it.over} {
+			if i.Seek(key) {
+				i.Prev()
+			} else {
+				i.Last()
+			}
+		}
+	} else {
+		if it.base.Valid() && comparer.DefaultComparer.Compare(it.base.Key(), key) == 0 {
+			it.base.Prev()
+		}
+		if it.over.Valid() && comparer.DefaultComparer.Compare(it.over.Key(), key) == 0 {
+			it.over.Prev()
+		}
+	}
+	return it.settle(false)
+}
+
+// settle points cur at the nearest visible entry in the direction of the walk
+func (it *overlayIterator) settle(forward bool) bool {
+	it.positioned = true
+	it.forward = forward
+	for {
+		if !it.over.Valid() {
+			if it.base.Valid() {
+				it.cur = it.base
+				return true
+			}
+			it.cur = nil
+			return false
+		}
+
+		cmp := -1
+		if it.base.Valid() {
+			cmp = comparer.DefaultComparer.Compare(it.over.Key(), it.base.Key())
+			if !forward {
+				cmp = -cmp
+			}
+		}
+		if cmp > 0 {
+			it.cur = it.base
+			return true
+		}
+		if it.over.Value()[0] != overlayDeleted {
+			it.cur = it.over
+			return true
+		}
+
+		/* tombstone, skip it and the snapshot entry it hides */
+		if cmp == 0 {
+			it.step(it.base, forward)
+		}
+		it.step(it.over, forward)
+	}
+}
+
+func (it *overlayIterator) step(i lvlIterator.Iterator, forward bool) {
+	if forward {
+		i.Next()
+	} else {
+		i.Prev()
+	}
+}
+
+func (it *overlayIterator) Key() []byte {
+	if it.cur == nil {
+		return nil
+	}
+	return it.cur.Key()
+}
+
+func (it *overlayIterator) Value() []byte {
+	if it.cur == nil {
+		return nil
+	}
+	if it.cur == it.over {
+		return it.over.Value()[1:]
+	}
+	return it.cur.Value()
+}
+
+func (it *overlayIterator) Release() {
+	it.base.Release()
+	it.over.Release()
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func codesOf(t *testing.T, db DataBase, reverse bool) []AccountName {
+	idx, err := db.GetIndex("Code", DbTableIdObject{})
+	assert.NoError(t, err)
+
+	codes := []AccountName{}
+	if !reverse {
+		for it := idx.Begin(); !idx.CompareEnd(it); it.Next() {
+			tmp := DbTableIdObject{}
+			assert.NoError(t, it.Data(&tmp))
+			codes = append(codes, tmp.Code)
+		}
+		return codes
+	}
+
+	it := idx.End()
+	for !idx.CompareBegin(it) {
+		it.Prev()
+		tmp := DbTableIdObject{}
+		assert.NoError(t, it.Data(&tmp))
+		codes = append([]AccountName{tmp.Code}, codes...)
+	}
+	return codes
+}
+
+// checkReadView builds revisions 1 to 3 and checks the views pinned at each of them
+func checkReadView(t *testing.T, db DataBase) {
+	objs, _ := Objects()
+	code := func(i int) AccountName { return objs[i].Code }
+	codes1 := []AccountName{code(0), code(1), code(2)}
+	codes2 := []AccountName{code(1), code(2), code(3), 99}
+
+	session := db.StartSession()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, db.Insert(&objs[i]))
+	}
+	session.Push()
+
+	session = db.StartSession()
+	assert.NoError(t, db.Insert(&objs[3]))
+	assert.NoError(t, db.Modify(&objs[0], func(obj *DbTableIdObject) {
+		obj.Code = 99
+	}))
+	session.Push()
+
+	session = db.StartSession()
+	assert.NoError(t, db.Remove(&objs[1]))
+	assert.NoError(t, db.Insert(&objs[4]))
+	session.Push()
+	assert.NoError(t, db.Flush())
+
+	head := codesOf(t, db, false)
+	views := map[int64][]AccountName{
+		0: {},
+		1: codes1,
+		2: codes2,
+		3: head,
+	}
+	for revision, codes := range views {
+		view, err := db.ReadView(revision)
+		assert.NoError(t, err)
+		assert.Equal(t, revision, view.Revision())
+		assert.Equal(t, codes, codesOf(t, view, false), "revision %d", revision)
+		assert.Equal(t, codes, codesOf(t, view, true), "revision %d", revision)
+		view.Close()
+	}
+
+	view, err := db.ReadView(2)
+	assert.NoError(t, err)
+	defer view.Close()
+
+	tmp := DbTableIdObject{}
+	assert.NoError(t, view.Find("id", DbTableIdObject{ID: objs[1].ID}, &tmp))
+	assert.Equal(t, objs[1], tmp)
+	assert.Equal(t, ErrNotFound, view.Find("id", DbTableIdObject{ID: objs[4].ID}, &tmp))
+	assert.Equal(t, ErrReadOnly, view.Insert(&DbTableIdObject{Code: 1}))
+
+	// the view does not follow the database
+	session = db.StartSession()
+	assert.NoError(t, db.Remove(&objs[2]))
+	assert.Equal(t, views[2], codesOf(t, view, false))
+	session.Undo()
+
+	_, err = db.ReadView(4)
+	assert.Equal(t, ErrRevisionNotAvailable, err)
+
+	db.Commit(2)
+	assert.NoError(t, db.Flush())
+	_, err = db.ReadView(1)
+	assert.Equal(t, ErrRevisionNotAvailable, err)
+}
+
+func Test_readView(t *testing.T) {
+	fileName := "./view"
+	os.RemoveAll(fileName)
+	defer os.RemoveAll(fileName)
+
+	db, err := NewDataBase(fileName)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	checkReadView(t, db)
+
+	// nothing written after the last Flush is seen
+	objs, _ := Objects()
+	session := db.StartSession()
+	assert.NoError(t, db.Insert(&objs[5]))
+	view, err := db.ReadView(3)
+	assert.NoError(t, err)
+	tmp := DbTableIdObject{}
+	assert.Equal(t, ErrNotFound, view.Find("id", DbTableIdObject{ID: objs[5].ID}, &tmp))
+	view.Close()
+	session.Undo()
+}
+
+func Test_memReadView(t *testing.T) {
+	db, err := NewMemDataBase()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	checkReadView(t, db)
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/eosspark/eos-go/chain/types"
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto/rlp"
+)
+
+// ActionReceiptsObject keeps the action receipts of an irreversible block, they are not part of the block
+// and only the node which applied it has them. FirstSequence is the global sequence of the first receipt.
+type ActionReceiptsObject struct {
+	ID             common.IdType `multiIndex:"id,increment"`
+	BlockNum       uint32        `multiIndex:"byNum,orderedUnique"`
+	FirstSequence  uint64        `multiIndex:"bySequence,orderedUnique"`
+	PackedReceipts common.HexBytes
+}
+
+func (aro *ActionReceiptsObject) SetReceipts(receipts []types.ActionReceipt) {
+	bo, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		fmt.Println("ActionReceiptsObject SetReceipts is error:", err)
+	}
+	aro.PackedReceipts = bo
+}
+
+func (aro *ActionReceiptsObject) GetReceipts() []types.ActionReceipt {
+	var result []types.ActionReceipt
+	rlp.DecodeBytes(aro.PackedReceipts, &result)
+	return result
+}
+
+func (aro ActionReceiptsObject) IsEmpty() bool {
+	return aro.ID == 0 && aro.BlockNum == 0 && aro.PackedReceipts.Size() == 0
+}
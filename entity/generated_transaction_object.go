@@ -7,14 +7,18 @@ import (
 	"github.com/eosspark/eos-go/crypto/rlp"
 )
 
+// the fields of a composite index are keyed in declaration order, Id follows the fields it is appended to:
+// byDelay is (DelayUntil, Id), byExpiration is (Expiration, Id) and byPayer is (Payer, Id), as in eosio.
+// The scheduled transactions are executed in byDelay order, those due at the same time in the order they
+// were scheduled. A state database written while Id came first has to be rebuilt with --replay-blockchain.
 type GeneratedTransactionObject struct {
-	Id         common.IdType            `multiIndex:"id,increment,byExpiration,byDelay"`
 	TrxId      common.TransactionIdType `multiIndex:"byTrxId,orderedUnique"`
 	Sender     common.AccountName       `multiIndex:"bySenderId,orderedUnique"`
 	SenderId   eos_math.Uint128         `multiIndex:"bySenderId,orderedUnique"`
-	Payer      common.AccountName
-	DelayUntil common.TimePoint `multiIndex:"byDelay,orderedUnique"`
-	Expiration common.TimePoint `multiIndex:"byExpiration,orderedUnique"`
+	Payer      common.AccountName       `multiIndex:"byPayer,orderedUnique"`
+	DelayUntil common.TimePoint         `multiIndex:"byDelay,orderedUnique"`
+	Expiration common.TimePoint         `multiIndex:"byExpiration,orderedUnique"`
+	Id         common.IdType            `multiIndex:"id,increment,byExpiration,byDelay,byPayer"`
 	Published  common.TimePoint
 	PackedTrx  common.HexBytes //c++ shared_string
 }
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"github.com/eosspark/eos-go/chain/types"
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto/ecc"
+)
+
+// PermissionAuthorizerObject mirrors one key or account entry of a permission's authority,
+// so that the permissions controlled by a public key or an account@permission can be found.
+// ID follows the indexed fields so that it is the last component of every secondary key.
+type PermissionAuthorizerObject struct {
+	PermissionId         common.IdType         `multiIndex:"byPermission,orderedUnique"`
+	Key                  ecc.PublicKey         `multiIndex:"byKey,orderedUnique"`
+	AuthorizerActor      common.AccountName    `multiIndex:"byAuthorizer,orderedUnique"`
+	AuthorizerPermission common.PermissionName `multiIndex:"byAuthorizer,orderedUnique"`
+	ID                   common.IdType         `multiIndex:"id,increment,byPermission,byKey,byAuthorizer"`
+	Owner                common.AccountName
+	Name                 common.PermissionName
+	Weight               types.WeightType
+	Threshold            uint32
+}
+
+func (p *PermissionAuthorizerObject) IsKey() bool {
+	return p.AuthorizerActor.Empty()
+}
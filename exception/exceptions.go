@@ -142,6 +142,12 @@ package exception
 //go:generate gotemplate -outfmt "gen_%v" "github.com/eosspark/eos-go/exception/template" "WasmSerializationError (_WasmException,3070003,\"Serialization Error Processing WASM\")"
 //go:generate gotemplate -outfmt "gen_%v" "github.com/eosspark/eos-go/exception/template" "OverlappingMemoryError (_WasmException,3070004,\"memcpy with overlapping memory\")"
 //go:generate gotemplate -outfmt "gen_%v" "github.com/eosspark/eos-go/exception/template" "BinaryenException (_WasmException,3070005,\"binaryen exception\")"
+//go:generate gotemplate -outfmt "gen_%v" "github.com/eosspark/eos-go/exception/template" "WasmImportNotAllowed (_WasmException,3070006,\"Smart contract imports a function that is not an intrinsic\")"
+//go:generate gotemplate -outfmt "gen_%v" "github.com/eosspark/eos-go/exception/template" "WasmImportSignatureMismatch (_WasmException,3070007,\"Smart contract imports an intrinsic with a wrong signature\")"
+//go:generate gotemplate -outfmt "gen_%v" "github.com/eosspark/eos-go/exception/template" "WasmMutableGlobalImport (_WasmException,3070008,\"Smart contract imports a mutable global\")"
+//go:generate gotemplate -outfmt "gen_%v" "github.com/eosspark/eos-go/exception/template" "WasmTooManyLocals (_WasmException,3070009,\"Smart contract function has too many locals\")"
+//go:generate gotemplate -outfmt "gen_%v" "github.com/eosspark/eos-go/exception/template" "WasmNestedTooDeep (_WasmException,3070010,\"Smart contract function has too deeply nested structures\")"
+//go:generate gotemplate -outfmt "gen_%v" "github.com/eosspark/eos-go/exception/template" "WasmBrTableTooLarge (_WasmException,3070011,\"Smart contract br_table has too many targets\")"
 
 //_ResourceExhaustedException
 //go:generate gotemplate -outfmt "gen_%v" "github.com/eosspark/eos-go/exception/template" "ResourceExhaustedException (_ResourceExhaustedException,3080000,\"Resource exhausted exception\")"
@@ -47,7 +47,8 @@ func (c *ChainApiPlugin) PluginStartup() {
 
 	httpPlugin := App().GetPlugin(http_plugin.HttpPlug).(*http_plugin.HttpPlugin)
 
-	ROApi := App().GetPlugin(chain_plugin.ChainPlug).(*chain_plugin.ChainPlugin).GetReadOnlyApi()
+	chainPlugin := App().GetPlugin(chain_plugin.ChainPlug).(*chain_plugin.ChainPlugin)
+	ROApi := chainPlugin.GetReadOnlyApi()
 
 	//TODO read_only api
 	ROApi.SetShortenAbiErrors(httpPlugin.VerboseErrors())
@@ -115,18 +116,18 @@ func (c *ChainApiPlugin) PluginStartup() {
 		}).End()
 	})
 
-	httpPlugin.AddHandler(common.GetAccountFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+	httpPlugin.AddHandler(common.GetBlockHeaderFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
 		Try(func() {
 			if len(body) == 0 {
 				body = []byte("{}")
 			}
 
-			var param chain_plugin.GetAccountParams
+			var param chain_plugin.GetBlockHeaderParams
 			if err := json.Unmarshal(body, &param); err != nil {
-				EosThrow(&EofException{}, "marshal get_account params: %s", err.Error())
+				EosThrow(&EofException{}, "marshal get_block_header params: %s", err.Error())
 			}
 
-			result := ROApi.GetAccount(param)
+			result := ROApi.GetBlockHeader(param)
 
 			if byte, err := json.Marshal(result); err == nil {
 				cb(200, byte)
@@ -134,6 +135,136 @@ func (c *ChainApiPlugin) PluginStartup() {
 				Throw(err)
 			}
 
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "chain", "get_block_header", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.GetTransactionProofFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			var param chain_plugin.GetTransactionProofParams
+			if err := json.Unmarshal(body, &param); err != nil {
+				EosThrow(&EofException{}, "marshal get_transaction_proof params: %s", err.Error())
+			}
+
+			result := ROApi.GetTransactionProof(param)
+
+			if byte, err := json.Marshal(result); err == nil {
+				cb(200, byte)
+			} else {
+				Throw(err)
+			}
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "chain", "get_transaction_proof", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.GetActionProofFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			var param chain_plugin.GetActionProofParams
+			if err := json.Unmarshal(body, &param); err != nil {
+				EosThrow(&EofException{}, "marshal get_action_proof params: %s", err.Error())
+			}
+
+			result := ROApi.GetActionProof(param)
+
+			if byte, err := json.Marshal(result); err == nil {
+				cb(200, byte)
+			} else {
+				Throw(err)
+			}
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "chain", "get_action_proof", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.GetBlockProofFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			var param chain_plugin.GetBlockProofParams
+			if err := json.Unmarshal(body, &param); err != nil {
+				EosThrow(&EofException{}, "marshal get_block_proof params: %s", err.Error())
+			}
+
+			result := ROApi.GetBlockProof(param)
+
+			if byte, err := json.Marshal(result); err == nil {
+				cb(200, byte)
+			} else {
+				Throw(err)
+			}
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "chain", "get_block_proof", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.DryRunTransactionFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			var param chain_plugin.DryRunTransactionParams
+			if err := json.Unmarshal(body, &param); err != nil {
+				EosThrow(&EofException{}, "marshal dry_run_transaction params: %s", err.Error())
+			}
+
+			result := ROApi.DryRunTransaction(param)
+
+			if byte, err := json.Marshal(result); err == nil {
+				cb(200, byte)
+			} else {
+				Throw(err)
+			}
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "chain", "dry_run_transaction", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.GetAccountFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			var param chain_plugin.GetAccountParams
+			if err := json.Unmarshal(body, &param); err != nil {
+				EosThrow(&EofException{}, "marshal get_account params: %s", err.Error())
+			}
+
+			// answered off the main thread, against the state of the head block
+			queued := chainPlugin.ReadOnlyQuery(func(view *chain.StateView) {
+				Try(func() {
+					result := ROApi.WithView(view).GetAccount(param)
+
+					if byte, err := json.Marshal(result); err == nil {
+						cb(200, byte)
+					} else {
+						Throw(err)
+					}
+				}).Catch(func(e interface{}) {
+					http_plugin.HandleException(e, "chain", "get_account", string(body), cb)
+				}).End()
+			})
+			if !queued {
+				http_plugin.ServiceUnavailable("too many read only queries in progress", cb)
+			}
+
 		}).Catch(func(e interface{}) {
 			http_plugin.HandleException(e, "chain", "get_account", string(body), cb)
 		}).End()
@@ -198,12 +329,22 @@ func (c *ChainApiPlugin) PluginStartup() {
 				EosThrow(&EofException{}, "marshal get_currency_balance params: %s", err.Error())
 			}
 
-			result := ROApi.GetCurrencyBalance(param)
-
-			if byte, err := json.Marshal(result); err == nil {
-				cb(200, byte)
-			} else {
-				Throw(err)
+			// answered off the main thread, against the state of the head block
+			queued := chainPlugin.ReadOnlyQuery(func(view *chain.StateView) {
+				Try(func() {
+					result := ROApi.WithView(view).GetCurrencyBalance(param)
+
+					if byte, err := json.Marshal(result); err == nil {
+						cb(200, byte)
+					} else {
+						Throw(err)
+					}
+				}).Catch(func(e interface{}) {
+					http_plugin.HandleException(e, "chain", "get_currency_balance", string(body), cb)
+				}).End()
+			})
+			if !queued {
+				http_plugin.ServiceUnavailable("too many read only queries in progress", cb)
 			}
 
 		}).Catch(func(e interface{}) {
@@ -259,18 +400,18 @@ func (c *ChainApiPlugin) PluginStartup() {
 		}).End()
 	})
 
-	httpPlugin.AddHandler(common.GetTableFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+	httpPlugin.AddHandler(common.ExplainAuthorizationFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
 		Try(func() {
 			if len(body) == 0 {
 				body = []byte("{}")
 			}
 
-			var param chain_plugin.GetTableRowsParams
+			var param chain_plugin.ExplainAuthorizationParams
 			if err := json.Unmarshal(body, &param); err != nil {
-				EosThrow(&EofException{}, "marshal get_table params: %s", err.Error())
+				EosThrow(&EofException{}, "marshal explain_authorization params: %s", err.Error())
 			}
 
-			result := ROApi.GetTableRows(param)
+			result := ROApi.ExplainAuthorization(param)
 
 			if byte, err := json.Marshal(result); err == nil {
 				cb(200, byte)
@@ -278,6 +419,112 @@ func (c *ChainApiPlugin) PluginStartup() {
 				Throw(err)
 			}
 
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "chain", "explain_authorization", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.GetAccountsByAuthorizersFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			var param chain_plugin.GetAccountsByAuthorizersParams
+			if err := json.Unmarshal(body, &param); err != nil {
+				EosThrow(&EofException{}, "marshal get_accounts_by_authorizers params: %s", err.Error())
+			}
+
+			result := ROApi.GetAccountsByAuthorizers(param)
+
+			if byte, err := json.Marshal(result); err == nil {
+				cb(200, byte)
+			} else {
+				Throw(err)
+			}
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "chain", "get_accounts_by_authorizers", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.GetProducersFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			var param chain_plugin.GetProducersParams
+			if err := json.Unmarshal(body, &param); err != nil {
+				EosThrow(&EofException{}, "marshal get_producers params: %s", err.Error())
+			}
+
+			result := ROApi.GetProducers(param)
+
+			if byte, err := json.Marshal(result); err == nil {
+				cb(200, byte)
+			} else {
+				Throw(err)
+			}
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "chain", "get_producers", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.GetScheduledTransactionsFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			var param chain_plugin.GetScheduledTransactionParams
+			if err := json.Unmarshal(body, &param); err != nil {
+				EosThrow(&EofException{}, "marshal get_scheduled_transactions params: %s", err.Error())
+			}
+
+			result := ROApi.GetScheduledTransactions(param)
+
+			if byte, err := json.Marshal(result); err == nil {
+				cb(200, byte)
+			} else {
+				Throw(err)
+			}
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "chain", "get_scheduled_transactions", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.GetTableFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			var param chain_plugin.GetTableRowsParams
+			if err := json.Unmarshal(body, &param); err != nil {
+				EosThrow(&EofException{}, "marshal get_table params: %s", err.Error())
+			}
+
+			// answered off the main thread, against the state of the head block
+			queued := chainPlugin.ReadOnlyQuery(func(view *chain.StateView) {
+				Try(func() {
+					result := ROApi.WithView(view).GetTableRows(param)
+
+					if byte, err := json.Marshal(result); err == nil {
+						cb(200, byte)
+					} else {
+						Throw(err)
+					}
+				}).Catch(func(e interface{}) {
+					http_plugin.HandleException(e, "chain", "get_table", string(body), cb)
+				}).End()
+			})
+			if !queued {
+				http_plugin.ServiceUnavailable("too many read only queries in progress", cb)
+			}
+
 		}).Catch(func(e interface{}) {
 			http_plugin.HandleException(e, "chain", "get_table", string(body), cb)
 		}).End()
@@ -311,6 +558,67 @@ func (c *ChainApiPlugin) PluginStartup() {
 		})
 	})
 
+	httpPlugin.AddHandler(common.PushTxnsFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			if len(body) == 0 {
+				body = []byte("[]")
+			}
+
+			RWApi.Validate()
+
+			var param chain_plugin.PushTransactionsParams
+			if err := json.Unmarshal(body, &param); err != nil {
+				EosThrow(&EofException{}, "marshal push_transactions params: %s", err.Error())
+			}
+
+			RWApi.PushTransactions(param, func(result common.StaticVariant) {
+				if exception, ok := result.(Exception); ok {
+					http_plugin.HandleException(exception, "chain", "push_transactions", string(body), cb)
+				} else {
+					if byte, err := json.Marshal(result); err == nil {
+						cb(202, byte)
+					} else {
+						http_plugin.HandleException(err, "chain", "push_transactions", string(body), cb)
+					}
+				}
+			})
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "chain", "push_transactions", string(body), cb)
+		}).End()
+	})
+
+	// the answer is held back until the transactions reach the requested finality or expire without being included
+	httpPlugin.AddLongPollHandler(common.SendTxnsFunc, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			RWApi.Validate()
+
+			var param chain_plugin.SendTransactionsParams
+			if err := json.Unmarshal(body, &param); err != nil {
+				EosThrow(&EofException{}, "marshal send_transactions params: %s", err.Error())
+			}
+
+			RWApi.SendTransactions(param, func(result common.StaticVariant) {
+				if exception, ok := result.(Exception); ok {
+					http_plugin.HandleException(exception, "chain", "send_transactions", string(body), cb)
+				} else {
+					if byte, err := json.Marshal(result); err == nil {
+						cb(202, byte)
+					} else {
+						http_plugin.HandleException(err, "chain", "send_transactions", string(body), cb)
+					}
+				}
+			})
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "chain", "send_transactions", string(body), cb)
+		}).End()
+	})
+
 }
 
 func (c *ChainApiPlugin) PluginShutdown() {
@@ -109,9 +109,11 @@ const (
 	//incoming
 	BlockSync
 	TransactionAsync
+	ConfirmationSync
 )
 
 type BlockSyncCaller = BlockCaller
+type ConfirmationSyncCaller = AcceptedConfirmationCaller
 
 type TransactionAsyncCaller struct {
 	Caller func(*types.PackedTransaction, bool, NextFunction /*TransactionTrace*/)
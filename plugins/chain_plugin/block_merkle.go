@@ -0,0 +1,132 @@
+package chain_plugin
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/eosspark/eos-go/chain"
+	"github.com/eosspark/eos-go/chain/types"
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto/rlp"
+	. "github.com/eosspark/eos-go/exception"
+	. "github.com/eosspark/eos-go/exception/try"
+	"github.com/eosspark/eos-go/log"
+	. "github.com/eosspark/eos-go/plugins/chain_interface"
+)
+
+// a block proof reads at most two subtrees of 1<<blockMerkleBaseLevel block ids from the block log
+const blockMerkleBaseLevel = 10
+
+// blockMerkleFile is the layout of the block merkle file, LastId is the id of the last appended block
+type blockMerkleFile struct {
+	LastId common.BlockIdType
+	Nodes  []byte
+}
+
+// blockMerkleTracker keeps the merkle nodes over the irreversible block ids for the block proofs. It appends
+// the blocks as they become irreversible, from the controller signal on the application thread, and saves the
+// nodes to the blocks directory every time a subtree of 1<<blockMerkleBaseLevel ids is complete and at shutdown.
+// The nodes only catch up with the block log once, when the chain starts.
+type blockMerkleTracker struct {
+	nodes   *types.MerkleNodes // shared with the read only apis, only replaced in place
+	lastId  common.BlockIdType
+	file    string
+	started bool
+	blockId func(blockNum uint32) common.BlockIdType
+}
+
+func newBlockMerkleTracker(chain *chain.Controller, file string) *blockMerkleTracker {
+	t := &blockMerkleTracker{nodes: types.NewMerkleNodes(blockMerkleBaseLevel), file: file, blockId: chain.GetBlockIdForNum}
+	t.load()
+
+	chain.IrreversibleBlock.Connect(&IrreversibleBlockCaller{Caller: t.onIrreversibleBlock})
+	return t
+}
+
+func (t *blockMerkleTracker) load() {
+	if !common.FileExist(t.file) {
+		return
+	}
+	Try(func() {
+		content, err := ioutil.ReadFile(t.file)
+		Throw(err)
+
+		file := blockMerkleFile{}
+		nodes := types.NewMerkleNodes(blockMerkleBaseLevel)
+		err = rlp.DecodeBytes(content, &file)
+		EosAssert(err == nil, &PluginException{}, "unable to decode block merkle file: %s", err)
+		err = rlp.DecodeBytes(file.Nodes, nodes)
+		EosAssert(err == nil, &PluginException{}, "unable to decode block merkle nodes: %s", err)
+
+		*t.nodes, t.lastId = *nodes, file.LastId
+	}).Catch(func(e Exception) {
+		log.Warn("block merkle file %s is ignored: %s", t.file, e.DetailMessage())
+	}).End()
+}
+
+/**
+ *  save writes the nodes to a temporary file which then replaces the block merkle file,
+ *  a crash leaves either the previous or the new file on disk but never a partial one.
+ */
+func (t *blockMerkleTracker) save() {
+	nodes, err := rlp.EncodeToBytes(t.nodes)
+	if err == nil {
+		var content []byte
+		if content, err = rlp.EncodeToBytes(&blockMerkleFile{LastId: t.lastId, Nodes: nodes}); err == nil {
+			if err = ioutil.WriteFile(t.file+".tmp", content, os.ModePerm); err == nil {
+				err = os.Rename(t.file+".tmp", t.file)
+			}
+		}
+	}
+	if err != nil {
+		log.Error("unable to save the block merkle file %s: %s", t.file, err)
+	}
+}
+
+/**
+ *  start drops saved nodes which are not over the ids of the block log, as after a truncated
+ *  replay, and appends the blocks which became irreversible while the nodes were not tracked.
+ *
+ *  @param lib - the last irreversible block number once the chain started
+ */
+func (t *blockMerkleTracker) start(lib uint32) {
+	if count := t.nodes.Count(); count > uint64(lib) || (count > 0 && t.blockId(uint32(count)) != t.lastId) {
+		log.Warn("block merkle file %s does not match the block log, the nodes are rebuilt", t.file)
+		*t.nodes, t.lastId = *types.NewMerkleNodes(blockMerkleBaseLevel), common.BlockIdType{}
+	}
+
+	if t.nodes.Count() < uint64(lib) {
+		log.Info("appending blocks %d to %d to the block merkle", t.nodes.Count()+1, lib)
+	}
+	t.catchUp(lib)
+	t.started = true
+}
+
+// catchUp appends the ids of the block log up to blockNum
+func (t *blockMerkleTracker) catchUp(blockNum uint32) {
+	for n := uint32(t.nodes.Count()) + 1; n <= blockNum; n++ {
+		t.append(t.blockId(n))
+	}
+}
+
+func (t *blockMerkleTracker) append(id common.BlockIdType) {
+	t.nodes.Append(common.DigestType(id))
+	t.lastId = id
+	if t.nodes.Count()%(1<<blockMerkleBaseLevel) == 0 {
+		t.save()
+	}
+}
+
+func (t *blockMerkleTracker) onIrreversibleBlock(bsp *types.BlockState) {
+	if !t.started || uint64(bsp.BlockNum) <= t.nodes.Count() {
+		return
+	}
+	t.catchUp(bsp.BlockNum - 1)
+	t.append(bsp.BlockId)
+}
+
+func (t *blockMerkleTracker) close() {
+	if t.started {
+		t.save()
+	}
+}
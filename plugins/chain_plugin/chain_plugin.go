@@ -12,6 +12,7 @@ import (
 	"github.com/eosspark/eos-go/log"
 	. "github.com/eosspark/eos-go/plugins/appbase/app"
 	"github.com/eosspark/eos-go/plugins/chain_interface"
+	"github.com/eosspark/eos-go/wasmgo"
 	"github.com/urfave/cli"
 	"os"
 	"path/filepath"
@@ -48,12 +49,25 @@ func (c *ChainPlugin) SetProgramOptions(options *[]cli.Flag) {
 
 		cli.StringFlag{
 			Name:  "wasm-runtime",
-			Usage: "Override default WASM runtime.",
+			Usage: "Override default WASM runtime (\"wasmgo\", \"wasmgo-aot\")",
 		},
 		cli.UintFlag{
 			Name:  "abi-serializer-max-time-ms",
 			Usage: "Override default maximum ABI serialization time allowed in ms",
 		},
+		cli.UintFlag{
+			Name:  "max-decompressed-trx-size-kb",
+			Usage: "Override default maximum size (in KiB) a zlib compressed transaction received from the api or the peers may inflate to. Blocks are always validated against the default of 1024 KiB",
+		},
+		cli.IntFlag{
+			Name:  "read-only-threads",
+			Usage: "Number of threads answering read only api queries against a state view (0 answers them on the main thread)",
+			Value: defaultReadOnlyThreads,
+		},
+		cli.UintFlag{
+			Name:  "action-receipts-retention-blocks",
+			Usage: "Number of the last irreversible blocks whose action receipts are kept for get_action_proof (0 keeps them only while the blocks are reversible)",
+		},
 		//TODO UNUSED
 		//cli.Uint64Flag{
 		//	Name:  "chain-state-db-size-mb",
@@ -91,6 +105,14 @@ func (c *ChainPlugin) SetProgramOptions(options *[]cli.Flag) {
 			Name:  "contract-blacklist",
 			Usage: "Contract account added to contract blacklist (may specify multiple times)",
 		},
+		cli.StringSliceFlag{
+			Name:  "contract-debug-account",
+			Usage: "Contract account whose failed actions get the wasm stack trace attached (may specify multiple times)",
+		},
+		cli.StringFlag{
+			Name:  "contract-trace-dir",
+			Usage: "Directory (absolute or relative to application data dir) every wasm instruction executed by a contract-debug-account is traced to",
+		},
 		cli.StringSliceFlag{
 			Name:  "action-blacklist",
 			Usage: "Action (in the form code::action) added to action blacklist (may specify multiple times)",
@@ -108,9 +130,10 @@ func (c *ChainPlugin) SetProgramOptions(options *[]cli.Flag) {
 		},
 		cli.StringFlag{
 			Name: "validation-mode",
-			Usage: "Chain validation mode (\"full\" or \"light\").\n" +
+			Usage: "Chain validation mode (\"full\", \"light\" or \"header-only\").\n" +
 				"In \"full\" mode all incoming blocks will be fully validated.\n" +
-				"In \"light\" mode all incoming blocks headers will be fully validated; transactions in those validated blocks will be trusted \n",
+				"In \"light\" mode all incoming blocks headers will be fully validated; transactions in those validated blocks will be trusted \n" +
+				"In \"header-only\" mode only the producer signatures and schedule changes of incoming blocks are verified and no transaction is applied; it implies read-mode = readonly \n",
 		},
 		cli.BoolFlag{
 			Name:  "disable-ram-billing-notify-checks",
@@ -217,6 +240,16 @@ func (c *ChainPlugin) PluginInitialize(options *cli.Context) {
 	for _, constract := range options.StringSlice("contract-blacklist") {
 		c.my.ChainConfig.ContractBlacklist.Add(N(constract))
 	}
+	for _, constract := range options.StringSlice("contract-debug-account") {
+		c.my.ChainConfig.ContractDebugAccounts.Add(N(constract))
+	}
+	if dir := options.String("contract-trace-dir"); dir != "" {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(App().DataDir(), dir)
+		}
+		c.my.ChainConfig.ContractTraceDir = dir
+	}
+	c.my.ChainConfig.ActionReceiptsRetention = uint32(options.Uint("action-receipts-retention-blocks"))
 
 	for _, producer := range options.StringSlice("trusted-producer") {
 		c.my.ChainConfig.TrustedProducers.Add(N(producer))
@@ -245,7 +278,11 @@ func (c *ChainPlugin) PluginInitialize(options *cli.Context) {
 		log.Debug(cp)
 	}
 
-	//TODO wasm-runtime: just use wasmgo?
+	if runtime := options.String("wasm-runtime"); runtime != "" {
+		vmType, err := wasmgo.ParseVmType(runtime)
+		EosAssert(err == nil, &PluginConfigException{}, "%s", err)
+		c.my.ChainConfig.VmType = vmType
+	}
 
 	if ms := options.Uint("abi-serializer-max-time-ms"); ms > 0 {
 		c.my.AbiSerializerMaxTimeMs = Microseconds(ms * 1000)
@@ -253,6 +290,19 @@ func (c *ChainPlugin) PluginInitialize(options *cli.Context) {
 		c.my.AbiSerializerMaxTimeMs = Microseconds(DefaultConfig.DefaultAbiSerializerMaxTimeMs)
 	}
 
+	c.my.MaxDecompressedTrxSize = DefaultConfig.MaxDecompressedTrxSize
+	if kb := options.Uint("max-decompressed-trx-size-kb"); kb > 0 {
+		c.my.MaxDecompressedTrxSize = uint64(kb) * 1024
+	}
+
+	// the option belongs to the producer plugin, it is unset when the node does not load it
+	if ms := options.Int("max-transaction-age"); ms != 0 {
+		c.my.MaxTransactionTime = Milliseconds(int64(ms))
+	}
+
+	c.my.ReadOnlyThreads = options.Int("read-only-threads")
+	EosAssert(c.my.ReadOnlyThreads >= 0, &PluginConfigException{}, "read-only-threads can not be negative")
+
 	c.my.ChainConfig.BlocksDir = c.my.BlockDir
 	c.my.ChainConfig.StateDir = App().DataDir() + "/" + DefaultConfig.DefaultStateDirName
 	c.my.ChainConfig.ReadOnly = c.my.Readonly
@@ -271,7 +321,6 @@ func (c *ChainPlugin) PluginInitialize(options *cli.Context) {
 	//	c.my.ChainConfig.ReversibleGuardSize = mb * 1024 * 1024
 	//}
 
-	//TODO handle wasm-runtime
 	c.my.ChainConfig.ForceAllChecks = options.Bool("force-all-checks")
 	c.my.ChainConfig.DisableReplayOpts = options.Bool("disable-replay-opts")
 	c.my.ChainConfig.ContractsConsole = options.Bool("contracts-console")
@@ -389,6 +438,11 @@ func (c *ChainPlugin) PluginInitialize(options *cli.Context) {
 		if blockValidationMode, ok := chain.ValidationModeFromString(validationMode); ok {
 			c.my.ChainConfig.BlockValidationMode = blockValidationMode
 		}
+		if c.my.ChainConfig.BlockValidationMode == chain.HEADER_ONLY {
+			EosAssert(options.String("read-mode") == "" || c.my.ChainConfig.ReadMode == chain.READONLY, &PluginConfigException{},
+				"header-only validation mode requires read-mode = readonly")
+			c.my.ChainConfig.ReadMode = chain.READONLY
+		}
 	} else {
 		c.my.ChainConfig.BlockValidationMode = chain.FULL
 	}
@@ -402,6 +456,9 @@ func (c *ChainPlugin) PluginInitialize(options *cli.Context) {
 
 	// relay signals to channels
 	//TODO
+
+	c.my.FinalityTracker = newFinalityTracker(c.my.Chain)
+	c.my.BlockMerkle = newBlockMerkleTracker(c.my.Chain, c.my.ChainConfig.BlocksDir+"/"+DefaultConfig.BlockMerkleName)
 }
 
 func (c *ChainPlugin) PluginStartup() {
@@ -414,33 +471,61 @@ func (c *ChainPlugin) PluginStartup() {
 		c.logGuardException(e)
 		Throw(e)
 	})
+	c.my.BlockMerkle.start(c.my.Chain.LastIrreversibleBlockNum())
 
 	if !c.my.Readonly {
 		log.Info("starting chain in read/write mode")
 	}
 
+	if c.my.ReadOnlyThreads > 0 {
+		c.my.ReadPool = newReadPool(c.my.ReadOnlyThreads)
+	}
+
 	log.Info("Blockchain started; head block is #%d, genesis timestamp is %s", c.my.Chain.HeadBlockNum(), c.my.ChainConfig.Genesis.InitialTimestamp)
 }
 
 func (c *ChainPlugin) PluginShutdown() {
+	if c.my.ReadPool != nil {
+		c.my.ReadPool.stop()
+	}
+	c.my.BlockMerkle.close()
 	c.my.Chain.Close()
 	log.Info("chain plugin shutdown")
 }
 
 func (c *ChainPlugin) GetReadOnlyApi() *ReadOnly {
-	return NewReadOnly(c.Chain(), c.GetAbiSerializerMaxTime())
+	ro := NewReadOnly(c.Chain(), c.GetAbiSerializerMaxTime())
+	ro.maxDecompressedTrxSize = c.my.MaxDecompressedTrxSize
+	ro.blockMerkle = c.my.BlockMerkle.nodes
+	ro.maxTransactionTime = c.my.MaxTransactionTime
+	return ro
 }
 
 func (c *ChainPlugin) GetReadWriteApi() *ReadWrite {
-	return NewReadWrite(c.Chain(), c.GetAbiSerializerMaxTime())
+	rw := NewReadWrite(c.Chain(), c.GetAbiSerializerMaxTime())
+	rw.finality = c.my.FinalityTracker
+	rw.maxDecompressedTrxSize = c.my.MaxDecompressedTrxSize
+	return rw
 }
 
 func (c *ChainPlugin) AcceptBlock(block *types.SignedBlock) {
 	c.my.IncomingBlockSyncMethod.CallMethods(block)
 }
 
+func (c *ChainPlugin) AcceptConfirmation(confirmation *types.HeaderConfirmation) {
+	c.my.IncomingConfirmationSyncMethod.CallMethods(confirmation)
+}
+
 func (c *ChainPlugin) AcceptTransaction(trx *types.PackedTransaction, next chain_interface.NextFunction) {
-	c.my.IncomingTransactionAsyncMethod.CallMethods(trx, false, next)
+	accepted := false
+	Try(func() {
+		trx.CheckDecompressedSize(c.my.MaxDecompressedTrxSize)
+		accepted = true
+	}).CatchAndCall(next).End()
+
+	if accepted {
+		c.my.IncomingTransactionAsyncMethod.CallMethods(trx, false, next)
+	}
 }
 
 func (c *ChainPlugin) RecoverReversibleBlocks(dbDir string, cacheSize uint32, newDbDir string, truncateAtBlock uint32) bool {
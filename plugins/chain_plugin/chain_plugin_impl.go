@@ -23,6 +23,8 @@ type ChainPluginImpl struct {
 
 	//fc::optional<vm_type>            wasm_runtime;
 	AbiSerializerMaxTimeMs common.Microseconds
+	MaxDecompressedTrxSize uint64              // applied to the transactions received from the api and the peers
+	MaxTransactionTime     common.Microseconds // applied to the dry run transactions like to the pushed ones
 	//fc::optional<bfs::path>          snapshot_path;
 
 	// retained references to channels for easy publication
@@ -38,6 +40,7 @@ type ChainPluginImpl struct {
 	// retained references to methods for easy calling
 	IncomingBlockSyncMethod        *include.Method
 	IncomingTransactionAsyncMethod *include.Method
+	IncomingConfirmationSyncMethod *include.Method
 
 	// method provider handles
 	GetBlockByNumberProvider               *include.Method
@@ -46,11 +49,22 @@ type ChainPluginImpl struct {
 	GetLastIrreversibleBlockNumberProvider *include.Method
 
 	// scoped connections for chain controller
+
+	// transactions waiting to be reported once included or irreversible
+	FinalityTracker *finalityTracker
+
+	// roots of the merkle tree over the irreversible block ids, shared by the block proofs
+	BlockMerkle *blockMerkleTracker
+
+	ReadOnlyThreads int
+	ReadPool        *readPool
 }
 
 func NewChainPluginImpl() *ChainPluginImpl {
 	return &ChainPluginImpl{
 		IncomingBlockSyncMethod:        app.App().GetMethod(BlockSync),
 		IncomingTransactionAsyncMethod: app.App().GetMethod(TransactionAsync),
+		IncomingConfirmationSyncMethod: app.App().GetMethod(ConfirmationSync),
+		MaxTransactionTime:             common.Milliseconds(defaultMaxTransactionTimeMs),
 	}
 }
@@ -8,7 +8,13 @@ import (
 	"github.com/eosspark/eos-go/common"
 	"github.com/eosspark/eos-go/crypto"
 	"github.com/eosspark/eos-go/crypto/ecc"
+	. "github.com/eosspark/eos-go/exception"
+	"github.com/eosspark/eos-go/plugins/appbase/app"
+	. "github.com/eosspark/eos-go/plugins/chain_interface"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"sync"
 	"testing"
 )
 
@@ -81,3 +87,260 @@ func TestConvertUint64(t *testing.T) {
 	c := convertToUint64("sys", "c")
 	fmt.Println(c)
 }
+
+func TestSendTransactionsParams(t *testing.T) {
+	var params SendTransactionsParams
+	err := json.Unmarshal([]byte(`{"transactions":[{},{}],"wait_for":"irreversible"}`), &params)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(params.Transactions))
+	assert.Equal(t, FinalityIrreversible, params.WaitFor)
+
+	err = json.Unmarshal([]byte(`{"transactions":[],"wait_for":"finalized"}`), &params)
+	assert.Error(t, err)
+
+	body, err := json.Marshal(FinalityIncluded)
+	assert.NoError(t, err)
+	assert.Equal(t, `"included"`, string(body))
+}
+
+func TestFinalityTracker(t *testing.T) {
+	f := &finalityTracker{pending: make(map[common.TransactionIdType][]*finalityWaiter)}
+
+	id := *crypto.Hash256String("trx")
+	blockId := *crypto.Hash256String("block")
+
+	var included, irreversible *SendTransactionResult
+	f.wait(&finalityWaiter{
+		result:     SendTransactionResult{PushTransactionResult: PushTransactionResult{TransactionId: id}},
+		expiration: common.MaxTimePointSec(),
+		finality:   FinalityIncluded,
+		done:       func(r SendTransactionResult) { included = &r },
+	})
+	f.wait(&finalityWaiter{
+		result:     SendTransactionResult{PushTransactionResult: PushTransactionResult{TransactionId: id}},
+		expiration: common.MaxTimePointSec(),
+		finality:   FinalityIrreversible,
+		done:       func(r SendTransactionResult) { irreversible = &r },
+	})
+
+	bsp := &types.BlockState{SignedBlock: &types.SignedBlock{}}
+	bsp.BlockNum = 10
+	bsp.BlockId = blockId
+	bsp.SignedBlock.Transactions = []types.TransactionReceipt{{Trx: types.TransactionWithID{TransactionID: id}}}
+
+	f.onAcceptedBlock(bsp)
+	assert.NotNil(t, included)
+	assert.Equal(t, uint32(10), included.BlockNum)
+	assert.Nil(t, irreversible)
+
+	f.onIrreversibleBlock(bsp)
+	assert.NotNil(t, irreversible)
+	assert.Equal(t, blockId, irreversible.BlockId)
+	assert.True(t, irreversible.Irreversible)
+	assert.Equal(t, 0, len(f.pending))
+}
+
+func TestFinalityTrackerFork(t *testing.T) {
+	f := &finalityTracker{pending: make(map[common.TransactionIdType][]*finalityWaiter)}
+	id := *crypto.Hash256String("trx")
+
+	var irreversible, expired *SendTransactionResult
+	f.wait(&finalityWaiter{
+		result:     SendTransactionResult{PushTransactionResult: PushTransactionResult{TransactionId: id}},
+		expiration: common.MaxTimePointSec(),
+		finality:   FinalityIrreversible,
+		done:       func(r SendTransactionResult) { irreversible = &r },
+	})
+	f.wait(&finalityWaiter{
+		result:     SendTransactionResult{PushTransactionResult: PushTransactionResult{TransactionId: *crypto.Hash256String("other")}},
+		expiration: common.NewTimePointSecTp(types.BlockTimeStamp(50).ToTimePoint()),
+		finality:   FinalityIrreversible,
+		done:       func(r SendTransactionResult) { expired = &r },
+	})
+
+	block := func(num uint32, name string, slot uint32, ids ...common.TransactionIdType) *types.BlockState {
+		bsp := &types.BlockState{SignedBlock: &types.SignedBlock{}}
+		bsp.BlockNum = num
+		bsp.BlockId = *crypto.Hash256String(name)
+		bsp.Header.Timestamp = types.BlockTimeStamp(slot)
+		for _, id := range ids {
+			bsp.SignedBlock.Transactions = append(bsp.SignedBlock.Transactions, types.TransactionReceipt{Trx: types.TransactionWithID{TransactionID: id}})
+		}
+		return bsp
+	}
+
+	// the transaction is included in 10a, then the chain switches to 10b and 11b which includes it again
+	f.onAcceptedBlock(block(10, "10a", 1, id))
+	f.onAcceptedBlock(block(10, "10b", 2))
+	f.onAcceptedBlock(block(11, "11b", 3, id))
+	f.onIrreversibleBlock(block(10, "10b", 2))
+	assert.Nil(t, irreversible)
+	assert.Nil(t, expired)
+
+	f.onIrreversibleBlock(block(11, "11b", 3, id))
+	assert.NotNil(t, irreversible)
+	assert.Equal(t, uint32(11), irreversible.BlockNum)
+	assert.Nil(t, irreversible.Processed["error"])
+
+	// the other transaction expires, its waiter is reported once
+	f.onAcceptedBlock(block(12, "12b", 100))
+	assert.NotNil(t, expired)
+	assert.NotNil(t, expired.Processed["error"])
+	assert.Equal(t, 0, len(f.pending))
+}
+
+func TestBlockMerkleTracker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "block_merkle")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ids := make([]common.BlockIdType, 2100)
+	for i := range ids {
+		ids[i] = *crypto.Hash256String(fmt.Sprintf("block-%d", i+1))
+	}
+	reads := 0
+	tracker := func(ids []common.BlockIdType) *blockMerkleTracker {
+		m := &blockMerkleTracker{nodes: types.NewMerkleNodes(blockMerkleBaseLevel), file: dir + "/block_merkle.dat"}
+		m.blockId = func(blockNum uint32) common.BlockIdType {
+			reads++
+			return ids[blockNum-1]
+		}
+		m.load()
+		return m
+	}
+	expected := func(ids []common.BlockIdType) types.MerkleNodes {
+		nodes := types.NewMerkleNodes(blockMerkleBaseLevel)
+		for _, id := range ids {
+			nodes.Append(common.DigestType(id))
+		}
+		return *nodes
+	}
+	irreversible := func(m *blockMerkleTracker, num uint32) {
+		bsp := &types.BlockState{}
+		bsp.BlockNum = num
+		bsp.BlockId = ids[num-1]
+		m.onIrreversibleBlock(bsp)
+	}
+
+	// the blocks which became irreversible before the start are read from the block log once
+	m := tracker(ids)
+	irreversible(m, 5)
+	assert.Equal(t, uint64(0), m.nodes.Count())
+	m.start(1500)
+	assert.Equal(t, 1500, reads)
+	irreversible(m, 1501)
+	irreversible(m, 1503)
+	irreversible(m, 1503)
+	assert.Equal(t, 1501, reads)
+	assert.Equal(t, expected(ids[:1503]), *m.nodes)
+	assert.Equal(t, ids[1502], m.lastId)
+
+	// the nodes saved with the first complete subtree are caught up on the next start
+	reads = 0
+	m = tracker(ids)
+	assert.Equal(t, uint64(1024), m.nodes.Count())
+	m.start(1600)
+	assert.Equal(t, 1+1600-1024, reads)
+	assert.Equal(t, expected(ids[:1600]), *m.nodes)
+
+	// the nodes saved at shutdown are not read again
+	m.close()
+	reads = 0
+	m = tracker(ids)
+	m.start(1600)
+	assert.Equal(t, 1, reads)
+	assert.Equal(t, expected(ids[:1600]), *m.nodes)
+
+	// nodes over other ids than the block log are rebuilt
+	other := append([]common.BlockIdType{}, ids...)
+	other[1599] = *crypto.Hash256String("fork")
+	m = tracker(other)
+	m.start(1600)
+	assert.Equal(t, expected(other[:1600]), *m.nodes)
+	m = tracker(other)
+	m.start(1000)
+	assert.Equal(t, expected(other[:1000]), *m.nodes)
+}
+
+var pushCaller func(trx *types.PackedTransaction, next NextFunction)
+var registerPushCaller sync.Once
+
+//setPushCaller replaces the producer behind transaction_async, the method keeps every registered caller
+func setPushCaller(caller func(trx *types.PackedTransaction, next NextFunction)) {
+	pushCaller = caller
+	registerPushCaller.Do(func() {
+		app.App().GetMethod(TransactionAsync).Register(&TransactionAsyncCaller{
+			Caller: func(trx *types.PackedTransaction, persistUntilExpired bool, next NextFunction) {
+				pushCaller(trx, next)
+			}})
+	})
+}
+
+func TestPushRecurseReportsOnce(t *testing.T) {
+	setPushCaller(func(trx *types.PackedTransaction, next NextFunction) {
+		next(&types.TransactionTrace{ID: trx.ID()})
+	})
+
+	var param PushTransactionParams
+	common.ToVariant(types.NewPackedTransactionByTrx(&types.Transaction{}, types.CompressionNone), &param)
+	params := PushTransactionsParams{param, param}
+
+	rw := &ReadWrite{}
+	results := 0
+	assert.Panics(t, func() {
+		rw.pushRecurse(params, 0, func(r SendTransactionResult, _ common.TimePointSec) {
+			assert.Nil(t, r.Processed["error"])
+			results++
+		}, func() {
+			panic("failure after the last transaction")
+		})
+	})
+	assert.Equal(t, len(params), results)
+}
+
+func TestPushRecurseReportsIds(t *testing.T) {
+	setPushCaller(func(trx *types.PackedTransaction, next NextFunction) {
+		if trx.GetTransaction().RefBlockNum == 2 {
+			next(&TxDuplicate{})
+			return
+		}
+		next(&types.TransactionTrace{ID: trx.ID()})
+	})
+
+	packed := func(refBlockNum uint16) (*types.PackedTransaction, PushTransactionParams) {
+		ptrx := types.NewPackedTransactionByTrx(&types.Transaction{TransactionHeader: types.TransactionHeader{RefBlockNum: refBlockNum}}, types.CompressionNone)
+		var param PushTransactionParams
+		common.ToVariant(ptrx, &param)
+		return ptrx, param
+	}
+	executed, executedParam := packed(1)
+	failed, failedParam := packed(2)
+	malformed := PushTransactionParams{"compression": "none", "packed_trx": "00ff"}
+
+	var results []SendTransactionResult
+	rw := &ReadWrite{}
+	rw.pushRecurse(PushTransactionsParams{executedParam, failedParam, malformed}, 0, func(r SendTransactionResult, _ common.TimePointSec) {
+		results = append(results, r)
+	}, func() {})
+
+	// the failures carry the id of their transaction as well
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, executed.ID(), results[0].TransactionId)
+	assert.Nil(t, results[0].Processed["error"])
+	assert.Equal(t, failed.ID(), results[1].TransactionId)
+	assert.NotNil(t, results[1].Processed["error"])
+	assert.Equal(t, *crypto.Hash256String(string([]byte{0x00, 0xff})), results[2].TransactionId)
+	assert.NotNil(t, results[2].Processed["error"])
+}
+
+func TestReadPoolFull(t *testing.T) {
+	p := &readPool{jobs: make(chan func(), 1)}
+	ran := 0
+	assert.True(t, p.post(func() { ran++ }))
+	assert.False(t, p.post(func() { ran++ }), "a full queue refuses the job instead of blocking")
+
+	p.wg.Add(1)
+	go p.run()
+	p.stop()
+	assert.Equal(t, 1, ran)
+}
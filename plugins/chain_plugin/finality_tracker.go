@@ -0,0 +1,157 @@
+package chain_plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/eosspark/eos-go/chain"
+	"github.com/eosspark/eos-go/chain/types"
+	"github.com/eosspark/eos-go/common"
+	. "github.com/eosspark/eos-go/plugins/chain_interface"
+)
+
+// TransactionFinality selects the point at which a pushed transaction is reported back to the caller
+type TransactionFinality uint8
+
+const (
+	FinalityExecuted     = TransactionFinality(iota) // after speculative execution (push_transaction behaviour)
+	FinalityIncluded                                 // after the transaction is included in an accepted block
+	FinalityIrreversible                             // after the including block becomes irreversible
+)
+
+func (f TransactionFinality) String() string {
+	switch f {
+	case FinalityExecuted:
+		return "executed"
+	case FinalityIncluded:
+		return "included"
+	case FinalityIrreversible:
+		return "irreversible"
+	default:
+		return ""
+	}
+}
+
+func (f TransactionFinality) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+func (f *TransactionFinality) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+
+	switch s {
+	case "", "executed":
+		*f = FinalityExecuted
+	case "included":
+		*f = FinalityIncluded
+	case "irreversible":
+		*f = FinalityIrreversible
+	default:
+		return fmt.Errorf("unknown transaction finality: %s", s)
+	}
+	return nil
+}
+
+type finalityWaiter struct {
+	result     SendTransactionResult
+	expiration common.TimePointSec
+	finality   TransactionFinality
+	done       func(SendTransactionResult)
+}
+
+// finalityTracker holds transactions whose result must be delayed until they are included in
+// a block or that block becomes irreversible. It is driven by the controller signals, which are
+// emitted on the application thread, so it needs no locking.
+//
+// A waiter keeps the last accepted block which included its transaction. A fork switch applies
+// the blocks of the new branch again, so a transaction included by the new branch is moved to
+// its new block before the old one is reported forked out by the irreversible block.
+type finalityTracker struct {
+	pending map[common.TransactionIdType][]*finalityWaiter
+}
+
+func newFinalityTracker(chain *chain.Controller) *finalityTracker {
+	f := &finalityTracker{
+		pending: make(map[common.TransactionIdType][]*finalityWaiter),
+	}
+
+	chain.AcceptedBlock.Connect(&AcceptedBlockCaller{Caller: f.onAcceptedBlock})
+	chain.IrreversibleBlock.Connect(&IrreversibleBlockCaller{Caller: f.onIrreversibleBlock})
+	return f
+}
+
+func (f *finalityTracker) wait(w *finalityWaiter) {
+	id := w.result.TransactionId
+	f.pending[id] = append(f.pending[id], w)
+}
+
+// filter keeps the waiters for which keep returns true
+func (f *finalityTracker) filter(keep func(w *finalityWaiter) bool) {
+	for id, waiters := range f.pending {
+		remain := waiters[:0]
+		for _, w := range waiters {
+			if keep(w) {
+				remain = append(remain, w)
+			}
+		}
+		if len(remain) == 0 {
+			delete(f.pending, id)
+		} else {
+			f.pending[id] = remain
+		}
+	}
+}
+
+func (f *finalityTracker) onAcceptedBlock(bsp *types.BlockState) {
+	for _, receipt := range bsp.SignedBlock.Transactions {
+		var id common.TransactionIdType
+		if !common.Empty(receipt.Trx.PackedTransaction) {
+			id = receipt.Trx.PackedTransaction.ID()
+		} else {
+			id = receipt.Trx.TransactionID
+		}
+
+		for _, w := range f.pending[id] {
+			w.result.BlockNum = bsp.BlockNum
+			w.result.BlockId = bsp.BlockId
+		}
+	}
+
+	blockTime := bsp.Header.Timestamp.ToTimePoint()
+	f.filter(func(w *finalityWaiter) bool {
+		if w.result.BlockNum != 0 {
+			if w.finality == FinalityIncluded {
+				w.done(w.result)
+				return false
+			}
+			return true
+		}
+		// transactions that can no longer be included will never be reported otherwise
+		if w.expiration.ToTimePoint() < blockTime {
+			w.result.Processed = common.Variants{"error": "transaction expired before it was included in a block"}
+			w.done(w.result)
+			return false
+		}
+		return true
+	})
+}
+
+func (f *finalityTracker) onIrreversibleBlock(bsp *types.BlockState) {
+	f.filter(func(w *finalityWaiter) bool {
+		if w.result.BlockNum == 0 || w.result.BlockNum > bsp.BlockNum {
+			return true
+		}
+		if w.result.BlockNum == bsp.BlockNum && w.result.BlockId != bsp.BlockId {
+			// the including block was forked out and the new branch did not include the transaction yet
+			w.result.BlockNum = 0
+			w.result.BlockId = common.BlockIdType{}
+			return true
+		}
+		w.result.Irreversible = true
+		w.done(w.result)
+		return false
+	})
+}
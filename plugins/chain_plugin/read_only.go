@@ -1,13 +1,14 @@
 package chain_plugin
 
 import (
-	"fmt"
 	"github.com/eosspark/eos-go/chain"
 	"github.com/eosspark/eos-go/chain/abi_serializer"
 	"github.com/eosspark/eos-go/chain/types"
+	. "github.com/eosspark/eos-go/chain/types/generated_containers"
 	"github.com/eosspark/eos-go/common"
 	math "github.com/eosspark/eos-go/common/eos_math"
 	"github.com/eosspark/eos-go/crypto"
+	"github.com/eosspark/eos-go/crypto/ecc"
 	"github.com/eosspark/eos-go/crypto/rlp"
 	"github.com/eosspark/eos-go/database"
 	. "github.com/eosspark/eos-go/entity"
@@ -29,17 +30,53 @@ const (
 )
 
 type ReadOnly struct {
-	db                   *chain.Controller
-	abiSerializerMaxTime common.Microseconds
-	shortenAbiErrors     bool
+	db                     *chain.Controller
+	view                   *chain.StateView
+	abiSerializerMaxTime   common.Microseconds
+	maxDecompressedTrxSize uint64
+	shortenAbiErrors       bool
+	blockMerkle            *types.MerkleNodes
+	maxTransactionTime     common.Microseconds
+	queryTimeBudget        common.Microseconds
 }
 
+// the default of the max-transaction-age option of the producer plugin
+const defaultMaxTransactionTimeMs = 30
+
+// the time a query listing rows spends before it stops and reports where to continue
+const defaultQueryTimeBudget = common.Microseconds(1000 * 10)
+
 func NewReadOnly(db *chain.Controller, abiSerializerMaxTime common.Microseconds) *ReadOnly {
-	return &ReadOnly{db: db, abiSerializerMaxTime: abiSerializerMaxTime}
+	return &ReadOnly{db: db, abiSerializerMaxTime: abiSerializerMaxTime, maxDecompressedTrxSize: common.DefaultConfig.MaxDecompressedTrxSize,
+		blockMerkle: types.NewMerkleNodes(blockMerkleBaseLevel), maxTransactionTime: common.Milliseconds(defaultMaxTransactionTimeMs),
+		queryTimeBudget: defaultQueryTimeBudget}
+}
+
+// SetQueryTimeBudget sets the time a query listing rows spends before it stops and reports where to continue
+func (ro *ReadOnly) SetQueryTimeBudget(budget common.Microseconds) {
+	ro.queryTimeBudget = budget
 }
 
-func GetAbi(db *chain.Controller, account common.Name) abi_serializer.AbiDef {
-	d := db.DataBase()
+// SetMaxTransactionTime bounds the execution time of the dry run transactions, a negative time does not bound it
+func (ro *ReadOnly) SetMaxTransactionTime(maxTransactionTime common.Microseconds) {
+	ro.maxTransactionTime = maxTransactionTime
+}
+
+// WithView returns a copy of the api answering state queries from view instead of the live database
+func (ro *ReadOnly) WithView(view *chain.StateView) *ReadOnly {
+	bound := *ro
+	bound.view = view
+	return &bound
+}
+
+func (ro *ReadOnly) stateDB() database.DataBase {
+	if ro.view != nil {
+		return ro.view.DB
+	}
+	return ro.db.DataBase()
+}
+
+func GetAbi(d database.DataBase, account common.Name) abi_serializer.AbiDef {
 	codeAccnt := &AccountObject{Name: account}
 	err := d.Find("byName", codeAccnt, codeAccnt)
 	EosAssert(err == nil, &AccountQueryException{}, "Fail to retrieve account for %s", account)
@@ -68,7 +105,7 @@ func (ro *ReadOnly) SetShortenAbiErrors(f bool) {
 }
 
 func (ro *ReadOnly) WalkKeyValueTable(code, scope, table common.Name, f func(KeyValueObject) bool) {
-	db := ro.db.DataBase()
+	db := ro.stateDB()
 	tid := TableIdObject{Code: code, Scope: scope, Table: table}
 	err := db.Find("byCodeScopeTable", tid, &tid)
 	if err == nil { //TODO: check miss or error
@@ -114,24 +151,43 @@ func (ro *ReadOnly) GetInfo() *GetInfoResult {
 	}
 }
 
-func (ro *ReadOnly) GetBlock(params GetBlockParams) *GetBlockResult {
+func (ro *ReadOnly) fetchBlock(blockNumOrID string) *types.SignedBlock {
 	var block *types.SignedBlock
-	var blockNum uint64
 
-	EosAssert(len(params.BlockNumOrID) != 0 && len(params.BlockNumOrID) <= 64, &BlockIdTypeException{},
+	EosAssert(len(blockNumOrID) != 0 && len(blockNumOrID) <= 64, &BlockIdTypeException{},
 		"Invalid Block number or ID,must be greater than 0 and less than 64 characters ")
 
-	num, ok := math.ParseUint64(params.BlockNumOrID)
+	num, ok := math.ParseUint64(blockNumOrID)
+	if ok {
+		block = ro.db.FetchBlockByNumber(uint32(num))
+	} else {
+		Try(func() {
+			block = ro.db.FetchBlockById(*crypto.NewSha256String(blockNumOrID))
+		}).EosRethrowExceptions(&BlockIdTypeException{}, "Invalid block ID: %s", blockNumOrID).End()
+	}
+
+	EosAssert(block != nil, &UnknownBlockException{}, "Could not find block: %s", blockNumOrID)
+	return block
+}
+
+func (ro *ReadOnly) fetchBlockState(blockNumOrID string) *types.BlockState {
+	var b *types.BlockState
+
+	num, ok := math.ParseUint64(blockNumOrID)
 	if ok {
-		blockNum = num
-		block = ro.db.FetchBlockByNumber(uint32(blockNum))
+		b = ro.db.FetchBlockStateByNumber(uint32(num))
 	} else {
 		Try(func() {
-			block = ro.db.FetchBlockById(*crypto.NewSha256String(params.BlockNumOrID))
-		}).EosRethrowExceptions(&BlockIdTypeException{}, "Invalid block ID: %s", params.BlockNumOrID).End()
+			b = ro.db.FetchBlockStateById(*crypto.NewSha256String(blockNumOrID))
+		}).EosRethrowExceptions(&BlockIdTypeException{}, "Invalid block ID: %s", blockNumOrID).End()
 	}
 
-	EosAssert(block != nil, &UnknownBlockException{}, "Could not find block: %s", params.BlockNumOrID)
+	EosAssert(b != nil, &UnknownBlockException{}, "Could not find reversible block: %s", blockNumOrID)
+	return b
+}
+
+func (ro *ReadOnly) GetBlock(params GetBlockParams) *GetBlockResult {
+	block := ro.fetchBlock(params.BlockNumOrID)
 
 	refBlockPrefix := uint32(block.BlockID().Hash[1])
 
@@ -144,22 +200,258 @@ func (ro *ReadOnly) GetBlock(params GetBlockParams) *GetBlockResult {
 }
 
 func (ro *ReadOnly) GetBlockHeaderState(params GetBlockHeaderStateParams) GetBlockHeaderStateResult {
-	var blockNum uint64
-	var b *types.BlockState
+	return ro.fetchBlockState(params.BlockNumOrID).BlockHeaderState
+}
 
-	num, ok := math.ParseUint64(params.BlockNumOrID)
-	if ok {
-		blockNum = num
-		b = ro.db.FetchBlockStateByNumber(uint32(blockNum))
+/**
+ * The headers of the blocks the node accepted, their producer signatures and schedule changes verified.
+ * A header-only node serves them without executing the transactions of the blocks.
+ */
+func (ro *ReadOnly) GetBlockHeader(params GetBlockHeaderParams) GetBlockHeaderResult {
+	block := ro.fetchBlock(params.BlockNumOrID)
+
+	return GetBlockHeaderResult{
+		ID:           block.BlockID(),
+		BlockNum:     block.BlockNumber(),
+		Header:       block.SignedBlockHeader,
+		Irreversible: block.BlockNumber() <= ro.db.LastIrreversibleBlockNum(),
+	}
+}
+
+func (ro *ReadOnly) GetTransactionProof(params GetTransactionProofParams) GetTransactionProofResult {
+	block := ro.fetchBlock(params.BlockNumOrID)
+
+	digests := make([]common.DigestType, len(block.Transactions))
+	index := -1
+	for i := range block.Transactions {
+		receipt := &block.Transactions[i]
+		digests[i] = receipt.Digest()
+
+		id := receipt.Trx.TransactionID
+		if receipt.Trx.PackedTransaction != nil {
+			id = receipt.Trx.PackedTransaction.ID()
+		}
+		if id == params.ID {
+			index = i
+		}
+	}
+	EosAssert(index >= 0, &TxNotFound{}, "Could not find transaction %s in block %s", params.ID, params.BlockNumOrID)
+
+	return GetTransactionProofResult{
+		BlockID:  block.BlockID(),
+		BlockNum: block.BlockNumber(),
+		Header:   block.SignedBlockHeader,
+		Receipt:  block.Transactions[index],
+		Proof:    types.NewMerkleProof(digests, index),
+	}
+}
+
+/**
+ * Action receipts are not part of a block, the node keeps the receipts of the blocks it applied:
+ * with the block state while the block is reversible, in the action receipts database afterwards
+ * for the number of blocks given by the action-receipts-retention-blocks option.
+ */
+func (ro *ReadOnly) GetActionProof(params GetActionProofParams) GetActionProofResult {
+	var block *types.SignedBlock
+	if len(params.BlockNumOrID) != 0 {
+		block = ro.fetchBlock(params.BlockNumOrID)
 	} else {
+		for n := ro.db.ForkDbHeadBlockNum(); n > 0 && n >= ro.db.LastIrreversibleBlockNum() && block == nil; n-- {
+			bs := ro.db.FetchBlockStateByNumber(n)
+			if bs == nil || len(bs.ActionReceipts) == 0 {
+				continue
+			}
+			first, last := bs.ActionReceipts[0].GlobalSequence, bs.ActionReceipts[len(bs.ActionReceipts)-1].GlobalSequence
+			if params.GlobalSequence >= first && params.GlobalSequence <= last {
+				block = bs.SignedBlock
+			}
+		}
+		if block == nil {
+			if n := ro.db.FindActionReceiptsBlock(params.GlobalSequence); n != 0 {
+				block = ro.db.FetchBlockByNumber(n)
+			}
+		}
+		EosAssert(block != nil, &UnknownBlockException{}, "Could not find a block with action %d", params.GlobalSequence)
+	}
+
+	var receipts []types.ActionReceipt
+	if bs := ro.db.FetchBlockStateById(block.BlockID()); bs != nil && len(bs.ActionReceipts) != 0 {
+		receipts = bs.ActionReceipts
+	} else {
+		receipts = ro.db.FetchActionReceipts(block.BlockNumber())
+	}
+
+	digests := make([]common.DigestType, len(receipts))
+	index := -1
+	for i := range receipts {
+		digests[i] = receipts[i].Digest()
+		if receipts[i].GlobalSequence == params.GlobalSequence {
+			index = i
+		}
+	}
+	EosAssert(len(receipts) != 0 && types.Merkle(append([]common.DigestType{}, digests...)) == block.ActionMRoot, &UnknownBlockException{},
+		"action receipts of block %s are not available", block.BlockID())
+	EosAssert(index >= 0, &UnknownBlockException{}, "Could not find action %d in block %s", params.GlobalSequence, block.BlockID())
+
+	return GetActionProofResult{
+		BlockID:  block.BlockID(),
+		BlockNum: block.BlockNumber(),
+		Header:   block.SignedBlockHeader,
+		Receipt:  receipts[index],
+		Proof:    types.NewMerkleProof(digests, index),
+	}
+}
+
+/**
+ * The blockroot_merkle of a block commits to the ids of all the blocks before it. The roots of the
+ * complete subtrees over the irreversible ids are kept in blockMerkle by the chain plugin, the ids
+ * they do not cover are read from the block log, the reversible ids are read back from the reference
+ * block so that it may be on any branch of the fork database.
+ */
+func (ro *ReadOnly) GetBlockProof(params GetBlockProofParams) GetBlockProofResult {
+	block := ro.fetchBlock(params.BlockNumOrID)
+
+	reference := ro.db.HeadBlockState()
+	if len(params.ReferenceBlockNumOrID) != 0 {
+		reference = ro.fetchBlockState(params.ReferenceBlockNumOrID)
+	}
+	EosAssert(block.BlockNumber() < reference.BlockNum, &BlockValidateException{},
+		"block %d is not before the reference block %d", block.BlockNumber(), reference.BlockNum)
+
+	count := uint64(reference.BlockNum - 1)
+	irreversible := uint64(ro.db.LastIrreversibleBlockNum())
+	var reversible []common.DigestType
+	if count > irreversible {
+		reversible = make([]common.DigestType, count-irreversible)
+		for b := reference; ; {
+			reversible[uint64(b.BlockNum-2)-irreversible] = common.DigestType(b.Header.Previous)
+			if uint64(b.BlockNum-2) == irreversible {
+				break
+			}
+			b = ro.db.FetchBlockStateById(b.Header.Previous)
+			EosAssert(b != nil, &ForkDatabaseException{}, "reference block %s is not linked to the irreversible blocks", reference.BlockId)
+		}
+	}
+
+	leaf := func(index uint64) common.DigestType {
+		if index >= irreversible {
+			return reversible[index-irreversible]
+		}
+		return common.DigestType(ro.db.GetBlockIdForNum(uint32(index + 1)))
+	}
+	proof := types.NewMerkleProofFromNodes(count, uint64(block.BlockNumber()-1), func(level uint, index uint64) common.DigestType {
+		return ro.blockMerkle.Subtree(count, level, index, leaf)
+	})
+	EosAssert(types.VerifyBlockProof(&reference.BlockHeaderState, block.BlockID(), proof), &ForkDatabaseException{},
+		"block %s is not an ancestor of the reference block %s", block.BlockID(), reference.BlockId)
+
+	return GetBlockProofResult{
+		BlockID:   block.BlockID(),
+		BlockNum:  block.BlockNumber(),
+		Reference: reference.BlockHeaderState,
+		Proof:     proof,
+	}
+}
+
+/**
+ * DryRunTransaction executes a transaction without broadcasting it and always rolls it back. The action data
+ * of the traces is decoded with the abi of the contracts, the ram deltas are summed up per account.
+ * It must be called from the main thread.
+ */
+func (ro *ReadOnly) DryRunTransaction(params DryRunTransactionParams) DryRunTransactionResult {
+	ptrx := packedTransactionFromVariant(&params.Transaction, ro.maxDecompressedTrxSize)
+	deadline := common.MaxTimePoint()
+	if ro.maxTransactionTime >= 0 {
+		deadline = common.Now().AddUs(ro.maxTransactionTime)
+	}
+	trace := ro.db.DryRunTransaction(types.NewTransactionMetadata(ptrx), deadline, params.SkipSignatures)
+
+	result := DryRunTransactionResult{
+		TransactionID: trace.ID,
+		ActionTraces:  make([]DryRunActionTrace, 0, len(trace.ActionTraces)),
+		CpuUsageUs:    trace.Receipt.CpuUsageUs,
+		NetUsage:      trace.NetUsage,
+		RamDeltas:     make([]common.AccountDelta, 0),
+	}
+	if trace.Except != nil {
+		result.Except = &DryRunException{int64(trace.Except.Code()), trace.Except.String(), trace.Except.DetailMessage()}
+	}
+
+	resolver := ro.abiResolver()
+
+	totals := make(map[common.AccountName]int)
+	var convert func(at *types.ActionTrace) DryRunActionTrace
+	convert = func(at *types.ActionTrace) DryRunActionTrace {
+		t := DryRunActionTrace{
+			Receiver:         at.Receipt.Receiver,
+			Account:          at.Act.Account,
+			Name:             at.Act.Name,
+			Authorization:    at.Act.Authorization,
+			HexData:          at.Act.Data,
+			Console:          at.Console,
+			Elapsed:          at.Elapsed,
+			AccountRamDeltas: make([]common.AccountDelta, 0),
+			InlineTraces:     make([]DryRunActionTrace, 0, len(at.InlineTraces)),
+		}
+
+		t.Data = ro.actionDataToVariant(resolver, &at.Act)
+
+		for itr := at.AccountRamDeltas.Iterator(); itr.Next(); {
+			delta := itr.Value()
+			t.AccountRamDeltas = append(t.AccountRamDeltas, delta)
+			if i, ok := totals[delta.Account]; ok {
+				result.RamDeltas[i].Delta += delta.Delta
+			} else {
+				totals[delta.Account] = len(result.RamDeltas)
+				result.RamDeltas = append(result.RamDeltas, delta)
+			}
+		}
+
+		for i := range at.InlineTraces {
+			t.InlineTraces = append(t.InlineTraces, convert(&at.InlineTraces[i]))
+		}
+		return t
+	}
+
+	for i := range trace.ActionTraces {
+		result.ActionTraces = append(result.ActionTraces, convert(&trace.ActionTraces[i]))
+	}
+	return result
+}
+
+// abiResolver returns a resolver caching the abi serializers of the accounts, nil for the accounts without abi
+func (ro *ReadOnly) abiResolver() func(account common.AccountName) *abi_serializer.AbiSerializer {
+	serializers := make(map[common.AccountName]*abi_serializer.AbiSerializer)
+	return func(account common.AccountName) *abi_serializer.AbiSerializer {
+		if abis, ok := serializers[account]; ok {
+			return abis
+		}
+		var abis *abi_serializer.AbiSerializer
 		Try(func() {
-			b = ro.db.FetchBlockStateById(*crypto.NewSha256String(params.BlockNumOrID))
-		}).EosRethrowExceptions(&BlockIdTypeException{}, "Invalid block ID: %s", params.BlockNumOrID).End()
+			if abi := GetAbi(ro.stateDB(), account); !abi.IsEmpty() {
+				abis = abi_serializer.NewAbiSerializer(&abi, ro.abiSerializerMaxTime)
+			}
+		}).Catch(func(e Exception) {
+			// the account may only exist in a rolled back transaction
+		}).End()
+		serializers[account] = abis
+		return abis
 	}
-	fmt.Println(blockNum, b)
-	EosAssert(b != nil, &UnknownBlockException{}, "Could not find reversible block: %s", params.BlockNumOrID)
+}
 
-	return b.BlockHeaderState
+// actionDataToVariant decodes the data of act with the abi of its contract, nil if it cannot be decoded
+func (ro *ReadOnly) actionDataToVariant(resolver func(account common.AccountName) *abi_serializer.AbiSerializer, act *types.Action) common.Variants {
+	var data common.Variants
+	if abis := resolver(act.Account); abis != nil {
+		if actionType := abis.GetActionType(act.Name); len(actionType) > 0 {
+			Try(func() {
+				data = abis.BinaryToVariant(actionType, act.Data, ro.abiSerializerMaxTime, ro.shortenAbiErrors)
+			}).Catch(func(e Exception) {
+				// the data is left in hex only
+			}).End()
+		}
+	}
+	return data
 }
 
 type RamMarketExchangeState struct {
@@ -174,7 +466,7 @@ func (ro *ReadOnly) ExtractCoreSymbol() common.Symbol {
 	coreSymbol := common.Symbol{} // Default to CORE_SYMBOL if the appropriate data structure cannot be found in the system contract table data
 
 	// The following code makes assumptions about the contract deployed on eosio account (i.e. the system contract) and how it stores its data.
-	d := ro.db.DataBase()
+	d := ro.stateDB()
 	tid := TableIdObject{Code: common.N("eosio"), Scope: common.N("eosio"), Table: common.N("rammarket")}
 	err := d.Find("byCodeScopeTable", tid, &tid)
 	if err == nil {
@@ -203,21 +495,30 @@ func (ro *ReadOnly) GetAccount(params GetAccountParams) GetAccountResult {
 	var result GetAccountResult
 	result.AccountName = params.AccountName
 
-	d := ro.db.DataBase()
-	rm := ro.db.GetMutableResourceLimitsManager()
-
-	result.HeadBlockNum = ro.db.HeadBlockNum()
-	result.HeadBlockTime = ro.db.HeadBlockTime()
+	d := ro.stateDB()
+	var rm *chain.ResourceLimitsManager
+	var a *AccountObject
+	var grelisted bool
+	if ro.view != nil {
+		rm = ro.view.ResourceLimits
+		result.HeadBlockNum = ro.view.BlockNum
+		result.HeadBlockTime = ro.view.BlockTime
+		a = ro.view.GetAccount(result.AccountName)
+		grelisted = ro.view.IsResourceGreylisted(result.AccountName)
+	} else {
+		rm = ro.db.GetMutableResourceLimitsManager()
+		result.HeadBlockNum = ro.db.HeadBlockNum()
+		result.HeadBlockTime = ro.db.HeadBlockTime()
+		a = ro.db.GetAccount(result.AccountName)
+		grelisted = ro.db.IsResourceGreylisted(result.AccountName)
+	}
 
 	rm.GetAccountLimits(result.AccountName, &result.RAMQuota, &result.NetWeight, &result.CPUWeight)
 
-	a := ro.db.GetAccount(result.AccountName)
-
 	result.Privileged = a.Privileged
 	result.LastCodeUpdate = a.LastCodeUpdate
 	result.Created = a.CreationDate.ToTimePoint()
 
-	grelisted := ro.db.IsResourceGreylisted(result.AccountName)
 	result.NetLimit = rm.GetAccountNetLimitEx(result.AccountName, !grelisted)
 	result.CpuLimit = rm.GetAccountCpuLimitEx(result.AccountName, !grelisted)
 	result.RAMUsage = rm.GetAccountRamUsage(result.AccountName)
@@ -347,7 +648,7 @@ func (ro *ReadOnly) GetAbi(params GetAbiParams) GetAbiResult {
 	result := GetAbiResult{}
 	result.AccountName = params.AccountName
 
-	d := ro.db.DataBase()
+	d := ro.stateDB()
 
 	account := AccountObject{Name: params.AccountName}
 	if err := d.Find("byName", account, &account); err != nil {
@@ -364,7 +665,7 @@ func (ro *ReadOnly) GetAbi(params GetAbiParams) GetAbiResult {
 
 func (ro *ReadOnly) GetCode(params GetCodeParams) GetCodeResult {
 	result := GetCodeResult{AccountName: params.AccountName}
-	d := ro.db.DataBase()
+	d := ro.stateDB()
 
 	account := AccountObject{Name: params.AccountName}
 	if err := d.Find("byName", account, &account); err != nil {
@@ -393,6 +694,71 @@ func (ro *ReadOnly) GetRequiredKeys(params GetRequiredKeysParams) GetRequiredKey
 	return GetRequiredKeysResult{RequiredKeys: ro.db.GetAuthorizationManager().GetRequiredKeys(trx, &params.AvailableKeys, 0)}
 }
 
+/**
+ * ExplainAuthorization tells why the transaction is or is not authorized by the available keys, which are
+ * used in addition to the keys recovered from its signatures, the provided permissions and its delay.
+ */
+func (ro *ReadOnly) ExplainAuthorization(params ExplainAuthorizationParams) *chain.AuthorizationExplanation {
+	trx := &types.SignedTransaction{}
+	common.FromVariant(&params.Transaction, trx)
+
+	keys := NewPublicKeySet()
+	itr := params.AvailableKeys.Iterator()
+	for itr.Next() {
+		keys.Add(itr.Value())
+	}
+	if len(trx.Signatures) > 0 {
+		chainID := ro.db.GetChainId()
+		recovered := trx.GetSignatureKeys(&chainID, false, true)
+		itr = recovered.Iterator()
+		for itr.Next() {
+			keys.Add(itr.Value())
+		}
+	}
+
+	permissions := NewPermissionLevelSet()
+	for _, level := range params.ProvidedPermissions {
+		permissions.Add(level)
+	}
+
+	return ro.db.GetAuthorizationManager().ExplainAuthorization(trx.Actions, keys, permissions, common.Seconds(int64(trx.DelaySec)))
+}
+
+func (ro *ReadOnly) GetAccountsByAuthorizers(params GetAccountsByAuthorizersParams) GetAccountsByAuthorizersResult {
+	result := GetAccountsByAuthorizersResult{Accounts: make([]AccountByAuthorizer, 0)}
+	am := ro.db.GetAuthorizationManager()
+
+	for i := range params.Accounts {
+		level := params.Accounts[i]
+		EosAssert(!level.Actor.Empty(), &InvalidPermission{}, "Invalid authorizing account")
+
+		for _, pao := range am.GetPermissionsByAuthorizer(&level) {
+			result.Accounts = append(result.Accounts, AccountByAuthorizer{
+				AccountName:        pao.Owner,
+				PermissionName:     pao.Name,
+				AuthorizingAccount: &common.PermissionLevel{Actor: pao.AuthorizerActor, Permission: pao.AuthorizerPermission},
+				Weight:             pao.Weight,
+				Threshold:          pao.Threshold,
+			})
+		}
+	}
+
+	for i := range params.Keys {
+		key := params.Keys[i]
+		for _, pao := range am.GetPermissionsByKey(&key) {
+			result.Accounts = append(result.Accounts, AccountByAuthorizer{
+				AccountName:    pao.Owner,
+				PermissionName: pao.Name,
+				AuthorizingKey: &key,
+				Weight:         pao.Weight,
+				Threshold:      pao.Threshold,
+			})
+		}
+	}
+
+	return result
+}
+
 func (ro *ReadOnly) GetTableIndexName(p GetTableRowsParams, primary *bool) uint64 {
 	// see multi_index packing of index name
 	table := p.Table
@@ -440,7 +806,7 @@ func (ro *ReadOnly) GetTableIndexName(p GetTableRowsParams, primary *bool) uint6
 
 func (ro *ReadOnly) GetTableRowsEx(p GetTableRowsParams, abi *abi_serializer.AbiDef) GetTableRowsResult {
 	result := GetTableRowsResult{}
-	d := ro.db.DataBase()
+	d := ro.stateDB()
 	scope := convertToUint64(p.Scope, "scope")
 
 	abis := abi_serializer.AbiSerializer{}
@@ -481,7 +847,7 @@ func (ro *ReadOnly) GetTableRowsEx(p GetTableRowsParams, abi *abi_serializer.Abi
 		}
 
 		var data []byte
-		end := common.Now().AddUs(common.Microseconds(1000 * 10)) /// 10ms max time
+		end := common.Now().AddUs(ro.queryTimeBudget)
 
 		count := uint32(0)
 		itr := lower
@@ -510,7 +876,7 @@ func (ro *ReadOnly) GetTableRowsEx(p GetTableRowsParams, abi *abi_serializer.Abi
 }
 
 func (ro *ReadOnly) GetTableRows(p GetTableRowsParams) GetTableRowsResult {
-	abi := GetAbi(ro.db, p.Code)
+	abi := GetAbi(ro.stateDB(), p.Code)
 
 	primary := false
 	tableWithIndex := ro.GetTableIndexName(p, &primary)
@@ -531,7 +897,7 @@ func (ro *ReadOnly) GetTableRows(p GetTableRowsParams) GetTableRowsResult {
 }
 
 func (ro *ReadOnly) GetTableByScope(p GetTableByScopeParams) GetTableByScopeResult {
-	d := ro.db.DB
+	d := ro.stateDB()
 	idx, err := d.GetIndex("byCodeScopeTable", TableIdObject{})
 	Throw(err)
 	var lower database.Iterator
@@ -552,7 +918,7 @@ func (ro *ReadOnly) GetTableByScope(p GetTableByScopeParams) GetTableByScopeResu
 		upper, err = idx.LowerBound(TableIdObject{Code: p.Code + 1, Scope: 0, Table: 0})
 	}
 
-	end := common.Now().AddUs(common.Microseconds(1000 * 10))
+	end := common.Now().AddUs(ro.queryTimeBudget)
 	count := uint32(0)
 	itr := lower
 	result := GetTableByScopeResult{}
@@ -580,7 +946,7 @@ func (ro *ReadOnly) GetTableByScope(p GetTableByScopeParams) GetTableByScopeResu
 }
 
 func (ro *ReadOnly) GetCurrencyBalance(params GetCurrencyBalanceParams) GetCurrencyBalanceResult {
-	abi := GetAbi(ro.db, params.Code)
+	abi := GetAbi(ro.stateDB(), params.Code)
 	GetTableType(&abi, common.N("accounts"))
 
 	var results []common.Asset
@@ -607,7 +973,7 @@ func (ro *ReadOnly) GetCurrencyBalance(params GetCurrencyBalanceParams) GetCurre
 func (ro *ReadOnly) GetCurrencyStats(params GetCurrencyStatsParams) map[string]GetCurrencyStatsResult {
 	results := make(map[string]GetCurrencyStatsResult)
 
-	abi := GetAbi(ro.db, params.Code)
+	abi := GetAbi(ro.stateDB(), params.Code)
 	GetTableType(&abi, common.N("stat")) //assert if error
 
 	scope := common.StringToSymbol(0, strings.ToUpper(params.Symbol)) >> 8
@@ -629,6 +995,154 @@ func (ro *ReadOnly) GetCurrencyStats(params GetCurrencyStatsParams) map[string]G
 	return results
 }
 
+func getGlobalRow(d database.DataBase, abi *abi_serializer.AbiDef, abis *abi_serializer.AbiSerializer, abiSerializerMaxTime common.Microseconds, shortenAbiErrors bool) common.Variants {
+	tableType := GetTableType(abi, common.N("global"))
+	EosAssert(tableType == KEYi64, &ContractTableQueryException{}, "Invalid table type %s for table global", tableType)
+
+	system := common.DefaultConfig.SystemAccountName
+	tid := TableIdObject{Code: system, Scope: common.ScopeName(system), Table: common.N("global")}
+	EosAssert(d.Find("byCodeScopeTable", tid, &tid) == nil, &ContractTableQueryException{}, "Missing table global")
+
+	obj := KeyValueObject{TId: tid.ID, PrimaryKey: uint64(common.N("global"))}
+	EosAssert(d.Find("byScopePrimary", obj, &obj) == nil, &ContractTableQueryException{}, "Missing row in table global")
+
+	var data []byte
+	CopyInlineRow(&obj, &data)
+	return abis.BinaryToVariant(abis.GetTableType(common.N("global")), data, abiSerializerMaxTime, shortenAbiErrors)
+}
+
+func variantToFloat64(v common.Variant) float64 {
+	switch f := v.(type) {
+	case float64:
+		return f
+	case string:
+		result, err := strconv.ParseFloat(f, 64)
+		EosAssert(err == nil, &ContractTableQueryException{}, "Invalid double %s", f)
+		return result
+	default:
+		EosAssert(false, &ContractTableQueryException{}, "Invalid double %v", v)
+		return 0
+	}
+}
+
+func (ro *ReadOnly) GetProducers(p GetProducersParams) GetProducersResult {
+	result := GetProducersResult{}
+	if p.Limit == 0 {
+		p.Limit = 50
+	}
+
+	system := common.DefaultConfig.SystemAccountName
+	d := ro.stateDB()
+
+	const secondaryIndexNum = 0
+	tid := TableIdObject{Code: system, Scope: common.ScopeName(system), Table: common.N("producers")}
+	secondaryTid := TableIdObject{Code: system, Scope: common.ScopeName(system), Table: common.N("producers") | secondaryIndexNum}
+	if d.Find("byCodeScopeTable", tid, &tid) != nil || d.Find("byCodeScopeTable", secondaryTid, &secondaryTid) != nil {
+		// no system contract deployed or no producer registered yet, report the active schedule instead
+		return ro.activeScheduleProducers(p)
+	}
+
+	abi := GetAbi(d, system)
+	tableType := GetTableType(&abi, common.N("producers"))
+	EosAssert(tableType == KEYi64, &ContractTableQueryException{}, "Invalid table type %s for table producers", tableType)
+
+	abis := abi_serializer.AbiSerializer{}
+	abis.SetAbi(&abi, ro.abiSerializerMaxTime)
+
+	secondaryIdx, err := d.GetIndex("bySecondary", IdxDoubleObject{})
+	Throw(err)
+
+	var itr database.Iterator
+	if len(p.LowerBound) == 0 {
+		itr, err = secondaryIdx.LowerBound(IdxDoubleObject{TId: secondaryTid.ID, SecondaryKey: math.Float64(0xFFEFFFFFFFFFFFFF)}) // lowest double
+		Throw(err)
+	} else {
+		// position the secondary iterator on the first producer whose name is not less than lower_bound
+		primaryIdx, err := d.GetIndex("byPrimary", IdxDoubleObject{})
+		Throw(err)
+		primary, err := primaryIdx.LowerBound(IdxDoubleObject{TId: secondaryTid.ID, PrimaryKey: uint64(common.N(p.LowerBound))})
+		Throw(err)
+
+		itr = secondaryIdx.End()
+		if !primaryIdx.CompareEnd(primary) {
+			start := IdxDoubleObject{}
+			Throw(primary.Data(&start))
+			if start.TId == secondaryTid.ID {
+				itr, err = secondaryIdx.LowerBound(start)
+				Throw(err)
+			}
+		}
+	}
+
+	end := common.Now().AddUs(ro.queryTimeBudget)
+	var data []byte
+	for ; !secondaryIdx.CompareEnd(itr); itr.Next() {
+		obj := IdxDoubleObject{}
+		Throw(itr.Data(&obj))
+		if obj.TId != secondaryTid.ID {
+			break
+		}
+		if uint32(len(result.Rows)) >= p.Limit || common.Now() > end {
+			result.More = common.Name(obj.PrimaryKey).String()
+			break
+		}
+
+		row := KeyValueObject{TId: tid.ID, PrimaryKey: obj.PrimaryKey}
+		Throw(d.Find("byScopePrimary", row, &row))
+		CopyInlineRow(&row, &data)
+
+		if p.Json {
+			result.Rows = append(result.Rows, abis.BinaryToVariant(abis.GetTableType(common.N("producers")), data, ro.abiSerializerMaxTime, ro.shortenAbiErrors))
+		} else {
+			result.Rows = append(result.Rows, common.HexBytes(data))
+		}
+	}
+
+	global := getGlobalRow(d, &abi, &abis, ro.abiSerializerMaxTime, ro.shortenAbiErrors)
+	result.TotalProducerVoteWeight = variantToFloat64(global["total_producer_vote_weight"])
+
+	return result
+}
+
+//producerInfo is the layout of the rows of the producers table of eosio.system
+type producerInfo struct {
+	Owner         common.AccountName `json:"owner"`
+	TotalVotes    float64            `json:"total_votes"`
+	ProducerKey   ecc.PublicKey      `json:"producer_key"`
+	IsActive      bool               `json:"is_active"`
+	Url           string             `json:"url"`
+	UnpaidBlocks  uint32             `json:"unpaid_blocks"`
+	LastClaimTime uint64             `json:"last_claim_time"`
+	Location      uint16             `json:"location"`
+}
+
+//activeScheduleProducers reports the producers of the active schedule as rows of the producers table
+func (ro *ReadOnly) activeScheduleProducers(p GetProducersParams) GetProducersResult {
+	result := GetProducersResult{}
+	for _, producer := range ro.db.ActiveProducers().Producers {
+		if len(p.LowerBound) > 0 && producer.ProducerName < common.N(p.LowerBound) {
+			continue
+		}
+		if uint32(len(result.Rows)) >= p.Limit {
+			result.More = producer.ProducerName.String()
+			break
+		}
+
+		info := producerInfo{Owner: producer.ProducerName, ProducerKey: producer.BlockSigningKey, IsActive: true}
+		if p.Json {
+			// the same variant as the rows decoded through the abi of the producers table
+			var row common.Variants
+			common.ToVariant(info, &row)
+			result.Rows = append(result.Rows, row)
+		} else {
+			data, err := rlp.EncodeToBytes(&info)
+			Throw(err)
+			result.Rows = append(result.Rows, common.HexBytes(data))
+		}
+	}
+	return result
+}
+
 func (ro *ReadOnly) GetProducerSchedule() GetProducerScheduleResult {
 	result := GetProducerScheduleResult{}
 
@@ -644,3 +1158,107 @@ func (ro *ReadOnly) GetProducerSchedule() GetProducerScheduleResult {
 
 	return result
 }
+
+/**
+ * GetScheduledTransactions lists the deferred transactions ordered by delay_until, by sender id for a sender,
+ * or in scheduling order for a payer. lower_bound is either a timestamp, or the id of a scheduled transaction
+ * which is then the first one listed.
+ */
+func (ro *ReadOnly) GetScheduledTransactions(p GetScheduledTransactionParams) GetScheduledTransactionResult {
+	EosAssert(p.Sender.Empty() || p.Payer.Empty(), &InvalidArgException{}, "sender and payer cannot be both set")
+	if p.Limit == 0 {
+		p.Limit = 50
+	}
+
+	indexName := "byDelay"
+	start := GeneratedTransactionObject{}
+	if !p.Sender.Empty() {
+		indexName = "bySenderId"
+		start.Sender = p.Sender
+	} else if !p.Payer.Empty() {
+		indexName = "byPayer"
+		start.Payer = p.Payer
+	}
+
+	d := ro.stateDB()
+	idx, err := d.GetIndex(indexName, GeneratedTransactionObject{})
+	Throw(err)
+
+	var itr database.Iterator
+	if len(p.LowerBound) == 0 {
+		itr, err = idx.LowerBound(start)
+		Throw(err)
+	} else if strings.IndexByte(p.LowerBound, '-') >= 0 {
+		EosAssert(indexName == "byDelay", &InvalidArgException{}, "lower_bound must be a transaction id when listing by sender or payer")
+		delayUntil, err := common.FromIsoString(p.LowerBound)
+		EosAssert(err == nil, &InvalidArgException{}, "invalid lower_bound timestamp %s", p.LowerBound)
+		itr, err = idx.LowerBound(GeneratedTransactionObject{DelayUntil: delayUntil})
+		Throw(err)
+	} else {
+		EosAssert(len(p.LowerBound) == 64, &InvalidArgException{}, "invalid lower_bound transaction id %s", p.LowerBound)
+		gto := GeneratedTransactionObject{TrxId: *crypto.NewSha256String(p.LowerBound)}
+		if d.Find("byTrxId", gto, &gto) == nil {
+			itr, err = idx.LowerBound(gto)
+			Throw(err)
+		} else {
+			itr = idx.End()
+		}
+	}
+
+	resolver := ro.abiResolver()
+	result := GetScheduledTransactionResult{Transactions: make([]ScheduledTransaction, 0)}
+	end := common.Now().AddUs(ro.queryTimeBudget)
+	for ; !idx.CompareEnd(itr); itr.Next() {
+		gto := GeneratedTransactionObject{}
+		Throw(itr.Data(&gto))
+		if (!p.Sender.Empty() && gto.Sender != p.Sender) || (!p.Payer.Empty() && gto.Payer != p.Payer) {
+			break
+		}
+		if uint32(len(result.Transactions)) >= p.Limit || common.Now() > end {
+			result.More = gto.TrxId.String()
+			break
+		}
+
+		trx := ScheduledTransaction{
+			TrxID:      gto.TrxId,
+			Sender:     gto.Sender,
+			SenderID:   gto.SenderId.String(),
+			Payer:      gto.Payer,
+			DelayUntil: gto.DelayUntil,
+			Expiration: gto.Expiration,
+			Published:  gto.Published,
+		}
+		if p.Json {
+			trx.Transaction = ro.packedTransactionToVariant(resolver, gto.PackedTrx)
+		} else {
+			trx.PackedTrx = gto.PackedTrx
+		}
+		result.Transactions = append(result.Transactions, trx)
+	}
+
+	return result
+}
+
+// packedTransactionToVariant decodes a packed transaction, the data of its actions is decoded with the abis
+func (ro *ReadOnly) packedTransactionToVariant(resolver func(account common.AccountName) *abi_serializer.AbiSerializer, packed []byte) common.Variants {
+	trx := types.Transaction{}
+	Throw(rlp.DecodeBytes(packed, &trx))
+
+	actionsToVariant := func(actions []*types.Action) []common.Variants {
+		result := make([]common.Variants, 0, len(actions))
+		for _, act := range actions {
+			v := common.Variants{"account": act.Account, "name": act.Name, "authorization": act.Authorization, "data": act.Data, "hex_data": act.Data}
+			if data := ro.actionDataToVariant(resolver, act); data != nil {
+				v["data"] = data
+			}
+			result = append(result, v)
+		}
+		return result
+	}
+
+	result := common.Variants{}
+	common.ToVariant(&trx, &result)
+	result["context_free_actions"] = actionsToVariant(trx.ContextFreeActions)
+	result["actions"] = actionsToVariant(trx.Actions)
+	return result
+}
@@ -0,0 +1,72 @@
+package chain_plugin
+
+import (
+	"sync"
+
+	"github.com/eosspark/eos-go/chain"
+	. "github.com/eosspark/eos-go/exception/try"
+	"github.com/eosspark/eos-go/log"
+)
+
+const defaultReadOnlyThreads = 2
+
+// readPool answers read only api queries against pinned state views, away from the main thread
+// so that api load does not hold up block production
+type readPool struct {
+	jobs chan func()
+	wg   sync.WaitGroup
+}
+
+func newReadPool(workers int) *readPool {
+	p := &readPool{jobs: make(chan func(), workers*256)}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.run()
+	}
+	return p
+}
+
+func (p *readPool) run() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		Try(job).Catch(func(e interface{}) {
+			log.Error("read only query failed: %v", e)
+		}).End()
+	}
+}
+
+// post queues job without blocking the caller, it returns false once the queue is full
+func (p *readPool) post(job func()) bool {
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *readPool) stop() {
+	close(p.jobs)
+	p.wg.Wait()
+}
+
+// ReadOnlyQuery pins a state view at the head block and runs query with it on the read only pool.
+// It must be called from the main thread, query must read the chain through the view only.
+// It returns false without running query when the pool is overloaded
+func (c *ChainPlugin) ReadOnlyQuery(query func(view *chain.StateView)) bool {
+	view := c.my.Chain.NewStateView(false)
+	job := func() {
+		defer view.Close()
+		query(view)
+	}
+
+	if c.my.ReadPool == nil {
+		job()
+		return true
+	}
+	if !c.my.ReadPool.post(job) {
+		view.Close()
+		return false
+	}
+	return true
+}
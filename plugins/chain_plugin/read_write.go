@@ -4,6 +4,7 @@ import (
 	"github.com/eosspark/eos-go/chain"
 	"github.com/eosspark/eos-go/chain/types"
 	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto"
 	. "github.com/eosspark/eos-go/exception"
 	. "github.com/eosspark/eos-go/exception/try"
 	"github.com/eosspark/eos-go/plugins/appbase/app"
@@ -11,12 +12,18 @@ import (
 )
 
 type ReadWrite struct {
-	db                   *chain.Controller
-	abiSerializerMaxTime common.Microseconds
+	db                     *chain.Controller
+	abiSerializerMaxTime   common.Microseconds
+	maxDecompressedTrxSize uint64
+	finality               *finalityTracker
 }
 
 func NewReadWrite(db *chain.Controller, abiSerializerMaxTime common.Microseconds) *ReadWrite {
-	return &ReadWrite{db: db, abiSerializerMaxTime: abiSerializerMaxTime}
+	return &ReadWrite{
+		db:                     db,
+		abiSerializerMaxTime:   abiSerializerMaxTime,
+		maxDecompressedTrxSize: common.DefaultConfig.MaxDecompressedTrxSize,
+	}
 }
 
 func (rw *ReadWrite) Validate() {
@@ -56,35 +63,201 @@ func (rw *ReadWrite) Validate() {
 type PushTransactionParams = map[string]interface{}
 
 type PushTransactionResult struct {
-	TransactionId common.TransactionIdType
-	Processed     map[string]interface{}
+	TransactionId common.TransactionIdType `json:"transaction_id"`
+	Processed     map[string]interface{}   `json:"processed"`
 }
 
 func (rw *ReadWrite) PushTransaction(params PushTransactionParams, next NextFunction) {
 	Try(func() {
-		prettyInput := &types.PackedTransaction{}
-		common.FromVariant(&params, prettyInput)
-
-		app.App().GetMethod(TransactionAsync).CallMethods(prettyInput, true, func(result interface{}) {
-			if exception, ok := result.(Exception); ok {
-				next(exception)
-			} else {
-				trxTracePtr := result.(*types.TransactionTrace)
-
-				Try(func() {
-					id := trxTracePtr.ID
-					//TODO processed output
-					var output common.Variants
-					common.ToVariant(trxTracePtr, &output)
-					next(PushTransactionResult{id, output})
-
-				}).CatchAndCall(next).End()
+		prettyInput := packedTransactionFromVariant(&params, rw.maxDecompressedTrxSize)
+
+		rw.pushPackedTransaction(prettyInput, next)
+
+	}).CatchAndCall(next).End()
+}
+
+// packedTransactionFromVariant decodes a packed transaction and unpacks it right away, so that
+// malformed or oversized compressed data is rejected before it reaches the controller. A
+// "transaction" field sent by the client is ignored, the id is always computed over packed_trx.
+func packedTransactionFromVariant(params *PushTransactionParams, maxDecompressedSize uint64) *types.PackedTransaction {
+	ptrx := &types.PackedTransaction{}
+	common.FromVariant(params, ptrx)
+	ptrx.UnpackedTrx = nil
+
+	ptrx.CheckDecompressedSize(maxDecompressedSize)
+	ptrx.GetTransaction()
+	ptrx.GetContextFreeData()
+	return ptrx
+}
+
+func (rw *ReadWrite) pushPackedTransaction(trx *types.PackedTransaction, next NextFunction) {
+	app.App().GetMethod(TransactionAsync).CallMethods(trx, true, func(result interface{}) {
+		if exception, ok := result.(Exception); ok {
+			next(exception)
+		} else {
+			trxTracePtr := result.(*types.TransactionTrace)
+
+			Try(func() {
+				id := trxTracePtr.ID
+				//TODO processed output
+				var output common.Variants
+				common.ToVariant(trxTracePtr, &output)
+				next(PushTransactionResult{id, output})
+
+			}).CatchAndCall(next).End()
+		}
+	})
+}
+
+// MaxPushTransactionsAtOnce bounds the size of a single push_transactions batch
+const MaxPushTransactionsAtOnce = 1000
+
+type PushTransactionsParams = []PushTransactionParams
+type PushTransactionsResults = []PushTransactionResult
+
+// PushTransactions submits the transactions one after another in the given order. A failing
+// transaction does not abort the batch, its error is reported in place of the processed trace.
+func (rw *ReadWrite) PushTransactions(params PushTransactionsParams, next NextFunction) {
+	Try(func() {
+		EosAssert(len(params) <= MaxPushTransactionsAtOnce, &TooManyTxAtOnce{}, "Attempt to push too many transactions at once")
+
+		results := make(PushTransactionsResults, 0, len(params))
+		rw.pushRecurse(params, 0, func(result SendTransactionResult, _ common.TimePointSec) {
+			results = append(results, result.PushTransactionResult)
+		}, func() {
+			next(results)
+		})
+
+	}).CatchAndCall(next).End()
+}
+
+type SendTransactionsParams struct {
+	Transactions PushTransactionsParams `json:"transactions"`
+	WaitFor      TransactionFinality    `json:"wait_for"`
+}
+
+type SendTransactionResult struct {
+	PushTransactionResult
+	BlockNum     uint32             `json:"block_num"`
+	BlockId      common.BlockIdType `json:"block_id"`
+	Irreversible bool               `json:"irreversible"`
+}
+
+type SendTransactionsResults = []SendTransactionResult
+
+// SendTransactions works like PushTransactions, but each result is held back until its
+// transaction reaches the requested finality. Results are delivered from the controller
+// signals, so next is called asynchronously when WaitFor is not FinalityExecuted.
+func (rw *ReadWrite) SendTransactions(params SendTransactionsParams, next NextFunction) {
+	Try(func() {
+		EosAssert(len(params.Transactions) <= MaxPushTransactionsAtOnce, &TooManyTxAtOnce{}, "Attempt to push too many transactions at once")
+		EosAssert(params.WaitFor == FinalityExecuted || rw.finality != nil, &MissingChainPluginException{},
+			"Waiting for transaction finality requires the chain plugin")
+
+		results := make(SendTransactionsResults, len(params.Transactions))
+		remaining := len(params.Transactions)
+		index := 0
+		allPushed := false
+
+		finish := func() {
+			if remaining == 0 && allPushed {
+				next(results)
+			}
+		}
+
+		rw.pushRecurse(params.Transactions, 0, func(result SendTransactionResult, expiration common.TimePointSec) {
+			i := index
+			index++
+
+			done := func(r SendTransactionResult) {
+				results[i] = r
+				remaining--
+				finish()
 			}
+
+			if _, failed := result.Processed["error"]; failed || params.WaitFor == FinalityExecuted {
+				done(result)
+				return
+			}
+
+			rw.finality.wait(&finalityWaiter{
+				result:     result,
+				expiration: expiration,
+				finality:   params.WaitFor,
+				done:       done,
+			})
+		}, func() {
+			allPushed = true
+			finish()
 		})
 
 	}).CatchAndCall(next).End()
 }
 
+func (rw *ReadWrite) pushRecurse(params PushTransactionsParams, index int,
+	onResult func(SendTransactionResult, common.TimePointSec), onFinish func()) {
+
+	if index == len(params) {
+		onFinish()
+		return
+	}
+
+	var expiration common.TimePointSec
+	var r SendTransactionResult
+	received, pushing := false, true
+
+	// the batch goes on outside of the Try below, a failure further down the batch must not be
+	// caught here and reported a second time as the result of this transaction
+	resume := func() {
+		onResult(r, expiration)
+		rw.pushRecurse(params, index+1, onResult, onFinish)
+	}
+	next := func(result common.StaticVariant) {
+		if received {
+			return
+		}
+		received = true
+		if exception, ok := result.(Exception); ok {
+			if r.TransactionId.Equals(common.TransactionIdType{}) {
+				r.TransactionId = transactionIdOf(&params[index])
+			}
+			r.Processed = common.Variants{"error": exception.DetailMessage()}
+		} else {
+			r.PushTransactionResult = result.(PushTransactionResult)
+		}
+		if !pushing {
+			resume()
+		}
+	}
+
+	Try(func() {
+		prettyInput := packedTransactionFromVariant(&params[index], rw.maxDecompressedTrxSize)
+		expiration = prettyInput.Expiration()
+		r.TransactionId = prettyInput.ID()
+
+		rw.pushPackedTransaction(prettyInput, next)
+
+	}).CatchAndCall(next).End()
+
+	pushing = false
+	if received {
+		resume()
+	}
+}
+
+// transactionIdOf returns the id of a batch entry which was refused before it could be unpacked, so that the
+// client can still match the failure to its input: the hash of its packed_trx, empty if it is not even a
+// packed transaction
+func transactionIdOf(params *PushTransactionParams) common.TransactionIdType {
+	var id common.TransactionIdType
+	Try(func() {
+		ptrx := &types.PackedTransaction{}
+		common.FromVariant(params, ptrx)
+		id = *crypto.Hash256String(string(ptrx.GetRawTransaction()))
+	}).Catch(func(Exception) {}).Catch(func(interface{}) {}).End()
+	return id
+}
+
 type PushBlockParams = types.SignedBlock
 
 func (rw *ReadWrite) PushBlock(params PushBlockParams, next NextFunction) {
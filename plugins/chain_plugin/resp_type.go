@@ -7,6 +7,7 @@ import (
 	"github.com/eosspark/eos-go/common"
 	"github.com/eosspark/eos-go/common/eos_math"
 	"github.com/eosspark/eos-go/crypto"
+	"github.com/eosspark/eos-go/crypto/ecc"
 	. "github.com/eosspark/eos-go/exception"
 	. "github.com/eosspark/eos-go/exception/try"
 	"strings"
@@ -69,6 +70,84 @@ type GetBlockHeaderStateParams struct {
 }
 type GetBlockHeaderStateResult = types.BlockHeaderState
 
+type GetBlockHeaderParams struct {
+	BlockNumOrID string `json:"block_num_or_id"`
+}
+type GetBlockHeaderResult struct {
+	ID           common.BlockIdType      `json:"id"`
+	BlockNum     uint32                  `json:"block_num"`
+	Header       types.SignedBlockHeader `json:"header"`
+	Irreversible bool                    `json:"irreversible"`
+}
+
+type GetTransactionProofParams struct {
+	ID           common.TransactionIdType `json:"id"`
+	BlockNumOrID string                   `json:"block_num_or_id"`
+}
+type GetTransactionProofResult struct {
+	BlockID  common.BlockIdType       `json:"block_id"`
+	BlockNum uint32                   `json:"block_num"`
+	Header   types.SignedBlockHeader  `json:"header"`
+	Receipt  types.TransactionReceipt `json:"receipt"`
+	Proof    types.MerkleProof        `json:"proof"`
+}
+
+type GetActionProofParams struct {
+	GlobalSequence uint64 `json:"global_sequence"`
+	BlockNumOrID   string `json:"block_num_or_id"`
+}
+type GetActionProofResult struct {
+	BlockID  common.BlockIdType      `json:"block_id"`
+	BlockNum uint32                  `json:"block_num"`
+	Header   types.SignedBlockHeader `json:"header"`
+	Receipt  types.ActionReceipt     `json:"receipt"`
+	Proof    types.MerkleProof       `json:"proof"`
+}
+
+type GetBlockProofParams struct {
+	BlockNumOrID          string `json:"block_num_or_id"`
+	ReferenceBlockNumOrID string `json:"reference_block_num_or_id"`
+}
+type GetBlockProofResult struct {
+	BlockID   common.BlockIdType     `json:"block_id"`
+	BlockNum  uint32                 `json:"block_num"`
+	Reference types.BlockHeaderState `json:"reference"`
+	Proof     types.MerkleProof      `json:"proof"`
+}
+
+type DryRunTransactionParams struct {
+	Transaction    PushTransactionParams `json:"transaction"`
+	SkipSignatures bool                  `json:"skip_signatures"`
+}
+
+type DryRunActionTrace struct {
+	Receiver         common.AccountName       `json:"receiver"`
+	Account          common.AccountName       `json:"account"`
+	Name             common.ActionName        `json:"name"`
+	Authorization    []common.PermissionLevel `json:"authorization"`
+	Data             common.Variants          `json:"data,omitempty"`
+	HexData          common.HexBytes          `json:"hex_data"`
+	Console          string                   `json:"console"`
+	Elapsed          common.Microseconds      `json:"elapsed"`
+	AccountRamDeltas []common.AccountDelta    `json:"account_ram_deltas"`
+	InlineTraces     []DryRunActionTrace      `json:"inline_traces"`
+}
+
+type DryRunException struct {
+	Code    int64  `json:"code"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+type DryRunTransactionResult struct {
+	TransactionID common.TransactionIdType `json:"transaction_id"`
+	ActionTraces  []DryRunActionTrace      `json:"action_traces"`
+	CpuUsageUs    uint32                   `json:"cpu_usage_us"`
+	NetUsage      uint64                   `json:"net_usage"`
+	RamDeltas     []common.AccountDelta    `json:"ram_deltas"`
+	Except        *DryRunException         `json:"except,omitempty"`
+}
+
 type Permission struct {
 	PermName     common.Name
 	Parent       common.Name
@@ -160,6 +239,28 @@ type GetRequiredKeysResult struct {
 	RequiredKeys PublicKeySet `json:"required_keys"`
 }
 
+type ExplainAuthorizationParams struct {
+	Transaction         common.Variant           `json:"transaction"`
+	AvailableKeys       PublicKeySet             `json:"available_keys"`
+	ProvidedPermissions []common.PermissionLevel `json:"provided_permissions"`
+}
+
+type GetAccountsByAuthorizersParams struct {
+	Accounts []common.PermissionLevel `json:"accounts"` //an empty permission matches any permission of the actor
+	Keys     []ecc.PublicKey          `json:"keys"`
+}
+type AccountByAuthorizer struct {
+	AccountName        common.AccountName      `json:"account_name"`
+	PermissionName     common.PermissionName   `json:"permission_name"`
+	AuthorizingAccount *common.PermissionLevel `json:"authorizing_account,omitempty"`
+	AuthorizingKey     *ecc.PublicKey          `json:"authorizing_key,omitempty"`
+	Weight             types.WeightType        `json:"weight"`
+	Threshold          uint32                  `json:"threshold"`
+}
+type GetAccountsByAuthorizersResult struct {
+	Accounts []AccountByAuthorizer `json:"accounts"`
+}
+
 type GetCurrencyBalanceParams struct {
 	Code    common.Name `json:"code"`
 	Account common.Name `json:"account_name"`
@@ -236,13 +337,26 @@ type GetProducersResult struct {
 }
 
 type GetScheduledTransactionParams struct {
-	Json       bool   `json:"json"`
-	LowerBound string `json:"lower_bound"` //timestamp OR transaction ID
-	Limit      uint32 `json:"limit"`       //default 50
-}
-type GetScheduleTransactionReuslt struct {
-	Transaction common.Variants `json:"transaction"`
-	More        string          `json:"more"` //fill lower_bound with this to fetch next set of transactions
+	Json       bool               `json:"json"`
+	LowerBound string             `json:"lower_bound"` //timestamp OR transaction ID
+	Limit      uint32             `json:"limit"`       //default 50
+	Sender     common.AccountName `json:"sender"`      //only the transactions of this sender, ordered by sender id
+	Payer      common.AccountName `json:"payer"`       //only the transactions paid by this account, in scheduling order
+}
+type ScheduledTransaction struct {
+	TrxID       common.TransactionIdType `json:"trx_id"`
+	Sender      common.AccountName       `json:"sender"`
+	SenderID    string                   `json:"sender_id"`
+	Payer       common.AccountName       `json:"payer"`
+	DelayUntil  common.TimePoint         `json:"delay_until"`
+	Expiration  common.TimePoint         `json:"expiration"`
+	Published   common.TimePoint         `json:"published"`
+	Transaction common.Variants          `json:"transaction,omitempty"` //the transaction with its action data decoded when json is set
+	PackedTrx   common.HexBytes          `json:"packed_trx,omitempty"`
+}
+type GetScheduledTransactionResult struct {
+	Transactions []ScheduledTransaction `json:"transactions"`
+	More         string                 `json:"more"` //fill lower_bound with this to fetch next set of transactions
 }
 
 type AbiJsonToBinParams struct {
@@ -158,6 +158,52 @@ func (a *chainAPI) GetScope(call otto.FunctionCall) (response otto.Value) {
 	return getJsResult(call, resp)
 }
 
+//GetScheduled lists the scheduled (deferred) transactions
+func (a *chainAPI) GetScheduled(call otto.FunctionCall) (response otto.Value) {
+	var params GetScheduledParams
+	readParams(&params, call)
+
+	if params.Limit == 0 {
+		params.Limit = 50
+	}
+	var resp chain_plugin.GetScheduledTransactionResult
+	err := DoHttpCall(&resp, common.GetScheduledTransactionsFunc, common.Variants{
+		"json":        true,
+		"lower_bound": params.Lower,
+		"limit":       params.Limit,
+		"sender":      params.Sender,
+		"payer":       params.Payer})
+	if err != nil {
+		throwJSException(fmt.Sprintf("http error : %s", err))
+	}
+
+	if params.PrintJson {
+		return getJsResult(call, resp)
+	}
+	if len(resp.Transactions) == 0 {
+		return getJsResult(call, fmt.Sprintln("No scheduled transactions found"))
+	}
+
+	fmt.Printf("%-64s %-13s %-13s %-23s %s\n", "Transaction id", "Sender", "Payer", "Delay until", "Actions")
+	for _, trx := range resp.Transactions {
+		actions := make([]string, 0)
+		if acts, ok := trx.Transaction["actions"].([]interface{}); ok {
+			for _, act := range acts {
+				if act, ok := act.(map[string]interface{}); ok {
+					actions = append(actions, fmt.Sprintf("%v::%v", act["account"], act["name"]))
+				}
+			}
+		}
+		fmt.Printf("%-64s %-13s %-13s %-23s %s\n", trx.TrxID, trx.Sender, trx.Payer, trx.DelayUntil, strings.Join(actions, " "))
+	}
+
+	if len(resp.More) > 0 {
+		fmt.Printf("-L %s for more\n", resp.More)
+	}
+
+	return
+}
+
 //GetCurrencyBalance retrieves information related to standard currencies
 func (a *chainAPI) GetCurrencyBalance(call otto.FunctionCall) (response otto.Value) {
 	var code, accountName, symbol string
@@ -387,6 +433,10 @@ func (a *chainAPI) ConvertPackTransaction(call otto.FunctionCall) (response otto
 	if err != nil {
 		return otto.UndefinedValue()
 	}
+	compression := types.CompressionNone
+	if call.Argument(2).IsDefined() {
+		compression = parseCompression(call.Argument(2).String())
+	}
 	var trx types.SignedTransaction
 	var trxVar common.Variants
 	err = json.Unmarshal([]byte(plainSignedTransactionJson), &trxVar)
@@ -397,13 +447,13 @@ func (a *chainAPI) ConvertPackTransaction(call otto.FunctionCall) (response otto
 	packedTrx := &types.PackedTransaction{}
 	if packActionDataFlag {
 		abi_serializer.FromVariant(&trxVar, &trx, abisSerializerResolver, abiSerializerMaxTime)
-		packedTrx = types.NewPackedTransactionBySignedTrx(&trx, types.CompressionNone)
+		packedTrx = types.NewPackedTransactionBySignedTrx(&trx, compression)
 	} else {
 		err = json.Unmarshal([]byte(plainSignedTransactionJson), &trx)
 		if err != nil {
 			return throwJSException(err.Error())
 		}
-		packedTrx = types.NewPackedTransactionBySignedTrx(&trx, types.CompressionNone)
+		packedTrx = types.NewPackedTransactionBySignedTrx(&trx, compression)
 
 	}
 	return getJsResult(call, packedTrx)
@@ -67,3 +67,35 @@ func (n *NetAPI) Peers(call otto.FunctionCall) (response otto.Value) {
 	}
 	return getJsResult(call, result)
 }
+
+//Bans peers which are banned for misbehaving
+func (n *NetAPI) Bans(call otto.FunctionCall) (response otto.Value) {
+	var result []net_plugin.PeerBan
+	if err := DoHttpCall(&result, common.NetBans, nil); err != nil {
+		return getJsResult(call, err.Error())
+	}
+	return getJsResult(call, result)
+}
+
+//Unban lifts the ban of a peer given by its node id or host
+func (n *NetAPI) Unban(call otto.FunctionCall) (response otto.Value) {
+	target, err := call.Argument(0).ToString()
+	if err != nil {
+		return otto.UndefinedValue()
+	}
+
+	var result string
+	if err = DoHttpCall(&result, common.NetUnban, target); err != nil {
+		return getJsResult(call, err.Error())
+	}
+	return getJsResult(call, result)
+}
+
+//Addresses peer addresses of the address book
+func (n *NetAPI) Addresses(call otto.FunctionCall) (response otto.Value) {
+	var result []net_plugin.KnownAddress
+	if err := DoHttpCall(&result, common.NetAddresses, nil); err != nil {
+		return getJsResult(call, err.Error())
+	}
+	return getJsResult(call, result)
+}
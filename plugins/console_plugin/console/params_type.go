@@ -18,6 +18,7 @@ type StandardTransactionOptions struct {
 	TxMaxCpuUsage     uint8    `json:"max_cpu_usage_ms"`
 	TxMaxNetUsage     uint32   `json:"max_net_usage"`
 	DelaySec          uint32   `json:"delay_sec"`
+	TxCompression     string   `json:"compression"` // "none" or "zlib"
 }
 
 func (s *StandardTransactionOptions) getOptions() *StandardTransactionOptions {
@@ -195,8 +196,8 @@ type RegproxyParams struct {
 }
 
 type CanceldelayParams struct {
-	CancelingAccount   string `json:"canceling_account"`
-	CanclingPermission string `json:"canceling_permission"`
+	CancelingAccount   string `json:"canceling_account"`    //default to the first authorization of the delayed transaction
+	CanclingPermission string `json:"canceling_permission"` //default to the first authorization of the delayed transaction
 	TrxID              string `json:"trx_id"`
 	StandardTransactionOptions
 }
@@ -221,6 +222,13 @@ type GetTableParams struct {
 	KeyType       string `json:"key_type"`
 	EncodeType    string `json:"encode_type"` //default ='dec'
 }
+type GetScheduledParams struct {
+	Lower     string `json:"lower"` //delay_until timestamp or transaction id
+	Limit     uint32 `json:"limit"` //default =50
+	Sender    string `json:"sender"`
+	Payer     string `json:"payer"`
+	PrintJson bool   `json:"json"`
+}
 type GetScopeParams struct {
 	Code  string `json:"code"`
 	Table string `json:"table"`
@@ -72,3 +72,32 @@ func (p *ProduceAPI) GetWhitelistBlacklist(call otto.FunctionCall) (response ott
 	}
 	return getJsResult(call, result)
 }
+
+func (p *ProduceAPI) StartContractProfiler(call otto.FunctionCall) (response otto.Value) {
+	err := DoHttpCall(nil, common.ProducerStartContractProfiler, nil)
+	if err != nil {
+		clog.Error("StartContractProfiler is error: %s", err.Error())
+		return otto.FalseValue()
+	}
+	return otto.TrueValue()
+}
+
+func (p *ProduceAPI) StopContractProfiler(call otto.FunctionCall) (response otto.Value) {
+	err := DoHttpCall(nil, common.ProducerStopContractProfiler, nil)
+	if err != nil {
+		clog.Error("StopContractProfiler is error: %s", err.Error())
+		return otto.FalseValue()
+	}
+	return otto.TrueValue()
+}
+
+func (p *ProduceAPI) GetContractProfile(call otto.FunctionCall) (response otto.Value) {
+	var result interface{}
+
+	err := DoHttpCall(&result, common.ProducerGetContractProfile, nil)
+	if err != nil {
+		clog.Error("GetContractProfile is error: %s", err.Error())
+		return otto.FalseValue()
+	}
+	return getJsResult(call, result)
+}
@@ -560,6 +560,34 @@ func (s *system) Canceldelay(call otto.FunctionCall) (response otto.Value) {
 	var params CanceldelayParams
 	readParams(&params, call)
 
+	if len(params.CancelingAccount) == 0 {
+		var resp chain_plugin.GetScheduledTransactionResult
+		err := DoHttpCall(&resp, common.GetScheduledTransactionsFunc, common.Variants{
+			"json":        true,
+			"lower_bound": params.TrxID,
+			"limit":       1})
+		if err != nil {
+			throwJSException(fmt.Sprintf("http error : %s", err))
+		}
+		if len(resp.Transactions) == 0 || resp.Transactions[0].TrxID.String() != params.TrxID {
+			throwJSException(fmt.Sprintf("no scheduled transaction %s", params.TrxID))
+		}
+
+		// the action data is decoded by the abis, only the authorizations are read back
+		var trx struct {
+			Actions []struct {
+				Authorization []common.PermissionLevel `json:"authorization"`
+			} `json:"actions"`
+		}
+		bytes, _ := json.Marshal(resp.Transactions[0].Transaction)
+		json.Unmarshal(bytes, &trx)
+		if len(trx.Actions) == 0 || len(trx.Actions[0].Authorization) == 0 {
+			throwJSException(fmt.Sprintf("scheduled transaction %s declares no authorization", params.TrxID))
+		}
+		params.CancelingAccount = trx.Actions[0].Authorization[0].Actor.String()
+		params.CanclingPermission = trx.Actions[0].Authorization[0].Permission.String()
+	}
+
 	cancelingAuth := common.PermissionLevel{common.N(params.CancelingAccount), common.N(params.CanclingPermission)}
 	actPayload := common.Variants{
 		"canceling_auth": cancelingAuth,
@@ -711,6 +739,9 @@ func pushTransaction(trx *types.SignedTransaction, extraKcpu int32, compression
 		requiredKeys := determineRequiredKeys(trx)
 		signTransaction(trx, requiredKeys, &info.ChainID)
 	}
+	if len(c.getOptions().TxCompression) > 0 {
+		compression = parseCompression(c.getOptions().TxCompression)
+	}
 	if !c.getOptions().TxDontBroadcast {
 		var result chain_plugin.PushTransactionResult
 		packedTrx := types.NewPackedTransactionBySignedTrx(trx, compression)
@@ -760,6 +791,18 @@ func determineRequiredKeys(trx *types.SignedTransaction) []string {
 	return re
 }
 
+func parseCompression(s string) types.CompressionType {
+	switch s {
+	case "none":
+		return types.CompressionNone
+	case "zlib":
+		return types.CompressionZlib
+	default:
+		EosThrow(&exception.UnknownTransactionCompression{}, "Unknown transaction compression algorithm: %s", s)
+	}
+	return types.CompressionNone
+}
+
 func signTransaction(trx *types.SignedTransaction, requiredKeys []string, chainID *common.ChainIdType) {
 	signedTrx := common.Variants{"signed_transaction": trx, "keys": requiredKeys, "id": chainID}
 	err := DoHttpCall(trx, common.WalletSignTrx, signedTrx)
@@ -218,7 +218,8 @@ func (w *walletApi) CreateKey(call otto.FunctionCall) (response otto.Value) {
 	return getJsResult(call, result)
 }
 
-//SignTransaction signs a transaction
+//SignTransaction signs a transaction, wallet.SignTransaction(trx,keys,chainID[,compression]).
+//When a compression is given the packed transaction is returned instead of the signed one.
 func (w *walletApi) SignTransaction(call otto.FunctionCall) (response otto.Value) {
 	trxJsonToSign, err := call.Argument(0).ToString()
 	if err != nil {
@@ -243,5 +244,8 @@ func (w *walletApi) SignTransaction(call otto.FunctionCall) (response otto.Value
 		return throwJSException("signedTransactoin is err")
 	}
 
+	if call.Argument(3).IsDefined() {
+		return getJsResult(call, types.NewPackedTransactionBySignedTrx(&resp, parseCompression(call.Argument(3).String())))
+	}
 	return getJsResult(call, resp)
 }
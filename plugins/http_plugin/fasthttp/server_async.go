@@ -16,12 +16,16 @@ type AsyncServer struct {
 }
 
 func ListenAndAsyncServe(ctx *asio.IoContext, addr string, handler RequestHandler) error {
+	return ListenAndAsyncServeWith(ctx, addr, &Server{Handler: handler})
+}
+
+// ListenAndAsyncServeWith serves HTTP requests from the given TCP4 addr
+// with the handler, error handler and limits of server.
+func ListenAndAsyncServeWith(ctx *asio.IoContext, addr string, server *Server) error {
 	s := &AsyncServer{
 		ctx:          ctx,
 		LogAllErrors: true,
-		Server: &Server{
-			Handler: handler,
-		},
+		Server:       server,
 	}
 
 	ln, err := net.Listen("tcp4", addr)
@@ -118,20 +122,21 @@ func (s *AsyncServer) Serve(ln net.Listener) error {
 			//	time.Sleep(100 * time.Millisecond)
 			//}
 
+			// connections are served on their own goroutines, the handler decides where a request runs
 			con := c
-			s.ctx.Post(func(err error) {
+			go func() {
 				if con == nil {
 					return
 				}
 
-				if err = s.serveConn(con); err != nil {
+				if err := s.serveConn(con); err != nil {
 					if err != errHijacked {
 						errStr := err.Error()
 						if s.LogAllErrors || !(strings.Contains(errStr, "broken pipe") ||
 							strings.Contains(errStr, "reset by peer") ||
 							strings.Contains(errStr, "request headers: small read buffer") ||
 							strings.Contains(errStr, "i/o timeout")) {
-							s.Logger.Printf("error when serving connection %q<->%q: %s", con.LocalAddr(), con.RemoteAddr(), err)
+							s.logger().Printf("error when serving connection %q<->%q: %s", con.LocalAddr(), con.RemoteAddr(), err)
 							con.Close()
 							s.setState(con, StateClosed)
 						}
@@ -139,7 +144,7 @@ func (s *AsyncServer) Serve(ln net.Listener) error {
 						s.setState(con, StateHijacked)
 					}
 				}
-			})
+			}()
 
 			c = nil
 			handleFunc()
@@ -10,6 +10,8 @@ import (
 	"github.com/eosspark/eos-go/libraries/asio"
 	"github.com/eosspark/eos-go/plugins/http_plugin/fasthttp"
 	"github.com/urfave/cli"
+	"sync"
+	"time"
 )
 
 const (
@@ -78,11 +80,16 @@ func (h *HttpPlugin) SetProgramOptions(options *[]cli.Flag) {
 			Usage: "The maximum body size in bytes allowed for incoming RPC requests",
 			Value: 1024 * 1024,
 		},
+		cli.UintFlag{
+			Name:  "http-max-response-time-ms",
+			Usage: "Maximum time for processing a request, the request is answered with 504 past it",
+			Value: 30000,
+		},
 		cli.BoolFlag{
 			Name:  "verbose-http-errors",
 			Usage: "Append the error log to HTTP responses",
 		},
-		cli.BoolFlag{ // default true
+		cli.BoolTFlag{
 			Name:  "http-validate-host",
 			Usage: "If set to false,then any incoming \"Host\" header is considered valid",
 		},
@@ -116,7 +123,16 @@ func (h *HttpPlugin) PluginInitialize(c *cli.Context) {
 			hlog.Info("configured http with Access-Control-Allow-Credentials: true")
 		}
 
+		h.my.validateHost = c.BoolT("http-validate-host")
+		for _, alias := range c.StringSlice("http-alias") {
+			h.my.validHosts[alias] = true
+		}
+
 		h.my.listenStr = c.String("http-server-address")
+		if len(h.my.listenStr) > 0 {
+			err := h.my.addAliasesForEndpoint(h.my.listenStr)
+			EosAssert(err == nil, &PluginConfigException{}, "failed to configure http to listen on %s: %v", h.my.listenStr, err)
+		}
 		hlog.Info("configured http to listen on %s", h.my.listenStr)
 		//listenStr := c.String("http-server-address")
 		//h.my.ListenEndpoint = http.NewServeMux()
@@ -129,6 +145,7 @@ func (h *HttpPlugin) PluginInitialize(c *cli.Context) {
 		//}
 
 		h.my.MaxBodySize = common.SizeT(c.Uint64("max-body-size"))
+		h.my.MaxResponseTime = time.Duration(c.Uint("http-max-response-time-ms")) * time.Millisecond
 		verboseHttpErrors = c.Bool("verbose-http-errors")
 
 	}).FcLogAndRethrow().End()
@@ -138,7 +155,12 @@ func (h *HttpPlugin) PluginStartup() {
 	hlog.Info("http plugin startup")
 
 	if len(h.my.listenStr) > 0 {
-		err := fasthttp.ListenAndAsyncServe(App().GetIoService(), h.my.listenStr, h.Handler)
+		server := &fasthttp.Server{
+			Handler:            h.Handler,
+			ErrorHandler:       h.ErrorHandler,
+			MaxRequestBodySize: int(h.my.MaxBodySize),
+		}
+		err := fasthttp.ListenAndAsyncServeWith(App().GetIoService(), h.my.listenStr, server)
 		if err != nil {
 			hlog.Error("start fastHttp is error: %s", err)
 		}
@@ -226,33 +248,146 @@ func (h *HttpPlugin) AddHandler(url string, handler UrlHandler) {
 	})
 }
 
+// AddLongPollHandler adds a handler which may hold its answer back longer than http-max-response-time-ms,
+// such as one waiting for a transaction to become irreversible. The handler must bound the wait itself
+func (h *HttpPlugin) AddLongPollHandler(url string, handler UrlHandler) {
+	hlog.Info("add long poll api url: %s", url)
+	App().GetIoService().Post(func(err error) {
+		h.my.UrlHandlers[url] = handler
+		h.my.longPollHandlers[url] = true
+	})
+}
+
+// AddHandlerWithContentType adds a handler whose successful answers are not json, such as a binary profile.
+// Its errors are answered in json like those of any other handler
+func (h *HttpPlugin) AddHandlerWithContentType(url string, contentType string, handler UrlHandler) {
+	hlog.Info("add api url: %s (%s)", url, contentType)
+	App().GetIoService().Post(func(err error) {
+		h.my.UrlHandlers[url] = handler
+		h.my.contentTypes[url] = contentType
+	})
+}
+
+// Handler runs on the goroutine serving the connection. Url handlers run on the main thread,
+// they may answer later from another goroutine, the response is written once cb is called
 func (h *HttpPlugin) Handler(ctx *fasthttp.RequestCtx) {
 	//hlog.Error("source: %s", ctx.Path())
 	//hlog.Info("body: %s", ctx.Request.Body())
 
-	ctx.SetContentType("text/plain; charset=utf8")
-	// Set arbitrary headers
-	ctx.Response.Header.Set("X-My-Header", "my-header-value")
-	// Set cookies
-	var c fasthttp.Cookie
-	c.SetKey("cookie-name")
-	c.SetValue("cookie-value")
-	ctx.Response.Header.SetCookie(&c)
+	if !h.my.hostIsValid(string(ctx.Host()), ctx.LocalAddr().String(), ctx.IsTLS()) {
+		hlog.Debug("400 - invalid host header: %s", ctx.Host())
+		errorResponse(ctx, fasthttp.StatusBadRequest, "Bad Request", "Invalid Host header")
+		return
+	}
+
+	if len(h.my.AccessControlAllowOrigin) > 0 {
+		ctx.Response.Header.Set("Access-Control-Allow-Origin", h.my.AccessControlAllowOrigin)
+	}
+	if len(h.my.AccessControlAllowHeaders) > 0 {
+		ctx.Response.Header.Set("Access-Control-Allow-Headers", h.my.AccessControlAllowHeaders)
+	}
+	if len(h.my.AccessControlMaxAge) > 0 {
+		ctx.Response.Header.Set("Access-Control-Max-Age", h.my.AccessControlMaxAge)
+	}
+	if h.my.AccessControlAllowCredentials {
+		ctx.Response.Header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if ctx.IsOptions() {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		return
+	}
+
+	ctx.SetContentType("application/json")
+
+	if h.my.MaxBodySize > 0 && len(ctx.Request.Body()) > int(h.my.MaxBodySize) {
+		errorResponse(ctx, fasthttp.StatusRequestEntityTooLarge, "Request Entity Too Large", "Request body exceeds max-body-size")
+		return
+	}
 
 	resource := string(ctx.Path())
-	body := ctx.Request.Body()
+	body := append([]byte(nil), ctx.Request.Body()...)
 
-	handler, ok := h.my.UrlHandlers[resource]
-	if !ok {
-		hlog.Debug("404 - not found: %s", resource)
-		ctx.NotFound()
-	} else {
+	type response struct {
+		code        int
+		body        []byte
+		contentType string
+	}
+	done := make(chan *response, 1)
+	longPoll := make(chan struct{}, 1)
+	var once sync.Once
+
+	App().GetIoService().Post(func(err error) {
+		handler, ok := h.my.UrlHandlers[resource]
+		if !ok {
+			done <- nil
+			return
+		}
+		if h.my.longPollHandlers[resource] {
+			longPoll <- struct{}{}
+		}
+		contentType := h.my.contentTypes[resource]
 		handler(resource, body, func(code int, body []byte) {
-			//hlog.Debug("body: %s",string(body))
-			ctx.SetBody([]byte(body))
-			ctx.SetStatusCode(code)
+			once.Do(func() {
+				done <- &response{code, body, contentType}
+			})
 		})
+	})
+
+	timeout := time.NewTimer(h.my.MaxResponseTime)
+	defer timeout.Stop()
+
+	var resp *response
+wait:
+	for {
+		select {
+		case resp = <-done:
+			break wait
+		case <-longPoll:
+			/* the time spent queued for the main thread is bounded, the wait of the handler is not */
+			timeout.Stop()
+		case <-timeout.C:
+			hlog.Debug("504 - timed out: %s", resource)
+			errorResponse(ctx, fasthttp.StatusGatewayTimeout, "Gateway Timeout", "Request exceeded http-max-response-time-ms")
+			return
+		}
+	}
+	if resp == nil {
+		hlog.Debug("404 - not found: %s", resource)
+		errorResponse(ctx, fasthttp.StatusNotFound, "Not Found", "Unknown Endpoint")
+		return
 	}
+	//hlog.Debug("body: %s",string(body))
+	if len(resp.contentType) > 0 && resp.code >= 200 && resp.code < 300 {
+		ctx.SetContentType(resp.contentType)
+	}
+	ctx.SetBody(resp.body)
+	ctx.SetStatusCode(resp.code)
+}
+
+// ErrorHandler answers the requests the server fails to read, such as those with a body over max-body-size
+func (h *HttpPlugin) ErrorHandler(ctx *fasthttp.RequestCtx, err error) {
+	if err == fasthttp.ErrBodyTooLarge {
+		errorResponse(ctx, fasthttp.StatusRequestEntityTooLarge, "Request Entity Too Large", "Request body exceeds max-body-size")
+		return
+	}
+	errorResponse(ctx, fasthttp.StatusBadRequest, "Bad Request", err.Error())
+}
+
+// ServiceUnavailable answers a request the node is too busy to serve
+func ServiceUnavailable(what string, cb UrlResponseCallback) {
+	results := ErrorResults{503, "Service Unavailable",
+		newErrorInfo(&FcException{Elog: log.Messages{log.FcLogMessage(log.LvlError, what)}}, verboseHttpErrors)}
+	re, _ := json.Marshal(results)
+	cb(503, re)
+}
+
+func errorResponse(ctx *fasthttp.RequestCtx, code int, message string, what string) {
+	results := ErrorResults{uint16(code), message,
+		newErrorInfo(&FcException{Elog: log.Messages{log.FcLogMessage(log.LvlError, what)}}, verboseHttpErrors)}
+	re, _ := json.Marshal(results)
+	ctx.SetContentType("application/json")
+	ctx.SetStatusCode(code)
+	ctx.SetBody(re)
 }
 
 func (h *HttpPlugin) IsOnLoopBack() bool { //TODO
@@ -5,6 +5,9 @@ import (
 	"github.com/eosspark/eos-go/libraries/asio"
 	"net"
 	"net/http"
+	"regexp"
+	"strconv"
+	"time"
 )
 
 type NextFunction = func(interface{})
@@ -12,16 +15,22 @@ type UrlResponseCallback = func(int, []byte)
 type UrlHandler = func(source string, body []byte, cb UrlResponseCallback)
 
 type HttpPluginImpl struct {
-	UrlHandlers map[string]UrlHandler
+	UrlHandlers      map[string]UrlHandler
+	longPollHandlers map[string]bool   // handlers bounding their own wait, they are not answered with 504 past MaxResponseTime
+	contentTypes     map[string]string // content type of the successful answers of handlers not answering json
 
 	AccessControlAllowOrigin      string
 	AccessControlAllowHeaders     string
 	AccessControlMaxAge           string
 	AccessControlAllowCredentials bool //default false
 	MaxBodySize                   common.SizeT
+	MaxResponseTime               time.Duration
 	httpsCeryChain                string
 	httpsKey                      string
 
+	validateHost bool
+	validHosts   map[string]bool
+
 	listenStr           string
 	ListenEndpoint      *http.ServeMux
 	HttpsListenEndpoint *http.ServeMux
@@ -35,6 +44,49 @@ type HttpPluginImpl struct {
 func NewHttpPluginImpl(io *asio.IoContext) *HttpPluginImpl {
 	impl := new(HttpPluginImpl)
 	impl.UrlHandlers = make(map[string]UrlHandler)
+	impl.longPollHandlers = make(map[string]bool)
+	impl.contentTypes = make(map[string]string)
 	impl.AccessControlAllowCredentials = false
+	impl.validateHost = true
+	impl.MaxResponseTime = 30 * time.Second
+	impl.validHosts = make(map[string]bool)
 	return impl
 }
+
+// addAliasesForEndpoint accepts the host of endpoint and the address it resolves to as Host headers
+func (impl *HttpPluginImpl) addAliasesForEndpoint(endpoint string) error {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return err
+	}
+	impl.validHosts[net.JoinHostPort(host, port)] = true
+
+	addr, err := net.ResolveTCPAddr("tcp4", endpoint)
+	if err != nil {
+		return err
+	}
+	impl.validHosts[net.JoinHostPort(addr.IP.String(), port)] = true
+	return nil
+}
+
+// ends in :<number> without a preceding colon which implies ipv6
+var hasPortExpr = regexp.MustCompile(`[^:]:[0-9]+$`)
+
+// hostIsValid tells if the Host header of a request received on the local address localHostPort is acceptable,
+// a host without port is normalised with the default port of the scheme
+func (impl *HttpPluginImpl) hostIsValid(host string, localHostPort string, secure bool) bool {
+	if !impl.validateHost {
+		return true
+	}
+	if len(host) == 0 {
+		return false
+	}
+	if !hasPortExpr.MatchString(host) {
+		port := 80
+		if secure {
+			port = 443
+		}
+		host += ":" + strconv.Itoa(port)
+	}
+	return host == localHostPort || impl.validHosts[host]
+}
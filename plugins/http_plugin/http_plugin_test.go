@@ -0,0 +1,162 @@
+package http_plugin
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/eosspark/eos-go/plugins/appbase/app"
+	"github.com/eosspark/eos-go/plugins/http_plugin/fasthttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPlugin(t *testing.T) *HttpPlugin {
+	h := NewHttpPlugin(nil)
+	assert.NoError(t, h.my.addAliasesForEndpoint("localhost:8888"))
+	h.my.MaxBodySize = 16
+	h.my.AccessControlAllowOrigin = "*"
+	h.my.AccessControlAllowHeaders = "Content-Type"
+	h.my.AccessControlMaxAge = "600"
+	h.my.AccessControlAllowCredentials = true
+	h.my.UrlHandlers["/v1/test/echo"] = func(source string, body []byte, cb UrlResponseCallback) {
+		cb(201, body)
+	}
+	return h
+}
+
+func serve(h *HttpPlugin, method string, host string, uri string, body string) *fasthttp.Response {
+	var req fasthttp.Request
+	req.Header.SetMethod(method)
+	req.Header.SetHost(host)
+	req.SetRequestURI(uri)
+	req.SetBodyString(body)
+
+	var ctx fasthttp.RequestCtx
+	ctx.Init(&req, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 50000}, nil)
+	h.Handler(&ctx)
+
+	resp := new(fasthttp.Response)
+	ctx.Response.CopyTo(resp)
+	return resp
+}
+
+func TestValidateHost(t *testing.T) {
+	h := newTestPlugin(t)
+	h.my.validHosts["eos.example.com:443"] = true
+
+	assert.True(t, h.my.hostIsValid("localhost:8888", "127.0.0.1:8888", false))
+	assert.True(t, h.my.hostIsValid("127.0.0.1:8888", "127.0.0.1:8888", false))
+	assert.True(t, h.my.hostIsValid("10.0.0.1:8888", "10.0.0.1:8888", false))
+	assert.True(t, h.my.hostIsValid("eos.example.com", "127.0.0.1:8888", true))
+	assert.False(t, h.my.hostIsValid("eos.example.com", "127.0.0.1:8888", false))
+	assert.False(t, h.my.hostIsValid("evil.com:8888", "127.0.0.1:8888", false))
+	assert.False(t, h.my.hostIsValid("", "127.0.0.1:8888", false))
+
+	resp := serve(h, "POST", "evil.com", "/v1/test/echo", "{}")
+	assert.Equal(t, fasthttp.StatusBadRequest, resp.StatusCode())
+	assert.Equal(t, "application/json", string(resp.Header.ContentType()))
+	assert.Empty(t, resp.Header.Peek("Access-Control-Allow-Origin"))
+
+	h.my.validateHost = false
+	assert.True(t, h.my.hostIsValid("evil.com:8888", "127.0.0.1:8888", false))
+}
+
+func TestPreflight(t *testing.T) {
+	h := newTestPlugin(t)
+
+	resp := serve(h, "OPTIONS", "localhost:8888", "/v1/test/echo", "")
+	assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+	assert.Equal(t, "*", string(resp.Header.Peek("Access-Control-Allow-Origin")))
+	assert.Equal(t, "Content-Type", string(resp.Header.Peek("Access-Control-Allow-Headers")))
+	assert.Equal(t, "600", string(resp.Header.Peek("Access-Control-Max-Age")))
+	assert.Equal(t, "true", string(resp.Header.Peek("Access-Control-Allow-Credentials")))
+	assert.Empty(t, resp.Body())
+}
+
+func TestMaxBodySize(t *testing.T) {
+	h := newTestPlugin(t)
+
+	resp := serve(h, "POST", "localhost:8888", "/v1/test/echo", `{"data":"0123456789"}`)
+	assert.Equal(t, fasthttp.StatusRequestEntityTooLarge, resp.StatusCode())
+	assert.Equal(t, "application/json", string(resp.Header.ContentType()))
+
+	var results ErrorResults
+	assert.NoError(t, json.Unmarshal(resp.Body(), &results))
+	assert.Equal(t, uint16(fasthttp.StatusRequestEntityTooLarge), results.Code)
+}
+
+func TestHandlerResponse(t *testing.T) {
+	h := newTestPlugin(t)
+	io := App().GetIoService()
+	go io.Run()
+	defer io.Stop()
+
+	resp := serve(h, "POST", "localhost:8888", "/v1/test/echo", `{}`)
+	assert.Equal(t, 201, resp.StatusCode())
+	assert.Equal(t, "{}", string(resp.Body()))
+	assert.Equal(t, "application/json", string(resp.Header.ContentType()))
+	assert.Equal(t, "*", string(resp.Header.Peek("Access-Control-Allow-Origin")))
+	assert.Empty(t, resp.Header.Peek("Set-Cookie"))
+	assert.Empty(t, resp.Header.Peek("X-My-Header"))
+
+	resp = serve(h, "POST", "localhost:8888", "/v1/test/unknown", `{}`)
+	assert.Equal(t, fasthttp.StatusNotFound, resp.StatusCode())
+	assert.Equal(t, "application/json", string(resp.Header.ContentType()))
+	var results ErrorResults
+	assert.NoError(t, json.Unmarshal(resp.Body(), &results))
+	assert.Equal(t, "Not Found", results.Message)
+}
+
+func TestMaxResponseTime(t *testing.T) {
+	h := newTestPlugin(t)
+	h.my.MaxResponseTime = 50 * time.Millisecond
+	answerLater := func(source string, body []byte, cb UrlResponseCallback) {
+		go func() {
+			time.Sleep(4 * h.my.MaxResponseTime)
+			cb(202, body)
+		}()
+	}
+	h.my.UrlHandlers["/v1/test/wait"] = answerLater
+	h.my.UrlHandlers["/v1/test/long_poll"] = answerLater
+	h.my.longPollHandlers["/v1/test/long_poll"] = true
+	io := App().GetIoService()
+	go io.Run()
+	defer io.Stop()
+
+	resp := serve(h, "POST", "localhost:8888", "/v1/test/wait", `{}`)
+	assert.Equal(t, fasthttp.StatusGatewayTimeout, resp.StatusCode())
+	var results ErrorResults
+	assert.NoError(t, json.Unmarshal(resp.Body(), &results))
+	assert.Equal(t, "Gateway Timeout", results.Message)
+
+	// long poll handlers answer once they are done waiting
+	resp = serve(h, "POST", "localhost:8888", "/v1/test/long_poll", `{}`)
+	assert.Equal(t, 202, resp.StatusCode())
+	assert.Equal(t, "{}", string(resp.Body()))
+}
+
+func TestHandlerContentType(t *testing.T) {
+	h := newTestPlugin(t)
+	h.my.UrlHandlers["/v1/test/binary"] = func(source string, body []byte, cb UrlResponseCallback) {
+		if len(body) == 0 {
+			cb(500, []byte(`{"code":500}`))
+			return
+		}
+		cb(200, []byte{0x1f, 0x8b})
+	}
+	h.my.contentTypes["/v1/test/binary"] = "application/octet-stream"
+	io := App().GetIoService()
+	go io.Run()
+	defer io.Stop()
+
+	resp := serve(h, "POST", "localhost:8888", "/v1/test/binary", `{}`)
+	assert.Equal(t, 200, resp.StatusCode())
+	assert.Equal(t, "application/octet-stream", string(resp.Header.ContentType()))
+	assert.Equal(t, []byte{0x1f, 0x8b}, resp.Body())
+
+	// errors are still answered in json
+	resp = serve(h, "POST", "localhost:8888", "/v1/test/binary", ``)
+	assert.Equal(t, 500, resp.StatusCode())
+	assert.Equal(t, "application/json", string(resp.Header.ContentType()))
+}
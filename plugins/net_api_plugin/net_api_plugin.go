@@ -112,6 +112,48 @@ func (n *NetApiPlugin) PluginStartup() {
 			http_plugin.HandleException(e, "net", "connections", string(body), cb)
 		}).End()
 	})
+
+	httpPlugin.AddHandler(common.NetBans, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			result := netMgr.Bans()
+			if byte, err := json.Marshal(result); err == nil {
+				cb(200, byte)
+			} else {
+				Throw(err)
+			}
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "net", "bans", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.NetUnban, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			result := netMgr.Unban(string(body[1 : len(body)-1]))
+			if byte, err := json.Marshal(result); err == nil {
+				cb(200, byte)
+			} else {
+				Throw(err)
+			}
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "net", "unban", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.NetAddresses, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			result := netMgr.KnownAddresses()
+			if byte, err := json.Marshal(result); err == nil {
+				cb(200, byte)
+			} else {
+				Throw(err)
+			}
+
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "net", "addresses", string(body), cb)
+		}).End()
+	})
 }
 
 func (n *NetApiPlugin) PluginShutdown() {}
@@ -0,0 +1,330 @@
+package net_plugin
+
+import (
+	"encoding/json"
+	"github.com/eosspark/eos-go/common"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defAddressBookFile    = "p2p-address-book.json"
+	defTargetOutbound     = 8
+	maxAddressBookSize    = 1000
+	maxAddressesPerMsg    = 64
+	maxAddressFailures    = 10             // an address is forgotten after that many failed attempts in a row
+	addressRetryBackoff   = time.Minute    // multiplied by the failures of the address
+	addressShareFreshness = 24 * time.Hour // only addresses seen more recently are shared with peers
+)
+
+//KnownAddress is an entry of the address book. LastSeen is set once a connection to the address completed
+//its handshake, addresses learned from peers are not shared until then.
+type KnownAddress struct {
+	Address     string           `json:"address"`
+	LastSeen    common.TimePoint `json:"last_seen"`
+	LastAttempt common.TimePoint `json:"last_attempt"`
+	Failures    uint32           `json:"failures"`
+}
+
+/**
+ * addressBook keeps the listening addresses of the peers, persisted in the data dir so that a restarted
+ * node does not depend on its configured peers only.
+ */
+type addressBook struct {
+	path         string
+	addresses    map[string]*KnownAddress
+	allowPrivate bool // loopback, link-local and private addresses are refused unless allowed
+	dirty        bool
+}
+
+func newAddressBook(path string) *addressBook {
+	return &addressBook{
+		path:      path,
+		addresses: make(map[string]*KnownAddress),
+	}
+}
+
+func addressBookPath(file string, dataDir string) string {
+	if !filepath.IsAbs(file) {
+		return filepath.Join(dataDir, file)
+	}
+	return file
+}
+
+//load reads the persisted address book, an unreadable book is replaced by an empty one
+func (b *addressBook) load() {
+	content, err := ioutil.ReadFile(b.path)
+	if os.IsNotExist(err) {
+		return
+	}
+	var addresses []KnownAddress
+	if err == nil {
+		err = json.Unmarshal(content, &addresses)
+	}
+	if err != nil {
+		netLog.Error("unable to read the address book %s, starting with an empty one: %s", b.path, err)
+		return
+	}
+	for i := range addresses {
+		if validPeerAddress(addresses[i].Address, b.allowPrivate) {
+			b.addresses[addresses[i].Address] = &addresses[i]
+		}
+	}
+	netLog.Info("loaded %d peer addresses from %s", len(b.addresses), b.path)
+}
+
+//save writes the address book if it changed, through a temporary file so that a crash never leaves a partial book
+func (b *addressBook) save() {
+	if !b.dirty {
+		return
+	}
+	addresses := make([]KnownAddress, 0, len(b.addresses))
+	for _, a := range b.addresses {
+		addresses = append(addresses, *a)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i].Address < addresses[j].Address })
+
+	content, _ := json.MarshalIndent(addresses, "", "  ")
+	err := os.MkdirAll(filepath.Dir(b.path), os.ModePerm)
+	if err == nil {
+		err = ioutil.WriteFile(b.path+".tmp", content, 0644)
+	}
+	if err == nil {
+		err = os.Rename(b.path+".tmp", b.path)
+	}
+	if err != nil {
+		netLog.Error("unable to write the address book %s: %s", b.path, err)
+		return
+	}
+	b.dirty = false
+}
+
+//validPeerAddress accepts "host:port" with a non zero port and a host which can be connected to. Unless allowPrivate,
+//the host must not be a loopback, link-local or private address: peers could otherwise steer the node to its own network.
+//A host name could resolve to any of those, it is only accepted along with them.
+func validPeerAddress(address string, allowPrivate bool) bool {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || len(host) == 0 {
+		return false
+	}
+	if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && (ip.IsUnspecified() || ip.IsMulticast()) {
+		return false
+	}
+	if allowPrivate {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	return !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsPrivate()
+}
+
+//advertisedAddress extracts the listening address from the p2p_address of a handshake, "host:port - id".
+//An unspecified host is replaced by the host the peer connects from.
+func advertisedAddress(p2pAddress string, remoteHost string, allowPrivate bool) string {
+	address := strings.TrimSpace(strings.SplitN(p2pAddress, " ", 2)[0])
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return ""
+	}
+	if ip := net.ParseIP(host); len(host) == 0 || (ip != nil && ip.IsUnspecified()) {
+		address = net.JoinHostPort(remoteHost, port)
+	}
+	if !validPeerAddress(address, allowPrivate) {
+		return ""
+	}
+	return address
+}
+
+//add records an address which is not known yet, the oldest unseen address is evicted from a full book
+func (b *addressBook) add(address string) {
+	if _, known := b.addresses[address]; known || !validPeerAddress(address, b.allowPrivate) {
+		return
+	}
+	if len(b.addresses) >= maxAddressBookSize && !b.evict() {
+		return
+	}
+	b.addresses[address] = &KnownAddress{Address: address}
+	b.dirty = true
+}
+
+func (b *addressBook) evict() bool {
+	var worst *KnownAddress
+	for _, a := range b.addresses {
+		if worst == nil || a.Failures > worst.Failures || (a.Failures == worst.Failures && a.LastSeen < worst.LastSeen) {
+			worst = a
+		}
+	}
+	if worst == nil || (worst.Failures == 0 && worst.LastSeen != 0) {
+		return false
+	}
+	delete(b.addresses, worst.Address)
+	return true
+}
+
+func (b *addressBook) remove(address string) {
+	if _, known := b.addresses[address]; known {
+		delete(b.addresses, address)
+		b.dirty = true
+	}
+}
+
+func (b *addressBook) markAttempt(address string) {
+	if a, known := b.addresses[address]; known {
+		a.LastAttempt = common.Now()
+		b.dirty = true
+	}
+}
+
+func (b *addressBook) markSeen(address string) {
+	b.add(address)
+	if a, known := b.addresses[address]; known {
+		a.LastSeen = common.Now()
+		a.Failures = 0
+		b.dirty = true
+	}
+}
+
+func (b *addressBook) markFailed(address string) {
+	a, known := b.addresses[address]
+	if !known {
+		return
+	}
+	a.Failures++
+	a.LastAttempt = common.Now()
+	if a.Failures >= maxAddressFailures {
+		netLog.Info("forgetting peer address %s after %d failed connections", address, a.Failures)
+		delete(b.addresses, address)
+	}
+	b.dirty = true
+}
+
+/**
+ * candidates returns the addresses which may be connected to now: those whose backoff after their last failed
+ * attempt is over, the ones with the fewest failures and then the most recently seen first.
+ */
+func (b *addressBook) candidates() []*KnownAddress {
+	now := common.Now()
+	result := make([]*KnownAddress, 0)
+	for _, a := range b.addresses {
+		backoff := common.Microseconds(int64(a.Failures) * int64(addressRetryBackoff/time.Microsecond))
+		if a.Failures > 0 && now < a.LastAttempt.AddUs(backoff) {
+			continue
+		}
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Failures != result[j].Failures {
+			return result[i].Failures < result[j].Failures
+		}
+		return result[i].LastSeen > result[j].LastSeen
+	})
+	return result
+}
+
+//sample returns the most recently seen addresses to share with a peer
+func (b *addressBook) sample() []PeerAddress {
+	since := common.Now().SubUs(common.Microseconds(addressShareFreshness / time.Microsecond))
+	fresh := make([]*KnownAddress, 0)
+	for _, a := range b.addresses {
+		if a.Failures == 0 && a.LastSeen > since {
+			fresh = append(fresh, a)
+		}
+	}
+	sort.Slice(fresh, func(i, j int) bool { return fresh[i].LastSeen > fresh[j].LastSeen })
+	if len(fresh) > maxAddressesPerMsg {
+		fresh = fresh[:maxAddressesPerMsg]
+	}
+
+	result := make([]PeerAddress, 0, len(fresh))
+	for _, a := range fresh {
+		result = append(result, PeerAddress{Address: a.Address, LastSeen: a.LastSeen})
+	}
+	return result
+}
+
+//learnPeerAddress records the listening address of a peer once its handshake is accepted, and shares the known
+//addresses with peers which support the address exchange
+func (impl *netPluginIMpl) learnPeerAddress(c *Connection, msg *HandshakeMessage) {
+	if msg.NodeID.Equals(impl.nodeID) {
+		// connected to ourselves through one of our own addresses
+		impl.addressBook.remove(c.peerAddr)
+		return
+	}
+	if c.outbound {
+		impl.addressBook.markSeen(c.peerAddr)
+	}
+	if address := advertisedAddress(msg.P2PAddress, c.remoteHost(), impl.addressBook.allowPrivate); len(address) > 0 && address != impl.p2PServerAddress {
+		impl.addressBook.add(address)
+	}
+	if c.protocolVersion >= protoAddressExchange {
+		c.enqueue(&AddressMessage{Addresses: impl.addressBook.sample()}, true)
+	}
+}
+
+func (impl *netPluginIMpl) handleAddress(c *Connection, msg *AddressMessage) {
+	FcLog.Debug("%s : received address_message with %d addresses", c.peerAddr, len(msg.Addresses))
+	if c.lastHandshakeRecv.Generation == 0 || c.addressesReceived {
+		impl.penalize(c, unsolicitedMessage)
+		return
+	}
+	c.addressesReceived = true
+	if len(msg.Addresses) > maxAddressesPerMsg {
+		impl.penalize(c, oversizedMessage)
+		return
+	}
+	for _, a := range msg.Addresses {
+		if a.Address != impl.p2PServerAddress {
+			impl.addressBook.add(a.Address)
+		}
+	}
+}
+
+/**
+ * connectOutbound opens connections to the addresses of the address book until targetOutbound outbound
+ * connections are open, without exceeding p2p-max-nodes-per-host connections to any host.
+ * Dropped connections are replaced in the same way, which rotates the peers.
+ */
+func (impl *netPluginIMpl) connectOutbound() {
+	if impl.targetOutbound == 0 {
+		return
+	}
+	outbound := uint32(0)
+	perHost := make(map[string]uint32)
+	for _, c := range impl.connections {
+		if c.outbound {
+			outbound++
+		}
+		// keyed by the host of the address as the candidates are, a host name is not resolved to compare it
+		perHost[hostOf(c.peerAddr)]++
+	}
+
+	for _, a := range impl.addressBook.candidates() {
+		if outbound >= impl.targetOutbound {
+			break
+		}
+		host := hostOf(a.Address)
+		if a.Address == impl.p2PServerAddress || perHost[host] >= impl.maxNodesPerHost || impl.reputation.isBannedHost(host) {
+			continue
+		}
+		if i, _ := impl.findConnection(a.Address); i >= 0 {
+			continue
+		}
+		FcLog.Info("connecting to known peer %s", a.Address)
+		impl.addressBook.markAttempt(a.Address)
+		impl.Self.Connect(a.Address)
+		outbound++
+		perHost[host]++
+	}
+	impl.addressBook.save()
+}
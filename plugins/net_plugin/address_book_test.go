@@ -0,0 +1,176 @@
+package net_plugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eosspark/eos-go/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func publicAddress(i int) string {
+	return fmt.Sprintf("8.8.%d.%d:9876", i/256, i%256)
+}
+
+func TestValidPeerAddress(t *testing.T) {
+	for _, a := range []string{"8.8.8.8:9876", "[2001:4860::8888]:9876"} {
+		assert.True(t, validPeerAddress(a, false), a)
+		assert.True(t, validPeerAddress(a, true), a)
+	}
+	for _, a := range []string{"8.8.8.8", "8.8.8.8:0", "8.8.8.8:65536", "8.8.8.8:port", ":9876", "0.0.0.0:9876", "[::]:9876", "224.0.0.1:9876"} {
+		assert.False(t, validPeerAddress(a, false), a)
+		assert.False(t, validPeerAddress(a, true), a)
+	}
+	// host names may resolve to private addresses
+	for _, a := range []string{"node.example.com:9876", "127.0.0.1:9876", "localhost:9876", "[::1]:9876", "169.254.1.1:9876", "[fe80::1]:9876",
+		"10.0.0.1:9876", "172.16.5.4:9876", "192.168.1.1:9876", "[fd00::1]:9876"} {
+		assert.False(t, validPeerAddress(a, false), a)
+		assert.True(t, validPeerAddress(a, true), a)
+	}
+}
+
+func TestAdvertisedAddress(t *testing.T) {
+	assert.Equal(t, "8.8.4.4:9876", advertisedAddress("8.8.4.4:9876 - 1a2b3c4", "8.8.8.8", false))
+	assert.Equal(t, "8.8.8.8:9876", advertisedAddress("0.0.0.0:9876 - 1a2b3c4", "8.8.8.8", false))
+	assert.Equal(t, "8.8.8.8:9876", advertisedAddress(":9876", "8.8.8.8", false))
+	assert.Equal(t, "", advertisedAddress("not an address", "8.8.8.8", false))
+
+	assert.Equal(t, "", advertisedAddress("0.0.0.0:9876 - 1a2b3c4", "127.0.0.1", false))
+	assert.Equal(t, "127.0.0.1:9876", advertisedAddress("0.0.0.0:9876 - 1a2b3c4", "127.0.0.1", true))
+	assert.Equal(t, "", advertisedAddress("192.168.1.1:9876", "8.8.8.8", false))
+}
+
+func TestAddressBookFailures(t *testing.T) {
+	b := newAddressBook("")
+	b.add(publicAddress(1))
+	b.add(publicAddress(2))
+	b.add("127.0.0.1:9876")
+	assert.Equal(t, 2, len(b.addresses))
+
+	// an address is retried once its backoff is over
+	b.markFailed(publicAddress(1))
+	candidates := b.candidates()
+	assert.Equal(t, 1, len(candidates))
+	assert.Equal(t, publicAddress(2), candidates[0].Address)
+
+	b.addresses[publicAddress(1)].LastAttempt = common.Now().SubUs(common.Microseconds(addressRetryBackoff / time.Microsecond))
+	candidates = b.candidates()
+	assert.Equal(t, 2, len(candidates))
+	assert.Equal(t, publicAddress(2), candidates[0].Address)
+
+	// a successful connection clears the failures
+	b.markSeen(publicAddress(1))
+	assert.Equal(t, uint32(0), b.addresses[publicAddress(1)].Failures)
+	assert.Equal(t, publicAddress(1), b.candidates()[0].Address)
+
+	// and an address failing too often is forgotten
+	for i := 0; i < maxAddressFailures; i++ {
+		b.markFailed(publicAddress(2))
+	}
+	_, known := b.addresses[publicAddress(2)]
+	assert.False(t, known)
+}
+
+func TestAddressBookEvict(t *testing.T) {
+	b := newAddressBook("")
+	for i := 0; i < maxAddressBookSize; i++ {
+		b.markSeen(publicAddress(i))
+	}
+	assert.Equal(t, maxAddressBookSize, len(b.addresses))
+
+	// a book full of working addresses refuses new ones
+	b.add(publicAddress(maxAddressBookSize))
+	assert.Equal(t, maxAddressBookSize, len(b.addresses))
+	_, known := b.addresses[publicAddress(maxAddressBookSize)]
+	assert.False(t, known)
+
+	// the worst address makes room otherwise
+	b.markFailed(publicAddress(7))
+	b.add(publicAddress(maxAddressBookSize))
+	assert.Equal(t, maxAddressBookSize, len(b.addresses))
+	_, known = b.addresses[publicAddress(7)]
+	assert.False(t, known)
+	_, known = b.addresses[publicAddress(maxAddressBookSize)]
+	assert.True(t, known)
+}
+
+func TestAddressBookSample(t *testing.T) {
+	b := newAddressBook("")
+	for i := 0; i < maxAddressesPerMsg+10; i++ {
+		b.markSeen(publicAddress(i))
+	}
+	b.add(publicAddress(1000))
+	b.markFailed(publicAddress(0))
+	b.addresses[publicAddress(1)].LastSeen = common.Now().SubUs(common.Microseconds(addressShareFreshness / time.Microsecond))
+
+	sample := b.sample()
+	assert.Equal(t, maxAddressesPerMsg, len(sample))
+	for _, a := range sample {
+		assert.NotEqual(t, publicAddress(0), a.Address)
+		assert.NotEqual(t, publicAddress(1), a.Address)
+		assert.NotEqual(t, publicAddress(1000), a.Address)
+	}
+}
+
+func TestAddressBookPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defAddressBookFile)
+	b := newAddressBook(path)
+	b.allowPrivate = true
+	b.markSeen(publicAddress(1))
+	b.add(publicAddress(2))
+	b.markFailed(publicAddress(2))
+	b.add("192.168.1.1:9876")
+	b.save()
+	assert.False(t, b.dirty)
+
+	loaded := newAddressBook(path)
+	loaded.allowPrivate = true
+	loaded.load()
+	assert.Equal(t, len(b.addresses), len(loaded.addresses))
+	for address, a := range b.addresses {
+		l, known := loaded.addresses[address]
+		assert.True(t, known, address)
+		assert.Equal(t, a.Failures, l.Failures)
+		// time points are persisted to the millisecond
+		assert.InDelta(t, int64(a.LastSeen), int64(l.LastSeen), 1000)
+		assert.InDelta(t, int64(a.LastAttempt), int64(l.LastAttempt), 1000)
+	}
+
+	// private addresses are dropped from the book when they are not allowed anymore
+	loaded = newAddressBook(path)
+	loaded.load()
+	assert.Equal(t, 2, len(loaded.addresses))
+	_, known := loaded.addresses["192.168.1.1:9876"]
+	assert.False(t, known)
+
+	// an unreadable book is replaced by an empty one
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	loaded = newAddressBook(path)
+	loaded.load()
+	assert.Equal(t, 0, len(loaded.addresses))
+}
+
+type remoteConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c remoteConn) RemoteAddr() net.Addr { return c.remote }
+
+func TestConnectOutboundPerHost(t *testing.T) {
+	impl := &netPluginIMpl{reputation: newPeerReputation(), targetOutbound: 2, maxNodesPerHost: 1}
+	impl.addressBook = newAddressBook(filepath.Join(t.TempDir(), defAddressBookFile))
+	impl.addressBook.allowPrivate = true
+
+	// a peer connected through its host name counts for that name, not for the address it resolved to
+	impl.connections = append(impl.connections, &Connection{impl: impl, peerAddr: "node.example.com:9876", outbound: true,
+		conn: remoteConn{remote: &net.TCPAddr{IP: net.ParseIP("8.8.0.1"), Port: 9876}}})
+	impl.addressBook.add("node.example.com:9877")
+	impl.connectOutbound()
+	assert.Equal(t, 1, len(impl.connections))
+	assert.Equal(t, common.TimePoint(0), impl.addressBook.addresses["node.example.com:9877"].LastAttempt)
+}
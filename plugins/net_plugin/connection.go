@@ -27,10 +27,13 @@ type PeerStatus struct {
 	Connecting    bool             `json:"connecting"`
 	Syncing       bool             `json:"syncing"`
 	LastHandshake HandshakeMessage `json:"last_handshake"`
+	Score         int32            `json:"score"`
+	Encrypted     bool             `json:"encrypted"`
 }
 
 type queuedWrite struct {
 	buff     []byte
+	seal     bool // sealed when written, so that the nonces follow the order of the frames on the wire
 	callback func(err error, n int)
 }
 
@@ -73,6 +76,9 @@ type Connection struct {
 	forkHead             common.BlockIdType
 	forkHeadNum          uint32
 	lastReq              *RequestMessage
+	session              *secureSession //nil on plaintext connections
+	outbound             bool           //connected by us rather than accepted
+	addressesReceived    bool
 
 	//outstandingReadBytes int //optional
 	bufTemp []byte
@@ -105,6 +111,7 @@ func NewConnectionByEndPoint(endpoint string, impl *netPluginIMpl) *Connection {
 		syncing:            false,
 		protocolVersion:    0,
 		peerAddr:           endpoint,
+		outbound:           true,
 		//responseExpected:
 		pendingFetch: &RequestMessage{},
 		noRetry:      noReason,
@@ -160,9 +167,19 @@ func (c *Connection) getStatus() *PeerStatus {
 		Connecting:    c.connecting,
 		Syncing:       c.syncing,
 		LastHandshake: *c.lastHandshakeRecv,
+		Score:         c.impl.reputation.score(c.nodeID, c.remoteHost()),
+		Encrypted:     c.encrypted(),
 	}
 }
 
+//remoteHost returns the ip address of the peer, or the host of its endpoint before it is connected
+func (c *Connection) remoteHost() string {
+	if c.conn != nil {
+		return hostOf(c.conn.RemoteAddr().String())
+	}
+	return hostOf(c.peerAddr)
+}
+
 func (c *Connection) connected() bool { //TODO
 	return c.socket != nil && !c.connecting
 }
@@ -197,6 +214,8 @@ func (c *Connection) close() {
 	FcLog.Debug("cancel wait on %s", c.PeerName())
 	c.cancelWait()
 	c.bufTemp = nil
+	c.session = nil
+	c.addressesReceived = false
 }
 
 func (c *Connection) sendHandshake() {
@@ -222,7 +241,7 @@ func (c *Connection) handshakePopulate(impl *netPluginIMpl, hello *HandshakeMess
 		hello.Token = crypto.NewSha256Nil()
 	}
 
-	hello.P2PAddress = impl.p2PAddress + " - " + hello.NodeID.String()[:7]
+	hello.P2PAddress = impl.p2PServerAddress + " - " + hello.NodeID.String()[:7]
 
 	switch runtime.GOOS {
 	case "darwin":
@@ -302,6 +321,7 @@ func (c *Connection) fetchWait() {
 
 func (c *Connection) syncTimeout(err error) { //TODO not same as C++
 	if err == nil {
+		c.impl.penalize(c, syncTimeout)
 		c.impl.syncMaster.reassignFetch(c, benignOther)
 	} else {
 		netLog.Error("setting timer for sync request fot error %s", err)
@@ -664,16 +684,26 @@ func (c *Connection) addPeerBlock(entry *PeerBlockState) bool {
 	return added
 }
 
-func (c *Connection) processNextMessage(payloadBytes []byte) bool {
+func (c *Connection) processNextMessage(frameBody []byte) bool {
 	result := true
 	Try(func() {
+		payloadBytes, err := c.openFrame(frameBody)
+		if err != nil || len(payloadBytes) == 0 {
+			c.impl.penalize(c, invalidSignature)
+			Throw(fmt.Errorf("processNextMessage, unable to decrypt message from %s", c.PeerName()))
+		}
+
 		messageType := NetMessageType(payloadBytes[0])
 		attr, ok := messageType.reflectTypes()
 		if !ok {
 			Throw(fmt.Errorf("processNextMessage, unknown p2p message type %d", messageType))
 		}
+		if !c.expectsMessage(messageType) {
+			c.impl.penalize(c, unsolicitedMessage)
+			Throw(fmt.Errorf("processNextMessage, unexpected p2p message type %d during the encryption handshake", messageType))
+		}
 		msg := reflect.New(attr.ReflectType)
-		err := rlp.DecodeBytes(payloadBytes[1:], msg.Interface())
+		err = rlp.DecodeBytes(payloadBytes[1:], msg.Interface())
 		if err != nil {
 			Throw(err)
 		}
@@ -698,6 +728,16 @@ func (c *Connection) processNextMessage(payloadBytes []byte) bool {
 			c.impl.handleSignedBlock(c, msg)
 		case *PackedTransactionMessage:
 			c.impl.handlePackTransaction(c, msg)
+		case *HeaderConfirmationMessage:
+			c.impl.handleHeaderConfirmation(c, msg)
+		case *EncryptionHelloMessage:
+			c.impl.handleEncryptionHello(c, msg)
+		case *EncryptionAcceptMessage:
+			c.impl.handleEncryptionAccept(c, msg)
+		case *EncryptionAuthMessage:
+			c.impl.handleEncryptionAuth(c, msg)
+		case *AddressMessage:
+			c.impl.handleAddress(c, msg)
 		default:
 			Throw(fmt.Errorf("unsuppoted p2p message type %d", messageType))
 		}
@@ -739,7 +779,7 @@ func (c *Connection) enqueue(m NetMessage, triggerSend bool) {
 }
 
 func (c *Connection) queueWrite(buf []byte, triggerSend bool, callback func(err error, n int)) {
-	c.writeQueue = append(c.writeQueue, queuedWrite{buf, callback})
+	c.writeQueue = append(c.writeQueue, queuedWrite{buff: buf, seal: c.encrypted(), callback: callback})
 	if len(c.outQueue) == 0 && triggerSend {
 		c.doQueueWrite()
 	}
@@ -759,6 +799,9 @@ func (c *Connection) doQueueWrite() {
 	bufs := make([]byte, 0)
 	for i := 0; i < len(c.writeQueue); i++ {
 		m := c.writeQueue[i]
+		if m.seal {
+			m.buff = c.sealFrame(m.buff)
+		}
 		bufs = append(bufs, m.buff...)
 		c.outQueue = append(c.outQueue, m)
 	}
@@ -12,19 +12,38 @@ import (
 )
 
 type dispatchManager struct {
-	justSendItMax        uint32
-	reqTrx               []common.TransactionIdType
-	receivedBlocks       map[common.BlockIdType][]*Connection
-	receivedTransactions map[common.TransactionIdType][]*Connection
-	myImpl               *netPluginIMpl
+	justSendItMax         uint32
+	reqTrx                []common.TransactionIdType
+	receivedBlocks        map[common.BlockIdType][]*Connection
+	receivedTransactions  map[common.TransactionIdType][]*Connection
+	receivedConfirmations map[confirmationKey][]*Connection
+	heldConfirmations     map[common.BlockIdType][]heldConfirmation
+	heldCount             int
+	myImpl                *netPluginIMpl
 }
 
+// a producer confirms a block once, so the pair identifies a confirmation
+type confirmationKey struct {
+	BlockId  common.BlockIdType
+	Producer common.AccountName
+}
+
+// a confirmation that arrived before its block, applied once the block is accepted
+type heldConfirmation struct {
+	conn *Connection
+	hc   types.HeaderConfirmation
+}
+
+const maxHeldConfirmations = 1024
+
 func NewDispatchManager(impl *netPluginIMpl) *dispatchManager {
 	return &dispatchManager{
-		reqTrx:               make([]common.TransactionIdType, 0),
-		receivedBlocks:       make(map[common.BlockIdType][]*Connection, 0),
-		receivedTransactions: make(map[common.TransactionIdType][]*Connection, 0),
-		myImpl:               impl,
+		reqTrx:                make([]common.TransactionIdType, 0),
+		receivedBlocks:        make(map[common.BlockIdType][]*Connection, 0),
+		receivedTransactions:  make(map[common.TransactionIdType][]*Connection, 0),
+		receivedConfirmations: make(map[confirmationKey][]*Connection, 0),
+		heldConfirmations:     make(map[common.BlockIdType][]heldConfirmation, 0),
+		myImpl:                impl,
 	}
 }
 
@@ -87,6 +106,9 @@ func (d *dispatchManager) rejectedBlock(id *common.BlockIdType) {
 	if _, ok := d.receivedBlocks[*id]; ok {
 		delete(d.receivedBlocks, *id)
 	}
+	for _, held := range d.takeConfirmations(*id) {
+		d.rejectedConfirmation(&held.hc)
+	}
 }
 
 func (d *dispatchManager) recvBlock(c *Connection, id common.BlockIdType, bnum uint32) {
@@ -322,6 +344,74 @@ func (d *dispatchManager) recvNotice(c *Connection, msg *NoticeMessage, generate
 	}
 }
 
+/**
+ * recvConfirmation records that c sent the confirmation and tells whether it was already known,
+ * the confirmation is then neither pushed to the chain nor relayed again
+ */
+func (d *dispatchManager) recvConfirmation(c *Connection, hc *types.HeaderConfirmation) bool {
+	key := confirmationKey{hc.BlockId, hc.Producer}
+	peers, known := d.receivedConfirmations[key]
+	d.receivedConfirmations[key] = append(peers, c)
+	return known
+}
+
+func (d *dispatchManager) rejectedConfirmation(hc *types.HeaderConfirmation) {
+	FcLog.Debug("not sending rejected header_confirmation of %s by %s", hc.BlockId, hc.Producer)
+	delete(d.receivedConfirmations, confirmationKey{hc.BlockId, hc.Producer})
+}
+
+// bcastConfirmation relays an accepted confirmation to the peers that did not send it to us and know the message
+func (d *dispatchManager) bcastConfirmation(hc *types.HeaderConfirmation) {
+	key := confirmationKey{hc.BlockId, hc.Producer}
+	skips := make(map[*Connection]bool)
+	for _, p := range d.receivedConfirmations[key] {
+		skips[p] = true
+	}
+	if _, known := d.receivedConfirmations[key]; !known {
+		d.receivedConfirmations[key] = make([]*Connection, 0)
+	}
+
+	msg := HeaderConfirmationMessage{*hc}
+	d.myImpl.sendAll(&msg, func(c *Connection) bool {
+		return !skips[c] && !c.syncing && c.protocolVersion >= protoHeaderConfirmation
+	})
+}
+
+/**
+ * holdConfirmation keeps a confirmation whose block is not known yet, the confirmation usually
+ * travels faster than the block it confirms. It returns false when too many are already held.
+ */
+func (d *dispatchManager) holdConfirmation(c *Connection, hc *types.HeaderConfirmation) bool {
+	if d.heldCount >= maxHeldConfirmations {
+		return false
+	}
+	d.heldConfirmations[hc.BlockId] = append(d.heldConfirmations[hc.BlockId], heldConfirmation{c, *hc})
+	d.heldCount++
+	return true
+}
+
+// takeConfirmations returns and forgets the confirmations held for a block
+func (d *dispatchManager) takeConfirmations(id common.BlockIdType) []heldConfirmation {
+	held := d.heldConfirmations[id]
+	delete(d.heldConfirmations, id)
+	d.heldCount -= len(held)
+	return held
+}
+
+// expireConfirmations forgets the confirmations of irreversible blocks, they are of no use anymore
+func (d *dispatchManager) expireConfirmations(libNum uint32) {
+	for key := range d.receivedConfirmations {
+		if types.NumFromID(&key.BlockId) <= libNum {
+			delete(d.receivedConfirmations, key)
+		}
+	}
+	for id := range d.heldConfirmations {
+		if types.NumFromID(&id) <= libNum {
+			d.takeConfirmations(id)
+		}
+	}
+}
+
 func (d *dispatchManager) retryFetch(c *Connection) {
 	if common.Empty(c.lastReq) {
 		return
@@ -0,0 +1,100 @@
+package net_plugin
+
+import (
+	"testing"
+
+	"github.com/eosspark/eos-go/chain/types"
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto/ecc"
+	"github.com/eosspark/eos-go/libraries/asio"
+	"github.com/stretchr/testify/assert"
+)
+
+func confirmationOf(num uint32, producer string) *types.HeaderConfirmation {
+	header := types.BlockHeader{Timestamp: types.BlockTimeStamp(num)}
+	header.Previous.Hash[0] = uint64(common.EndianReverseU32(num - 1))
+	return &types.HeaderConfirmation{BlockId: header.BlockID(), Producer: common.N(producer)}
+}
+
+func TestHeldConfirmations(t *testing.T) {
+	d := NewDispatchManager(nil)
+	c := &Connection{}
+
+	early := confirmationOf(10, "alice")
+	other := confirmationOf(10, "bob")
+	later := confirmationOf(12, "alice")
+	assert.False(t, d.recvConfirmation(c, early))
+	assert.True(t, d.holdConfirmation(c, early))
+	assert.True(t, d.holdConfirmation(c, other))
+	assert.True(t, d.holdConfirmation(c, later))
+	assert.Equal(t, 3, d.heldCount)
+
+	// a duplicate of a held confirmation is still recognized
+	assert.True(t, d.recvConfirmation(&Connection{}, early))
+
+	// the confirmations are handed back once their block arrives
+	held := d.takeConfirmations(early.BlockId)
+	assert.Equal(t, 2, len(held))
+	assert.Equal(t, *early, held[0].hc)
+	assert.Equal(t, *other, held[1].hc)
+	assert.Equal(t, c, held[0].conn)
+	assert.Equal(t, 0, len(d.takeConfirmations(early.BlockId)))
+	assert.Equal(t, 1, d.heldCount)
+
+	// and forgotten when the block turns out invalid
+	id := later.BlockId
+	d.recvConfirmation(c, later)
+	d.rejectedBlock(&id)
+	assert.Equal(t, 0, d.heldCount)
+	assert.False(t, d.recvConfirmation(c, later))
+
+	// or irreversible
+	assert.True(t, d.holdConfirmation(c, later))
+	d.expireConfirmations(11)
+	assert.Equal(t, 1, d.heldCount)
+	d.expireConfirmations(12)
+	assert.Equal(t, 0, d.heldCount)
+	assert.Equal(t, 0, len(d.heldConfirmations))
+}
+
+func TestHeldConfirmationsBound(t *testing.T) {
+	d := NewDispatchManager(nil)
+	c := &Connection{}
+	for i := 0; i < maxHeldConfirmations; i++ {
+		assert.True(t, d.holdConfirmation(c, confirmationOf(uint32(i+2), "alice")))
+	}
+	assert.False(t, d.holdConfirmation(c, confirmationOf(1, "bob")))
+
+	d.takeConfirmations(confirmationOf(2, "alice").BlockId)
+	assert.True(t, d.holdConfirmation(c, confirmationOf(1, "bob")))
+}
+
+//peerOf is a connected peer of the protocol version whose frames stay queued
+func peerOf(impl *netPluginIMpl, protocolVersion uint16) *Connection {
+	c := &Connection{impl: impl, socket: &asio.ReactiveSocket{}, protocolVersion: protocolVersion}
+	c.outQueue = []queuedWrite{{}}
+	impl.connections = append(impl.connections, c)
+	return c
+}
+
+func TestBcastConfirmationProtocolVersion(t *testing.T) {
+	impl := &netPluginIMpl{}
+	d := NewDispatchManager(impl)
+	sender := peerOf(impl, netVersion)
+	current := peerOf(impl, netVersion)
+	old := peerOf(impl, protoAddressExchange)
+	base := peerOf(impl, protoBase)
+
+	hc := confirmationOf(10, "alice")
+	key, _ := ecc.NewRandomPrivateKey()
+	hc.ProducerSignature, _ = key.Sign(hc.BlockId.Bytes())
+	assert.False(t, d.recvConfirmation(sender, hc))
+	d.bcastConfirmation(hc)
+
+	// peers of an older protocol do not know the message and are skipped
+	assert.Equal(t, 0, len(sender.writeQueue))
+	assert.Equal(t, 1, len(current.writeQueue))
+	assert.Equal(t, byte(HeaderConfirmationMessageType), current.writeQueue[0].buff[messageHeaderSize])
+	assert.Equal(t, 0, len(old.writeQueue))
+	assert.Equal(t, 0, len(base.writeQueue))
+}
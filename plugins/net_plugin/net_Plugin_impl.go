@@ -60,10 +60,12 @@ const (
 
 	//If there is a change to network protocol or behavior, increment net version to identify
 	//the need for compatibility hooks
-	protoBase         uint16 = 0
-	protoExplicitSync uint16 = 1
+	protoBase               uint16 = 0
+	protoExplicitSync       uint16 = 1
+	protoAddressExchange    uint16 = 2
+	protoHeaderConfirmation uint16 = 3
 
-	netVersion uint16 = protoExplicitSync
+	netVersion uint16 = protoHeaderConfirmation
 
 	nonePossible      possibleConnections = 0
 	producersPossible possibleConnections = 1 << 0
@@ -79,14 +81,19 @@ type netPluginIMpl struct {
 
 	Listener           net.Listener
 	p2PAddress         string
+	p2PServerAddress   string //< address advertised to the peers
 	resolver           *ReactiveSocket
 	maxClientCount     uint32
 	maxNodesPerHost    uint32
 	numClients         uint32
 	suppliedPeers      []string
+	addressBook        *addressBook
+	targetOutbound     uint32                           //< outbound connections opened to the addresses of the address book
 	AllowedPeers       []ecc.PublicKey                  //< peer keys allowed to connect
 	privateKeys        map[ecc.PublicKey]ecc.PrivateKey //< overlapping with producer keys, also authenticating non-producing nodes
 	allowedConnections possibleConnections
+	encryption         encryptionMode
+	plaintextPeers     map[string]bool //< peers which do not support encrypted connections
 
 	connectorCheck   *DeadlineTimer
 	transactionCheck *DeadlineTimer
@@ -105,6 +112,7 @@ type netPluginIMpl struct {
 	userAgentName       string
 
 	connections []*Connection
+	reputation  *peerReputation
 
 	ChainPlugin *chain_plugin.ChainPlugin
 	context     context.Context
@@ -129,6 +137,11 @@ func NewNetPluginIMpl(io *IoContext) *netPluginIMpl {
 		resolver:                   NewReactiveSocket(io),
 		context:                    context.Background(),
 		suppliedPeers:              make([]string, 0),
+		reputation:                 newPeerReputation(),
+		encryption:                 encryptionOptional,
+		plaintextPeers:             make(map[string]bool),
+		addressBook:                newAddressBook(defAddressBookFile),
+		targetOutbound:             defTargetOutbound,
 	}
 
 	impl.syncMaster = NewSyncManager(impl, 100)
@@ -157,6 +170,13 @@ func (impl *netPluginIMpl) startListenLoop() {
 			pAddr := conn.RemoteAddr().String()
 			netLog.Info("accept connection: %s,visitor: %d, fromAddr: %d", pAddr, visitors, fromAddr)
 
+			if impl.reputation.isBannedHost(hostOf(pAddr)) {
+				netLog.Info("refusing connection from banned host %s", pAddr)
+				conn.Close()
+				impl.startListenLoop()
+				return
+			}
+
 			for _, c := range impl.connections {
 				if c.conn != nil {
 					if len(c.peerAddr) == 0 {
@@ -228,13 +248,14 @@ func (impl *netPluginIMpl) startReadMessage(socket *ReactiveSocket, conn *Connec
 							messageLength := int(binary.LittleEndian.Uint32(pendingMessageBuffer[i : i+4]))
 							if messageLength > defSendBufferSize*2 || messageLength == 0 {
 								netLog.Error("incoming message length unexpected %d, from %s", messageLength, conn.conn.RemoteAddr())
+								impl.penalize(conn, oversizedMessage)
 								impl.close(conn)
 								returning = true
 								return
 							}
 							totalMessageBytes := messageLength + messageHeaderSize
 							if bytesInBuf >= totalMessageBytes {
-								if !conn.processNextMessage(pendingMessageBuffer[i+4 : i+4+totalMessageBytes]) {
+								if !conn.processNextMessage(pendingMessageBuffer[i+messageHeaderSize : i+totalMessageBytes]) {
 									returning = true
 									return
 								}
@@ -254,7 +275,9 @@ func (impl *netPluginIMpl) startReadMessage(socket *ReactiveSocket, conn *Connec
 					} else {
 						netLog.Info("Peer %s closed connection", pName)
 					}
-					impl.close(conn)
+					if !impl.fallbackToPlaintext(conn) {
+						impl.close(conn)
+					}
 				}
 
 			}).Catch(func(ex interface{}) {
@@ -324,6 +347,11 @@ func (impl *netPluginIMpl) connect2(c *Connection, endPoint string) {
 		return
 	}
 
+	if impl.reputation.isBannedHost(hostOf(endPoint)) {
+		netLog.Info("skipping connect to %s, %s is banned", c.peerAddr, endPoint)
+		return
+	}
+
 	c.connecting = true
 	c.socket.AsyncConnect("tcp", endPoint, func(conn net.Conn, err error) {
 		if c == nil {
@@ -332,7 +360,11 @@ func (impl *netPluginIMpl) connect2(c *Connection, endPoint string) {
 		if err == nil {
 			c.conn = conn
 			impl.startReadMessage(c.socket, c)
-			c.sendHandshake()
+			if impl.encryption != encryptionOff && !impl.plaintextPeers[c.peerAddr] {
+				c.startEncryption()
+			} else {
+				c.sendHandshake()
+			}
 
 		} else {
 			netLog.Error("connection failed to %s:%s", c.PeerName(), err.Error())
@@ -351,6 +383,9 @@ func (impl *netPluginIMpl) close(c *Connection) {
 			impl.numClients--
 		}
 	}
+	if c.outbound && c.lastHandshakeRecv.Generation == 0 {
+		impl.addressBook.markFailed(c.peerAddr)
+	}
 	impl.eraseConnection(c)
 	c.close()
 }
@@ -378,6 +413,7 @@ func (impl *netPluginIMpl) AcceptedBlock(block *types.BlockState) {
 
 func (impl *netPluginIMpl) IrreversibleBlock(block *types.BlockState) {
 	FcLog.Debug("signaled,id = %s", block.BlockId)
+	impl.dispatcher.expireConfirmations(block.BlockNum)
 }
 
 func (impl *netPluginIMpl) AcceptedTransaction(md *types.TransactionMetadata) {
@@ -392,6 +428,7 @@ func (impl *netPluginIMpl) AppliedTransaction(txn *types.TransactionTrace) {
 
 func (impl *netPluginIMpl) AcceptedConfirmation(head *types.HeaderConfirmation) {
 	FcLog.Debug("signaled,id = %s", head.BlockId)
+	impl.dispatcher.bcastConfirmation(head)
 }
 
 func (impl *netPluginIMpl) TransactionAck(results common.Pair) {
@@ -438,6 +475,7 @@ func (impl *netPluginIMpl) connectionMonitor(fromConnection *Connection) {
 		if len(impl.connections) > 0 {
 			i, it = 0, impl.connections[0]
 		} else {
+			impl.connectOutbound()
 			impl.startConnTimer(impl.connectorPeriod, nil)
 			return
 		}
@@ -457,6 +495,7 @@ func (impl *netPluginIMpl) connectionMonitor(fromConnection *Connection) {
 			}
 		}
 	}
+	impl.connectOutbound()
 	impl.startConnTimer(impl.connectorPeriod, nil)
 }
 
@@ -474,6 +513,7 @@ func (impl *netPluginIMpl) startTxnTimer() {
 
 func (impl *netPluginIMpl) expireTxns() {
 	impl.startTxnTimer()
+	impl.reputation.expire()
 
 	old := impl.localTxns.GetByExpiry()
 	exUp := old.UpperBound(common.NewTimePointSecTp(common.Now()))
@@ -514,9 +554,7 @@ func (impl *netPluginIMpl) ticker() {
 //authenticatePeer determine if a peer is allowed to connect.
 //Checks current connection mode and key authentication.
 //return False if the peer should not connect, True otherwise.
-func (impl *netPluginIMpl) authenticatePeer(msg *HandshakeMessage) bool {
-	var allowedIt, privateIt, foundProducerKey bool
-
+func (impl *netPluginIMpl) authenticatePeer(c *Connection, msg *HandshakeMessage) bool {
 	if impl.allowedConnections == nonePossible {
 		return false
 	}
@@ -524,18 +562,7 @@ func (impl *netPluginIMpl) authenticatePeer(msg *HandshakeMessage) bool {
 		return true
 	}
 	if impl.allowedConnections&(producersPossible|specifiedPossible) != 0 {
-		for _, pubKey := range impl.AllowedPeers {
-			if pubKey == msg.Key {
-				allowedIt = true
-			}
-		}
-		_, privateIt = impl.privateKeys[msg.Key]
-
-		pp := App().FindPlugin(producer_plugin.ProducerPlug).(*producer_plugin.ProducerPlugin)
-		if pp != nil {
-			foundProducerKey = pp.IsProducerKey(msg.Key)
-		}
-		if !allowedIt && !privateIt && !foundProducerKey {
+		if !impl.authorizedKey(msg.Key) {
 			netLog.Error("Peer %s sent a handshake with an unauthorized key: %s", msg.P2PAddress, msg.Key)
 			return false
 		}
@@ -552,16 +579,19 @@ func (impl *netPluginIMpl) authenticatePeer(msg *HandshakeMessage) bool {
 		hash := crypto.Hash256(msg.Time)
 		if !hash.Equals(msg.Token) {
 			netLog.Error("Peer %s sent a handshake with an invalid token.", msg.P2PAddress)
+			impl.penalize(c, invalidSignature)
 			return false
 		}
 
 		peerKey, err := msg.Signature.PublicKey(msg.Token.Bytes())
 		if err != nil {
 			netLog.Error("Peer %s sent a handshake with an unrecoverable key.", msg.P2PAddress)
+			impl.penalize(c, invalidSignature)
 			return false
 		}
 		if (impl.allowedConnections&(producersPossible|specifiedPossible)) != 0 && peerKey.String() != msg.Key.String() {
 			netLog.Error("Peer %s sent a handshake with an unauthenticated key.", msg.P2PAddress)
+			impl.penalize(c, invalidSignature)
 			return false
 		}
 	} else if impl.allowedConnections&(producersPossible|specifiedPossible) != 0 {
@@ -572,6 +602,24 @@ func (impl *netPluginIMpl) authenticatePeer(msg *HandshakeMessage) bool {
 	return true
 }
 
+//authorizedKey determine if key is one of the configured peer keys, private keys or producer keys.
+func (impl *netPluginIMpl) authorizedKey(key ecc.PublicKey) bool {
+	var allowedIt, privateIt, foundProducerKey bool
+
+	for _, pubKey := range impl.AllowedPeers {
+		if pubKey == key {
+			allowedIt = true
+		}
+	}
+	_, privateIt = impl.privateKeys[key]
+
+	pp := App().FindPlugin(producer_plugin.ProducerPlug).(*producer_plugin.ProducerPlugin)
+	if pp != nil {
+		foundProducerKey = pp.IsProducerKey(key)
+	}
+	return allowedIt || privateIt || foundProducerKey
+}
+
 //getAuthenticationKey retrieve public key used to authenticate with peers.
 //Finds a key to use for authentication.  If this node is a producer, use
 //the front of the producer key map.  If the node is not a producer but has
@@ -696,8 +744,19 @@ func (impl *netPluginIMpl) handleHandshake(c *Connection, msg *HandshakeMessage)
 		if !c.nodeID.Equals(msg.NodeID) {
 			c.nodeID = msg.NodeID
 		}
+		impl.reputation.learnNodeID(msg.NodeID, c.remoteHost())
 
-		if !impl.authenticatePeer(msg) {
+		if impl.reputation.isBannedNode(msg.NodeID) {
+			netLog.Info("Peer %s is banned. Closing connection", msg.P2PAddress)
+			goAwayMsg := &GoAwayMessage{
+				Reason: fatalOther,
+				NodeID: crypto.NewSha256Nil(),
+			}
+			c.enqueue(goAwayMsg, true)
+			return
+		}
+
+		if !impl.authenticatePeer(c, msg) {
 			netLog.Error("Peer not authenticated. Closing connection")
 			goAwayMsg := &GoAwayMessage{
 				Reason: authentication,
@@ -707,6 +766,25 @@ func (impl *netPluginIMpl) handleHandshake(c *Connection, msg *HandshakeMessage)
 			return
 		}
 
+		if !c.encrypted() && impl.encryptionRequired() {
+			netLog.Error("Peer %s did not encrypt the connection. Closing connection", msg.P2PAddress)
+			goAwayMsg := &GoAwayMessage{
+				Reason: authentication,
+				NodeID: crypto.NewSha256Nil(),
+			}
+			c.enqueue(goAwayMsg, true)
+			return
+		}
+		if c.encrypted() && !sameKey(&c.session.remoteKey, &msg.Key) {
+			netLog.Error("Peer %s sent a handshake with another key than the key of the encrypted session", msg.P2PAddress)
+			goAwayMsg := &GoAwayMessage{
+				Reason: authentication,
+				NodeID: crypto.NewSha256Nil(),
+			}
+			c.enqueue(goAwayMsg, true)
+			return
+		}
+
 		onFork := false
 		FcLog.Debug("lib_num = %d peer_lib = %d", libNum, peerLib)
 		if peerLib <= libNum && peerLib > 0 {
@@ -735,6 +813,7 @@ func (impl *netPluginIMpl) handleHandshake(c *Connection, msg *HandshakeMessage)
 		if c.sentHandshakeCount == 0 {
 			c.sendHandshake()
 		}
+		impl.learnPeerAddress(c, msg)
 	}
 
 	c.lastHandshakeRecv = msg
@@ -749,7 +828,10 @@ func (impl *netPluginIMpl) handleGoaway(c *Connection, msg *GoAwayMessage) {
 		c.nodeID = msg.NodeID
 	}
 	c.flushQueues()
-	impl.close(c)
+	// a peer with another encryption version may still speak plaintext
+	if msg.Reason != wrongVersion || !impl.fallbackToPlaintext(c) {
+		impl.close(c)
+	}
 }
 
 //handleTime processes time_message
@@ -923,6 +1005,49 @@ func (impl *netPluginIMpl) handlePackTransaction(c *Connection, msg *PackedTrans
 	})
 }
 
+func (impl *netPluginIMpl) handleHeaderConfirmation(c *Connection, msg *HeaderConfirmationMessage) {
+	FcLog.Info("%s : receive header_confirmation of %s by %s", c.peerAddr, msg.BlockId, msg.Producer)
+
+	if impl.syncMaster.isActive(c) {
+		FcLog.Debug("got a header_confirmation during sync - dropping")
+		return
+	}
+	if msg.BlockNum() <= impl.ChainPlugin.Chain().LastIrreversibleBlockNum() {
+		FcLog.Debug("got a header_confirmation of an irreversible block - dropping")
+		return
+	}
+	if impl.dispatcher.recvConfirmation(c, &msg.HeaderConfirmation) {
+		FcLog.Debug("got a duplicate header_confirmation - dropping")
+		return
+	}
+
+	impl.acceptConfirmation(c, &msg.HeaderConfirmation, true)
+}
+
+/**
+ * acceptConfirmation pushes the confirmation to the chain, which relays it once accepted.
+ * A confirmation of a block not received yet is held until the block arrives if canHold.
+ */
+func (impl *netPluginIMpl) acceptConfirmation(c *Connection, hc *types.HeaderConfirmation, canHold bool) {
+	Try(func() {
+		impl.ChainPlugin.AcceptConfirmation(hc)
+	}).Catch(func(e *ForkDbBlockNotFound) {
+		if canHold && impl.dispatcher.holdConfirmation(c, hc) {
+			FcLog.Debug("holding header_confirmation of %s until the block arrives", hc.BlockId)
+			return
+		}
+		FcLog.Error("bad header_confirmation : %s", e.DetailMessage())
+		impl.dispatcher.rejectedConfirmation(hc)
+	}).Catch(func(e *WrongSigningKey) {
+		FcLog.Error("bad header_confirmation : %s", e.DetailMessage())
+		impl.dispatcher.rejectedConfirmation(hc)
+		impl.penalize(c, invalidSignature)
+	}).Catch(func(e Exception) {
+		FcLog.Error("bad header_confirmation : %s", e.DetailMessage())
+		impl.dispatcher.rejectedConfirmation(hc)
+	}).End()
+}
+
 func (impl *netPluginIMpl) handleSignedBlock(c *Connection, msg *SignedBlockMessage) {
 	FcLog.Info("%s : receive signed_block message %d, %v", c.peerAddr, msg.BlockNumber(), msg.String())
 
@@ -952,12 +1077,17 @@ func (impl *netPluginIMpl) handleSignedBlock(c *Connection, msg *SignedBlockMess
 	FcLog.Info("received signed_block : %d block age in secs = %d", blkNum, age.ToSeconds())
 
 	reason := fatalOther
+	penalty := invalidBlock
 	Try(func() {
 		impl.ChainPlugin.AcceptBlock(&msg.SignedBlock)
 		reason = noReason
 	}).Catch(func(ex UnlinkableBlockException) {
 		FcLog.Error("bad signed_block : %s", ex.DetailMessage())
 		reason = unlinkable
+	}).Catch(func(ex *WrongSigningKey) {
+		FcLog.Error("bad signed_block : %s", ex.DetailMessage())
+		reason = validation
+		penalty = invalidSignature
 	}).Catch(func(ex BlockValidateException) {
 		FcLog.Error("bad signed_block : %s", ex.DetailMessage())
 		reason = validation
@@ -1001,7 +1131,15 @@ func (impl *netPluginIMpl) handleSignedBlock(c *Connection, msg *SignedBlockMess
 		}
 
 		impl.syncMaster.recvBlock(c, blkID, blkNum)
+
+		for _, held := range impl.dispatcher.takeConfirmations(blkID) {
+			impl.acceptConfirmation(held.conn, &held.hc, false)
+		}
 	} else {
+		if reason == validation {
+			impl.penalize(c, penalty)
+			impl.dispatcher.rejectedBlock(&blkID)
+		}
 		impl.syncMaster.rejectedBlock(c, blkNum)
 	}
 
@@ -1033,3 +1171,27 @@ func (impl *netPluginIMpl) eraseConnection(it *Connection) {
 		}
 	}
 }
+
+//penalize lowers the reputation of the peer of c for m. When the peer gets banned,
+//every connection to its node id or host is told to go away.
+func (impl *netPluginIMpl) penalize(c *Connection, m misbehavior) {
+	host := c.remoteHost()
+	if !impl.reputation.penalize(c.nodeID, host, m) {
+		netLog.Warn("%s penalized for %s, score is %d", c.PeerName(), misbehaviorStr[m], impl.reputation.score(c.nodeID, host))
+		return
+	}
+
+	netLog.Error("%s banned for %s during %s", c.PeerName(), misbehaviorStr[m], impl.reputation.banDuration)
+	for _, check := range impl.connections {
+		if check.socket == nil || check.conn == nil {
+			continue
+		}
+		if check == c || check.remoteHost() == host || (knownNodeID(c.nodeID) && check.nodeID.Equals(c.nodeID)) {
+			goAwayMsg := &GoAwayMessage{
+				Reason: fatalOther,
+				NodeID: crypto.NewSha256Nil(),
+			}
+			check.enqueue(goAwayMsg, true)
+		}
+	}
+}
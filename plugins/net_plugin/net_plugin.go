@@ -58,6 +58,20 @@ func (n *NetPlugin) SetProgramOptions(options *[]cli.Flag) {
 			Usage: "Maximum number of client nodes from any single IP address",
 			Value: defMaxNodesPerHost,
 		},
+		cli.StringFlag{
+			Name:  "p2p-address-book",
+			Usage: "The file keeping the peer addresses learned from the peers. If relative, it is relative to the data dir.",
+			Value: defAddressBookFile,
+		},
+		cli.IntFlag{
+			Name:  "p2p-target-outbound-connections",
+			Usage: "Number of outbound connections opened to the peers of the address book, use 0 to only connect to p2p-peer-address",
+			Value: defTargetOutbound,
+		},
+		cli.BoolFlag{
+			Name:  "p2p-address-book-allow-private",
+			Usage: "True to keep loopback, link-local and private addresses and host names in the address book, for networks within a private range.",
+		},
 		cli.StringFlag{
 			Name:  "agent-name",
 			Usage: "The name supplied to identify this node amongst the peers.",
@@ -69,6 +83,13 @@ func (n *NetPlugin) SetProgramOptions(options *[]cli.Flag) {
 				"If only 'producers', peer-key is not required. 'producers' and 'specified' may be combined.",
 			Value: &cli.StringSlice{"any"},
 		},
+		cli.StringFlag{
+			Name: "p2p-encryption",
+			Usage: "Can be 'off', 'optional' or 'required'. If 'optional', connections are encrypted when the peer supports it, " +
+				"and encryption is required if allowed-connection is 'producers' or 'specified'. If 'required', plaintext peers are refused. " +
+				"The encryption handshake is authenticated by peer-private-key or the producer keys.",
+			Value: "optional",
+		},
 		cli.StringSliceFlag{
 			Name:  "peer_key",
 			Usage: "Optional public key of peer allowed to connect.  May be used multiple times.",
@@ -82,6 +103,16 @@ func (n *NetPlugin) SetProgramOptions(options *[]cli.Flag) {
 			Usage: "Maximum number of clients from which connections are accepted, use 0 for no limit",
 			Value: defMaxClients,
 		},
+		cli.IntFlag{
+			Name:  "peer-ban-threshold",
+			Usage: "Penalty points after which a misbehaving peer is banned",
+			Value: defPeerBanThreshold,
+		},
+		cli.IntFlag{
+			Name:  "peer-ban-duration-sec",
+			Usage: "Number of seconds a misbehaving peer stays banned",
+			Value: defPeerBanDuration,
+		},
 		cli.IntFlag{
 			Name:  "connection-cleanup-period",
 			Usage: "number of seconds to wait before cleaning up dead connections",
@@ -138,9 +169,24 @@ func (n *NetPlugin) PluginInitialize(c *cli.Context) {
 		n.my.maxClientCount = uint32(c.Int("max-clients"))
 		n.my.maxNodesPerHost = uint32(c.Int("p2p-max-nodes-per-host"))
 		n.my.numClients = 0
+		n.my.reputation.banThreshold = int32(c.Int("peer-ban-threshold"))
+		n.my.reputation.banDuration = time.Duration(c.Int("peer-ban-duration-sec")) * time.Second
+		EosAssert(n.my.reputation.banThreshold > 0, &exception.PluginConfigException{}, "peer-ban-threshold must be positive")
 
 		n.my.p2PAddress = c.String("p2p-listen-endpoint")
+		n.my.p2PServerAddress = c.String("p2p-server-address")
+		if len(n.my.p2PServerAddress) == 0 {
+			n.my.p2PServerAddress = n.my.p2PAddress
+		}
 		n.my.suppliedPeers = c.StringSlice("p2p-peer-address")
+		n.my.targetOutbound = uint32(c.Int("p2p-target-outbound-connections"))
+
+		n.my.addressBook = newAddressBook(addressBookPath(c.String("p2p-address-book"), App().DataDir()))
+		n.my.addressBook.allowPrivate = c.Bool("p2p-address-book-allow-private")
+		n.my.addressBook.load()
+		for _, peer := range n.my.suppliedPeers {
+			n.my.addressBook.add(peer)
+		}
 		n.my.userAgentName = c.String("agent-name")
 
 		allowedRemotes := c.StringSlice("allowed-connection")
@@ -157,6 +203,10 @@ func (n *NetPlugin) PluginInitialize(c *cli.Context) {
 			}
 		}
 
+		encryption, ok := encryptionModes[c.String("p2p-encryption")]
+		EosAssert(ok, &exception.PluginConfigException{}, "p2p-encryption must be 'off', 'optional' or 'required', not '%s'", c.String("p2p-encryption"))
+		n.my.encryption = encryption
+
 		if n.my.allowedConnections&specifiedPossible != 0 {
 			EosAssert(c.IsSet("peer-key"), &exception.PluginConfigException{}, "At least one peer-key must accompany 'allowed-connection=specified'")
 		}
@@ -257,6 +307,7 @@ func (n *NetPlugin) PluginShutdown() {
 		for _, p := range peers {
 			n.my.close(p)
 		}
+		n.my.addressBook.save()
 		netLog.Info("exit shutdown")
 	}).FcCaptureAndRethrow().End()
 }
@@ -303,3 +354,25 @@ func (n *NetPlugin) Connections() []*PeerStatus {
 	}
 	return result
 }
+
+//Bans lists the peers which are currently banned
+func (n *NetPlugin) Bans() []PeerBan {
+	return n.my.reputation.activeBans()
+}
+
+//Unban lifts the ban of a peer, given by its node id or its host
+func (n *NetPlugin) Unban(target string) string {
+	if n.my.reputation.unban(target) {
+		return "ban lifted"
+	}
+	return "no ban for " + target
+}
+
+//KnownAddresses lists the peer addresses of the address book
+func (n *NetPlugin) KnownAddresses() []KnownAddress {
+	result := make([]KnownAddress, 0, len(n.my.addressBook.addresses))
+	for _, a := range n.my.addressBook.candidates() {
+		result = append(result, *a)
+	}
+	return result
+}
@@ -0,0 +1,265 @@
+package net_plugin
+
+import (
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto"
+	"net"
+	"time"
+)
+
+type misbehavior byte
+
+const (
+	invalidBlock = misbehavior(iota)
+	invalidSignature
+	unsolicitedMessage
+	oversizedMessage
+	syncTimeout
+)
+
+var misbehaviorStr = map[misbehavior]string{
+	invalidBlock:       "invalid block",
+	invalidSignature:   "invalid signature",
+	unsolicitedMessage: "unsolicited message",
+	oversizedMessage:   "oversized message",
+	syncTimeout:        "sync timeout",
+}
+
+// points taken from the score of a peer for each misbehavior
+var misbehaviorPenalty = map[misbehavior]int32{
+	invalidBlock:       50,
+	invalidSignature:   50,
+	unsolicitedMessage: 10,
+	oversizedMessage:   100,
+	syncTimeout:        20,
+}
+
+const (
+	defPeerBanThreshold = 100
+	defPeerBanDuration  = 10 * 60 // seconds
+
+	scoreRecoveryPeriod = time.Minute // one penalty point is forgiven per period of good behavior
+)
+
+//peerScore is the reputation of a peer, the score never goes above 0 and the peer is banned at -ban_threshold
+type peerScore struct {
+	NodeID      common.NodeIdType `json:"node_id"`
+	Host        string            `json:"host"`
+	Score       int32             `json:"score"`
+	LastPenalty string            `json:"last_penalty"`
+	updated     common.TimePoint
+}
+
+type PeerBan struct {
+	NodeID      common.NodeIdType `json:"node_id"`
+	Host        string            `json:"host"`
+	Reason      string            `json:"reason"`
+	BannedUntil common.TimePoint  `json:"banned_until"`
+}
+
+/**
+ * peerReputation scores the peers by node id, or by host until the node id is learned from a handshake.
+ * A peer whose score reaches the threshold is banned for the ban duration: both its node id and its host
+ * are banned, so that it can neither reconnect to us nor be connected to.
+ */
+type peerReputation struct {
+	scores       map[string]*peerScore
+	bans         map[string]*PeerBan // keyed by node id and by host
+	banThreshold int32
+	banDuration  time.Duration
+}
+
+func newPeerReputation() *peerReputation {
+	return &peerReputation{
+		scores:       make(map[string]*peerScore),
+		bans:         make(map[string]*PeerBan),
+		banThreshold: defPeerBanThreshold,
+		banDuration:  defPeerBanDuration * time.Second,
+	}
+}
+
+func hostOf(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
+
+func knownNodeID(id common.NodeIdType) bool {
+	return !id.Equals(crypto.NewSha256Nil())
+}
+
+func (r *peerReputation) scoreKey(nodeID common.NodeIdType, host string) string {
+	if knownNodeID(nodeID) {
+		return nodeID.String()
+	}
+	return host
+}
+
+// forgive forgives the penalties older than the recovery period
+func (s *peerScore) forgive(now common.TimePoint) {
+	if s.Score >= 0 {
+		s.updated = now
+		return
+	}
+	periods := int64(now.Sub(s.updated)) / int64(common.Microseconds(scoreRecoveryPeriod/time.Microsecond))
+	if periods <= 0 {
+		return
+	}
+	if periods >= int64(-s.Score) {
+		s.Score = 0
+	} else {
+		s.Score += int32(periods)
+	}
+	s.updated = now
+}
+
+func (r *peerReputation) score(nodeID common.NodeIdType, host string) int32 {
+	key := r.scoreKey(nodeID, host)
+	if s, ok := r.scores[key]; ok {
+		s.forgive(common.Now())
+		if s.Score == 0 {
+			delete(r.scores, key)
+		}
+		return s.Score
+	}
+	return 0
+}
+
+//expire drops the scores which are fully forgiven
+func (r *peerReputation) expire() {
+	now := common.Now()
+	for key, s := range r.scores {
+		s.forgive(now)
+		if s.Score == 0 {
+			delete(r.scores, key)
+		}
+	}
+}
+
+/**
+ * penalize lowers the score of the peer and bans it when the threshold is crossed
+ * @return true if the peer is banned
+ */
+func (r *peerReputation) penalize(nodeID common.NodeIdType, host string, m misbehavior) bool {
+	now := common.Now()
+	key := r.scoreKey(nodeID, host)
+	s, ok := r.scores[key]
+	if !ok {
+		s = &peerScore{NodeID: nodeID, Host: host, updated: now}
+		r.scores[key] = s
+	}
+	s.forgive(now)
+	s.Score -= misbehaviorPenalty[m]
+	s.LastPenalty = misbehaviorStr[m]
+	s.Host = host
+
+	if s.Score > -r.banThreshold {
+		return false
+	}
+	r.ban(key, nodeID, host, misbehaviorStr[m], now)
+	return true
+}
+
+/**
+ * learnNodeID moves the score collected for the host of a peer, before its handshake, to its node id
+ * @return true if the peer is banned
+ */
+func (r *peerReputation) learnNodeID(nodeID common.NodeIdType, host string) bool {
+	if !knownNodeID(nodeID) || len(host) == 0 {
+		return false
+	}
+	hs, ok := r.scores[host]
+	if !ok {
+		return false
+	}
+	delete(r.scores, host)
+
+	now := common.Now()
+	hs.forgive(now)
+	if hs.Score == 0 {
+		return false
+	}
+	key := nodeID.String()
+	s, ok := r.scores[key]
+	if !ok {
+		s = &peerScore{NodeID: nodeID, updated: now}
+		r.scores[key] = s
+	}
+	s.forgive(now)
+	s.Score += hs.Score
+	s.LastPenalty = hs.LastPenalty
+	s.Host = host
+
+	if s.Score > -r.banThreshold {
+		return false
+	}
+	r.ban(key, nodeID, host, s.LastPenalty, now)
+	return true
+}
+
+//ban bans both the node id and the host of the peer scored under key
+func (r *peerReputation) ban(key string, nodeID common.NodeIdType, host string, reason string, now common.TimePoint) {
+	ban := &PeerBan{
+		NodeID:      nodeID,
+		Host:        host,
+		Reason:      reason,
+		BannedUntil: now.AddUs(common.Microseconds(r.banDuration / time.Microsecond)),
+	}
+	if knownNodeID(nodeID) {
+		r.bans[nodeID.String()] = ban
+	}
+	if len(host) > 0 {
+		r.bans[host] = ban
+	}
+	// the peer starts over when the ban is lifted
+	delete(r.scores, key)
+}
+
+func (r *peerReputation) bannedKey(key string) bool {
+	ban, ok := r.bans[key]
+	if !ok {
+		return false
+	}
+	if common.Now() >= ban.BannedUntil {
+		delete(r.bans, key)
+		return false
+	}
+	return true
+}
+
+func (r *peerReputation) isBannedHost(host string) bool {
+	return len(host) > 0 && r.bannedKey(host)
+}
+
+func (r *peerReputation) isBannedNode(nodeID common.NodeIdType) bool {
+	return knownNodeID(nodeID) && r.bannedKey(nodeID.String())
+}
+
+//unban lifts the ban of a node id or a host, as well as the ban of the peer behind it
+func (r *peerReputation) unban(key string) bool {
+	ban, ok := r.bans[key]
+	if !ok {
+		return false
+	}
+	delete(r.bans, key)
+	delete(r.bans, ban.Host)
+	if knownNodeID(ban.NodeID) {
+		delete(r.bans, ban.NodeID.String())
+	}
+	return true
+}
+
+func (r *peerReputation) activeBans() []PeerBan {
+	result := make([]PeerBan, 0)
+	seen := make(map[*PeerBan]bool)
+	for key, ban := range r.bans {
+		if !r.bannedKey(key) || seen[ban] {
+			continue
+		}
+		seen[ban] = true
+		result = append(result, *ban)
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+package net_plugin
+
+import (
+	"testing"
+
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func nodeIDOf(b byte) common.NodeIdType {
+	return common.NodeIdType(*crypto.Hash256([]byte{b}))
+}
+
+func TestPeerReputationForgive(t *testing.T) {
+	r := newPeerReputation()
+	alice := nodeIDOf(1)
+
+	assert.False(t, r.penalize(alice, "10.0.0.1", syncTimeout))
+	assert.Equal(t, int32(-20), r.score(alice, "10.0.0.1"))
+
+	// one point is forgiven per recovery period
+	s := r.scores[alice.String()]
+	s.updated = s.updated.SubUs(common.Seconds(5 * 60))
+	assert.Equal(t, int32(-15), r.score(alice, "10.0.0.1"))
+
+	// a fully forgiven peer is forgotten
+	s.updated = s.updated.SubUs(common.Seconds(60 * 60))
+	assert.Equal(t, int32(0), r.score(alice, "10.0.0.1"))
+	assert.Equal(t, 0, len(r.scores))
+
+	assert.False(t, r.penalize(alice, "10.0.0.1", syncTimeout))
+	assert.False(t, r.penalize(common.NodeIdType(crypto.NewSha256Nil()), "10.0.0.2", unsolicitedMessage))
+	for _, s := range r.scores {
+		s.updated = s.updated.SubUs(common.Seconds(60 * 60))
+	}
+	r.expire()
+	assert.Equal(t, 0, len(r.scores))
+}
+
+func TestPeerReputationBan(t *testing.T) {
+	r := newPeerReputation()
+	alice, bob := nodeIDOf(1), nodeIDOf(2)
+
+	assert.False(t, r.penalize(alice, "10.0.0.1", invalidBlock))
+	assert.False(t, r.isBannedNode(alice))
+	assert.True(t, r.penalize(alice, "10.0.0.1", invalidSignature))
+	assert.True(t, r.isBannedNode(alice))
+	assert.True(t, r.isBannedHost("10.0.0.1"))
+	assert.False(t, r.isBannedNode(bob))
+	assert.False(t, r.isBannedHost("10.0.0.2"))
+	assert.Equal(t, int32(0), r.score(alice, "10.0.0.1"))
+
+	assert.True(t, r.penalize(bob, "10.0.0.2", oversizedMessage))
+	bans := r.activeBans()
+	assert.Equal(t, 2, len(bans))
+
+	// lifting the ban of the host lifts the ban of the node id as well
+	assert.True(t, r.unban("10.0.0.1"))
+	assert.False(t, r.isBannedNode(alice))
+	assert.False(t, r.isBannedHost("10.0.0.1"))
+	assert.False(t, r.unban(alice.String()))
+	assert.Equal(t, 1, len(r.activeBans()))
+
+	// expired bans are not active
+	r.bans[bob.String()].BannedUntil = common.Now().SubUs(common.Seconds(1))
+	assert.Equal(t, 0, len(r.activeBans()))
+	assert.False(t, r.isBannedNode(bob))
+	assert.False(t, r.isBannedHost("10.0.0.2"))
+	assert.Equal(t, 0, len(r.bans))
+}
+
+func TestPeerReputationLearnNodeID(t *testing.T) {
+	r := newPeerReputation()
+	alice := nodeIDOf(1)
+	unknown := common.NodeIdType(crypto.NewSha256Nil())
+
+	// penalties before the handshake are scored by host and follow the node id once it is learned
+	assert.False(t, r.penalize(unknown, "10.0.0.1", invalidBlock))
+	assert.False(t, r.penalize(alice, "10.0.0.1", syncTimeout))
+	assert.False(t, r.learnNodeID(alice, "10.0.0.1"))
+	assert.Equal(t, int32(-70), r.score(alice, "10.0.0.1"))
+	assert.Equal(t, int32(0), r.score(unknown, "10.0.0.1"))
+	assert.Equal(t, 1, len(r.scores))
+
+	// and may get the node banned
+	assert.False(t, r.penalize(unknown, "10.0.0.1", invalidBlock))
+	assert.True(t, r.learnNodeID(alice, "10.0.0.1"))
+	assert.True(t, r.isBannedNode(alice))
+	assert.True(t, r.isBannedHost("10.0.0.1"))
+	assert.Equal(t, 0, len(r.scores))
+
+	assert.False(t, r.learnNodeID(alice, "10.0.0.3"))
+	assert.False(t, r.learnNodeID(unknown, "10.0.0.1"))
+}
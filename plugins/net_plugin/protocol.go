@@ -22,6 +22,11 @@ const (
 	SyncRequestMessageType
 	SignedBlockType
 	PackedTransactionMessageType //8
+	HeaderConfirmationMessageType
+	EncryptionHelloMessageType // 10
+	EncryptionAcceptMessageType
+	EncryptionAuthMessageType
+	AddressMessageType
 )
 
 type MessageReflectTypes struct {
@@ -39,6 +44,11 @@ var messageAttributes = []MessageReflectTypes{
 	{Name: "SyncRequest", ReflectType: reflect.TypeOf(SyncRequestMessage{})},
 	{Name: "SignedBlock", ReflectType: reflect.TypeOf(SignedBlockMessage{})},
 	{Name: "PackedTransaction", ReflectType: reflect.TypeOf(PackedTransactionMessage{})},
+	{Name: "HeaderConfirmation", ReflectType: reflect.TypeOf(HeaderConfirmationMessage{})},
+	{Name: "EncryptionHello", ReflectType: reflect.TypeOf(EncryptionHelloMessage{})},
+	{Name: "EncryptionAccept", ReflectType: reflect.TypeOf(EncryptionAcceptMessage{})},
+	{Name: "EncryptionAuth", ReflectType: reflect.TypeOf(EncryptionAuthMessage{})},
+	{Name: "Address", ReflectType: reflect.TypeOf(AddressMessage{})},
 }
 
 func (t NetMessageType) isValid() bool {
@@ -274,6 +284,80 @@ func (p *PackedTransactionMessage) String() string {
 	return string(bytes)
 }
 
+// HeaderConfirmationMessage relays the confirmation of a block by a producer, it is sent to peers of protoHeaderConfirmation
+type HeaderConfirmationMessage struct {
+	types.HeaderConfirmation
+}
+
+func (h *HeaderConfirmationMessage) GetType() NetMessageType {
+	return HeaderConfirmationMessageType
+}
+func (h *HeaderConfirmationMessage) String() string {
+	bytes, _ := json.Marshal(h)
+	return string(bytes)
+}
+
+// EncryptionHelloMessage is sent in plaintext by the initiator of a connection to open an encrypted session
+type EncryptionHelloMessage struct {
+	Version   uint16          `json:"version"`
+	Ephemeral common.HexBytes `json:"ephemeral"` // x25519 ephemeral public key of the initiator
+}
+
+func (e *EncryptionHelloMessage) GetType() NetMessageType {
+	return EncryptionHelloMessageType
+}
+func (e *EncryptionHelloMessage) String() string {
+	bytes, _ := json.Marshal(e)
+	return string(bytes)
+}
+
+// EncryptionAcceptMessage answers an EncryptionHelloMessage, the responder signs the transcript with its static key
+type EncryptionAcceptMessage struct {
+	Ephemeral common.HexBytes `json:"ephemeral"` // x25519 ephemeral public key of the responder
+	Key       ecc.PublicKey   `json:"key"`       // static key of the responder, or empty
+	Signature ecc.Signature   `json:"sig"`       // signature of the transcript by the static key
+}
+
+func (e *EncryptionAcceptMessage) GetType() NetMessageType {
+	return EncryptionAcceptMessageType
+}
+func (e *EncryptionAcceptMessage) String() string {
+	bytes, _ := json.Marshal(e)
+	return string(bytes)
+}
+
+// EncryptionAuthMessage completes the encryption handshake, every following message is encrypted
+type EncryptionAuthMessage struct {
+	Key       ecc.PublicKey `json:"key"` // static key of the initiator, or empty
+	Signature ecc.Signature `json:"sig"` // signature of the transcript by the static key
+}
+
+func (e *EncryptionAuthMessage) GetType() NetMessageType {
+	return EncryptionAuthMessageType
+}
+func (e *EncryptionAuthMessage) String() string {
+	bytes, _ := json.Marshal(e)
+	return string(bytes)
+}
+
+type PeerAddress struct {
+	Address  string           `json:"address"`   // host:port the peer listens on
+	LastSeen common.TimePoint `json:"last_seen"` // last time the sender completed a handshake with it
+}
+
+// AddressMessage shares the known peer addresses, it is sent once after the handshake to peers of protoAddressExchange
+type AddressMessage struct {
+	Addresses []PeerAddress `json:"addresses"`
+}
+
+func (a *AddressMessage) GetType() NetMessageType {
+	return AddressMessageType
+}
+func (a *AddressMessage) String() string {
+	bytes, _ := json.Marshal(a)
+	return string(bytes)
+}
+
 /**
 Goals of Network Code
 1. low latency to minimize missed blocks and potentially reduce block interval
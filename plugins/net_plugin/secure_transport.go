@@ -0,0 +1,361 @@
+package net_plugin
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto"
+	"github.com/eosspark/eos-go/crypto/ecc"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"io"
+)
+
+type encryptionMode byte
+
+const (
+	encryptionOff      = encryptionMode(iota) // plaintext only, encryption handshakes are refused
+	encryptionOptional                        // encrypt when the peer supports it
+	encryptionRequired                        // plaintext peers are refused
+)
+
+var encryptionModes = map[string]encryptionMode{
+	"off":      encryptionOff,
+	"optional": encryptionOptional,
+	"required": encryptionRequired,
+}
+
+const (
+	secureSessionVersion uint16 = 1
+	noiseProtocolName           = "eosgo_p2p_XX_25519_AESGCM_SHA256"
+	ephemeralKeySize            = 32
+)
+
+/**
+ *  secureSession is the state of the encrypted transport of a connection. The handshake follows the Noise XX
+ *  pattern with signatures in place of the static Diffie-Hellman, so that the ecc keys of peer-private-key and
+ *  of the producers can be used as static keys:
+ *
+ *    -> e                 EncryptionHelloMessage
+ *    <- e, s, sig(h)      EncryptionAcceptMessage
+ *    -> s, sig(h)         EncryptionAuthMessage
+ *
+ *  h is the hash of the transcript, mixed with the chain id, both ephemeral keys and both static keys. The keys of
+ *  both directions are derived from the x25519 shared secret of the ephemeral keys, salted with h. Once established,
+ *  every NetMessage is sealed by AES-256-GCM with a counter nonce, a frame which fails to open ends the connection.
+ */
+type secureSession struct {
+	initiator       bool
+	established     bool
+	ephemeral       [ephemeralKeySize]byte // private, erased once the keys are derived
+	remoteEphemeral [ephemeralKeySize]byte
+	transcript      crypto.Sha256
+	remoteKey       ecc.PublicKey
+
+	sendCipher cipher.AEAD
+	recvCipher cipher.AEAD
+	sendNonce  uint64
+	recvNonce  uint64
+}
+
+func newSecureSession(initiator bool, chainID common.ChainIdType) *secureSession {
+	s := &secureSession{initiator: initiator}
+	if _, err := io.ReadFull(rand.Reader, s.ephemeral[:]); err != nil {
+		panic(fmt.Errorf("unable to generate an ephemeral key: %s", err))
+	}
+	s.transcript = *crypto.NewSha256Byte(sha256Sum([]byte(noiseProtocolName)))
+	s.mix(chainID.Bytes())
+	return s
+}
+
+func sha256Sum(data ...[]byte) []byte {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+func (s *secureSession) mix(data []byte) {
+	s.transcript = *crypto.NewSha256Byte(sha256Sum(s.transcript.Bytes(), data))
+}
+
+func (s *secureSession) mixKey(key *ecc.PublicKey) {
+	packed, _ := key.Pack()
+	s.mix(packed)
+}
+
+func (s *secureSession) ephemeralKey() common.HexBytes {
+	var pub [ephemeralKeySize]byte
+	curve25519.ScalarBaseMult(&pub, &s.ephemeral)
+	return pub[:]
+}
+
+func (s *secureSession) setRemoteEphemeral(key common.HexBytes) bool {
+	if len(key) != ephemeralKeySize {
+		return false
+	}
+	copy(s.remoteEphemeral[:], key)
+	s.mix(key)
+	return true
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+//deriveKeys ends the handshake, the session is established afterwards
+func (s *secureSession) deriveKeys() error {
+	var secret, zero [32]byte
+	curve25519.ScalarMult(&secret, &s.ephemeral, &s.remoteEphemeral)
+	s.ephemeral = zero
+	if secret == zero {
+		return errors.New("remote ephemeral key has a low order")
+	}
+
+	keys := make([]byte, 64)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret[:], s.transcript.Bytes(), []byte(noiseProtocolName)), keys); err != nil {
+		return err
+	}
+	sendKey, recvKey := keys[:32], keys[32:]
+	if !s.initiator {
+		sendKey, recvKey = recvKey, sendKey
+	}
+
+	var err error
+	if s.sendCipher, err = newAEAD(sendKey); err != nil {
+		return err
+	}
+	if s.recvCipher, err = newAEAD(recvKey); err != nil {
+		return err
+	}
+	s.established = true
+	return nil
+}
+
+func (s *secureSession) nonce(counter uint64) []byte {
+	nonce := make([]byte, s.sendCipher.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], counter)
+	return nonce
+}
+
+func (s *secureSession) seal(plaintext []byte) []byte {
+	sealed := s.sendCipher.Seal(nil, s.nonce(s.sendNonce), plaintext, nil)
+	s.sendNonce++
+	return sealed
+}
+
+func (s *secureSession) open(ciphertext []byte) ([]byte, error) {
+	plaintext, err := s.recvCipher.Open(nil, s.nonce(s.recvNonce), ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.recvNonce++
+	return plaintext, nil
+}
+
+func (c *Connection) encrypted() bool {
+	return c.session != nil && c.session.established
+}
+
+//expectsMessage tells whether a message of type t fits the state of the encryption handshake
+func (c *Connection) expectsMessage(t NetMessageType) bool {
+	s := c.session
+	switch {
+	case t == EncryptionAcceptMessageType:
+		return s != nil && s.initiator && !s.established
+	case t == EncryptionAuthMessageType:
+		return s != nil && !s.initiator && !s.established
+	case s == nil || s.established:
+		return true
+	default:
+		return t == GoAwayMessageType
+	}
+}
+
+//sealFrame encrypts the message of a frame when the session is established
+func (c *Connection) sealFrame(frame []byte) []byte {
+	if !c.encrypted() {
+		return frame
+	}
+	sealed := c.session.seal(frame[messageHeaderSize:])
+	out := make([]byte, messageHeaderSize, messageHeaderSize+len(sealed))
+	binary.LittleEndian.PutUint32(out, uint32(len(sealed)))
+	return append(out, sealed...)
+}
+
+//openFrame returns the message carried by the body of a frame, decrypted when the session is established
+func (c *Connection) openFrame(body []byte) ([]byte, error) {
+	if !c.encrypted() {
+		return body, nil
+	}
+	return c.session.open(body)
+}
+
+//startEncryption opens the encrypted session of an outgoing connection, the handshake_message is sent once it is established
+func (c *Connection) startEncryption() {
+	s := newSecureSession(true, c.impl.chainID)
+	ephemeral := s.ephemeralKey()
+	s.mix(ephemeral)
+	c.session = s
+	c.enqueue(&EncryptionHelloMessage{Version: secureSessionVersion, Ephemeral: ephemeral}, true)
+}
+
+func (impl *netPluginIMpl) restrictedConnections() bool {
+	return impl.allowedConnections&(producersPossible|specifiedPossible) != 0
+}
+
+//encryptionRequired tells whether plaintext peers are refused. Unless encryption is off, it is required
+//as soon as only authenticated peers may connect.
+func (impl *netPluginIMpl) encryptionRequired() bool {
+	return impl.encryption == encryptionRequired || (impl.encryption == encryptionOptional && impl.restrictedConnections())
+}
+
+//fallbackToPlaintext connects again in plaintext to a peer which closed the connection during the encryption
+//handshake, as peers without encryption support do. Returns false if the connection is not in that case.
+func (impl *netPluginIMpl) fallbackToPlaintext(c *Connection) bool {
+	if c.session == nil || !c.session.initiator || c.session.established || impl.encryptionRequired() || len(c.peerAddr) == 0 {
+		return false
+	}
+	netLog.Info("%s does not support encrypted connections, connecting again in plaintext", c.peerAddr)
+	peer := c.peerAddr
+	impl.plaintextPeers[peer] = true
+	impl.close(c)
+	impl.Self.Connect(peer)
+	return true
+}
+
+//verifySessionKey checks that the static key of the peer signed the transcript of the encryption handshake and
+//that it may connect. Anonymous peers, which send no signature, may connect unless only authenticated peers may.
+func (impl *netPluginIMpl) verifySessionKey(c *Connection, key *ecc.PublicKey, sig *ecc.Signature) bool {
+	if sig.String() == ecc.NewSigNil().String() {
+		if impl.restrictedConnections() {
+			netLog.Error("%s : anonymous encryption handshake, but this node accepts only authenticated connections", c.PeerName())
+			return false
+		}
+		c.session.remoteKey = ecc.PublicKey{}
+		return true
+	}
+
+	peerKey, err := sig.PublicKey(c.session.transcript.Bytes())
+	if err != nil || !sameKey(&peerKey, key) {
+		netLog.Error("%s : encryption handshake is not signed by its key", c.PeerName())
+		impl.penalize(c, invalidSignature)
+		return false
+	}
+	if impl.restrictedConnections() && !impl.authorizedKey(*key) {
+		netLog.Error("%s : encryption handshake with an unauthorized key: %s", c.PeerName(), key)
+		return false
+	}
+	c.session.remoteKey = *key
+	return true
+}
+
+func sameKey(a, b *ecc.PublicKey) bool {
+	return a.Curve == b.Curve && a.Content == b.Content
+}
+
+func (impl *netPluginIMpl) refuseEncryption(c *Connection, reason GoAwayReason) {
+	goAwayMsg := &GoAwayMessage{
+		Reason: reason,
+		NodeID: crypto.NewSha256Nil(),
+	}
+	c.enqueue(goAwayMsg, true)
+}
+
+func (impl *netPluginIMpl) handleEncryptionHello(c *Connection, msg *EncryptionHelloMessage) {
+	FcLog.Info("%s : receives encryption_hello version %d", c.peerAddr, msg.Version)
+
+	if impl.encryption == encryptionOff {
+		netLog.Info("%s : encryption is off, closing connection", c.peerAddr)
+		impl.close(c)
+		return
+	}
+	if c.session != nil || c.lastHandshakeRecv.Generation > 0 {
+		netLog.Error("%s : unexpected encryption_hello", c.peerAddr)
+		impl.penalize(c, unsolicitedMessage)
+		impl.close(c)
+		return
+	}
+	if msg.Version != secureSessionVersion {
+		netLog.Error("%s : encryption version does not match expected %d but got %d", c.peerAddr, secureSessionVersion, msg.Version)
+		impl.refuseEncryption(c, wrongVersion)
+		return
+	}
+
+	s := newSecureSession(false, impl.chainID)
+	c.session = s
+	if !s.setRemoteEphemeral(msg.Ephemeral) {
+		netLog.Error("%s : bad encryption_hello", c.peerAddr)
+		impl.refuseEncryption(c, fatalOther)
+		return
+	}
+	ephemeral := s.ephemeralKey()
+	s.mix(ephemeral)
+	key := *impl.getAuthenticationKey()
+	s.mixKey(&key)
+
+	c.enqueue(&EncryptionAcceptMessage{
+		Ephemeral: ephemeral,
+		Key:       key,
+		Signature: *impl.signCompact(&key, &s.transcript),
+	}, true)
+}
+
+func (impl *netPluginIMpl) handleEncryptionAccept(c *Connection, msg *EncryptionAcceptMessage) {
+	FcLog.Info("%s : receives encryption_accept", c.peerAddr)
+
+	s := c.session
+	if !s.setRemoteEphemeral(msg.Ephemeral) {
+		netLog.Error("%s : bad encryption_accept", c.peerAddr)
+		impl.refuseEncryption(c, fatalOther)
+		return
+	}
+	s.mixKey(&msg.Key)
+	if !impl.verifySessionKey(c, &msg.Key, &msg.Signature) {
+		impl.refuseEncryption(c, authentication)
+		return
+	}
+
+	key := *impl.getAuthenticationKey()
+	s.mixKey(&key)
+	c.enqueue(&EncryptionAuthMessage{
+		Key:       key,
+		Signature: *impl.signCompact(&key, &s.transcript),
+	}, true)
+
+	if err := s.deriveKeys(); err != nil {
+		netLog.Error("%s : unable to establish the encrypted session: %s", c.peerAddr, err)
+		impl.close(c)
+		return
+	}
+	netLog.Info("encrypted connection established with %s", c.peerAddr)
+	c.sendHandshake()
+}
+
+func (impl *netPluginIMpl) handleEncryptionAuth(c *Connection, msg *EncryptionAuthMessage) {
+	FcLog.Info("%s : receives encryption_auth", c.peerAddr)
+
+	s := c.session
+	s.mixKey(&msg.Key)
+	if !impl.verifySessionKey(c, &msg.Key, &msg.Signature) {
+		impl.refuseEncryption(c, authentication)
+		return
+	}
+
+	if err := s.deriveKeys(); err != nil {
+		netLog.Error("%s : unable to establish the encrypted session: %s", c.peerAddr, err)
+		impl.close(c)
+		return
+	}
+	netLog.Info("encrypted connection established with %s", c.peerAddr)
+}
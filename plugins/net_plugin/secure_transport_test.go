@@ -0,0 +1,191 @@
+package net_plugin
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto"
+	"github.com/eosspark/eos-go/crypto/ecc"
+	"github.com/stretchr/testify/assert"
+)
+
+func frameOf(payload string) []byte {
+	frame := make([]byte, messageHeaderSize, messageHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(frame, uint32(len(payload)))
+	return append(frame, payload...)
+}
+
+func signTranscript(t *testing.T, key *ecc.PrivateKey, s *secureSession) ecc.Signature {
+	sig, err := key.Sign(s.transcript.Bytes())
+	assert.NoError(t, err)
+	return sig
+}
+
+//secureHandshake runs the encryption handshake between an initiator and a responder connection
+func secureHandshake(t *testing.T, initiatorChain, responderChain common.ChainIdType) (*Connection, *Connection) {
+	impl := &netPluginIMpl{reputation: newPeerReputation()}
+	initiatorKey, _ := ecc.NewRandomPrivateKey()
+	responderKey, _ := ecc.NewRandomPrivateKey()
+	initiatorPub, responderPub := initiatorKey.PublicKey(), responderKey.PublicKey()
+
+	i := &Connection{impl: impl, lastHandshakeRecv: &HandshakeMessage{}, session: newSecureSession(true, initiatorChain)}
+	r := &Connection{impl: impl, lastHandshakeRecv: &HandshakeMessage{}, session: newSecureSession(false, responderChain)}
+
+	// -> e
+	hello := i.session.ephemeralKey()
+	i.session.mix(hello)
+
+	// <- e, s, sig(h)
+	assert.True(t, r.session.setRemoteEphemeral(hello))
+	accept := r.session.ephemeralKey()
+	r.session.mix(accept)
+	r.session.mixKey(&responderPub)
+	acceptSig := signTranscript(t, responderKey, r.session)
+
+	assert.True(t, i.session.setRemoteEphemeral(accept))
+	i.session.mixKey(&responderPub)
+	verified := impl.verifySessionKey(i, &responderPub, &acceptSig)
+
+	// -> s, sig(h)
+	i.session.mixKey(&initiatorPub)
+	authSig := signTranscript(t, initiatorKey, i.session)
+	assert.NoError(t, i.session.deriveKeys())
+
+	r.session.mixKey(&initiatorPub)
+	verified = impl.verifySessionKey(r, &initiatorPub, &authSig) && verified
+	assert.NoError(t, r.session.deriveKeys())
+
+	assert.Equal(t, initiatorChain.Equals(responderChain), verified)
+	if verified {
+		assert.True(t, sameKey(&responderPub, &i.session.remoteKey))
+		assert.True(t, sameKey(&initiatorPub, &r.session.remoteKey))
+	}
+	return i, r
+}
+
+func TestSecureHandshake(t *testing.T) {
+	chainID := *crypto.Hash256("chain")
+	i, r := secureHandshake(t, chainID, chainID)
+	assert.True(t, i.encrypted())
+	assert.True(t, r.encrypted())
+	assert.Equal(t, i.session.transcript, r.session.transcript)
+
+	for _, msg := range []string{"first", "second", "third"} {
+		sealed := i.sealFrame(frameOf(msg))
+		assert.NotEqual(t, frameOf(msg), sealed)
+		assert.Equal(t, uint32(len(sealed)-messageHeaderSize), binary.LittleEndian.Uint32(sealed))
+		opened, err := r.openFrame(sealed[messageHeaderSize:])
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(msg), opened)
+
+		back := r.sealFrame(frameOf(msg))
+		opened, err = i.openFrame(back[messageHeaderSize:])
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(msg), opened)
+	}
+
+	// sessions of another chain do not share keys
+	i, r = secureHandshake(t, chainID, *crypto.Hash256("other chain"))
+	sealed := i.sealFrame(frameOf("message"))
+	_, err := r.openFrame(sealed[messageHeaderSize:])
+	assert.Error(t, err)
+}
+
+func TestSecureFrameTampered(t *testing.T) {
+	chainID := *crypto.Hash256("chain")
+	i, r := secureHandshake(t, chainID, chainID)
+
+	sealed := i.sealFrame(frameOf("message"))
+	tampered := append([]byte{}, sealed[messageHeaderSize:]...)
+	tampered[0] ^= 1
+	_, err := r.openFrame(tampered)
+	assert.Error(t, err)
+	assert.Equal(t, uint64(0), r.session.recvNonce)
+
+	// the genuine frame still opens
+	opened, err := r.openFrame(sealed[messageHeaderSize:])
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("message"), opened)
+}
+
+func TestSecureFrameReplayed(t *testing.T) {
+	chainID := *crypto.Hash256("chain")
+	i, r := secureHandshake(t, chainID, chainID)
+
+	first := i.sealFrame(frameOf("first"))
+	second := i.sealFrame(frameOf("second"))
+
+	// frames open in order only
+	_, err := r.openFrame(second[messageHeaderSize:])
+	assert.Error(t, err)
+	_, err = r.openFrame(first[messageHeaderSize:])
+	assert.NoError(t, err)
+	_, err = r.openFrame(first[messageHeaderSize:])
+	assert.Error(t, err)
+	_, err = r.openFrame(second[messageHeaderSize:])
+	assert.NoError(t, err)
+
+	// a frame sent back to its sender does not open either
+	_, err = i.openFrame(first[messageHeaderSize:])
+	assert.Error(t, err)
+}
+
+func TestSecureFrameSealedWhenWritten(t *testing.T) {
+	chainID := *crypto.Hash256("chain")
+	i, r := secureHandshake(t, chainID, chainID)
+	noop := func(err error, n int) {}
+
+	// dropped frames do not consume nonces
+	i.queueWrite(frameOf("dropped"), false, noop)
+	i.flushQueues()
+	assert.Equal(t, uint64(0), i.session.sendNonce)
+
+	i.queueWrite(frameOf("sent"), false, noop)
+	assert.True(t, i.writeQueue[0].seal)
+	sealed := i.sealFrame(i.writeQueue[0].buff)
+	opened, err := r.openFrame(sealed[messageHeaderSize:])
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("sent"), opened)
+
+	// frames queued before the session is established are written in plaintext
+	pending := &Connection{session: newSecureSession(true, chainID)}
+	pending.queueWrite(frameOf("hello"), false, noop)
+	assert.False(t, pending.writeQueue[0].seal)
+}
+
+func TestPlaintextFallback(t *testing.T) {
+	impl := &netPluginIMpl{encryption: encryptionOptional}
+	plain := &Connection{impl: impl, peerAddr: "127.0.0.1:9876"}
+
+	// plaintext connections carry the frames as they are
+	assert.False(t, plain.encrypted())
+	assert.Equal(t, frameOf("message"), plain.sealFrame(frameOf("message")))
+	opened, err := plain.openFrame([]byte("message"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("message"), opened)
+	assert.False(t, impl.fallbackToPlaintext(plain))
+
+	// during the handshake, the initiator accepts nothing but the answer to its hello or a go away
+	c := &Connection{impl: impl, peerAddr: "127.0.0.1:9876", session: newSecureSession(true, *crypto.Hash256("chain"))}
+	assert.False(t, c.expectsMessage(HandshakeMessageType))
+	assert.False(t, c.expectsMessage(SignedBlockType))
+	assert.False(t, c.expectsMessage(EncryptionAuthMessageType))
+	assert.True(t, c.expectsMessage(EncryptionAcceptMessageType))
+	assert.True(t, c.expectsMessage(GoAwayMessageType))
+
+	responder := &Connection{impl: impl, session: newSecureSession(false, *crypto.Hash256("chain"))}
+	assert.False(t, responder.expectsMessage(HandshakeMessageType))
+	assert.False(t, responder.expectsMessage(EncryptionAcceptMessageType))
+	assert.True(t, responder.expectsMessage(EncryptionAuthMessageType))
+	assert.False(t, impl.fallbackToPlaintext(responder))
+
+	// falling back to plaintext is refused when encryption is required
+	impl.encryption = encryptionRequired
+	assert.False(t, impl.fallbackToPlaintext(c))
+	impl.encryption = encryptionOptional
+	impl.allowedConnections = specifiedPossible
+	assert.True(t, impl.encryptionRequired())
+	assert.False(t, impl.fallbackToPlaintext(c))
+	assert.Equal(t, 0, len(impl.plaintextPeers))
+}
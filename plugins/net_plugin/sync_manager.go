@@ -363,6 +363,7 @@ func (s *syncManager) recvBlock(c *Connection, blkID common.BlockIdType, blkNum
 	if s.state == libCatchup {
 		if blkNum != s.syncNextExpectedNum {
 			FcLog.Info("expected block %d but got %d", s.syncNextExpectedNum, blkNum)
+			s.myImpl.penalize(c, unsolicitedMessage)
 			s.myImpl.close(c)
 			return
 		}
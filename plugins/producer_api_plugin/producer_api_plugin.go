@@ -1,6 +1,7 @@
 package producer_api_plugin
 
 import (
+	"bytes"
 	"encoding/json"
 	"github.com/eosspark/eos-go/chain"
 	"github.com/eosspark/eos-go/chain/types/generated_containers"
@@ -123,6 +124,51 @@ func (c *ProducerApiPlugin) PluginStartup() {
 			http_plugin.HandleException(e, "producer", "get_whitelist_blacklist", string(body), cb)
 		}).End()
 	})
+
+	httpPlugin.AddHandler(common.ProducerStartContractProfiler, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			proApi.StartContractProfiler()
+			if byte, err := json.Marshal("contract profiler started"); err == nil {
+				cb(200, byte)
+			}
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "producer", "start_contract_profiler", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.ProducerStopContractProfiler, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			proApi.StopContractProfiler()
+			if byte, err := json.Marshal("contract profiler stopped"); err == nil {
+				cb(200, byte)
+			}
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "producer", "stop_contract_profiler", string(body), cb)
+		}).End()
+	})
+
+	httpPlugin.AddHandler(common.ProducerGetContractProfile, func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			result, err := json.Marshal(proApi.GetContractProfile())
+			if err != nil {
+				log.Error("producer_plugin ProducerGetContractProfile is error:", err)
+			}
+			cb(200, result)
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "producer", "get_contract_profile", string(body), cb)
+		}).End()
+	})
+
+	// the body is the gzipped protobuf of pprof, not json: curl it to a file for `go tool pprof`
+	httpPlugin.AddHandlerWithContentType(common.ProducerGetContractPprof, "application/octet-stream", func(source string, body []byte, cb http_plugin.UrlResponseCallback) {
+		Try(func() {
+			buf := new(bytes.Buffer)
+			proApi.WriteContractPprof(buf)
+			cb(200, buf.Bytes())
+		}).Catch(func(e interface{}) {
+			http_plugin.HandleException(e, "producer", "get_contract_pprof", string(body), cb)
+		}).End()
+	})
 }
 
 func (c *ProducerApiPlugin) PluginShutdown() {
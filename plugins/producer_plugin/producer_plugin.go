@@ -18,7 +18,9 @@ import (
 	"github.com/eosspark/eos-go/log"
 	. "github.com/eosspark/eos-go/plugins/appbase/app"
 	"github.com/eosspark/eos-go/libraries/asio"
+	"github.com/eosspark/eos-go/wasmgo"
 	"github.com/urfave/cli"
+	"io"
 	"strings"
 	"time"
 )
@@ -68,6 +70,11 @@ type GreylistParams struct {
 	Accounts []common.AccountName
 }
 
+type ContractProfile struct {
+	Profiling bool                   `json:"profiling"`
+	Actions   []wasmgo.ActionProfile `json:"actions"`
+}
+
 func NewProducerPlugin(io *asio.IoContext) *ProducerPlugin {
 	plugin := &ProducerPlugin{}
 
@@ -229,6 +236,7 @@ func (p *ProducerPlugin) PluginInitialize(c *cli.Context) {
 
 		App().GetMethod(BlockSync).Register(&BlockSyncCaller{Caller: p.my.OnIncomingBlock})
 		App().GetMethod(TransactionAsync).Register(&TransactionAsyncCaller{Caller: p.my.OnIncomingTransactionAsync})
+		App().GetMethod(ConfirmationSync).Register(&ConfirmationSyncCaller{Caller: p.my.OnIncomingConfirmation})
 
 	}).FcLogAndRethrow().End()
 }
@@ -424,6 +432,32 @@ func (p *ProducerPlugin) SetWhitelistBlacklist(params WhitelistAndBlacklist) {
 	}
 }
 
+// StartContractProfiler profiles the contracts executed from now on, dropping the previous profile
+func (p *ProducerPlugin) StartContractProfiler() {
+	p.my.Chain.GetWasmInterface().StartProfiler()
+}
+
+func (p *ProducerPlugin) StopContractProfiler() {
+	p.my.Chain.GetWasmInterface().StopProfiler()
+}
+
+func (p *ProducerPlugin) GetContractProfile() ContractProfile {
+	wasmIf := p.my.Chain.GetWasmInterface()
+	result := ContractProfile{Profiling: wasmIf.Profiling(), Actions: []wasmgo.ActionProfile{}}
+	if profile := wasmIf.Profile(); profile != nil {
+		result.Actions = profile.Actions()
+	}
+	return result
+}
+
+// WriteContractPprof writes the contract profile in the pprof format, to be read with `go tool pprof`
+func (p *ProducerPlugin) WriteContractPprof(w io.Writer) {
+	profile := p.my.Chain.GetWasmInterface().Profile()
+	EosAssert(profile != nil, &ProducerException{}, "contract profiler was never started")
+	err := profile.WritePprof(w)
+	EosAssert(err == nil, &ProducerException{}, "write contract pprof: %s", err)
+}
+
 func failureIsSubjective(e Exception, deadlineIsSubjective bool) bool {
 	code := e.Code()
 	return (code == BlockCpuUsageExceeded{}.Code()) ||
@@ -138,13 +138,22 @@ func (impl *ProducerPluginImpl) OnBlock(bsp *types.BlockState) {
 			if itr != nil {
 				privateKeyItr := impl.SignatureProviders[itr.BlockSigningKey]
 				if privateKeyItr != nil {
-					//TODO signal ConfirmedBlock
-					//d := bsp.SigDigest()
-					//sig := privateKeyItr(d)
+					d := bsp.SigDigest()
+					confirmation := types.HeaderConfirmation{BlockId: bsp.BlockId, Producer: producer, ProducerSignature: *privateKeyItr(d)}
 					impl.LastSignedBlockTime = bsp.Header.Timestamp.ToTimePoint()
 					impl.LastSignedBlockNum = bsp.BlockNum
 
-					//impl.Self.ConfirmedBlock
+					// the block is still being committed, confirm it once the controller is done with it
+					app.App().GetIoService().Post(func(err error) {
+						if confirmation.BlockNum() <= impl.Chain.LastIrreversibleBlockNum() {
+							return
+						}
+						Try(func() {
+							impl.OnIncomingConfirmation(&confirmation)
+						}).Catch(func(e Exception) {
+							log.Warn("unable to confirm block %s as %s: %s", confirmation.BlockId, confirmation.Producer, e.DetailMessage())
+						}).End()
+					})
 				}
 			}
 		}
@@ -181,6 +190,21 @@ func (impl *ProducerPluginImpl) OnIrreversibleBlock(lib *types.SignedBlock) {
 	impl.IrreversibleBlockTime = lib.Timestamp.ToTimePoint()
 }
 
+// OnIncomingConfirmation pushes a header confirmation, which like a block requires the pending block to be aborted
+func (impl *ProducerPluginImpl) OnIncomingConfirmation(hc *types.HeaderConfirmation) {
+	ppLog.Debug("received incoming header_confirmation of %s by %s", hc.BlockId, hc.Producer)
+
+	chain := impl.Chain
+	chain.AbortBlock()
+
+	// exceptions throw out, make sure we restart our loop
+	defer func() {
+		impl.ScheduleProductionLoop()
+	}()
+
+	chain.PushConfirmation(hc)
+}
+
 func (impl *ProducerPluginImpl) OnIncomingBlock(block *types.SignedBlock) {
 
 	ppLog.Debug("received incoming block %s", block.BlockID())
@@ -67,6 +67,9 @@ func main() {
 			} else if strings.Contains(e.DetailMessage(), "database metadata dirty flag set") {
 				log.Error("database metadata dirty flag set (likely due to unclean shutdown): replay required")
 				os.Exit(DATABASE_DIRTY)
+			} else if strings.Contains(e.DetailMessage(), "database revision does not match its undo stack") {
+				log.Error("database revision does not match its undo stack: replay required")
+				os.Exit(DATABASE_DIRTY)
 			}
 		}
 		log.Error(e.DetailMessage())
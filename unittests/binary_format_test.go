@@ -0,0 +1,263 @@
+package unittests
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/eosspark/eos-go/chain/abi_serializer"
+	"github.com/eosspark/eos-go/chain/types"
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/crypto/ecc"
+	"github.com/eosspark/eos-go/crypto/rlp"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+// binaryFormatVectors are golden vectors packed with the reference EOSIO (fc::raw) layout,
+// see test_vectors/binary_format.json
+type binaryFormatVectors struct {
+	Varuint32 []struct {
+		Value uint32 `json:"value"`
+		Hex   string `json:"hex"`
+	} `json:"varuint32"`
+	Varint32 []struct {
+		Value int32  `json:"value"`
+		Hex   string `json:"hex"`
+	} `json:"varint32"`
+	ActionData struct {
+		Account string            `json:"account"`
+		Name    string            `json:"name"`
+		Json    map[string]string `json:"json"`
+		Hex     string            `json:"hex"`
+	} `json:"action_data"`
+	Transaction struct {
+		Hex string `json:"hex"`
+		Id  string `json:"id"`
+	} `json:"transaction"`
+	PackedTransaction []struct {
+		Compression     string   `json:"compression"`
+		Hex             string   `json:"hex"`
+		PackedDigest    string   `json:"packed_digest"`
+		Id              string   `json:"id"`
+		RawTransaction  string   `json:"raw_transaction"`
+		ContextFreeData []string `json:"context_free_data"`
+	} `json:"packed_transaction"`
+	BlockHeader []struct {
+		Hex      string `json:"hex"`
+		Id       string `json:"id"`
+		BlockNum uint32 `json:"block_num"`
+	} `json:"block_header"`
+	SignedBlock struct {
+		Hex            string   `json:"hex"`
+		Id             string   `json:"id"`
+		ReceiptDigests []string `json:"receipt_digests"`
+	} `json:"signed_block"`
+	Abi []struct {
+		Version  string `json:"version"`
+		Hex      string `json:"hex"`
+		Variants int    `json:"variants"`
+	} `json:"abi"`
+}
+
+func loadBinaryFormatVectors(t *testing.T) *binaryFormatVectors {
+	data, err := ioutil.ReadFile("test_vectors/binary_format.json")
+	assert.NoError(t, err)
+
+	vectors := &binaryFormatVectors{}
+	assert.NoError(t, json.Unmarshal(data, vectors))
+	return vectors
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	data, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	return data
+}
+
+func assertRepack(t *testing.T, expected []byte, v interface{}) {
+	packed, err := rlp.EncodeToBytes(v)
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(packed))
+}
+
+func TestBinaryFormatVarint(t *testing.T) {
+	vectors := loadBinaryFormatVectors(t)
+
+	for _, v := range vectors.Varuint32 {
+		data := mustDecodeHex(t, v.Hex)
+		assertRepack(t, data, common.Vuint32(v.Value))
+
+		var out common.Vuint32
+		assert.NoError(t, rlp.DecodeBytes(data, &out))
+		assert.Equal(t, common.Vuint32(v.Value), out)
+	}
+
+	for _, v := range vectors.Varint32 {
+		data := mustDecodeHex(t, v.Hex)
+		assertRepack(t, data, common.Vint32(v.Value))
+
+		var out common.Vint32
+		assert.NoError(t, rlp.DecodeBytes(data, &out))
+		assert.Equal(t, common.Vint32(v.Value), out)
+	}
+
+	// a truncated varint must not decode as zero
+	var out common.Vuint32
+	assert.Error(t, rlp.DecodeBytes([]byte{0x80}, &out))
+	assert.Error(t, rlp.DecodeBytes([]byte{}, &out))
+}
+
+func TestBinaryFormatActionData(t *testing.T) {
+	vectors := loadBinaryFormatVectors(t)
+	data := mustDecodeHex(t, vectors.ActionData.Hex)
+
+	type transfer struct {
+		From     common.AccountName
+		To       common.AccountName
+		Quantity common.Asset
+		Memo     string
+	}
+	quantity, err := common.NewAsset(vectors.ActionData.Json["quantity"])
+	assert.NoError(t, err)
+	assertRepack(t, data, transfer{
+		From:     common.N(vectors.ActionData.Json["from"]),
+		To:       common.N(vectors.ActionData.Json["to"]),
+		Quantity: quantity,
+		Memo:     vectors.ActionData.Json["memo"],
+	})
+
+	// the same bytes through the abi serializer, using the golden abi
+	abi := abi_serializer.AbiDef{}
+	assert.NoError(t, rlp.DecodeBytes(mustDecodeHex(t, vectors.Abi[1].Hex), &abi))
+
+	decoded, err := abi.DecodeAction(vectors.ActionData.Name, data)
+	assert.NoError(t, err)
+	result := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(decoded, &result))
+	assert.Equal(t, vectors.ActionData.Json["from"], result["from"])
+	assert.Equal(t, vectors.ActionData.Json["to"], result["to"])
+	assert.Equal(t, vectors.ActionData.Json["quantity"], result["quantity"])
+	assert.Equal(t, vectors.ActionData.Json["memo"], result["memo"])
+}
+
+func TestBinaryFormatTransaction(t *testing.T) {
+	vectors := loadBinaryFormatVectors(t)
+	data := mustDecodeHex(t, vectors.Transaction.Hex)
+
+	trx := types.Transaction{}
+	assert.NoError(t, rlp.DecodeBytes(data, &trx))
+	assert.Equal(t, uint16(0x1234), trx.RefBlockNum)
+	assert.Equal(t, uint32(0xdeadbeef), trx.RefBlockPrefix)
+	assert.Equal(t, common.Vuint32(300), trx.MaxNetUsageWords)
+	assert.Equal(t, uint8(5), trx.MaxCpuUsageMS)
+	assert.Equal(t, 1, len(trx.ContextFreeActions))
+	assert.Equal(t, 1, len(trx.Actions))
+	assert.Equal(t, common.N("transfer"), trx.Actions[0].Name)
+	assert.Equal(t, 1, len(trx.TransactionExtensions))
+	assert.Equal(t, uint16(1), trx.TransactionExtensions[0].Type)
+
+	assertRepack(t, data, &trx)
+	assert.Equal(t, vectors.Transaction.Id, trx.ID().String())
+
+	// every strict prefix is malformed
+	for i := 0; i < len(data); i++ {
+		truncated := types.Transaction{}
+		assert.Error(t, rlp.DecodeBytes(data[:i], &truncated), "prefix of %d bytes", i)
+	}
+}
+
+func TestBinaryFormatPackedTransaction(t *testing.T) {
+	vectors := loadBinaryFormatVectors(t)
+
+	for _, v := range vectors.PackedTransaction {
+		data := mustDecodeHex(t, v.Hex)
+
+		ptrx := types.PackedTransaction{}
+		assert.NoError(t, rlp.DecodeBytes(data, &ptrx))
+		assert.Equal(t, v.Compression, ptrx.Compression.String())
+		assert.Equal(t, 1, len(ptrx.Signatures))
+
+		assertRepack(t, data, &ptrx)
+		assert.Equal(t, v.PackedDigest, ptrx.PackedDigest().String())
+		assert.Equal(t, v.RawTransaction, hex.EncodeToString(ptrx.GetRawTransaction()))
+		assert.Equal(t, v.Id, ptrx.ID().String())
+
+		cfd := ptrx.GetContextFreeData()
+		assert.Equal(t, len(v.ContextFreeData), len(cfd))
+		for i := range cfd {
+			assert.Equal(t, v.ContextFreeData[i], hex.EncodeToString(cfd[i]))
+		}
+	}
+}
+
+func TestBinaryFormatBlockHeader(t *testing.T) {
+	vectors := loadBinaryFormatVectors(t)
+
+	key, err := ecc.NewPublicKey("EOS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV")
+	assert.NoError(t, err)
+
+	for i, v := range vectors.BlockHeader {
+		data := mustDecodeHex(t, v.Hex)
+
+		header := types.BlockHeader{}
+		assert.NoError(t, rlp.DecodeBytes(data, &header))
+		assert.Equal(t, common.N("eosio"), header.Producer)
+		assert.Equal(t, v.BlockNum, header.BlockNumber())
+		assert.Equal(t, 1, len(header.HeaderExtensions))
+		if i == 0 {
+			assert.Nil(t, header.NewProducers)
+		} else {
+			assert.NotNil(t, header.NewProducers)
+			assert.Equal(t, uint32(2), header.NewProducers.Version)
+			assert.Equal(t, key, header.NewProducers.Producers[0].BlockSigningKey)
+		}
+
+		assertRepack(t, data, &header)
+		assert.Equal(t, v.Id, header.BlockID().String())
+	}
+}
+
+func TestBinaryFormatSignedBlock(t *testing.T) {
+	vectors := loadBinaryFormatVectors(t)
+	data := mustDecodeHex(t, vectors.SignedBlock.Hex)
+
+	block := types.SignedBlock{}
+	assert.NoError(t, rlp.DecodeBytes(data, &block))
+	assert.Equal(t, 2, len(block.Transactions))
+	assert.Equal(t, common.Vuint32(17), block.Transactions[0].NetUsageWords)
+	assert.Nil(t, block.Transactions[0].Trx.PackedTransaction)
+	assert.NotNil(t, block.Transactions[1].Trx.PackedTransaction)
+	assert.Equal(t, block.Transactions[0].Trx.TransactionID, block.Transactions[1].Trx.PackedTransaction.ID())
+
+	assertRepack(t, data, &block)
+	assert.Equal(t, vectors.SignedBlock.Id, block.BlockID().String())
+	for i := range block.Transactions {
+		assert.Equal(t, vectors.SignedBlock.ReceiptDigests[i], block.Transactions[i].Digest().String())
+	}
+}
+
+func TestBinaryFormatAbi(t *testing.T) {
+	vectors := loadBinaryFormatVectors(t)
+
+	for _, v := range vectors.Abi {
+		data := mustDecodeHex(t, v.Hex)
+
+		abi := abi_serializer.AbiDef{}
+		assert.NoError(t, rlp.DecodeBytes(data, &abi))
+		assert.Equal(t, "eosio::abi/"+v.Version, abi.Version)
+		assert.Equal(t, 1, len(abi.Types))
+		assert.Equal(t, 1, len(abi.Structs))
+		assert.Equal(t, 4, len(abi.Structs[0].Fields))
+		assert.Equal(t, common.N("transfer"), abi.Actions[0].Name)
+		assert.Equal(t, common.N("accounts"), abi.Tables[0].Name)
+		assert.Equal(t, "body", abi.RicardianClauses[0].Body)
+		assert.Equal(t, uint64(1), abi.ErrorMessages[0].Code)
+		assert.Equal(t, v.Variants, len(abi.Variants))
+
+		// variants is a binary extension, it is always packed
+		if v.Variants == 0 {
+			data = append(data, 0)
+		}
+		assertRepack(t, data, &abi)
+	}
+}
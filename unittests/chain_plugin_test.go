@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/eosspark/eos-go/chain"
 	"github.com/eosspark/eos-go/chain/abi_serializer"
 	"github.com/eosspark/eos-go/chain/types"
 	. "github.com/eosspark/eos-go/chain/types/generated_containers"
 	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/common/eos_math"
+	"github.com/eosspark/eos-go/crypto"
+	"github.com/eosspark/eos-go/crypto/ecc"
+	"github.com/eosspark/eos-go/crypto/rlp"
 	"github.com/eosspark/eos-go/entity"
 	. "github.com/eosspark/eos-go/exception"
 	. "github.com/eosspark/eos-go/exception/try"
@@ -15,6 +20,7 @@ import (
 	"github.com/eosspark/eos-go/plugins/chain_plugin"
 	"github.com/eosspark/eos-go/unittests/test_contracts"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
@@ -29,7 +35,7 @@ func TestGetBlockWithInvalidAbi(t *testing.T) {
 		tester.ProduceBlock(common.Milliseconds(common.DefaultConfig.BlockIntervalMs), 0)
 
 		// setup contract and abi
-		tester.SetCode(common.N("asserter"), wast2wasm(test_contracts.AsserterWast), nil)
+		tester.SetCode2(common.N("asserter"), []byte(test_contracts.AsserterWast), nil)
 		tester.SetAbi(common.N("asserter"), test_contracts.AsserterAbi, nil)
 
 		tester.ProduceBlocks(1, false)
@@ -114,7 +120,7 @@ func TestGetBlockWithInvalidAbi(t *testing.T) {
 		assert.Equal(t, true, bytes.Contains(blockStr, []byte("011253686f756c64204e6f742041737365727421"))) //action data
 
 		// set an invalid abi (int8->xxxx)
-		abi2 := test_contracts.AsserterAbi
+		abi2 := append([]byte(nil), test_contracts.AsserterAbi...)
 		pos := bytes.Index(abi2, []byte("int8"))
 		assert.Equal(t, true, pos > 0)
 		copy(abi2[pos:pos+4], []byte("xxxx"))
@@ -137,3 +143,603 @@ func TestGetBlockWithInvalidAbi(t *testing.T) {
 		t.Fatal(e.DetailMessage())
 	}) // get_block_with_invalid_abi
 }
+
+func TestGetAccountsByAuthorizers(t *testing.T) {
+	_, vt := initializeValidatingTester()
+	defer vt.close()
+
+	vt.CreateAccounts([]common.AccountName{common.N("alice"), common.N("bob")}, false, true)
+	vt.ProduceBlocks(1, false)
+
+	plugin := chain_plugin.NewReadOnly(vt.Control, common.MaxMicroseconds())
+
+	aliceKey := vt.getPublicKey(common.N("alice"), "active")
+	result := plugin.GetAccountsByAuthorizers(chain_plugin.GetAccountsByAuthorizersParams{Keys: []ecc.PublicKey{aliceKey}})
+	assert.Equal(t, 1, len(result.Accounts))
+	assert.Equal(t, common.N("alice"), result.Accounts[0].AccountName)
+	assert.Equal(t, common.DefaultConfig.ActiveName, result.Accounts[0].PermissionName)
+	assert.Equal(t, uint32(1), result.Accounts[0].Threshold)
+
+	// bob@active delegates alice's "spending" permission
+	spending := types.Authority{
+		Threshold: 2,
+		Accounts:  []types.PermissionLevelWeight{{Permission: common.PermissionLevel{Actor: common.N("bob"), Permission: common.DefaultConfig.ActiveName}, Weight: 1}},
+		Keys:      []types.KeyWeight{{Key: aliceKey, Weight: 1}},
+	}
+	vt.SetAuthority2(common.N("alice"), common.N("spending"), spending, common.DefaultConfig.ActiveName)
+
+	// bob@eosio.code is also in bob's own owner and active
+	result = plugin.GetAccountsByAuthorizers(chain_plugin.GetAccountsByAuthorizersParams{
+		Accounts: []common.PermissionLevel{{Actor: common.N("bob")}},
+	})
+	assert.Equal(t, 3, len(result.Accounts))
+
+	result = plugin.GetAccountsByAuthorizers(chain_plugin.GetAccountsByAuthorizersParams{
+		Accounts: []common.PermissionLevel{{Actor: common.N("bob"), Permission: common.DefaultConfig.ActiveName}},
+	})
+	assert.Equal(t, 1, len(result.Accounts))
+	assert.Equal(t, common.N("spending"), result.Accounts[0].PermissionName)
+	assert.Equal(t, common.N("bob"), result.Accounts[0].AuthorizingAccount.Actor)
+	assert.Equal(t, uint32(2), result.Accounts[0].Threshold)
+
+	result = plugin.GetAccountsByAuthorizers(chain_plugin.GetAccountsByAuthorizersParams{Keys: []ecc.PublicKey{aliceKey}})
+	assert.Equal(t, 2, len(result.Accounts))
+
+	// modified and removed permissions must drop their old entries
+	vt.SetAuthority2(common.N("alice"), common.N("spending"), types.NewAuthority(aliceKey, 0), common.DefaultConfig.ActiveName)
+	result = plugin.GetAccountsByAuthorizers(chain_plugin.GetAccountsByAuthorizersParams{
+		Accounts: []common.PermissionLevel{{Actor: common.N("bob"), Permission: common.DefaultConfig.ActiveName}},
+	})
+	assert.Equal(t, 0, len(result.Accounts))
+
+	vt.DeleteAuthority2(common.N("alice"), common.N("spending"))
+	result = plugin.GetAccountsByAuthorizers(chain_plugin.GetAccountsByAuthorizersParams{Keys: []ecc.PublicKey{aliceKey}})
+	assert.Equal(t, 1, len(result.Accounts))
+	assert.Equal(t, common.DefaultConfig.ActiveName, result.Accounts[0].PermissionName)
+}
+
+func TestGetProducers(t *testing.T) {
+	_, vt := initializeValidatingTester()
+	defer vt.close()
+
+	plugin := chain_plugin.NewReadOnly(vt.Control, common.MaxMicroseconds())
+
+	// without a system contract the active schedule is reported
+	result := plugin.GetProducers(chain_plugin.GetProducersParams{Json: true})
+	assert.Equal(t, 1, len(result.Rows))
+	assert.Equal(t, eosio.String(), result.Rows[0].(common.Variants)["owner"])
+	assert.Equal(t, "", result.More)
+
+	// rows are binary unless json is requested
+	result = plugin.GetProducers(chain_plugin.GetProducersParams{})
+	assert.Equal(t, 1, len(result.Rows))
+	_, isBinary := result.Rows[0].(common.HexBytes)
+	assert.True(t, isBinary)
+}
+
+func TestGetProducersTable(t *testing.T) {
+	e := initEosioSystemTester()
+	defer e.close()
+
+	for _, producer := range []common.AccountName{alice, bob, carol} {
+		assert.Equal(t, e.Success(), e.RegProducer(producer))
+	}
+	e.ProduceBlocks(1, false)
+
+	plugin := chain_plugin.NewReadOnly(e.Control, common.MaxMicroseconds())
+
+	result := plugin.GetProducers(chain_plugin.GetProducersParams{Json: true, Limit: 2})
+	assert.Equal(t, 2, len(result.Rows))
+	assert.Equal(t, alice.String(), result.Rows[0].(common.Variants)["owner"])
+	assert.Equal(t, bob.String(), result.Rows[1].(common.Variants)["owner"])
+	assert.Equal(t, carol.String(), result.More)
+
+	result = plugin.GetProducers(chain_plugin.GetProducersParams{Json: true, LowerBound: result.More})
+	assert.Equal(t, 1, len(result.Rows))
+	assert.Equal(t, carol.String(), result.Rows[0].(common.Variants)["owner"])
+	assert.Equal(t, "", result.More)
+
+	result = plugin.GetProducers(chain_plugin.GetProducersParams{LowerBound: bob.String()})
+	assert.Equal(t, 2, len(result.Rows))
+	_, isBinary := result.Rows[0].(common.HexBytes)
+	assert.True(t, isBinary)
+}
+
+func TestReadOnlyStateView(t *testing.T) {
+	_, vt := initializeValidatingTester()
+	defer vt.close()
+
+	vt.ProduceBlocks(1, false)
+	plugin := chain_plugin.NewReadOnly(vt.Control, common.MaxMicroseconds())
+
+	view := vt.Control.NewStateView(false)
+	defer view.Close()
+	headNum := vt.Control.HeadBlockNum()
+	assert.Equal(t, headNum, view.BlockNum)
+
+	// the view stays at its block while the chain goes on
+	vt.CreateAccounts([]common.AccountName{common.N("carol")}, false, true)
+	vt.ProduceBlocks(1, false)
+
+	CheckThrowException(t, &AccountQueryException{}, func() {
+		plugin.WithView(view).GetAccount(chain_plugin.GetAccountParams{AccountName: common.N("carol")})
+	})
+	result := plugin.WithView(view).GetAccount(chain_plugin.GetAccountParams{AccountName: common.N("eosio")})
+	assert.Equal(t, headNum, result.HeadBlockNum)
+
+	latest := vt.Control.NewStateView(false)
+	defer latest.Close()
+	result = plugin.WithView(latest).GetAccount(chain_plugin.GetAccountParams{AccountName: common.N("carol")})
+	assert.Equal(t, common.N("carol"), result.AccountName)
+	assert.Equal(t, vt.Control.HeadBlockNum(), result.HeadBlockNum)
+}
+
+func TestMerkleProofs(t *testing.T) {
+	_, vt := initializeValidatingTester()
+	defer vt.close()
+
+	vt.ProduceBlocks(1, false)
+	trace := vt.CreateAccount(common.N("alice"), eosio, false, true)
+	vt.CreateAccount(common.N("bob"), eosio, false, true)
+	block := vt.ProduceBlock(common.Milliseconds(common.DefaultConfig.BlockIntervalMs), 0)
+	blockNum := fmt.Sprintf("%d", block.BlockNumber())
+
+	// the producer and the validator, which applied the block from the network, prove the same
+	controls := []*chain.Controller{vt.Control, vt.ValidatingControl}
+	for _, control := range controls {
+		control.Config.ActionReceiptsRetention = 4
+	}
+
+	sequence := trace.ActionTraces[0].Receipt.GlobalSequence
+	checkActionProof := func(plugin *chain_plugin.ReadOnly) {
+		for _, params := range []chain_plugin.GetActionProofParams{{GlobalSequence: sequence, BlockNumOrID: blockNum}, {GlobalSequence: sequence}} {
+			actProof := plugin.GetActionProof(params)
+			assert.Equal(t, block.BlockID(), actProof.BlockID)
+			assert.Equal(t, sequence, actProof.Receipt.GlobalSequence)
+			assert.Equal(t, actProof.Receipt.Digest(), actProof.Proof.Leaf)
+			assert.True(t, types.VerifyActionProof(&actProof.Header.BlockHeader, actProof.Proof))
+		}
+	}
+	for _, control := range controls {
+		checkActionProof(chain_plugin.NewReadOnly(control, common.MaxMicroseconds()))
+	}
+
+	// the receipts of the block are read from the action receipts database once it left the fork database
+	vt.ProduceBlocks(3, false)
+	for _, control := range controls {
+		assert.Nil(t, control.FetchBlockStateById(block.BlockID()))
+		plugin := chain_plugin.NewReadOnly(control, common.MaxMicroseconds())
+		checkActionProof(plugin)
+
+		trxProof := plugin.GetTransactionProof(chain_plugin.GetTransactionProofParams{ID: trace.ID, BlockNumOrID: blockNum})
+		assert.Equal(t, block.BlockID(), trxProof.BlockID)
+		assert.Equal(t, trxProof.Receipt.Digest(), trxProof.Proof.Leaf)
+		assert.True(t, types.VerifyTransactionProof(&trxProof.Header.BlockHeader, trxProof.Proof))
+
+		blockProof := plugin.GetBlockProof(chain_plugin.GetBlockProofParams{BlockNumOrID: blockNum})
+		assert.Equal(t, control.HeadBlockId(), blockProof.Reference.BlockId)
+		assert.True(t, types.VerifyBlockProof(&blockProof.Reference, block.BlockID(), blockProof.Proof))
+		assert.False(t, types.VerifyBlockProof(&blockProof.Reference, block.Previous, blockProof.Proof))
+
+		CheckThrowException(t, &TxNotFound{}, func() {
+			plugin.GetTransactionProof(chain_plugin.GetTransactionProofParams{ID: trace.ID, BlockNumOrID: "1"})
+		})
+		CheckThrowException(t, &UnknownBlockException{}, func() {
+			plugin.GetActionProof(chain_plugin.GetActionProofParams{GlobalSequence: sequence, BlockNumOrID: "1"})
+		})
+		CheckThrowException(t, &BlockValidateException{}, func() {
+			plugin.GetBlockProof(chain_plugin.GetBlockProofParams{BlockNumOrID: fmt.Sprintf("%d", control.HeadBlockNum())})
+		})
+	}
+
+	// the receipts are removed once the block is older than the retention window, the block proof remains
+	vt.ProduceBlocks(4, false)
+	for _, control := range controls {
+		assert.True(t, control.LastIrreversibleBlockNum() >= block.BlockNumber()+4)
+		assert.Nil(t, control.FetchActionReceipts(block.BlockNumber()))
+		plugin := chain_plugin.NewReadOnly(control, common.MaxMicroseconds())
+		for _, params := range []chain_plugin.GetActionProofParams{{GlobalSequence: sequence, BlockNumOrID: blockNum}, {GlobalSequence: sequence}} {
+			CheckThrowException(t, &UnknownBlockException{}, func() {
+				plugin.GetActionProof(params)
+			})
+		}
+
+		blockProof := plugin.GetBlockProof(chain_plugin.GetBlockProofParams{BlockNumOrID: blockNum})
+		assert.True(t, types.VerifyBlockProof(&blockProof.Reference, block.BlockID(), blockProof.Proof))
+	}
+}
+
+func TestDryRunTransaction(t *testing.T) {
+	e := initEosioTokenTester()
+	defer e.close()
+
+	alice, bob := common.N("alice"), common.N("bob")
+	supply, issued := "1000.000 TKN", "500.000 TKN"
+	e.create(alice, common.Asset{}.FromString(&supply))
+	e.issue(alice, alice, common.Asset{}.FromString(&issued), "hola")
+	e.ProduceBlocks(1, false)
+
+	maxTransactionTime := common.Microseconds(-1)
+	dryRun := func(quantity string, sign bool, skipSignatures bool) chain_plugin.DryRunTransactionResult {
+		trx := types.SignedTransaction{}
+		trx.Actions = append(trx.Actions, e.GetAction(eosioToken, common.N("transfer"),
+			[]common.PermissionLevel{{Actor: alice, Permission: common.DefaultConfig.ActiveName}},
+			&common.Variants{"from": alice, "to": bob, "quantity": quantity, "memo": "dry run"}))
+		e.SetTransactionHeaders(&trx.Transaction, e.DefaultExpirationDelta, 0)
+		if sign {
+			key := e.getPrivateKey(alice, "active")
+			chainID := e.Control.GetChainId()
+			trx.Sign(&key, &chainID)
+		}
+
+		params := chain_plugin.DryRunTransactionParams{SkipSignatures: skipSignatures}
+		common.ToVariant(types.NewPackedTransactionBySignedTrx(&trx, types.CompressionNone), &params.Transaction)
+		plugin := chain_plugin.NewReadOnly(e.Control, common.MaxMicroseconds())
+		plugin.SetMaxTransactionTime(maxTransactionTime)
+		return plugin.DryRunTransaction(params)
+	}
+
+	head := e.Control.HeadBlockId()
+	result := dryRun("100.000 TKN", true, false)
+	assert.Nil(t, result.Except)
+	assert.True(t, result.CpuUsageUs > 0)
+	assert.True(t, result.NetUsage > 0)
+	assert.Equal(t, 1, len(result.RamDeltas))
+	assert.Equal(t, alice, result.RamDeltas[0].Account) // the sender pays for the row of bob
+	assert.True(t, result.RamDeltas[0].Delta > 0)
+
+	// the transfer and the notifications of both parties, with the data decoded by the abi
+	assert.Equal(t, 1, len(result.ActionTraces))
+	assert.Equal(t, 2, len(result.ActionTraces[0].InlineTraces))
+	assert.Equal(t, "100.000 TKN", result.ActionTraces[0].Data["quantity"])
+	assert.Equal(t, "bob", result.ActionTraces[0].Data["to"])
+
+	// nothing is kept
+	assert.Equal(t, head, e.Control.HeadBlockId())
+	balance := common.Variants{"balance": "500.000 TKN"}
+	assert.True(t, equal(e.getAccount(alice, "3,TKN"), &balance))
+	symbolName := "3,TKN"
+	symbol := common.Symbol{}.FromString(&symbolName)
+	assert.Equal(t, 0, len(e.GetRowByAccount(uint64(eosioToken), uint64(bob), uint64(common.N("accounts")), uint64(symbol.ToSymbolCode()))))
+
+	// without a pending block the transaction runs in a throwaway one on the head
+	e.Control.AbortBlock()
+	result = dryRun("100.000 TKN", true, false)
+	assert.Nil(t, result.Except)
+	assert.Nil(t, e.Control.PendingBlockState())
+
+	// an unsigned transaction is only executed when signatures are skipped
+	result = dryRun("100.000 TKN", false, false)
+	assert.NotNil(t, result.Except)
+	assert.Equal(t, 0, len(result.ActionTraces))
+	result = dryRun("100.000 TKN", false, true)
+	assert.Nil(t, result.Except)
+
+	// a failing action is reported with its trace
+	result = dryRun("600.000 TKN", true, false)
+	assert.NotNil(t, result.Except)
+	assert.True(t, strings.Contains(result.Except.Message, "overdrawn balance"))
+
+	// the execution is bounded by the max transaction time
+	maxTransactionTime = 0
+	result = dryRun("100.000 TKN", true, false)
+	assert.NotNil(t, result.Except)
+	assert.Equal(t, DeadlineException{}.Code(), result.Except.Code)
+	maxTransactionTime = -1
+
+	// the same transaction can still be pushed afterwards
+	e.transfer(alice, bob, common.Asset{}.FromString(&issued), "pushed")
+	balance = common.Variants{"balance": "500.000 TKN"}
+	assert.True(t, equal(e.getAccount(bob, "3,TKN"), &balance))
+}
+
+func TestExplainAuthorization(t *testing.T) {
+	bt := newBaseTester(true, chain.SPECULATIVE)
+	defer bt.close()
+
+	alice, bob := common.N("alice"), common.N("bob")
+	bt.CreateAccounts([]common.AccountName{alice, bob}, false, true)
+	bt.ProduceBlocks(1, false)
+
+	spendingKey := bt.getPublicKey(alice, "spending")
+	bobKey := bt.getPublicKey(bob, "active")
+	spending := common.PermissionName(common.N("spending"))
+	bt.SetAuthority2(alice, spending, types.Authority{
+		Threshold: 2,
+		Keys:      []types.KeyWeight{{Key: spendingKey, Weight: 1}},
+		Accounts:  []types.PermissionLevelWeight{{Permission: common.PermissionLevel{Actor: bob, Permission: common.DefaultConfig.ActiveName}, Weight: 1}},
+		Waits:     []types.WaitWeight{{WaitSec: 3600, Weight: 1}},
+	}, common.DefaultConfig.ActiveName)
+	bt.ProduceBlocks(1, false)
+
+	explain := func(keys []ecc.PublicKey, permissions []common.PermissionLevel) *chain.AuthorizationExplanation {
+		trx := types.SignedTransaction{}
+		data, _ := rlp.EncodeToBytes(struct{ From common.AccountName }{alice})
+		trx.Actions = append(trx.Actions, &types.Action{
+			Account:       eosio,
+			Name:          common.ActionName(common.N("reqauth")),
+			Authorization: []common.PermissionLevel{{Actor: alice, Permission: spending}},
+			Data:          data,
+		})
+		bt.SetTransactionHeaders(&trx.Transaction, bt.DefaultExpirationDelta, 0)
+
+		params := chain_plugin.ExplainAuthorizationParams{AvailableKeys: *NewPublicKeySet(), ProvidedPermissions: permissions}
+		for _, key := range keys {
+			params.AvailableKeys.Add(key)
+		}
+		common.ToVariant(trx, &params.Transaction)
+		return chain_plugin.NewReadOnly(bt.Control, common.MaxMicroseconds()).ExplainAuthorization(params)
+	}
+
+	// reqauth is not linked yet, spending is irrelevant as it is a child of active
+	result := explain([]ecc.PublicKey{spendingKey, bobKey}, nil)
+	assert.False(t, result.Authorized)
+	assert.Equal(t, 1, len(result.Authorizations))
+	auth := result.Authorizations[0]
+	assert.Nil(t, auth.LinkedPermission)
+	assert.Equal(t, common.DefaultConfig.ActiveName, *auth.MinimumPermission)
+	assert.False(t, auth.Relevant)
+	assert.True(t, auth.Satisfied)
+
+	bt.LinkAuthority(alice, eosio, spending, common.N("reqauth"))
+	bt.ProduceBlocks(1, false)
+
+	// the key of alice alone only reaches half of the threshold
+	result = explain([]ecc.PublicKey{spendingKey}, nil)
+	assert.False(t, result.Authorized)
+	assert.True(t, strings.Contains(result.Error, "transaction declares authority"))
+	auth = result.Authorizations[0]
+	assert.Equal(t, spending, *auth.LinkedPermission)
+	assert.Equal(t, spending, *auth.MinimumPermission)
+	assert.True(t, auth.Relevant)
+	assert.False(t, auth.Satisfied)
+	tree := auth.Explanation
+	assert.Equal(t, types.PermissionEvaluated, tree.Status)
+	assert.Equal(t, uint32(2), tree.Threshold)
+	assert.Equal(t, uint32(1), tree.TotalWeight)
+	assert.True(t, tree.Keys[0].Satisfied)
+	assert.False(t, tree.Waits[0].Satisfied)
+	assert.Equal(t, uint32(3600), tree.Waits[0].WaitSec)
+	bobTree := tree.Accounts[0]
+	assert.Equal(t, types.PermissionEvaluated, bobTree.Status)
+	assert.Equal(t, uint16(1), bobTree.Depth)
+	assert.False(t, bobTree.Satisfied)
+	assert.Equal(t, bobKey, bobTree.Keys[0].Key)
+	assert.False(t, bobTree.Keys[0].Satisfied)
+
+	// bob co-signs
+	result = explain([]ecc.PublicKey{spendingKey, bobKey}, nil)
+	assert.True(t, result.Authorized)
+	assert.Equal(t, "", result.Error)
+	assert.True(t, result.Authorizations[0].Satisfied)
+	assert.Equal(t, uint32(2), result.Authorizations[0].Explanation.TotalWeight)
+	assert.True(t, result.Authorizations[0].Explanation.Accounts[0].Satisfied)
+	assert.Equal(t, 0, len(result.UnusedKeys))
+
+	// bob@active is provided as a permission, an extra key is irrelevant
+	result = explain([]ecc.PublicKey{spendingKey, bobKey}, []common.PermissionLevel{{Actor: bob, Permission: common.DefaultConfig.ActiveName}})
+	assert.False(t, result.Authorized)
+	assert.True(t, result.Authorizations[0].Satisfied)
+	assert.Equal(t, types.PermissionProvided, result.Authorizations[0].Explanation.Accounts[0].Status)
+	assert.Equal(t, []ecc.PublicKey{bobKey}, result.UnusedKeys)
+}
+
+func TestGetScheduledTransactions(t *testing.T) {
+	e := initEosioTokenTester()
+	defer e.close()
+
+	alice, bob := common.N("alice"), common.N("bob")
+	supply, issued := "1000.000 TKN", "500.000 TKN"
+	e.create(alice, common.Asset{}.FromString(&supply))
+	e.issue(alice, alice, common.Asset{}.FromString(&issued), "hola")
+	e.transfer(alice, bob, common.Asset{}.FromString(&issued), "all")
+	e.ProduceBlocks(1, false)
+
+	pushDelayed := func(from common.AccountName, to common.AccountName, quantity string, delaySec uint32) common.TransactionIdType {
+		trx := types.SignedTransaction{}
+		trx.Actions = append(trx.Actions, e.GetAction(eosioToken, common.N("transfer"),
+			[]common.PermissionLevel{{Actor: from, Permission: common.DefaultConfig.ActiveName}},
+			&common.Variants{"from": from, "to": to, "quantity": quantity, "memo": "later"}))
+		e.SetTransactionHeaders(&trx.Transaction, e.DefaultExpirationDelta, delaySec)
+		key := e.getPrivateKey(from, "active")
+		chainID := e.Control.GetChainId()
+		trx.Sign(&key, &chainID)
+		trace := e.PushTransaction(&trx, common.MaxTimePoint(), e.DefaultBilledCpuTimeUs)
+		assert.Equal(t, types.TransactionStatusDelayed, trace.Receipt.Status)
+		return trx.ID()
+	}
+	late := pushDelayed(bob, alice, "3.000 TKN", 30)
+	early := pushDelayed(bob, alice, "1.000 TKN", 10)
+	middle := pushDelayed(alice, bob, "2.000 TKN", 20)
+	e.ProduceBlocks(1, false)
+
+	// a query time the test never reaches keeps the results independent of the speed of the machine
+	budget := common.Seconds(60)
+	get := func(params chain_plugin.GetScheduledTransactionParams) chain_plugin.GetScheduledTransactionResult {
+		plugin := chain_plugin.NewReadOnly(e.Control, common.MaxMicroseconds())
+		plugin.SetQueryTimeBudget(budget)
+		return plugin.GetScheduledTransactions(params)
+	}
+	ids := func(result chain_plugin.GetScheduledTransactionResult) []common.TransactionIdType {
+		ids := make([]common.TransactionIdType, 0)
+		for _, trx := range result.Transactions {
+			ids = append(ids, trx.TrxID)
+		}
+		return ids
+	}
+
+	// ordered by delay, with the action data decoded
+	result := get(chain_plugin.GetScheduledTransactionParams{Json: true})
+	assert.Equal(t, []common.TransactionIdType{early, middle, late}, ids(result))
+	assert.Equal(t, "", result.More)
+	assert.Equal(t, alice, result.Transactions[1].Payer)
+	action := result.Transactions[1].Transaction["actions"].([]common.Variants)[0]
+	assert.Equal(t, "2.000 TKN", action["data"].(common.Variants)["quantity"])
+	assert.Nil(t, result.Transactions[1].PackedTrx)
+
+	// paging by transaction id
+	result = get(chain_plugin.GetScheduledTransactionParams{Limit: 2})
+	assert.Equal(t, []common.TransactionIdType{early, middle}, ids(result))
+	assert.Equal(t, late.String(), result.More)
+	assert.NotNil(t, result.Transactions[0].PackedTrx)
+	result = get(chain_plugin.GetScheduledTransactionParams{LowerBound: result.More})
+	assert.Equal(t, []common.TransactionIdType{late}, ids(result))
+
+	// a query out of time reports where to continue
+	budget = -1
+	result = get(chain_plugin.GetScheduledTransactionParams{})
+	assert.Equal(t, 0, len(result.Transactions))
+	assert.Equal(t, early.String(), result.More)
+	budget = common.Seconds(60)
+
+	// lower bound by delay
+	delayUntil := get(chain_plugin.GetScheduledTransactionParams{}).Transactions[1].DelayUntil
+	result = get(chain_plugin.GetScheduledTransactionParams{LowerBound: delayUntil.String()})
+	assert.Equal(t, []common.TransactionIdType{middle, late}, ids(result))
+
+	// by payer, in scheduling order
+	result = get(chain_plugin.GetScheduledTransactionParams{Payer: bob})
+	assert.Equal(t, []common.TransactionIdType{late, early}, ids(result))
+	result = get(chain_plugin.GetScheduledTransactionParams{Payer: alice})
+	assert.Equal(t, []common.TransactionIdType{middle}, ids(result))
+	result = get(chain_plugin.GetScheduledTransactionParams{Payer: common.N("carol")})
+	assert.Equal(t, 0, len(result.Transactions))
+
+	CheckThrowException(t, &InvalidArgException{}, func() {
+		get(chain_plugin.GetScheduledTransactionParams{Payer: bob, LowerBound: delayUntil.String()})
+	})
+	CheckThrowException(t, &InvalidArgException{}, func() {
+		get(chain_plugin.GetScheduledTransactionParams{Payer: bob, Sender: bob})
+	})
+
+	// the executed transactions leave the queue
+	e.ProduceBlock(common.Seconds(15), 0)
+	result = get(chain_plugin.GetScheduledTransactionParams{})
+	assert.Equal(t, []common.TransactionIdType{middle, late}, ids(result))
+}
+
+func TestScheduledTransactionExecutionOrder(t *testing.T) {
+	e := initEosioTokenTester()
+	defer e.close()
+
+	alice, bob := common.N("alice"), common.N("bob")
+	supply, issued := "1000.000 TKN", "500.000 TKN"
+	e.create(alice, common.Asset{}.FromString(&supply))
+	e.issue(alice, alice, common.Asset{}.FromString(&issued), "hola")
+	e.ProduceBlocks(1, false)
+
+	pushDelayed := func(quantity string, delaySec uint32) common.TransactionIdType {
+		trx := types.SignedTransaction{}
+		trx.Actions = append(trx.Actions, e.GetAction(eosioToken, common.N("transfer"),
+			[]common.PermissionLevel{{Actor: alice, Permission: common.DefaultConfig.ActiveName}},
+			&common.Variants{"from": alice, "to": bob, "quantity": quantity, "memo": "later"}))
+		e.SetTransactionHeaders(&trx.Transaction, e.DefaultExpirationDelta, delaySec)
+		key := e.getPrivateKey(alice, "active")
+		chainID := e.Control.GetChainId()
+		trx.Sign(&key, &chainID)
+		trace := e.PushTransaction(&trx, common.MaxTimePoint(), e.DefaultBilledCpuTimeUs)
+		assert.Equal(t, types.TransactionStatusDelayed, trace.Receipt.Status)
+		return trx.ID()
+	}
+	// scheduled in this order within one block, the last two are due at the same time
+	late := pushDelayed("3.000 TKN", 30)
+	early := pushDelayed("1.000 TKN", 10)
+	first := pushDelayed("2.000 TKN", 20)
+	second := pushDelayed("4.000 TKN", 20)
+	e.ProduceBlocks(1, false)
+
+	// a block produced once all of them are due executes them by delay, then in the order they were scheduled
+	block := e.ProduceBlock(common.Seconds(40), 0)
+	executed := make([]common.TransactionIdType, 0)
+	for _, receipt := range block.Transactions {
+		assert.Equal(t, types.TransactionStatusExecuted, receipt.Status)
+		executed = append(executed, receipt.Trx.TransactionID)
+	}
+	assert.Equal(t, []common.TransactionIdType{early, first, second, late}, executed)
+}
+
+func TestGeneratedTransactionLayout(t *testing.T) {
+	c := &BaseTester{DefaultExpirationDelta: 6, DefaultBilledCpuTimeUs: 2000, AbiSerializerMaxTime: 1000 * 1000}
+	c.LastProducedBlock = make(map[common.AccountName]common.BlockIdType)
+	c.Cfg = *newConfig(chain.SPECULATIVE)
+	c.Cfg.InMemoryState = false
+	c.open()
+	c.pushGenesisBlock()
+	c.ProduceBlocks(1, false)
+
+	current := entity.GeneratedTransactionObject{
+		TrxId:      *crypto.Hash256("scheduled"),
+		Sender:     eosio,
+		Payer:      eosio,
+		DelayUntil: common.Now() + common.TimePoint(common.Seconds(10)),
+		Expiration: common.Now() + common.TimePoint(common.Seconds(20)),
+	}
+	c.Control.AbortBlock()
+	assert.NoError(t, c.Control.DB.Insert(&current))
+	c.close()
+	c.open()
+	assert.NoError(t, c.Control.DB.Find("byTrxId", current, &current))
+	c.Control.AbortBlock()
+	assert.NoError(t, c.Control.DB.Remove(&current))
+
+	// a row written while Id was the first field of the generated transactions
+	type GeneratedTransactionObject struct {
+		Id         common.IdType            `multiIndex:"id,increment,byExpiration,byDelay"`
+		TrxId      common.TransactionIdType `multiIndex:"byTrxId,orderedUnique"`
+		Sender     common.AccountName       `multiIndex:"bySenderId,orderedUnique"`
+		SenderId   eos_math.Uint128         `multiIndex:"bySenderId,orderedUnique"`
+		Payer      common.AccountName
+		DelayUntil common.TimePoint `multiIndex:"byDelay,orderedUnique"`
+		Expiration common.TimePoint `multiIndex:"byExpiration,orderedUnique"`
+		Published  common.TimePoint
+		PackedTrx  common.HexBytes
+	}
+	assert.NoError(t, c.Control.DB.Insert(&GeneratedTransactionObject{
+		TrxId:      current.TrxId,
+		Sender:     current.Sender,
+		Payer:      current.Payer,
+		DelayUntil: current.DelayUntil,
+		Expiration: current.Expiration,
+	}))
+	c.close()
+
+	// is not read as the current layout, the state database has to be replayed
+	CheckThrowException(t, &DatabaseException{}, func() {
+		c.open()
+	})
+	c.Control.Close()
+}
+
+func TestPermissionAuthorizersNotIndexed(t *testing.T) {
+	c := &BaseTester{DefaultExpirationDelta: 6, DefaultBilledCpuTimeUs: 2000, AbiSerializerMaxTime: 1000 * 1000}
+	c.LastProducedBlock = make(map[common.AccountName]common.BlockIdType)
+	c.Cfg = *newConfig(chain.SPECULATIVE)
+	c.Cfg.InMemoryState = false
+	c.open()
+	c.pushGenesisBlock()
+	c.ProduceBlocks(1, false)
+	c.close()
+	c.open()
+
+	// a state database written before the permission authorizers were indexed
+	c.Control.AbortBlock()
+	idx, err := c.Control.DB.GetIndex("id", &entity.PermissionAuthorizerObject{})
+	assert.NoError(t, err)
+	authorizers := make([]entity.PermissionAuthorizerObject, 0)
+	for itr := idx.Begin(); !idx.CompareEnd(itr); itr.Next() {
+		pao := entity.PermissionAuthorizerObject{}
+		assert.NoError(t, itr.Data(&pao))
+		authorizers = append(authorizers, pao)
+	}
+	assert.NotEqual(t, 0, len(authorizers))
+	for i := range authorizers {
+		assert.NoError(t, c.Control.DB.Remove(&authorizers[i]))
+	}
+	c.close()
+
+	// would answer get_accounts_by_authorizers with nothing, the state database has to be replayed
+	CheckThrowException(t, &DatabaseException{}, func() {
+		c.open()
+	})
+	c.Control.Close()
+}
@@ -1,6 +1,8 @@
 package unittests
 
 import (
+	"bytes"
+	"compress/gzip"
 	"github.com/eosspark/eos-go/chain"
 	"github.com/eosspark/eos-go/chain/abi_serializer"
 	"github.com/eosspark/eos-go/chain/types"
@@ -9,8 +11,12 @@ import (
 	"github.com/eosspark/eos-go/exception"
 	"github.com/eosspark/eos-go/exception/try"
 	"github.com/eosspark/eos-go/log"
+	"github.com/eosspark/eos-go/wasmgo"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -363,3 +369,142 @@ func TestTransfer(t *testing.T) {
 	assert.Equal(t, returning, true)
 	eosioToken.close()
 }
+
+func TestTransferAOT(t *testing.T) {
+	eosioToken := initEosioTokenTester()
+	eosioToken.Control.GetWasmInterface().SetVmType(wasmgo.AOT)
+	defer eosioToken.Control.GetWasmInterface().SetVmType(wasmgo.Interpreter)
+
+	symbol := "1000 CERO"
+	eosioToken.create(common.N("alice"), common.Asset{}.FromString(&symbol))
+	eosioToken.issue(common.N("alice"), common.N("alice"), common.Asset{}.FromString(&symbol), "hola")
+	quantity := "300 CERO"
+	eosioToken.transfer(common.N("alice"), common.N("bob"), common.Asset{}.FromString(&quantity), "hola")
+	eosioToken.ProduceBlocks(1, false)
+
+	assert.Equal(t, true, equal(eosioToken.getAccount(common.N("alice"), "0,CERO"), &common.Variants{"balance": "700 CERO"}))
+	assert.Equal(t, true, equal(eosioToken.getAccount(common.N("bob"), "0,CERO"), &common.Variants{"balance": "300 CERO"}))
+
+	returning := false
+	try.Try(func() {
+		quantity = "701 CERO"
+		eosioToken.transfer(common.N("alice"), common.N("bob"), common.Asset{}.FromString(&quantity), "hola")
+	}).Catch(func(e exception.Exception) {
+		returning = strings.Contains(e.DetailMessage(), "overdrawn balance")
+	}).End()
+	assert.Equal(t, true, returning)
+	eosioToken.close()
+}
+
+func TestTransferDebug(t *testing.T) {
+	traceDir, err := ioutil.TempDir("", "contract-trace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(traceDir)
+
+	eosioToken := initEosioTokenTester()
+	eosioToken.Control.GetWasmInterface().EnableDebug([]common.AccountName{common.N("eosio.token")}, traceDir)
+	defer eosioToken.Control.GetWasmInterface().EnableDebug(nil, "")
+
+	symbol := "1000 CERO"
+	eosioToken.create(common.N("alice"), common.Asset{}.FromString(&symbol))
+	eosioToken.issue(common.N("alice"), common.N("alice"), common.Asset{}.FromString(&symbol), "hola")
+
+	detail := ""
+	try.Try(func() {
+		quantity := "1001 CERO"
+		eosioToken.transfer(common.N("alice"), common.N("bob"), common.Asset{}.FromString(&quantity), "hola")
+	}).Catch(func(e exception.Exception) {
+		detail = e.DetailMessage()
+	}).End()
+	assert.Contains(t, detail, "overdrawn balance")
+	assert.Contains(t, detail, "wasm stack trace")
+	assert.Contains(t, detail, "env.eosio_assert")
+	assert.Contains(t, detail, "apply")
+	eosioToken.close()
+
+	trace, err := ioutil.ReadFile(filepath.Join(traceDir, "eosio.token.trace"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(trace), "apply eosio.token eosio.token::transfer")
+	assert.Contains(t, string(trace), "mem[")
+}
+
+func TestContractProfiler(t *testing.T) {
+	eosioToken := initEosioTokenTester()
+	wasmIf := eosioToken.Control.GetWasmInterface()
+	assert.False(t, wasmIf.Profiling())
+
+	symbol := "1000 CERO"
+	eosioToken.create(common.N("alice"), common.Asset{}.FromString(&symbol))
+	wasmIf.StartProfiler()
+	defer wasmIf.StopProfiler()
+	eosioToken.issue(common.N("alice"), common.N("alice"), common.Asset{}.FromString(&symbol), "hola")
+	quantity := "300 CERO"
+	eosioToken.transfer(common.N("alice"), common.N("bob"), common.Asset{}.FromString(&quantity), "hola")
+	eosioToken.transfer(common.N("alice"), common.N("bob"), common.Asset{}.FromString(&quantity), "hola")
+	wasmIf.StopProfiler()
+	assert.False(t, wasmIf.Profiling())
+	eosioToken.transfer(common.N("alice"), common.N("bob"), common.Asset{}.FromString(&quantity), "hola")
+
+	/* a transfer is applied by eosio.token only, alice and bob have no contract to be notified */
+	actions := make(map[string]wasmgo.ActionProfile)
+	for _, a := range wasmIf.Profile().Actions() {
+		actions[a.Receiver.String()+"::"+a.Action.String()] = a
+	}
+	assert.Contains(t, actions, "eosio::onblock")
+	transfer := actions["eosio.token::transfer"]
+	assert.Equal(t, uint64(2), transfer.Count)
+	assert.Equal(t, uint64(1), actions["eosio.token::issue"].Count)
+
+	functions := make(map[string]uint64)
+	for _, f := range transfer.Functions {
+		functions[f.Name] = f.Instructions
+		assert.False(t, strings.HasPrefix(f.Name, "env."))
+	}
+	assert.True(t, functions["apply"] > 0)
+	assert.True(t, functions["_ZN5eosio5token11sub_balanceEyNS_5assetE"] > 0)
+
+	intrinsics := make(map[string]wasmgo.IntrinsicProfile)
+	for _, i := range transfer.Intrinsics {
+		intrinsics[i.Name] = i
+	}
+	assert.Equal(t, uint64(2), intrinsics["env.require_auth"].Calls)
+	assert.True(t, intrinsics["env.db_find_i64"].Calls > 0)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, wasmIf.Profile().WritePprof(buf))
+	zr, err := gzip.NewReader(buf)
+	assert.NoError(t, err)
+	pprof, err := ioutil.ReadAll(zr)
+	assert.NoError(t, err)
+	assert.Contains(t, string(pprof), "eosio.token::transfer")
+	assert.Contains(t, string(pprof), "env.db_find_i64")
+	eosioToken.close()
+}
+
+// BenchmarkTransfer pushes eosio.token transfers with each wasm runtime
+func BenchmarkTransfer(b *testing.B) {
+	for _, vmType := range []wasmgo.VmType{wasmgo.Interpreter, wasmgo.AOT} {
+		b.Run(vmType.String(), func(b *testing.B) {
+			eosioToken := initEosioTokenTester()
+			eosioToken.Control.GetWasmInterface().SetVmType(vmType)
+			defer eosioToken.Control.GetWasmInterface().SetVmType(wasmgo.Interpreter)
+
+			supply := "1000000000 CERO"
+			eosioToken.create(common.N("alice"), common.Asset{}.FromString(&supply))
+			eosioToken.issue(common.N("alice"), common.N("alice"), common.Asset{}.FromString(&supply), "hola")
+			eosioToken.ProduceBlocks(1, false)
+			one := "1 CERO"
+			quantity := common.Asset{}.FromString(&one)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				eosioToken.transfer(common.N("alice"), common.N("bob"), quantity, strconv.Itoa(i))
+				if i%100 == 99 {
+					eosioToken.ProduceBlocks(1, false)
+				}
+			}
+			b.StopTimer()
+			eosioToken.close()
+		})
+	}
+}
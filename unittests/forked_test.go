@@ -6,11 +6,14 @@ import (
 	"github.com/eosspark/eos-go/common"
 	"github.com/eosspark/eos-go/crypto"
 	"github.com/eosspark/eos-go/crypto/ecc"
+	"github.com/eosspark/eos-go/entity"
 	"github.com/eosspark/eos-go/exception"
 	"github.com/eosspark/eos-go/exception/try"
 	"github.com/eosspark/eos-go/log"
+	"github.com/eosspark/eos-go/plugins/chain_plugin"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
+	"os"
 	"testing"
 )
 
@@ -502,6 +505,141 @@ func TestConfirmation(t *testing.T) {
 
 }
 
+func TestConfirmationWithPendingBlock(t *testing.T) {
+	c := NewForkedTester().tester
+	c.ProduceBlocks(10, false)
+	dan := common.N("dan")
+	sam := common.N("sam")
+	pam := common.N("pam")
+	scott := common.N("scott")
+	accounts := []common.AccountName{dan, sam, pam, scott}
+	c.CreateAccounts(accounts, false, true)
+	c.SetProducers(&accounts)
+	c.ProduceBlocks(50, false)
+	assert.NotNil(t, c.Control.PendingBlockState())
+
+	head := c.Control.HeadBlockState()
+	assert.True(t, c.Control.LastIrreversibleBlockNum() < head.BlockNum)
+
+	confirm := func(producer common.AccountName) {
+		h := types.HeaderConfirmation{head.BlockId, producer, ecc.Signature{}}
+		priv := c.getPrivateKey(producer, "active")
+		h.ProducerSignature, _ = priv.Sign(head.SigDigest().Bytes())
+		c.Control.PushConfirmation(&h)
+	}
+
+	// the pending block has to be aborted first, as for blocks
+	var ex string
+	try.Try(func() {
+		confirm(dan)
+	}).Catch(func(e exception.Exception) {
+		ex = e.DetailMessage()
+	}).End()
+	assert.True(t, inString(ex, "it is not valid to push a confirmation when there is a pending block"))
+	assert.Equal(t, 0, len(head.Confirmations))
+
+	c.Control.AbortBlock()
+	for _, producer := range []common.AccountName{dan, sam, pam} {
+		confirm(producer)
+	}
+	assert.Equal(t, head.BlockNum, head.BftIrreversibleBlocknum)
+	assert.Equal(t, head.BlockNum, c.Control.LastIrreversibleBlockNum())
+
+	c.ProduceBlocks(1, false)
+	assert.Equal(t, head.BlockNum, c.Control.HeadBlockState().BftIrreversibleBlocknum)
+}
+
+func TestForkDbCrashRecovery(t *testing.T) {
+	c := &BaseTester{DefaultExpirationDelta: 6, DefaultBilledCpuTimeUs: 2000, AbiSerializerMaxTime: 1000 * 1000}
+	c.LastProducedBlock = make(map[common.AccountName]common.BlockIdType)
+	c.Cfg = *newConfig(chain.SPECULATIVE)
+	c.Cfg.InMemoryState = false
+	c.open()
+	c.pushGenesisBlock()
+
+	accounts := []common.AccountName{common.N("dan"), common.N("sam"), common.N("pam"), common.N("scott")}
+	c.CreateAccounts(accounts, false, true)
+	c.SetProducers(&accounts)
+	c.ProduceBlocks(50, false)
+
+	// the fork database is left as it is on disk, as if the node crashed
+	crash := func() {
+		c.Control.AbortBlock()
+		c.Control.DB.Close()
+		c.Control.ReversibleBlocks.Close()
+		c.Control.ActionReceipts.Close()
+	}
+
+	head := c.Control.HeadBlockId()
+	lib := c.Control.LastIrreversibleBlockNum()
+	assert.True(t, lib < c.Control.HeadBlockNum())
+
+	crash()
+	c.open()
+	assert.Equal(t, head, c.Control.HeadBlockId())
+	assert.Equal(t, lib, c.Control.LastIrreversibleBlockNum())
+	c.ProduceBlocks(10, false)
+
+	// confirmations that make a block irreversible are checkpointed right away
+	c.Control.AbortBlock()
+	confirmed := c.Control.HeadBlockState()
+	for _, producer := range accounts[:3] {
+		h := types.HeaderConfirmation{confirmed.BlockId, producer, ecc.Signature{}}
+		priv := c.getPrivateKey(producer, "active")
+		h.ProducerSignature, _ = priv.Sign(confirmed.SigDigest().Bytes())
+		c.Control.PushConfirmation(&h)
+	}
+	assert.Equal(t, confirmed.BlockNum, c.Control.LastIrreversibleBlockNum())
+	crash()
+	c.open()
+	assert.Equal(t, confirmed.BlockId, c.Control.HeadBlockId())
+	assert.Equal(t, confirmed.BlockNum, c.Control.HeadBlockState().BftIrreversibleBlocknum)
+	c.ProduceBlocks(10, false)
+
+	// a crash after the state database is flushed but before the fork database is written
+	forkDbDat := c.Cfg.StateDir + "/" + common.DefaultConfig.ForkDbName
+	behind, err := ioutil.ReadFile(forkDbDat)
+	assert.NoError(t, err)
+	c.ProduceBlocks(1, false)
+	head = c.Control.HeadBlockId()
+	lib = c.Control.LastIrreversibleBlockNum()
+	crash()
+	assert.NoError(t, ioutil.WriteFile(forkDbDat, behind, os.ModePerm))
+
+	// leaves the fork database a block behind, it catches up from the reversible blocks database
+	c.open()
+	assert.Equal(t, head, c.Control.HeadBlockId())
+	assert.Equal(t, lib, c.Control.LastIrreversibleBlockNum())
+	c.ProduceBlocks(10, false)
+
+	head = c.Control.HeadBlockId()
+	lib = c.Control.LastIrreversibleBlockNum()
+	crash()
+	assert.NoError(t, ioutil.WriteFile(forkDbDat, []byte("corrupted fork database"), os.ModePerm))
+
+	// a corrupted fork database is replaced by the previous checkpoint
+	c.open()
+	assert.True(t, common.FileExist(forkDbDat+".corrupted"))
+	assert.Equal(t, head, c.Control.HeadBlockId())
+	assert.Equal(t, lib, c.Control.LastIrreversibleBlockNum())
+	c.ProduceBlocks(10, false)
+
+	head = c.Control.HeadBlockId()
+	lib = c.Control.LastIrreversibleBlockNum()
+	crash()
+	assert.NoError(t, os.Remove(forkDbDat))
+	assert.NoError(t, os.Remove(forkDbDat+".prev"))
+
+	// and without any, it is rebuilt from the block log and the reversible blocks database,
+	// the confirmations only the fork database had are lost
+	c.open()
+	assert.Equal(t, head, c.Control.HeadBlockId())
+	assert.True(t, c.Control.LastIrreversibleBlockNum() <= lib)
+	assert.True(t, c.Control.LastIrreversibleBlockNum() >= c.Control.Blog.ReadHead().BlockNumber())
+	c.ProduceBlocks(10, false)
+	c.close()
+}
+
 func TestReadModes(t *testing.T) {
 	c := NewForkedTester().tester
 	dan := common.N("dan")
@@ -535,3 +673,83 @@ func TestReadModes(t *testing.T) {
 	assert.Equal(t, headBlockNum-49, irreversible.Control.HeadBlockNum())
 
 }
+
+func TestHeaderOnlyValidation(t *testing.T) {
+	c := NewForkedTester().tester
+	defer c.close()
+	dan := common.N("dan")
+	sam := common.N("sam")
+	pam := common.N("pam")
+	c.ProduceBlocks(2, false)
+	accounts := []common.AccountName{dan, sam, pam}
+	c.CreateAccounts(accounts, false, true)
+	c.ProduceBlocks(1, false)
+	c.SetProducers(&accounts)
+	c.ProduceBlocks(100, false)
+
+	cfg := newConfig(chain.READONLY)
+	cfg.BlockValidationMode = chain.HEADER_ONLY
+	light := chain.NewController(cfg)
+	light.Startup()
+	defer light.Close()
+
+	for n := light.HeadBlockNum() + 1; n <= c.Control.HeadBlockNum(); n++ {
+		light.PushBlock(c.Control.FetchBlockByNumber(n), types.Complete)
+	}
+
+	// the light node follows the chain, its producer schedule and irreversibility from the headers alone
+	assert.Equal(t, c.Control.HeadBlockId(), light.HeadBlockId())
+	assert.Equal(t, c.Control.LastIrreversibleBlockNum(), light.LastIrreversibleBlockNum())
+	assert.True(t, light.LastIrreversibleBlockNum() > 1)
+	assert.Equal(t, c.Control.ActiveProducers(), light.ActiveProducers())
+	assert.Equal(t, c.Control.LastIrreversibleBlockId(), light.FetchBlockByNumber(light.LastIrreversibleBlockNum()).BlockID())
+
+	// without applying a single transaction
+	account := entity.AccountObject{Name: dan}
+	assert.Error(t, light.DataBase().Find("byName", account, &account))
+	CheckThrowException(t, &exception.BlockValidateException{}, func() {
+		light.StartBlock(types.NewBlockTimeStamp(common.Now()), 0)
+	})
+
+	// forged headers are rejected
+	c2 := newBaseTesterSecNode(true, chain.SPECULATIVE)
+	defer c2.close()
+	pushBlocks(c, c2)
+	b := c.ProduceBlock(common.Milliseconds(common.DefaultConfig.BlockIntervalMs), 0)
+	forged := *b
+	forged.ActionMRoot = forged.Previous
+	CheckThrowException(t, &exception.WrongSigningKey{}, func() {
+		light.PushBlock(&forged, types.Complete)
+	})
+	light.PushBlock(b, types.Complete)
+	assert.Equal(t, b.BlockID(), light.HeadBlockId())
+
+	// and the longest fork wins
+	c2.ProduceBlock(common.Milliseconds(common.DefaultConfig.BlockIntervalMs*2), 0)
+	c2.ProduceBlocks(1, false)
+	for n := b.BlockNumber(); n <= c2.Control.HeadBlockNum(); n++ {
+		light.PushBlock(c2.Control.FetchBlockByNumber(n), types.Complete)
+	}
+	assert.Equal(t, c2.Control.HeadBlockId(), light.HeadBlockId())
+	assert.Equal(t, c2.Control.HeadBlockId(), light.FetchBlockByNumber(light.HeadBlockNum()).BlockID())
+	assert.Nil(t, light.FetchBlockByNumber(light.HeadBlockNum()+1))
+
+	// transactions that do not match the signed merkle root are rejected
+	c2.CreateAccount(common.N("eve"), eosio, false, true)
+	b = c2.ProduceBlock(common.Milliseconds(common.DefaultConfig.BlockIntervalMs), 0)
+	assert.True(t, len(b.Transactions) > 0)
+	tampered := *b
+	tampered.Transactions = append([]types.TransactionReceipt{}, b.Transactions[1:]...)
+	CheckThrowException(t, &exception.BlockValidateException{}, func() {
+		light.PushBlock(&tampered, types.Complete)
+	})
+	light.PushBlock(b, types.Complete)
+	assert.Equal(t, b.BlockID(), light.HeadBlockId())
+
+	// the verified headers are served without the block bodies
+	header := chain_plugin.NewReadOnly(light, common.MaxMicroseconds()).GetBlockHeader(chain_plugin.GetBlockHeaderParams{BlockNumOrID: b.BlockID().String()})
+	assert.Equal(t, b.BlockID(), header.ID)
+	assert.Equal(t, b.BlockNumber(), header.BlockNum)
+	assert.Equal(t, b.SignedBlockHeader, header.Header)
+	assert.False(t, header.Irreversible)
+}
@@ -0,0 +1,16 @@
+package unittests
+
+import (
+	"os"
+	"testing"
+)
+
+//TestMain runs the tests against the in memory state backend, then against leveldb, and fails if either run fails
+func TestMain(m *testing.M) {
+	code := m.Run()
+	inMemoryState = false
+	if levelCode := m.Run(); code == 0 {
+		code = levelCode
+	}
+	os.Exit(code)
+}
@@ -1,6 +1,8 @@
 package unittests
 
 import (
+	"bytes"
+	"compress/zlib"
 	"github.com/eosspark/eos-go/chain"
 	"github.com/eosspark/eos-go/chain/types"
 	. "github.com/eosspark/eos-go/chain/types/generated_containers"
@@ -580,3 +582,80 @@ func TestTransactionTest(t *testing.T) {
 	assert.Equal(t, raw.Size(), raw2.Size())
 	bt.close()
 }
+
+func TestPackedTransactionZlib(t *testing.T) {
+	trx := types.SignedTransaction{}
+	trx.Expiration = common.NewTimePointSecTp(common.Now())
+	trx.Actions = append(trx.Actions, &types.Action{
+		Account:       eosio,
+		Name:          common.N("reqauth"),
+		Authorization: []common.PermissionLevel{{eosio, common.DefaultConfig.ActiveName}},
+		Data:          make([]byte, 512),
+	})
+	trx.ContextFreeActions = append(trx.ContextFreeActions, &types.Action{
+		Account:       eosio,
+		Name:          common.N("nonce"),
+		Authorization: []common.PermissionLevel{},
+	})
+	trx.ContextFreeData = []common.HexBytes{make([]byte, 1024), []byte("dummy")}
+
+	plain := types.NewPackedTransactionBySignedTrx(&trx, types.CompressionNone)
+	zipped := types.NewPackedTransactionBySignedTrx(&trx, types.CompressionZlib)
+	assert.True(t, len(zipped.PackedTrx) < len(plain.PackedTrx))
+	assert.True(t, len(zipped.PackedContextFreeData) < len(plain.PackedContextFreeData))
+
+	// round trip through the wire format, the id is computed over the unpacked transaction
+	data, err := rlp.EncodeToBytes(zipped)
+	assert.NoError(t, err)
+	decoded := types.PackedTransaction{}
+	assert.NoError(t, rlp.DecodeBytes(data, &decoded))
+	assert.Equal(t, types.CompressionZlib, decoded.Compression)
+	assert.Equal(t, trx.ID(), decoded.ID())
+	assert.Equal(t, trx.ID(), decoded.GetUncachedID())
+	assert.Equal(t, plain.GetRawTransaction(), decoded.GetRawTransaction())
+	assert.Equal(t, trx.ContextFreeData, decoded.GetContextFreeData())
+	assert.Equal(t, trx.ContextFreeData, decoded.GetSignedTransaction().ContextFreeData)
+
+	// switching the transaction drops the context free data and the cached transaction
+	other := trx.Transaction
+	other.DelaySec = 1
+	decoded.SetTransaction(&other, types.CompressionZlib)
+	assert.Equal(t, 0, len(decoded.PackedContextFreeData))
+	assert.Equal(t, other.ID(), decoded.ID())
+
+	// a packed_trx which inflates past the ceiling is rejected
+	var bomb bytes.Buffer
+	w := zlib.NewWriter(&bomb)
+	w.Write(make([]byte, common.DefaultConfig.MaxDecompressedTrxSize+1))
+	w.Close()
+
+	CheckThrowExceptionAndMsg(t, &exception.TxDecompressionError{}, "Exceeded maximum decompressed transaction size", func() {
+		ptrx := types.PackedTransaction{Compression: types.CompressionZlib, PackedTrx: bomb.Bytes()}
+		ptrx.GetTransaction()
+	})
+	CheckThrowExceptionAndMsg(t, &exception.TxDecompressionError{}, "Exceeded maximum decompressed transaction size", func() {
+		ptrx := types.PackedTransaction{Compression: types.CompressionZlib, PackedContextFreeData: bomb.Bytes()}
+		ptrx.GetContextFreeData()
+	})
+	CheckThrowException(t, &exception.TxDecompressionError{}, func() {
+		ptrx := types.PackedTransaction{Compression: types.CompressionZlib, PackedTrx: []byte("not zlib")}
+		ptrx.GetTransaction()
+	})
+
+	// a lower local limit applies to the transactions received from the api and the peers only
+	decoded.SetTransaction(&trx.Transaction, types.CompressionZlib)
+	size := uint64(len(decoded.GetRawTransaction()))
+	decoded.CheckDecompressedSize(size)
+	CheckThrowExceptionAndMsg(t, &exception.TxDecompressionError{}, "Exceeded maximum decompressed transaction size", func() {
+		decoded.CheckDecompressedSize(size - 1)
+	})
+	CheckThrowExceptionAndMsg(t, &exception.TxDecompressionError{}, "Exceeded maximum decompressed transaction size", func() {
+		ptrx := types.PackedTransaction{Compression: types.CompressionZlib, PackedTrx: zipped.PackedTrx, PackedContextFreeData: zipped.PackedContextFreeData}
+		ptrx.CheckDecompressedSize(uint64(len(plain.PackedContextFreeData)) - 1)
+	})
+	plain.CheckDecompressedSize(0)
+	CheckThrowException(t, &exception.UnknownTransactionCompression{}, func() {
+		ptrx := types.PackedTransaction{Compression: types.CompressionType(2), PackedTrx: plain.PackedTrx}
+		ptrx.GetTransaction()
+	})
+}
@@ -16,6 +16,8 @@ import (
 	. "github.com/eosspark/eos-go/exception/try"
 	"github.com/eosspark/eos-go/log"
 	"github.com/eosspark/eos-go/plugins/chain_interface"
+	"github.com/eosspark/eos-go/wasmgo"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wast"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"math"
@@ -147,11 +149,15 @@ func tmpdir() string {
 	return "/tmp/data/" + strconv.FormatInt(time.Now().UnixNano(), 10) + "/"
 }
 
+//inMemoryState selects the state backend of the tester chains, tests reopening a chain set cfg.InMemoryState themselves
+var inMemoryState = true
+
 func newConfig(readMode DBReadMode) *Config {
 	cfg := &Config{}
 	prefix := tmpdir()
 	cfg.BlocksDir = prefix + "blocks"
 	cfg.StateDir = prefix + "state"
+	cfg.InMemoryState = inMemoryState
 	cfg.StateSize = 1024 * 1024 * 8
 	cfg.StateGuardSize = 0
 	cfg.ReversibleCacheSize = 1024 * 1024 * 8
@@ -171,8 +177,9 @@ func newConfig(readMode DBReadMode) *Config {
 	cfg.KeyBlacklist = *NewPublicKeySet()
 	cfg.ResourceGreylist = *NewAccountNameSet()
 	cfg.TrustedProducers = *NewAccountNameSet()
+	cfg.ContractDebugAccounts = *NewAccountNameSet()
 
-	//cfg.VmType = common.DefaultConfig.DefaultWasmRuntime // TODO
+	cfg.VmType = wasmgo.Interpreter
 
 	return cfg
 }
@@ -785,8 +792,17 @@ func (t BaseTester) SetCode(account common.AccountName, wasm []uint8, signer *ec
 	t.PushTransaction(&trx, common.MaxTimePoint(), t.DefaultBilledCpuTimeUs)
 }
 
-func (t BaseTester) SetCode2(account common.AccountName, wast *byte, signer *ecc.PrivateKey) {
-	//t.SetCode(account, wastToWasm(wast), signer)
+func (t BaseTester) SetCode2(account common.AccountName, text []byte, signer *ecc.PrivateKey) {
+	code, err := wast.Compile(text)
+	EosAssert(err == nil, &FcException{}, "%s", err)
+	t.SetCode(account, code, signer)
+}
+
+// wast2wasm compiles a module in the text format, a module which does not compile fails the test
+func wast2wasm(text []byte) []byte {
+	code, err := wast.Compile(text)
+	EosAssert(err == nil, &FcException{}, "wast2wasm: %s", err)
+	return code
 }
 
 func (t BaseTester) SetAbi(account common.AccountName, abiJson []byte, signer *ecc.PrivateKey) {
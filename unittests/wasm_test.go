@@ -12,8 +12,6 @@ import (
 	"github.com/eosspark/eos-go/wasmgo"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
-	"os"
-	"os/exec"
 	"path/filepath"
 	"testing"
 )
@@ -388,20 +386,6 @@ func TestFloat64Bitwise(t *testing.T) {
 	})
 }
 
-func wast2wasm(wast []uint8) []uint8 {
-	wastTmp := "wast_tmp.wast"
-	wasmTmp := "wast_tmp.wasm"
-	os.Remove(wastTmp)
-	os.Remove(wasmTmp)
-	ioutil.WriteFile(wastTmp, wast, os.ModePerm)
-	cmd := exec.Command("./test_contracts/wat2wasm", wastTmp)
-	cmd.Run()
-	code, _ := ioutil.ReadFile(wasmTmp)
-	os.Remove(wastTmp)
-	os.Remove(wasmTmp)
-	return code
-}
-
 func TestF32F64overflow(t *testing.T) {
 	t.Run("", func(t *testing.T) {
 
@@ -1448,6 +1432,36 @@ func TestCheckBigDeserialization(t *testing.T) {
 	})
 }
 
+func TestCheckImportWhitelist(t *testing.T) {
+	t.Run("", func(t *testing.T) {
+		b := newBaseTester(true, chain.SPECULATIVE)
+		b.ProduceBlocks(2, false)
+
+		account := common.N("imports")
+		b.CreateAccounts([]common.AccountName{account}, false, true)
+		b.ProduceBlocks(1, false)
+
+		setCode := func(imports string) (code int64) {
+			wasm := wast2wasm([]byte(`(module ` + imports + `
+			 (export "apply" (func $apply))
+			 (func $apply (param $0 i64) (param $1 i64) (param $2 i64)))`))
+			try.Try(func() {
+				b.SetCode(account, wasm, nil)
+			}).Catch(func(e exception.Exception) {
+				code = e.Code()
+			}).End()
+			return
+		}
+
+		assert.Equal(t, exception.WasmImportNotAllowed{}.Code(), setCode(`(import "env" "system" (func (param i32) (result i32)))`))
+		assert.Equal(t, exception.WasmImportSignatureMismatch{}.Code(), setCode(`(import "env" "require_auth" (func (param i32)))`))
+		assert.Equal(t, exception.WasmMutableGlobalImport{}.Code(), setCode(`(import "env" "g" (global (mut i64)))`))
+		assert.Equal(t, int64(0), setCode(`(import "env" "require_auth" (func (param i64)))`))
+		b.ProduceBlocks(1, false)
+		b.close()
+	})
+}
+
 func TestCheckTableMaximum(t *testing.T) {
 	t.Run("", func(t *testing.T) {
 		b := newBaseTester(true, chain.SPECULATIVE)
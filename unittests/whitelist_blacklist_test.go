@@ -155,6 +155,7 @@ func (t *WhitelistBlacklistTester) GetDefaultChainConfiguration(path string) *ch
 	cfg.KeyBlacklist = *generated.NewPublicKeySet()
 	cfg.ResourceGreylist = *generated.NewAccountNameSet()
 	cfg.TrustedProducers = *generated.NewAccountNameSet()
+	cfg.ContractDebugAccounts = *generated.NewAccountNameSet()
 
 	return cfg
 }
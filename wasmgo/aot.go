@@ -0,0 +1,1060 @@
+package wasmgo
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/eosspark/eos-go/wasmgo/compiler"
+	"github.com/eosspark/eos-go/wasmgo/compiler/opcodes"
+	"github.com/eosspark/eos-go/wasmgo/utils"
+)
+
+// The ahead-of-time tier translates the register bytecode produced by CompileForInterpreter
+// into one Go closure per instruction when the VirtualMachine is created. Operands are decoded
+// and jump targets resolved once, so executing an instruction is a single indirect call.
+// Every closure does exactly what the matching case of Execute does, the injected checktime
+// calls and AddGas included, so both tiers compute the same results and fail the same way.
+
+// aotOp executes the instruction of a frame and returns the index of the next instruction,
+// or aotFrameChanged/aotSuspend.
+type aotOp func(vm *VirtualMachine, f *Frame) int
+
+const (
+	aotFrameChanged = -1 // a call or a return changed the current frame, which resumes at its IP
+	aotSuspend      = -2 // Execute has to return, the current frame resumes at its IP
+)
+
+// aotFixup is a jump target to resolve once the index of every instruction is known
+type aotFixup struct {
+	target *int
+	offset int
+}
+
+type aotCompiler struct {
+	code   []byte
+	ip     int
+	ops    []aotOp
+	index  map[int]int // byte offset of an instruction -> index of its closure
+	fixups []aotFixup
+}
+
+// compileAOT translates the bytecode of a function, which the FunctionCode then keeps in JITInfo.
+func compileAOT(code *compiler.InterpreterCode) (_ []aotOp, retErr error) {
+	defer utils.CatchPanic(&retErr)
+
+	c := &aotCompiler{code: code.Bytes, index: make(map[int]int)}
+	for c.ip < len(c.code) {
+		c.index[c.ip] = len(c.ops)
+		c.ops = append(c.ops, c.next())
+	}
+	for _, fix := range c.fixups {
+		i, ok := c.index[fix.offset]
+		if !ok {
+			return nil, fmt.Errorf("aot: jump to offset %d which is not an instruction", fix.offset)
+		}
+		*fix.target = i
+	}
+	return c.ops, nil
+}
+
+func (c *aotCompiler) u32() uint32 {
+	v := LE.Uint32(c.code[c.ip : c.ip+4])
+	c.ip += 4
+	return v
+}
+
+func (c *aotCompiler) u64() uint64 {
+	v := LE.Uint64(c.code[c.ip : c.ip+8])
+	c.ip += 8
+	return v
+}
+
+func (c *aotCompiler) reg() int {
+	return int(c.u32())
+}
+
+func (c *aotCompiler) regs(n int) []int {
+	regs := make([]int, n)
+	for i := range regs {
+		regs[i] = c.reg()
+	}
+	return regs
+}
+
+func (c *aotCompiler) jump(target *int) {
+	c.fixups = append(c.fixups, aotFixup{target: target, offset: c.reg()})
+}
+
+func (c *aotCompiler) target() *int {
+	target := new(int)
+	c.jump(target)
+	return target
+}
+
+func flag(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// next translates the instruction at c.ip
+func (c *aotCompiler) next() aotOp {
+	d := c.reg()
+	ins := opcodes.Opcode(c.code[c.ip])
+	c.ip++
+	n := len(c.ops) + 1
+
+	switch ins {
+	case opcodes.Nop:
+		return func(vm *VirtualMachine, f *Frame) int { return n }
+	case opcodes.Unreachable:
+		return func(vm *VirtualMachine, f *Frame) int { panic("wasm: unreachable executed") }
+	case opcodes.Select:
+		a, b, cond := c.reg(), c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			if int32(f.Regs[cond]) != 0 {
+				f.Regs[d] = f.Regs[a]
+			} else {
+				f.Regs[d] = f.Regs[b]
+			}
+			return n
+		}
+
+	case opcodes.I32Const:
+		val := int64(c.u32())
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = val; return n }
+	case opcodes.I32Add:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(int32(f.Regs[a]) + int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32Sub:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(int32(f.Regs[a]) - int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32Mul:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(int32(f.Regs[a]) * int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32DivS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			x, y := int32(f.Regs[a]), int32(f.Regs[b])
+			if y == 0 {
+				panic("integer division by zero")
+			}
+			if x == math.MinInt32 && y == -1 {
+				panic("signed integer overflow")
+			}
+			f.Regs[d] = int64(x / y)
+			return n
+		}
+	case opcodes.I32DivU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			x, y := uint32(f.Regs[a]), uint32(f.Regs[b])
+			if y == 0 {
+				panic("integer division by zero")
+			}
+			f.Regs[d] = int64(x / y)
+			return n
+		}
+	case opcodes.I32RemS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			x, y := int32(f.Regs[a]), int32(f.Regs[b])
+			if y == 0 {
+				panic("integer division by zero")
+			}
+			f.Regs[d] = int64(x % y)
+			return n
+		}
+	case opcodes.I32RemU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			x, y := uint32(f.Regs[a]), uint32(f.Regs[b])
+			if y == 0 {
+				panic("integer division by zero")
+			}
+			f.Regs[d] = int64(x % y)
+			return n
+		}
+	case opcodes.I32And:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(int32(f.Regs[a]) & int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32Or:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(int32(f.Regs[a]) | int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32Xor:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(int32(f.Regs[a]) ^ int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32Shl:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(int32(f.Regs[a]) << (uint32(f.Regs[b]) % 32))
+			return n
+		}
+	case opcodes.I32ShrS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(int32(f.Regs[a]) >> (uint32(f.Regs[b]) % 32))
+			return n
+		}
+	case opcodes.I32ShrU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(uint32(f.Regs[a]) >> (uint32(f.Regs[b]) % 32))
+			return n
+		}
+	case opcodes.I32Rotl:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(bits.RotateLeft32(uint32(f.Regs[a]), int(uint32(f.Regs[b]))))
+			return n
+		}
+	case opcodes.I32Rotr:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(bits.RotateLeft32(uint32(f.Regs[a]), -int(uint32(f.Regs[b]))))
+			return n
+		}
+	case opcodes.I32Clz:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(bits.LeadingZeros32(uint32(f.Regs[a])))
+			return n
+		}
+	case opcodes.I32Ctz:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(bits.TrailingZeros32(uint32(f.Regs[a])))
+			return n
+		}
+	case opcodes.I32PopCnt:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(bits.OnesCount32(uint32(f.Regs[a])))
+			return n
+		}
+	case opcodes.I32EqZ:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = flag(uint32(f.Regs[a]) == 0); return n }
+	case opcodes.I32Eq:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(int32(f.Regs[a]) == int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32Ne:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(int32(f.Regs[a]) != int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32LtS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(int32(f.Regs[a]) < int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32LtU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(uint32(f.Regs[a]) < uint32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32LeS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(int32(f.Regs[a]) <= int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32LeU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(uint32(f.Regs[a]) <= uint32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32GtS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(int32(f.Regs[a]) > int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32GtU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(uint32(f.Regs[a]) > uint32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32GeS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(int32(f.Regs[a]) >= int32(f.Regs[b]))
+			return n
+		}
+	case opcodes.I32GeU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(uint32(f.Regs[a]) >= uint32(f.Regs[b]))
+			return n
+		}
+
+	case opcodes.I64Const:
+		val := int64(c.u64())
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = val; return n }
+	case opcodes.I64Add:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f.Regs[a] + f.Regs[b]; return n }
+	case opcodes.I64Sub:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f.Regs[a] - f.Regs[b]; return n }
+	case opcodes.I64Mul:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f.Regs[a] * f.Regs[b]; return n }
+	case opcodes.I64DivS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			x, y := f.Regs[a], f.Regs[b]
+			if y == 0 {
+				panic("integer division by zero")
+			}
+			if x == math.MinInt64 && y == -1 {
+				panic("signed integer overflow")
+			}
+			f.Regs[d] = x / y
+			return n
+		}
+	case opcodes.I64DivU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			x, y := uint64(f.Regs[a]), uint64(f.Regs[b])
+			if y == 0 {
+				panic("integer division by zero")
+			}
+			f.Regs[d] = int64(x / y)
+			return n
+		}
+	case opcodes.I64RemS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			x, y := f.Regs[a], f.Regs[b]
+			if y == 0 {
+				panic("integer division by zero")
+			}
+			f.Regs[d] = x % y
+			return n
+		}
+	case opcodes.I64RemU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			x, y := uint64(f.Regs[a]), uint64(f.Regs[b])
+			if y == 0 {
+				panic("integer division by zero")
+			}
+			f.Regs[d] = int64(x % y)
+			return n
+		}
+	case opcodes.I64And:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f.Regs[a] & f.Regs[b]; return n }
+	case opcodes.I64Or:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f.Regs[a] | f.Regs[b]; return n }
+	case opcodes.I64Xor:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f.Regs[a] ^ f.Regs[b]; return n }
+	case opcodes.I64Shl:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f.Regs[a] << (uint64(f.Regs[b]) % 64); return n }
+	case opcodes.I64ShrS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f.Regs[a] >> (uint64(f.Regs[b]) % 64); return n }
+	case opcodes.I64ShrU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(uint64(f.Regs[a]) >> (uint64(f.Regs[b]) % 64))
+			return n
+		}
+	case opcodes.I64Rotl:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(bits.RotateLeft64(uint64(f.Regs[a]), int(uint64(f.Regs[b]))))
+			return n
+		}
+	case opcodes.I64Rotr:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(bits.RotateLeft64(uint64(f.Regs[a]), -int(uint64(f.Regs[b]))))
+			return n
+		}
+	case opcodes.I64Clz:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(bits.LeadingZeros64(uint64(f.Regs[a])))
+			return n
+		}
+	case opcodes.I64Ctz:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(bits.TrailingZeros64(uint64(f.Regs[a])))
+			return n
+		}
+	case opcodes.I64PopCnt:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(bits.OnesCount64(uint64(f.Regs[a])))
+			return n
+		}
+	case opcodes.I64EqZ:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = flag(f.Regs[a] == 0); return n }
+	case opcodes.I64Eq:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = flag(f.Regs[a] == f.Regs[b]); return n }
+	case opcodes.I64Ne:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = flag(f.Regs[a] != f.Regs[b]); return n }
+	case opcodes.I64LtS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = flag(f.Regs[a] < f.Regs[b]); return n }
+	case opcodes.I64LtU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(uint64(f.Regs[a]) < uint64(f.Regs[b]))
+			return n
+		}
+	case opcodes.I64LeS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = flag(f.Regs[a] <= f.Regs[b]); return n }
+	case opcodes.I64LeU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(uint64(f.Regs[a]) <= uint64(f.Regs[b]))
+			return n
+		}
+	case opcodes.I64GtS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = flag(f.Regs[a] > f.Regs[b]); return n }
+	case opcodes.I64GtU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(uint64(f.Regs[a]) > uint64(f.Regs[b]))
+			return n
+		}
+	case opcodes.I64GeS:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = flag(f.Regs[a] >= f.Regs[b]); return n }
+	case opcodes.I64GeU:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(uint64(f.Regs[a]) >= uint64(f.Regs[b]))
+			return n
+		}
+	}
+
+	if op := c.nextFloat(d, ins, n); op != nil {
+		return op
+	}
+	if op := c.nextMemory(d, ins, n); op != nil {
+		return op
+	}
+	return c.nextControl(d, ins, n)
+}
+
+func regF32(v int64) float32 { return math.Float32frombits(uint32(v)) }
+func regF64(v int64) float64 { return math.Float64frombits(uint64(v)) }
+func f32Reg(v float32) int64 { return int64(math.Float32bits(v)) }
+func f64Reg(v float64) int64 { return int64(math.Float64bits(v)) }
+
+// nextFloat translates floating point arithmetic and conversions, nil for any other instruction
+func (c *aotCompiler) nextFloat(d int, ins opcodes.Opcode, n int) aotOp {
+	switch ins {
+	case opcodes.F32Add:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f32Reg(regF32(f.Regs[a]) + regF32(f.Regs[b]))
+			return n
+		}
+	case opcodes.F32Sub:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f32Reg(regF32(f.Regs[a]) - regF32(f.Regs[b]))
+			return n
+		}
+	case opcodes.F32Mul:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f32Reg(regF32(f.Regs[a]) * regF32(f.Regs[b]))
+			return n
+		}
+	case opcodes.F32Div:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f32Reg(regF32(f.Regs[a]) / regF32(f.Regs[b]))
+			return n
+		}
+	case opcodes.F32Sqrt:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f32Reg(float32(math.Sqrt(float64(regF32(f.Regs[a])))))
+			return n
+		}
+	case opcodes.F32Min:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f32Reg(float32(fmin(float64(regF32(f.Regs[a])), float64(regF32(f.Regs[b])))))
+			return n
+		}
+	case opcodes.F32Max:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f32Reg(float32(fmax(float64(regF32(f.Regs[a])), float64(regF32(f.Regs[b])))))
+			return n
+		}
+	case opcodes.F32Ceil:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f32Reg(float32(math.Ceil(float64(regF32(f.Regs[a])))))
+			return n
+		}
+	case opcodes.F32Floor:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f32Reg(float32(math.Floor(float64(regF32(f.Regs[a])))))
+			return n
+		}
+	case opcodes.F32Trunc:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f32Reg(float32(math.Trunc(float64(regF32(f.Regs[a])))))
+			return n
+		}
+	case opcodes.F32Nearest:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f32Reg(float32(math.RoundToEven(float64(regF32(f.Regs[a])))))
+			return n
+		}
+	case opcodes.F32Abs:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(uint32(f.Regs[a]) &^ (1 << 31))
+			return n
+		}
+	case opcodes.F32Neg:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f32Reg(-regF32(f.Regs[a])); return n }
+	case opcodes.F32CopySign:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(uint32(f.Regs[a])&^(1<<31) | uint32(f.Regs[b])&(1<<31))
+			return n
+		}
+	case opcodes.F32Eq:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF32(f.Regs[a]) == regF32(f.Regs[b]))
+			return n
+		}
+	case opcodes.F32Ne:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF32(f.Regs[a]) != regF32(f.Regs[b]))
+			return n
+		}
+	case opcodes.F32Lt:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF32(f.Regs[a]) < regF32(f.Regs[b]))
+			return n
+		}
+	case opcodes.F32Le:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF32(f.Regs[a]) <= regF32(f.Regs[b]))
+			return n
+		}
+	case opcodes.F32Gt:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF32(f.Regs[a]) > regF32(f.Regs[b]))
+			return n
+		}
+	case opcodes.F32Ge:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF32(f.Regs[a]) >= regF32(f.Regs[b]))
+			return n
+		}
+
+	case opcodes.F64Add:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f64Reg(regF64(f.Regs[a]) + regF64(f.Regs[b]))
+			return n
+		}
+	case opcodes.F64Sub:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f64Reg(regF64(f.Regs[a]) - regF64(f.Regs[b]))
+			return n
+		}
+	case opcodes.F64Mul:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f64Reg(regF64(f.Regs[a]) * regF64(f.Regs[b]))
+			return n
+		}
+	case opcodes.F64Div:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f64Reg(regF64(f.Regs[a]) / regF64(f.Regs[b]))
+			return n
+		}
+	case opcodes.F64Sqrt:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f64Reg(math.Sqrt(regF64(f.Regs[a]))); return n }
+	case opcodes.F64Min:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f64Reg(fmin(regF64(f.Regs[a]), regF64(f.Regs[b])))
+			return n
+		}
+	case opcodes.F64Max:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f64Reg(fmax(regF64(f.Regs[a]), regF64(f.Regs[b])))
+			return n
+		}
+	case opcodes.F64Ceil:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f64Reg(math.Ceil(regF64(f.Regs[a]))); return n }
+	case opcodes.F64Floor:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f64Reg(math.Floor(regF64(f.Regs[a]))); return n }
+	case opcodes.F64Trunc:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f64Reg(math.Trunc(regF64(f.Regs[a]))); return n }
+	case opcodes.F64Nearest:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f64Reg(math.RoundToEven(regF64(f.Regs[a])))
+			return n
+		}
+	case opcodes.F64Abs:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f64Reg(math.Abs(regF64(f.Regs[a]))); return n }
+	case opcodes.F64Neg:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f64Reg(-regF64(f.Regs[a])); return n }
+	case opcodes.F64CopySign:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = f64Reg(math.Copysign(regF64(f.Regs[a]), regF64(f.Regs[b])))
+			return n
+		}
+	case opcodes.F64Eq:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF64(f.Regs[a]) == regF64(f.Regs[b]))
+			return n
+		}
+	case opcodes.F64Ne:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF64(f.Regs[a]) != regF64(f.Regs[b]))
+			return n
+		}
+	case opcodes.F64Lt:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF64(f.Regs[a]) < regF64(f.Regs[b]))
+			return n
+		}
+	case opcodes.F64Le:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF64(f.Regs[a]) <= regF64(f.Regs[b]))
+			return n
+		}
+	case opcodes.F64Gt:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF64(f.Regs[a]) > regF64(f.Regs[b]))
+			return n
+		}
+	case opcodes.F64Ge:
+		a, b := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = flag(regF64(f.Regs[a]) >= regF64(f.Regs[b]))
+			return n
+		}
+
+	case opcodes.I32WrapI64, opcodes.I64ExtendUI32:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = int64(uint32(f.Regs[a])); return n }
+	case opcodes.I64ExtendSI32:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = int64(int32(uint32(f.Regs[a]))); return n }
+	case opcodes.I32TruncSF32:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(int32(truncS(float64(regF32(f.Regs[a])), 32)))
+			return n
+		}
+	case opcodes.I32TruncUF32:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(uint32(truncU(float64(regF32(f.Regs[a])), 32)))
+			return n
+		}
+	case opcodes.I32TruncSF64:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(int32(truncS(regF64(f.Regs[a]), 32)))
+			return n
+		}
+	case opcodes.I32TruncUF64:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(uint32(truncU(regF64(f.Regs[a]), 32)))
+			return n
+		}
+	case opcodes.I64TruncSF32:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = truncS(float64(regF32(f.Regs[a])), 64); return n }
+	case opcodes.I64TruncUF32:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(truncU(float64(regF32(f.Regs[a])), 64))
+			return n
+		}
+	case opcodes.I64TruncSF64:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = truncS(regF64(f.Regs[a]), 64); return n }
+	case opcodes.I64TruncUF64:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = int64(truncU(regF64(f.Regs[a]), 64)); return n }
+	case opcodes.F32DemoteF64:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f32Reg(float32(regF64(f.Regs[a]))); return n }
+	case opcodes.F64PromoteF32:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f64Reg(float64(regF32(f.Regs[a]))); return n }
+	case opcodes.F32ConvertSI32:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f32Reg(float32(int32(f.Regs[a]))); return n }
+	case opcodes.F32ConvertUI32:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f32Reg(float32(uint32(f.Regs[a]))); return n }
+	case opcodes.F32ConvertSI64:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f32Reg(float32(f.Regs[a])); return n }
+	case opcodes.F32ConvertUI64:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f32Reg(float32(uint64(f.Regs[a]))); return n }
+	case opcodes.F64ConvertSI32:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f64Reg(float64(int32(f.Regs[a]))); return n }
+	case opcodes.F64ConvertUI32:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f64Reg(float64(uint32(f.Regs[a]))); return n }
+	case opcodes.F64ConvertSI64:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f64Reg(float64(f.Regs[a])); return n }
+	case opcodes.F64ConvertUI64:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f64Reg(float64(uint64(f.Regs[a]))); return n }
+	}
+	return nil
+}
+
+// memarg reads the alignment, which is ignored, the offset and the base register of a load or a store
+func (c *aotCompiler) memarg() (offset uint64, base int) {
+	c.u32()
+	return uint64(c.u32()), c.reg()
+}
+
+// nextMemory translates loads and stores, nil for any other instruction
+func (c *aotCompiler) nextMemory(d int, ins opcodes.Opcode, n int) aotOp {
+	switch ins {
+	case opcodes.I32Load, opcodes.I64Load32U:
+		offset, base := c.memarg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			e := int(uint64(uint32(f.Regs[base])) + offset)
+			f.Regs[d] = int64(LE.Uint32(vm.Memory[e : e+4]))
+			return n
+		}
+	case opcodes.I64Load32S:
+		offset, base := c.memarg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			e := int(uint64(uint32(f.Regs[base])) + offset)
+			f.Regs[d] = int64(int32(LE.Uint32(vm.Memory[e : e+4])))
+			return n
+		}
+	case opcodes.I64Load:
+		offset, base := c.memarg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			e := int(uint64(uint32(f.Regs[base])) + offset)
+			f.Regs[d] = int64(LE.Uint64(vm.Memory[e : e+8]))
+			return n
+		}
+	case opcodes.I32Load8S, opcodes.I64Load8S:
+		offset, base := c.memarg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(int8(vm.Memory[int(uint64(uint32(f.Regs[base]))+offset)]))
+			return n
+		}
+	case opcodes.I32Load8U, opcodes.I64Load8U:
+		offset, base := c.memarg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Regs[d] = int64(vm.Memory[int(uint64(uint32(f.Regs[base]))+offset)])
+			return n
+		}
+	case opcodes.I32Load16S, opcodes.I64Load16S:
+		offset, base := c.memarg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			e := int(uint64(uint32(f.Regs[base])) + offset)
+			f.Regs[d] = int64(int16(LE.Uint16(vm.Memory[e : e+2])))
+			return n
+		}
+	case opcodes.I32Load16U, opcodes.I64Load16U:
+		offset, base := c.memarg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			e := int(uint64(uint32(f.Regs[base])) + offset)
+			f.Regs[d] = int64(LE.Uint16(vm.Memory[e : e+2]))
+			return n
+		}
+	case opcodes.I32Store, opcodes.I64Store32:
+		offset, base := c.memarg()
+		v := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			e := int(uint64(uint32(f.Regs[base])) + offset)
+			LE.PutUint32(vm.Memory[e:e+4], uint32(f.Regs[v]))
+			return n
+		}
+	case opcodes.I64Store:
+		offset, base := c.memarg()
+		v := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			e := int(uint64(uint32(f.Regs[base])) + offset)
+			LE.PutUint64(vm.Memory[e:e+8], uint64(f.Regs[v]))
+			return n
+		}
+	case opcodes.I32Store8, opcodes.I64Store8:
+		offset, base := c.memarg()
+		v := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			vm.Memory[int(uint64(uint32(f.Regs[base]))+offset)] = byte(f.Regs[v])
+			return n
+		}
+	case opcodes.I32Store16, opcodes.I64Store16:
+		offset, base := c.memarg()
+		v := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			e := int(uint64(uint32(f.Regs[base])) + offset)
+			LE.PutUint16(vm.Memory[e:e+2], uint16(f.Regs[v]))
+			return n
+		}
+	case opcodes.CurrentMemory:
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = int64(len(vm.Memory) / DefaultPageSize); return n }
+	case opcodes.GrowMemory:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			pages := int(uint32(f.Regs[a]))
+			current := len(vm.Memory) / DefaultPageSize
+			if vm.Config.MaxMemoryPages == 0 || (current+pages >= current && current+pages <= vm.Config.MaxMemoryPages) {
+				f.Regs[d] = int64(current)
+				vm.Memory = append(vm.Memory, make([]byte, pages*DefaultPageSize)...)
+			} else {
+				f.Regs[d] = -1
+			}
+			return n
+		}
+	}
+	return nil
+}
+
+// nextControl translates branches, calls, variables and gas accounting
+func (c *aotCompiler) nextControl(d int, ins opcodes.Opcode, n int) aotOp {
+	switch ins {
+	case opcodes.Jmp:
+		target, yielded := c.target(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			vm.Yielded = f.Regs[yielded]
+			return *target
+		}
+	case opcodes.JmpEither:
+		targetA, targetB := c.target(), c.target()
+		cond, yielded := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			vm.Yielded = f.Regs[yielded]
+			if f.Regs[cond] != 0 {
+				return *targetA
+			}
+			return *targetB
+		}
+	case opcodes.JmpIf:
+		target := c.target()
+		cond, yielded := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			if f.Regs[cond] != 0 {
+				vm.Yielded = f.Regs[yielded]
+				return *target
+			}
+			return n
+		}
+	case opcodes.JmpTable:
+		targets := make([]int, c.reg())
+		for i := range targets {
+			c.jump(&targets[i])
+		}
+		defaultTarget := c.target()
+		cond, yielded := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			vm.Yielded = f.Regs[yielded]
+			if val := int(f.Regs[cond]); val >= 0 && val < len(targets) {
+				return targets[val]
+			}
+			return *defaultTarget
+		}
+	case opcodes.Phi:
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = vm.Yielded; return n }
+
+	case opcodes.ReturnValue:
+		a := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			val := f.Regs[a]
+			f.Destroy(vm)
+			vm.CurrentFrame--
+			if vm.CurrentFrame == -1 {
+				vm.Exited = true
+				vm.ReturnValue = val
+				return aotSuspend
+			}
+			caller := vm.GetCurrentFrame()
+			caller.Regs[caller.ReturnReg] = val
+			return aotFrameChanged
+		}
+	case opcodes.ReturnVoid:
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.Destroy(vm)
+			vm.CurrentFrame--
+			if vm.CurrentFrame == -1 {
+				vm.Exited = true
+				vm.ReturnValue = 0
+				return aotSuspend
+			}
+			vm.GetCurrentFrame()
+			return aotFrameChanged
+		}
+
+	case opcodes.GetLocal:
+		id := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = f.Locals[id]; return n }
+	case opcodes.SetLocal:
+		id, a := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Locals[id] = f.Regs[a]; return n }
+	case opcodes.GetGlobal:
+		id := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { f.Regs[d] = vm.Globals[id]; return n }
+	case opcodes.SetGlobal:
+		id, a := c.reg(), c.reg()
+		return func(vm *VirtualMachine, f *Frame) int { vm.Globals[id] = f.Regs[a]; return n }
+
+	case opcodes.Call:
+		functionID := c.reg()
+		args := c.regs(c.reg())
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.IP = n
+			f.ReturnReg = d
+			vm.CurrentFrame++
+			callee := vm.GetCurrentFrame()
+			callee.Init(vm, functionID, vm.FunctionCode[functionID])
+			for i, r := range args {
+				callee.Locals[i] = f.Regs[r]
+			}
+			return aotFrameChanged
+		}
+	case opcodes.CallIndirect:
+		typeID := c.reg()
+		args := c.regs(c.reg() - 1)
+		tableItem := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			functionID := vm.indirectFunction(typeID, f.Regs[tableItem])
+			code := vm.FunctionCode[functionID]
+
+			f.IP = n
+			f.ReturnReg = d
+			vm.CurrentFrame++
+			callee := vm.GetCurrentFrame()
+			callee.Init(vm, functionID, code)
+			for i, r := range args {
+				callee.Locals[i] = f.Regs[r]
+			}
+			return aotFrameChanged
+		}
+	case opcodes.InvokeImport:
+		importID := c.reg()
+		return func(vm *VirtualMachine, f *Frame) int {
+			f.IP = n
+			vm.Delegate = func() {
+				f.Regs[d] = vm.callImport(importID)
+			}
+			return aotSuspend
+		}
+
+	case opcodes.AddGas:
+		delta := c.u64()
+		return func(vm *VirtualMachine, f *Frame) int {
+			if !vm.AddAndCheckGas(delta) {
+				f.IP = n
+				vm.GasLimitExceeded = true
+				return aotSuspend
+			}
+			return n
+		}
+	case opcodes.FPDisabledError:
+		return func(vm *VirtualMachine, f *Frame) int { panic("wasm: floating point disabled") }
+	}
+	panic(fmt.Errorf("aot: unknown instruction %s", ins))
+}
+
+// executeAOT is the loop of Execute for a VirtualMachine whose FunctionCode was compiled ahead of time
+func (vm *VirtualMachine) executeAOT() {
+	frame := vm.GetCurrentFrame()
+	ops := vm.FunctionCode[frame.FunctionID].JITInfo.([]aotOp)
+	ip := frame.IP
+
+	for {
+		if vm.Profile != nil {
+			vm.Profile.Instructions[frame.FunctionID]++
+		}
+		ip = ops[ip](vm, frame)
+		if ip < 0 {
+			if ip == aotSuspend {
+				return
+			}
+			frame = &vm.CallStack[vm.CurrentFrame]
+			ops = vm.FunctionCode[frame.FunctionID].JITInfo.([]aotOp)
+			ip = frame.IP
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Command wasm-spec runs scripts of the WebAssembly spec test suite against the wasmgo interpreter.
+//
+//	wasm-spec [-v] [-aot] script.wast...
+//
+// It prints a summary per script, -v lists the result of every directive.
+// -aot runs the scripts with the ahead-of-time tier instead of the interpreter.
+// The exit status is 1 when a directive failed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/eosspark/eos-go/wasmgo"
+	"github.com/eosspark/eos-go/wasmgo/spectest"
+)
+
+func main() {
+	log.SetPrefix("wasm-spec: ")
+	log.SetFlags(0)
+
+	verbose := flag.Bool("v", false, "list the result of every directive")
+	aot := flag.Bool("aot", false, "use the ahead-of-time tier")
+	flag.Parse()
+
+	config := wasmgo.ContractVMConfig
+	config.EnableJIT = *aot
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		report, err := spectest.RunFileWith(path, config)
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+
+		failed = failed || report.Count(spectest.Fail) > 0
+		if *verbose {
+			report.WriteTo(os.Stdout)
+			continue
+		}
+		for _, res := range report.Failures() {
+			fmt.Printf("%s:%s\n", report.Name, res)
+		}
+		fmt.Println(report.Summary())
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
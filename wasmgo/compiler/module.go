@@ -12,7 +12,6 @@ import (
 	//"github.com/eosspark/eos-go/wasmgo/wagon/validate"
 	"github.com/eosspark/eos-go/wasmgo/compiler/opcodes"
 	"github.com/eosspark/eos-go/wasmgo/utils"
-	"github.com/eosspark/eos-go/wasmgo/wagon/wasm/leb128"
 )
 
 type Module struct {
@@ -49,59 +48,16 @@ func LoadModule(raw []byte) (*Module, error) {
 		return nil, err
 	}*/
 
+	/* names are debug information only, a malformed name section is ignored */
 	functionNames := make(map[int]string)
-
-	for _, sec := range m.Customs {
-		if sec.Name == "name" {
-			r := bytes.NewReader(sec.RawSection.Bytes)
-			for {
-				ty, err := leb128.ReadVarUint32(r)
-				if err != nil || ty != 1 {
-					break
-				}
-				payloadLen, err := leb128.ReadVarUint32(r)
-				if err != nil {
-					panic(err)
-				}
-				data := make([]byte, int(payloadLen))
-				n, err := r.Read(data)
-				if err != nil {
-					panic(err)
-				}
-				if n != len(data) {
-					panic("len mismatch")
-				}
-				{
-					r := bytes.NewReader(data)
-					for {
-						count, err := leb128.ReadVarUint32(r)
-						if err != nil {
-							break
-						}
-						for i := 0; i < int(count); i++ {
-							index, err := leb128.ReadVarUint32(r)
-							if err != nil {
-								panic(err)
-							}
-							nameLen, err := leb128.ReadVarUint32(r)
-							if err != nil {
-								panic(err)
-							}
-							name := make([]byte, int(nameLen))
-							n, err := r.Read(name)
-							if err != nil {
-								panic(err)
-							}
-							if n != len(name) {
-								panic("len mismatch")
-							}
-							functionNames[int(index)] = string(name)
-							//fmt.Printf("%d -> %s\n", int(index), string(name))
-						}
-					}
+	if s := m.Custom(wasm.CustomSectionName); s != nil {
+		var names wasm.NameSection
+		if err := names.UnmarshalWASM(bytes.NewReader(s.Data)); err == nil {
+			if sub, err := names.Decode(wasm.NameFunction); err == nil && sub != nil {
+				for index, name := range sub.(*wasm.FunctionNames).Names {
+					functionNames[int(index)] = name
 				}
 			}
-			//fmt.Printf("%d function names written\n", len(functionNames))
 		}
 	}
 
@@ -153,7 +109,7 @@ func (m *Module) CompileForInterpreter(gp GasPolicy) (_retCode []InterpreterCode
 
 			binary.Write(buf, binary.LittleEndian, uint32(1)) // value ID
 			binary.Write(buf, binary.LittleEndian, opcodes.InvokeImport)
-			binary.Write(buf, binary.LittleEndian, uint32(i))
+			binary.Write(buf, binary.LittleEndian, uint32(len(ret))) // function imports only, as the VM resolves them
 
 			binary.Write(buf, binary.LittleEndian, uint32(0))
 			if len(ty.ReturnTypes) != 0 {
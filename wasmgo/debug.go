@@ -0,0 +1,196 @@
+package wasmgo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/eosspark/eos-go/chain/types/generated_containers"
+	"github.com/eosspark/eos-go/common"
+	"github.com/eosspark/eos-go/exception"
+	"github.com/eosspark/eos-go/exception/try"
+	"github.com/eosspark/eos-go/log"
+	"github.com/eosspark/eos-go/wasmgo/compiler/opcodes"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+)
+
+// EnableDebug turns on the debug mode for the contracts of accounts: when an action of theirs fails, the wasm
+// call stack is attached to the exception thrown or, for a trap, to the console of the action trace.
+// If traceDir is not empty, every instruction executed for them is appended to traceDir/<account>.trace.
+// Passing no accounts turns the debug mode off.
+func (w *WasmGo) EnableDebug(accounts []common.AccountName, traceDir string) {
+	w.debugAccounts = nil
+	if len(accounts) > 0 {
+		w.debugAccounts = NewAccountNameSet(accounts...)
+	}
+	w.traceDir = traceDir
+}
+
+func (w *WasmGo) debugging(account common.AccountName) bool {
+	return w.debugAccounts != nil && w.debugAccounts.Contains(account)
+}
+
+// run executes a function of vm, in debug mode the wasm call stack of a failure is attached to the action trace
+func (w *WasmGo) run(vm *VirtualMachine, debug bool, functionID int, args ...int64) {
+	if !debug {
+		if _, err := vm.Run(functionID, args...); err != nil {
+			w.ilog.Error("vm execute err: %v", err)
+		}
+		return
+	}
+
+	try.Try(func() {
+		if _, err := vm.Run(functionID, args...); err != nil {
+			w.ilog.Error("vm execute err: %v", err)
+			w.context.ContextAppend(fmt.Sprintf("wasm trap: %v\nwasm stack trace:\n%s", err, vm.StackTrace()))
+		}
+	}).Catch(func(e exception.Exception) {
+		try.FcRethrowException(e, log.LvlError, "wasm stack trace:\n%s", vm.StackTrace())
+	}).End()
+}
+
+// startTrace appends the instructions executed for the current action to the trace file of its receiver,
+// vm runs on the interpreter until the returned stop is called as the AOT tier is not traced
+func (w *WasmGo) startTrace(vm *VirtualMachine) (stop func()) {
+	receiver := w.context.GetReceiver()
+	if err := os.MkdirAll(w.traceDir, os.ModePerm); err != nil {
+		w.ilog.Error("could not create trace dir: %v", err)
+		return func() {}
+	}
+	file, err := os.OpenFile(filepath.Join(w.traceDir, receiver.String()+".trace"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		w.ilog.Error("could not open trace file: %v", err)
+		return func() {}
+	}
+
+	jit := vm.Config.EnableJIT
+	vm.Config.EnableJIT = false
+	vm.Tracer = NewTracer(file)
+	vm.Tracer.Printf("apply %s %s::%s", receiver, w.context.GetCode(), w.context.GetAct())
+
+	return func() {
+		if err := vm.Tracer.Flush(); err != nil {
+			w.ilog.Error("could not write trace file: %v", err)
+		}
+		file.Close()
+		vm.Tracer = nil
+		vm.Config.EnableJIT = jit
+	}
+}
+
+// StackFrame is a frame of the wasm call stack.
+type StackFrame struct {
+	FunctionID int
+	Name       string
+}
+
+// StackTrace is the wasm call stack, innermost frame first.
+type StackTrace []StackFrame
+
+func (t StackTrace) String() string {
+	buf := new(bytes.Buffer)
+	for i, f := range t {
+		fmt.Fprintf(buf, "<%d> [%d] %s\n", len(t)-1-i, f.FunctionID, f.Name)
+	}
+	return buf.String()
+}
+
+// StackTrace returns the call stack of the current or the last trapped execution,
+// it is emptied by Reset.
+func (vm *VirtualMachine) StackTrace() StackTrace {
+	top := vm.CurrentFrame
+	if top >= len(vm.CallStack) {
+		top = len(vm.CallStack) - 1 // overflowed
+	}
+	trace := make(StackTrace, 0, top+1)
+	for i := top; i >= 0; i-- {
+		functionID := vm.CallStack[i].FunctionID
+		trace = append(trace, StackFrame{FunctionID: functionID, Name: vm.FunctionName(functionID)})
+	}
+	return trace
+}
+
+// FunctionName returns the name of a function from the name custom section, falling back to
+// the name it is imported or exported with.
+func (vm *VirtualMachine) FunctionName(functionID int) string {
+	if name, ok := vm.Module.FunctionNames[functionID]; ok {
+		return name
+	}
+
+	m := vm.Module.Base
+	if m.Import != nil {
+		index := 0
+		for _, entry := range m.Import.Entries {
+			if _, ok := entry.Type.(wasm.FuncImport); !ok {
+				continue
+			}
+			if index == functionID {
+				return entry.ModuleName + "." + entry.FieldName
+			}
+			index++
+		}
+	}
+	if m.Export != nil {
+		for name, entry := range m.Export.Entries {
+			if entry.Kind == wasm.ExternalFunction && int(entry.Index) == functionID {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// Tracer records every instruction executed by the interpreter together with the locals
+// of its frame, and the range and value of every memory store. The AOT tier is not traced.
+type Tracer struct {
+	w     *bufio.Writer
+	Steps int
+}
+
+// NewTracer returns a Tracer writing to w, Flush must be called once execution is done.
+func NewTracer(w io.Writer) *Tracer {
+	return &Tracer{w: bufio.NewWriter(w)}
+}
+
+// Flush writes the buffered trace to the underlying writer.
+func (t *Tracer) Flush() error {
+	return t.w.Flush()
+}
+
+// Printf adds a line of free text to the trace.
+func (t *Tracer) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(t.w, format+"\n", args...)
+}
+
+// step is called by Execute once ins of frame is decoded, frame.IP is at its operands
+func (t *Tracer) step(vm *VirtualMachine, frame *Frame, ins opcodes.Opcode) {
+	t.Steps++
+
+	fmt.Fprintf(t.w, "<%d> %s+%d %s locals=%v\n", vm.CurrentFrame, vm.functionLabel(frame.FunctionID), frame.IP-5, ins, frame.Locals)
+
+	switch ins {
+	case opcodes.I32Store8, opcodes.I64Store8:
+		t.store(frame, 1)
+	case opcodes.I32Store16, opcodes.I64Store16:
+		t.store(frame, 2)
+	case opcodes.I32Store, opcodes.I64Store32:
+		t.store(frame, 4)
+	case opcodes.I64Store:
+		t.store(frame, 8)
+	}
+}
+
+func (t *Tracer) store(frame *Frame, size int) {
+	offset := LE.Uint32(frame.Code[frame.IP+4 : frame.IP+8])
+	base := uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP+8:frame.IP+12]))])
+	value := uint64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP+12:frame.IP+16]))])
+	if size < 8 {
+		value &= 1<<uint(size*8) - 1
+	}
+
+	effective := uint64(base) + uint64(offset)
+	fmt.Fprintf(t.w, "    mem[%d:%d] = %#x\n", effective, effective+uint64(size), value)
+}
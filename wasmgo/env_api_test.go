@@ -2319,6 +2319,7 @@ func newConfig(readMode chain.DBReadMode) *chain.Config {
 	cfg.KeyBlacklist = *NewPublicKeySet()
 	cfg.ResourceGreylist = *NewAccountNameSet()
 	cfg.TrustedProducers = *NewAccountNameSet()
+	cfg.ContractDebugAccounts = *NewAccountNameSet()
 
 	//cfg.VmType = common.DefaultConfig.DefaultWasmRuntime // TODO
 
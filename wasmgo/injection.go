@@ -10,6 +10,14 @@ import (
 
 func Inject(m *wasm.Module) {
 
+	//modules without imports or exports still get checktime
+	if m.Import == nil {
+		m.Import = &wasm.SectionImports{}
+	}
+	if m.Export == nil {
+		m.Export = &wasm.SectionExports{Entries: make(map[string]wasm.ExportEntry)}
+	}
+
 	//inject checktime
 	importChecktime := wasm.ImportEntry{ModuleName: "env", FieldName: "checktime", Type: wasm.FuncImport{uint32(GetOrCreateCheckTimeSig(m))}}
 	m.Import.Entries = append([]wasm.ImportEntry{importChecktime}, m.Import.Entries[0:]...)
@@ -26,6 +34,9 @@ func Inject(m *wasm.Module) {
 
 	//shift all exported functions by 1
 	for k, export := range m.Export.Entries {
+		if export.Kind != wasm.ExternalFunction {
+			continue
+		}
 		export.Index = export.Index + 1
 		m.Export.Entries[k] = export
 	}
@@ -79,6 +90,9 @@ func InjectBody(d *disasm.Disassembly) *disasm.Disassembly {
 }
 
 func GetOrCreateCheckTimeSig(m *wasm.Module) int {
+	if m.Types == nil {
+		m.Types = &wasm.SectionTypes{}
+	}
 
 	for i, typ := range m.Types.Entries {
 		if len(typ.ParamTypes) == 0 && len(typ.ReturnTypes) == 0 {
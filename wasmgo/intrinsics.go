@@ -0,0 +1,187 @@
+package wasmgo
+
+import "github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+
+// Intrinsics is the whitelist of the functions a contract may import from the "env" module,
+// with the signatures they are registered with on the reference chain. Every entry is resolved by Resolver.
+var Intrinsics = intrinsics()
+
+func intrinsics() map[string]wasm.FunctionSig {
+	const (
+		i32 = wasm.ValueTypeI32
+		i64 = wasm.ValueTypeI64
+		f32 = wasm.ValueTypeF32
+		f64 = wasm.ValueTypeF64
+	)
+	var (
+		none   []wasm.ValueType
+		retI32 = []wasm.ValueType{i32}
+		retI64 = []wasm.ValueType{i64}
+		retF32 = []wasm.ValueType{f32}
+		retF64 = []wasm.ValueType{f64}
+	)
+	sig := func(ret []wasm.ValueType, params ...wasm.ValueType) wasm.FunctionSig {
+		return wasm.FunctionSig{ParamTypes: params, ReturnTypes: ret}
+	}
+
+	intrinsics := map[string]wasm.FunctionSig{
+		"action_data_size": sig(retI32),
+		"read_action_data": sig(retI32, i32, i32),
+		"current_receiver": sig(retI64),
+
+		"require_auth":      sig(none, i64),
+		"has_auth":          sig(retI32, i64),
+		"require_auth2":     sig(none, i64, i64),
+		"require_recipient": sig(none, i64),
+		"is_account":        sig(retI32, i64),
+
+		"__ashlti3":     sig(none, i32, i64, i64, i32),
+		"__ashrti3":     sig(none, i32, i64, i64, i32),
+		"__lshlti3":     sig(none, i32, i64, i64, i32),
+		"__lshrti3":     sig(none, i32, i64, i64, i32),
+		"__divti3":      sig(none, i32, i64, i64, i64, i64),
+		"__udivti3":     sig(none, i32, i64, i64, i64, i64),
+		"__multi3":      sig(none, i32, i64, i64, i64, i64),
+		"__modti3":      sig(none, i32, i64, i64, i64, i64),
+		"__umodti3":     sig(none, i32, i64, i64, i64, i64),
+		"__addtf3":      sig(none, i32, i64, i64, i64, i64),
+		"__subtf3":      sig(none, i32, i64, i64, i64, i64),
+		"__multf3":      sig(none, i32, i64, i64, i64, i64),
+		"__divtf3":      sig(none, i32, i64, i64, i64, i64),
+		"__negtf2":      sig(none, i32, i64, i64),
+		"__extendsftf2": sig(none, i32, f32),
+		"__extenddftf2": sig(none, i32, f64),
+		"__trunctfdf2":  sig(retF64, i64, i64),
+		"__trunctfsf2":  sig(retF32, i64, i64),
+		"__fixtfsi":     sig(retI32, i64, i64),
+		"__fixtfdi":     sig(retI64, i64, i64),
+		"__fixtfti":     sig(none, i32, i64, i64),
+		"__fixunstfsi":  sig(retI32, i64, i64),
+		"__fixunstfdi":  sig(retI64, i64, i64),
+		"__fixunstfti":  sig(none, i32, i64, i64),
+		"__fixsfti":     sig(none, i32, f32),
+		"__fixdfti":     sig(none, i32, f64),
+		"__fixunssfti":  sig(none, i32, f32),
+		"__fixunsdfti":  sig(none, i32, f64),
+		"__floatsidf":   sig(retF64, i32),
+		"__floatsitf":   sig(none, i32, i32),
+		"__floatditf":   sig(none, i32, i64),
+		"__floatunsitf": sig(none, i32, i32),
+		"__floatunditf": sig(none, i32, i64),
+		"__floattidf":   sig(retF64, i64, i64),
+		"__floatuntidf": sig(retF64, i64, i64),
+		"___cmptf2":     sig(retI32, i64, i64, i64, i64, i32),
+		"__eqtf2":       sig(retI32, i64, i64, i64, i64),
+		"__netf2":       sig(retI32, i64, i64, i64, i64),
+		"__getf2":       sig(retI32, i64, i64, i64, i64),
+		"__gttf2":       sig(retI32, i64, i64, i64, i64),
+		"__letf2":       sig(retI32, i64, i64, i64, i64),
+		"__lttf2":       sig(retI32, i64, i64, i64, i64),
+		"__cmptf2":      sig(retI32, i64, i64, i64, i64),
+		"__unordtf2":    sig(retI32, i64, i64, i64, i64),
+
+		"assert_recover_key": sig(none, i32, i32, i32, i32, i32),
+		"recover_key":        sig(retI32, i32, i32, i32, i32, i32),
+		"assert_sha256":      sig(none, i32, i32, i32),
+		"assert_sha1":        sig(none, i32, i32, i32),
+		"assert_sha512":      sig(none, i32, i32, i32),
+		"assert_ripemd160":   sig(none, i32, i32, i32),
+		"sha1":               sig(none, i32, i32, i32),
+		"sha256":             sig(none, i32, i32, i32),
+		"sha512":             sig(none, i32, i32, i32),
+		"ripemd160":          sig(none, i32, i32, i32),
+
+		"db_store_i64":      sig(retI32, i64, i64, i64, i64, i32, i32),
+		"db_update_i64":     sig(none, i32, i64, i32, i32),
+		"db_remove_i64":     sig(none, i32),
+		"db_get_i64":        sig(retI32, i32, i32, i32),
+		"db_next_i64":       sig(retI32, i32, i32),
+		"db_previous_i64":   sig(retI32, i32, i32),
+		"db_find_i64":       sig(retI32, i64, i64, i64, i64),
+		"db_lowerbound_i64": sig(retI32, i64, i64, i64, i64),
+		"db_upperbound_i64": sig(retI32, i64, i64, i64, i64),
+		"db_end_i64":        sig(retI32, i64, i64, i64),
+
+		"db_idx256_store":          sig(retI32, i64, i64, i64, i64, i32, i32),
+		"db_idx256_remove":         sig(none, i32),
+		"db_idx256_update":         sig(none, i32, i64, i32, i32),
+		"db_idx256_find_primary":   sig(retI32, i64, i64, i64, i32, i32, i64),
+		"db_idx256_find_secondary": sig(retI32, i64, i64, i64, i32, i32, i32),
+		"db_idx256_lowerbound":     sig(retI32, i64, i64, i64, i32, i32, i32),
+		"db_idx256_upperbound":     sig(retI32, i64, i64, i64, i32, i32, i32),
+		"db_idx256_end":            sig(retI32, i64, i64, i64),
+		"db_idx256_next":           sig(retI32, i32, i32),
+		"db_idx256_previous":       sig(retI32, i32, i32),
+
+		"memcpy":  sig(retI32, i32, i32, i32),
+		"memmove": sig(retI32, i32, i32, i32),
+		"memcmp":  sig(retI32, i32, i32, i32),
+		"memset":  sig(retI32, i32, i32, i32),
+
+		"prints":     sig(none, i32),
+		"prints_l":   sig(none, i32, i32),
+		"printi":     sig(none, i64),
+		"printui":    sig(none, i64),
+		"printi128":  sig(none, i32),
+		"printui128": sig(none, i32),
+		"printsf":    sig(none, f32),
+		"printdf":    sig(none, f64),
+		"printqf":    sig(none, i32),
+		"printn":     sig(none, i64),
+		"printhex":   sig(none, i32, i32),
+
+		"check_transaction_authorization": sig(retI32, i32, i32, i32, i32, i32, i32),
+		"check_permission_authorization":  sig(retI32, i64, i64, i32, i32, i32, i32, i64),
+		"get_permission_last_used":        sig(retI64, i64, i64),
+		"get_account_creation_time":       sig(retI64, i64),
+
+		"is_feature_active":                sig(retI32, i64),
+		"activate_feature":                 sig(none, i64),
+		"set_resource_limits":              sig(none, i64, i64, i64, i64),
+		"get_resource_limits":              sig(none, i64, i32, i32, i32),
+		"get_blockchain_parameters_packed": sig(retI32, i32, i32),
+		"set_blockchain_parameters_packed": sig(none, i32, i32),
+		"is_privileged":                    sig(retI32, i64),
+		"set_privileged":                   sig(none, i64, i32),
+		"set_proposed_producers":           sig(retI64, i32, i32),
+		"get_active_producers":             sig(retI32, i32, i32),
+
+		"checktime":        sig(none),
+		"current_time":     sig(retI64),
+		"publication_time": sig(retI64),
+
+		"abort":                sig(none),
+		"eosio_assert":         sig(none, i32, i32),
+		"eosio_assert_message": sig(none, i32, i32, i32),
+		"eosio_assert_code":    sig(none, i32, i64),
+		"eosio_exit":           sig(none, i32),
+
+		"send_inline":              sig(none, i32, i32),
+		"send_context_free_inline": sig(none, i32, i32),
+		"send_deferred":            sig(none, i32, i64, i32, i32, i32),
+		"cancel_deferred":          sig(retI32, i32),
+
+		"read_transaction":      sig(retI32, i32, i32),
+		"transaction_size":      sig(retI32),
+		"expiration":            sig(retI32),
+		"tapos_block_num":       sig(retI32),
+		"tapos_block_prefix":    sig(retI32),
+		"get_action":            sig(retI32, i32, i32, i32, i32),
+		"get_context_free_data": sig(retI32, i32, i32, i32),
+	}
+
+	for _, index := range []string{"idx64", "idx128", "idx_double", "idx_long_double"} {
+		prefix := "db_" + index + "_"
+		intrinsics[prefix+"store"] = sig(retI32, i64, i64, i64, i64, i32)
+		intrinsics[prefix+"remove"] = sig(none, i32)
+		intrinsics[prefix+"update"] = sig(none, i32, i64, i32)
+		intrinsics[prefix+"find_primary"] = sig(retI32, i64, i64, i64, i32, i64)
+		intrinsics[prefix+"find_secondary"] = sig(retI32, i64, i64, i64, i32, i32)
+		intrinsics[prefix+"lowerbound"] = sig(retI32, i64, i64, i64, i32, i32)
+		intrinsics[prefix+"upperbound"] = sig(retI32, i64, i64, i64, i32, i32)
+		intrinsics[prefix+"end"] = sig(retI32, i64, i64, i64)
+		intrinsics[prefix+"next"] = sig(retI32, i32, i32)
+		intrinsics[prefix+"previous"] = sig(retI32, i32, i32)
+	}
+	return intrinsics
+}
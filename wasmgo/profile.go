@@ -0,0 +1,396 @@
+package wasmgo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/eosspark/eos-go/common"
+)
+
+// StartProfiler starts profiling the contracts executed, the Profile collected before is dropped.
+func (w *WasmGo) StartProfiler() {
+	w.profile = newProfile()
+	w.profiling = true
+}
+
+// StopProfiler stops profiling, the Profile collected stays available until the profiler is started again.
+func (w *WasmGo) StopProfiler() {
+	if w.profiling {
+		w.profile.Duration = time.Since(w.profile.Start)
+		w.profiling = false
+	}
+}
+
+// Profiling reports whether the profiler runs.
+func (w *WasmGo) Profiling() bool {
+	return w.profiling
+}
+
+// Profile returns the profile being or last collected, nil if the profiler was never started.
+func (w *WasmGo) Profile() *Profile {
+	return w.profile
+}
+
+// VMProfile counts what a VirtualMachine executes, indexed by function ID.
+// Imported functions come first in the function index space, so the ID of a host function is its import index.
+type VMProfile struct {
+	Instructions []uint64        // bytecode instructions executed by each function, its callees excluded
+	HostCalls    []uint64        // calls of each host function
+	HostTime     []time.Duration // time spent in each host function
+}
+
+// NewVMProfile returns an empty profile sized for the functions of vm.
+func NewVMProfile(vm *VirtualMachine) *VMProfile {
+	return &VMProfile{
+		Instructions: make([]uint64, len(vm.FunctionCode)),
+		HostCalls:    make([]uint64, len(vm.FunctionImports)),
+		HostTime:     make([]time.Duration, len(vm.FunctionImports)),
+	}
+}
+
+// callImport calls a host function, it is timed when vm is profiled
+func (vm *VirtualMachine) callImport(importID int) int64 {
+	if vm.Profile == nil {
+		return vm.FunctionImports[importID](vm)
+	}
+
+	start := time.Now()
+	defer func() {
+		vm.Profile.HostCalls[importID]++
+		vm.Profile.HostTime[importID] += time.Since(start)
+	}()
+	return vm.FunctionImports[importID](vm)
+}
+
+// functionLabel returns FunctionName, or func[id] for an unnamed function. The labels are resolved once per VM.
+func (vm *VirtualMachine) functionLabel(functionID int) string {
+	if vm.labels == nil {
+		vm.labels = make([]string, len(vm.FunctionCode))
+		for id := range vm.labels {
+			if vm.labels[id] = vm.FunctionName(id); vm.labels[id] == "" {
+				vm.labels[id] = fmt.Sprintf("func[%d]", id)
+			}
+		}
+	}
+	return vm.labels[functionID]
+}
+
+// ActionProfile sums up the executions of the actions of a receiver and name.
+type ActionProfile struct {
+	Receiver     common.AccountName  `json:"receiver"`
+	Action       common.ActionName   `json:"action"`
+	Count        uint64              `json:"count"`        // times applied
+	Elapsed      common.Microseconds `json:"elapsed_us"`   // spent in the contract, host functions included
+	Instructions uint64              `json:"instructions"` // bytecode instructions executed
+	Functions    []FunctionProfile   `json:"functions"`    // most instructions first
+	Intrinsics   []IntrinsicProfile  `json:"intrinsics"`   // most time first
+}
+
+// FunctionProfile is the instructions executed by a contract function, its callees excluded.
+type FunctionProfile struct {
+	Name         string `json:"name"`
+	Instructions uint64 `json:"instructions"`
+}
+
+// IntrinsicProfile is the calls and the time spent in a host function.
+type IntrinsicProfile struct {
+	Name    string              `json:"name"`
+	Calls   uint64              `json:"calls"`
+	Elapsed common.Microseconds `json:"elapsed_us"`
+}
+
+type actionKey struct {
+	receiver common.AccountName
+	action   common.ActionName
+}
+
+type intrinsicStats struct {
+	calls   uint64
+	elapsed time.Duration
+}
+
+type actionStats struct {
+	count        uint64
+	elapsed      time.Duration
+	instructions map[string]uint64
+	intrinsics   map[string]*intrinsicStats
+}
+
+// Profile aggregates the VMProfile of every action applied while the profiler runs, per receiver and action.
+// It is only accessed from the main thread, as WasmGo is.
+type Profile struct {
+	Start    time.Time
+	Duration time.Duration // until the profiler was stopped, zero while it runs
+	actions  map[actionKey]*actionStats
+}
+
+func newProfile() *Profile {
+	return &Profile{Start: time.Now(), actions: make(map[actionKey]*actionStats)}
+}
+
+// begin profiles vm for the action being applied, until the returned end is called
+func (p *Profile) begin(vm *VirtualMachine, context EnvContext) (end func()) {
+	key := actionKey{context.GetReceiver(), context.GetAct()}
+	vm.Profile = NewVMProfile(vm)
+	start := time.Now()
+
+	return func() {
+		p.add(key, vm, time.Since(start))
+		vm.Profile = nil
+	}
+}
+
+func (p *Profile) add(key actionKey, vm *VirtualMachine, elapsed time.Duration) {
+	stats := p.actions[key]
+	if stats == nil {
+		stats = &actionStats{instructions: make(map[string]uint64), intrinsics: make(map[string]*intrinsicStats)}
+		p.actions[key] = stats
+	}
+	stats.count++
+	stats.elapsed += elapsed
+
+	for id, n := range vm.Profile.Instructions {
+		if n > 0 && id >= len(vm.FunctionImports) { // the stubs calling host functions are not contract code
+			stats.instructions[vm.functionLabel(id)] += n
+		}
+	}
+	for id, n := range vm.Profile.HostCalls {
+		if n > 0 {
+			name := vm.functionLabel(id)
+			intrinsic := stats.intrinsics[name]
+			if intrinsic == nil {
+				intrinsic = &intrinsicStats{}
+				stats.intrinsics[name] = intrinsic
+			}
+			intrinsic.calls += n
+			intrinsic.elapsed += vm.Profile.HostTime[id]
+		}
+	}
+}
+
+// Actions returns the profile of every action, most time first.
+func (p *Profile) Actions() []ActionProfile {
+	actions := make([]ActionProfile, 0, len(p.actions))
+	for key, stats := range p.actions {
+		a := ActionProfile{
+			Receiver:   key.receiver,
+			Action:     key.action,
+			Count:      stats.count,
+			Elapsed:    common.Microseconds(stats.elapsed / time.Microsecond),
+			Functions:  make([]FunctionProfile, 0, len(stats.instructions)),
+			Intrinsics: make([]IntrinsicProfile, 0, len(stats.intrinsics)),
+		}
+		for name, n := range stats.instructions {
+			a.Instructions += n
+			a.Functions = append(a.Functions, FunctionProfile{Name: name, Instructions: n})
+		}
+		for name, intrinsic := range stats.intrinsics {
+			a.Intrinsics = append(a.Intrinsics, IntrinsicProfile{
+				Name:    name,
+				Calls:   intrinsic.calls,
+				Elapsed: common.Microseconds(intrinsic.elapsed / time.Microsecond),
+			})
+		}
+		sort.Slice(a.Functions, func(i, j int) bool {
+			if a.Functions[i].Instructions != a.Functions[j].Instructions {
+				return a.Functions[i].Instructions > a.Functions[j].Instructions
+			}
+			return a.Functions[i].Name < a.Functions[j].Name
+		})
+		sort.Slice(a.Intrinsics, func(i, j int) bool {
+			if a.Intrinsics[i].Elapsed != a.Intrinsics[j].Elapsed {
+				return a.Intrinsics[i].Elapsed > a.Intrinsics[j].Elapsed
+			}
+			return a.Intrinsics[i].Name < a.Intrinsics[j].Name
+		})
+		actions = append(actions, a)
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		if actions[i].Elapsed != actions[j].Elapsed {
+			return actions[i].Elapsed > actions[j].Elapsed
+		}
+		if actions[i].Receiver != actions[j].Receiver {
+			return actions[i].Receiver < actions[j].Receiver
+		}
+		return actions[i].Action < actions[j].Action
+	})
+	return actions
+}
+
+// WritePprof writes the profile in the gzipped protobuf format of pprof, with two sample types:
+// the instructions executed by contract functions, and the time spent in host functions or, for the
+// receiver::action root frames themselves, in wasm code.
+func (p *Profile) WritePprof(w io.Writer) error {
+	e := newPprofEncoder()
+
+	for _, sampleType := range [][2]string{{"instructions", "count"}, {"time", "nanoseconds"}} {
+		e.message(1, func(b *pprofEncoder) {
+			b.int64(1, e.string(sampleType[0]))
+			b.int64(2, e.string(sampleType[1]))
+		})
+	}
+
+	keys := make([]actionKey, 0, len(p.actions))
+	for key := range p.actions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].receiver != keys[j].receiver {
+			return keys[i].receiver < keys[j].receiver
+		}
+		return keys[i].action < keys[j].action
+	})
+
+	for _, key := range keys {
+		stats := p.actions[key]
+		receiver := key.receiver.String()
+		root := e.location("", receiver+"::"+key.action.String())
+
+		intrinsics := make([]string, 0, len(stats.intrinsics))
+		for name := range stats.intrinsics {
+			intrinsics = append(intrinsics, name)
+		}
+		sort.Strings(intrinsics)
+		functions := make([]string, 0, len(stats.instructions))
+		for name := range stats.instructions {
+			functions = append(functions, name)
+		}
+		sort.Strings(functions)
+
+		hostTime := time.Duration(0)
+		for _, name := range intrinsics {
+			intrinsic := stats.intrinsics[name]
+			hostTime += intrinsic.elapsed
+			e.sample([]uint64{e.location("", name), root}, 0, int64(intrinsic.elapsed))
+		}
+		for _, name := range functions {
+			e.sample([]uint64{e.location(receiver, name), root}, int64(stats.instructions[name]), 0)
+		}
+		if wasmTime := stats.elapsed - hostTime; wasmTime > 0 {
+			e.sample([]uint64{root}, 0, int64(wasmTime))
+		}
+	}
+
+	e.buf.Write(e.tables.buf.Bytes())
+	for _, s := range e.stringTable {
+		e.bytes(6, []byte(s))
+	}
+	e.int64(9, p.Start.UnixNano())
+	duration := p.Duration
+	if duration == 0 {
+		duration = time.Since(p.Start)
+	}
+	e.int64(10, int64(duration))
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(e.buf.Bytes()); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// pprofEncoder writes the messages of profile.proto, see github.com/google/pprof/proto/profile.proto.
+// Samples go to buf as they are added, the locations and functions they refer to are collected in tables.
+type pprofEncoder struct {
+	buf         bytes.Buffer
+	tables      *pprofEncoder
+	strings     map[string]int64
+	stringTable []string
+	locations   map[[2]string]uint64 // by file and function name, a location has the ID of its single function
+}
+
+func newPprofEncoder() *pprofEncoder {
+	return &pprofEncoder{
+		tables:      &pprofEncoder{},
+		strings:     map[string]int64{"": 0},
+		stringTable: []string{""},
+		locations:   make(map[[2]string]uint64),
+	}
+}
+
+func (e *pprofEncoder) varint(x uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf.Write(b[:binary.PutUvarint(b[:], x)])
+}
+
+func (e *pprofEncoder) key(tag, wireType int) {
+	e.varint(uint64(tag)<<3 | uint64(wireType))
+}
+
+func (e *pprofEncoder) int64(tag int, x int64) {
+	if x != 0 {
+		e.key(tag, 0)
+		e.varint(uint64(x))
+	}
+}
+
+func (e *pprofEncoder) bytes(tag int, b []byte) {
+	e.key(tag, 2)
+	e.varint(uint64(len(b)))
+	e.buf.Write(b)
+}
+
+func (e *pprofEncoder) packed(tag int, xs []int64) {
+	sub := &pprofEncoder{}
+	for _, x := range xs {
+		sub.varint(uint64(x))
+	}
+	e.bytes(tag, sub.buf.Bytes())
+}
+
+func (e *pprofEncoder) message(tag int, write func(b *pprofEncoder)) {
+	sub := &pprofEncoder{}
+	write(sub)
+	e.bytes(tag, sub.buf.Bytes())
+}
+
+func (e *pprofEncoder) string(s string) int64 {
+	index, ok := e.strings[s]
+	if !ok {
+		index = int64(len(e.stringTable))
+		e.strings[s] = index
+		e.stringTable = append(e.stringTable, s)
+	}
+	return index
+}
+
+func (e *pprofEncoder) location(file, name string) uint64 {
+	id, ok := e.locations[[2]string{file, name}]
+	if ok {
+		return id
+	}
+	id = uint64(len(e.locations) + 1)
+	e.locations[[2]string{file, name}] = id
+
+	nameIndex, fileIndex := e.string(name), e.string(file)
+	e.tables.message(4, func(b *pprofEncoder) {
+		b.int64(1, int64(id))
+		b.message(4, func(line *pprofEncoder) {
+			line.int64(1, int64(id))
+		})
+	})
+	e.tables.message(5, func(b *pprofEncoder) {
+		b.int64(1, int64(id))
+		b.int64(2, nameIndex)
+		b.int64(3, nameIndex)
+		b.int64(4, fileIndex)
+	})
+	return id
+}
+
+func (e *pprofEncoder) sample(locations []uint64, instructions, nanoseconds int64) {
+	e.message(2, func(b *pprofEncoder) {
+		ids := make([]int64, len(locations))
+		for i, id := range locations {
+			ids[i] = int64(id)
+		}
+		b.packed(1, ids)
+		b.packed(2, []int64{instructions, nanoseconds})
+	})
+}
@@ -0,0 +1,140 @@
+package spectest_test
+
+import (
+	"testing"
+
+	"github.com/eosspark/eos-go/wasmgo"
+	"github.com/eosspark/eos-go/wasmgo/compiler"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wast"
+	"github.com/stretchr/testify/assert"
+)
+
+const fibModule = `(module
+  (import "env" "count" (func $count (param i64)))
+  (memory 1)
+  (func $fib (export "fib") (param $n i64) (result i64)
+    (if (result i64) (i64.lt_u (get_local $n) (i64.const 2))
+      (then (get_local $n))
+      (else (i64.add (call $fib (i64.sub (get_local $n) (i64.const 1)))
+                     (call $fib (i64.sub (get_local $n) (i64.const 2)))))))
+  (func (export "sum") (param $n i64) (result i64) (local $i i64) (local $s i64)
+    (block $done
+      (loop $next
+        (br_if $done (i64.ge_u (get_local $i) (get_local $n)))
+        (call $count (get_local $i))
+        (i64.store (i32.const 8) (i64.add (i64.load (i32.const 8)) (get_local $i)))
+        (set_local $s (i64.add (get_local $s) (call $fib (i64.rem_u (get_local $i) (i64.const 10)))))
+        (set_local $i (i64.add (get_local $i) (i64.const 1)))
+        (br $next)))
+    (i64.add (get_local $s) (i64.load (i32.const 8)))))
+`
+
+type countResolver struct {
+	calls []int64
+}
+
+func (r *countResolver) ResolveFunc(module, field string) wasmgo.FunctionImport {
+	switch field {
+	case "checktime":
+		return func(vm *wasmgo.VirtualMachine) int64 { return 0 }
+	case "count":
+		return func(vm *wasmgo.VirtualMachine) int64 {
+			r.calls = append(r.calls, vm.GetCurrentFrame().Locals[0])
+			return 0
+		}
+	}
+	panic("unknown import " + module + "." + field)
+}
+
+func (r *countResolver) ResolveGlobal(module, field string) int64 {
+	panic("unknown import " + module + "." + field)
+}
+
+func newFibVM(t testing.TB, aot bool, config wasmgo.VMConfig) (*wasmgo.VirtualMachine, *countResolver) {
+	code, err := wast.Compile([]byte(fibModule))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.EnableJIT = aot
+	r := &countResolver{}
+	vm, err := wasmgo.NewVirtualMachine(nil, code, config, r, &compiler.SimpleGasPolicy{GasPerInstruction: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vm, r
+}
+
+func TestAOTMatchesInterpreter(t *testing.T) {
+	type outcome struct {
+		Ret    int64
+		Err    string
+		Gas    uint64
+		Calls  []int64
+		Memory []byte
+	}
+	run := func(aot bool, config wasmgo.VMConfig, field string, arg int64) outcome {
+		vm, r := newFibVM(t, aot, config)
+		id, ok := vm.GetFunctionExport(field)
+		assert.True(t, ok)
+		ret, err := vm.Run(id, arg)
+		o := outcome{Ret: ret, Gas: vm.Gas, Calls: r.calls, Memory: vm.Memory[:16]}
+		if err != nil {
+			o.Err = err.Error()
+		}
+		return o
+	}
+
+	for _, tc := range []struct {
+		name   string
+		config wasmgo.VMConfig
+		field  string
+		arg    int64
+	}{
+		{"fib", wasmgo.ContractVMConfig, "fib", 15},
+		{"sum", wasmgo.ContractVMConfig, "sum", 50},
+		{"call depth", wasmgo.VMConfig{MaxCallStackDepth: 5, DefaultMemoryPages: 1}, "fib", 10},
+		{"gas limit", wasmgo.VMConfig{GasLimit: 5000, DefaultMemoryPages: 1}, "sum", 50},
+	} {
+		interpreted := run(false, tc.config, tc.field, tc.arg)
+		compiled := run(true, tc.config, tc.field, tc.arg)
+		assert.Equal(t, interpreted, compiled, tc.name)
+	}
+}
+
+func TestAOTReturnOnGasLimitExceeded(t *testing.T) {
+	config := wasmgo.VMConfig{GasLimit: 1000, ReturnOnGasLimitExceeded: true, DefaultMemoryPages: 1}
+	var suspended [2][2]uint64
+	for i, aot := range []bool{false, true} {
+		vm, _ := newFibVM(t, aot, config)
+		id, _ := vm.GetFunctionExport("fib")
+		vm.Ignite(id, 20)
+		for !vm.Exited && !vm.GasLimitExceeded {
+			vm.Execute()
+			if vm.Delegate != nil {
+				vm.Delegate()
+				vm.Delegate = nil
+			}
+		}
+		assert.True(t, vm.GasLimitExceeded)
+		suspended[i] = [2]uint64{vm.Gas, uint64(vm.CurrentFrame)}
+	}
+	assert.Equal(t, suspended[0], suspended[1])
+}
+
+func BenchmarkExecute(b *testing.B) {
+	for _, tier := range []struct {
+		name string
+		aot  bool
+	}{{"interpreter", false}, {"aot", true}} {
+		b.Run(tier.name, func(b *testing.B) {
+			vm, _ := newFibVM(b, tier.aot, wasmgo.ContractVMConfig)
+			id, _ := vm.GetFunctionExport("fib")
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := vm.Run(id, 20); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package spectest_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/eosspark/eos-go/wasmgo"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm/leb128"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wast"
+	"github.com/stretchr/testify/assert"
+)
+
+const trapModule = `(module
+  (import "env" "count" (func $count (param i64)))
+  (memory 1)
+  (func $inner (param $p i32)
+    (i32.store (i32.const 16) (get_local $p))
+    (if (i32.eq (get_local $p) (i32.const 1)) (then unreachable)))
+  (func (export "run") (param i32)
+    (call $count (i64.const 0))
+    (call $inner (get_local 0))))
+`
+
+// withFunctionNames appends a name custom section naming the functions of code
+func withFunctionNames(t *testing.T, code []byte, names wasm.NameMap) []byte {
+	sub := new(bytes.Buffer)
+	if err := (&wasm.FunctionNames{Names: names}).MarshalWASM(sub); err != nil {
+		t.Fatal(err)
+	}
+	payload := new(bytes.Buffer)
+	leb128.WriteVarUint32(payload, uint32(len(wasm.CustomSectionName)))
+	payload.WriteString(wasm.CustomSectionName)
+	section := wasm.NameSection{Types: map[wasm.NameType][]byte{wasm.NameFunction: sub.Bytes()}}
+	if err := section.MarshalWASM(payload); err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.NewBuffer(append([]byte(nil), code...))
+	out.WriteByte(byte(wasm.SectionIDCustom))
+	leb128.WriteVarUint32(out, uint32(payload.Len()))
+	out.Write(payload.Bytes())
+	return out.Bytes()
+}
+
+func newTrapVM(t *testing.T, aot bool) *wasmgo.VirtualMachine {
+	code, err := wast.Compile([]byte(trapModule))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code = withFunctionNames(t, code, wasm.NameMap{1: "inner"})
+	config := wasmgo.ContractVMConfig
+	config.EnableJIT = aot
+	vm, err := wasmgo.NewVirtualMachine(nil, code, config, &countResolver{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vm
+}
+
+func TestStackTrace(t *testing.T) {
+	for _, aot := range []bool{false, true} {
+		vm := newTrapVM(t, aot)
+		id, ok := vm.GetFunctionExport("run")
+		assert.True(t, ok)
+
+		_, err := vm.Run(id, 1)
+		assert.Error(t, err)
+
+		/* inner is named by the name section, the others by their import and export,
+		   function 0 is the injected checktime */
+		expected := wasmgo.StackTrace{{FunctionID: 2, Name: "inner"}, {FunctionID: 3, Name: "run"}}
+		assert.Equal(t, expected, vm.StackTrace(), "aot: %v", aot)
+		assert.Equal(t, "env.checktime", vm.FunctionName(0))
+		assert.Equal(t, "env.count", vm.FunctionName(1))
+		assert.Equal(t, "<1> [2] inner\n<0> [3] run\n", vm.StackTrace().String())
+
+		vm.Reset()
+		assert.Empty(t, vm.StackTrace())
+	}
+}
+
+func TestTracer(t *testing.T) {
+	vm := newTrapVM(t, false)
+	id, _ := vm.GetFunctionExport("run")
+
+	out := new(bytes.Buffer)
+	vm.Tracer = wasmgo.NewTracer(out)
+	_, err := vm.Run(id, 7)
+	assert.NoError(t, err)
+	assert.NoError(t, vm.Tracer.Flush())
+
+	trace := out.String()
+	assert.True(t, vm.Tracer.Steps > 0)
+	assert.Equal(t, vm.Tracer.Steps, bytes.Count(out.Bytes(), []byte(" locals=")))
+	assert.Contains(t, trace, "<0> run+0 ")
+	assert.Contains(t, trace, "<1> inner+")
+	assert.Contains(t, trace, "locals=[7]")
+	assert.Contains(t, trace, "    mem[16:20] = 0x7\n")
+}
@@ -0,0 +1,30 @@
+package spectest_test
+
+import (
+	"testing"
+
+	"github.com/eosspark/eos-go/wasmgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVMProfile(t *testing.T) {
+	profiles := make([]*wasmgo.VMProfile, 0, 2)
+	for _, aot := range []bool{false, true} {
+		vm, r := newFibVM(t, aot, wasmgo.ContractVMConfig)
+		vm.Profile = wasmgo.NewVMProfile(vm)
+		id, _ := vm.GetFunctionExport("sum")
+		_, err := vm.Run(id, 12)
+		assert.NoError(t, err)
+
+		/* function 0 is the injected checktime, then come env.count, fib and sum */
+		assert.Equal(t, "env.count", vm.FunctionName(1))
+		assert.Equal(t, uint64(len(r.calls)), vm.Profile.HostCalls[1])
+		assert.True(t, vm.Profile.HostTime[1] > 0)
+		assert.True(t, vm.Profile.Instructions[2] > vm.Profile.Instructions[3])
+		profiles = append(profiles, vm.Profile)
+	}
+
+	/* both tiers execute the same bytecode instructions */
+	assert.Equal(t, profiles[0].Instructions, profiles[1].Instructions)
+	assert.Equal(t, profiles[0].HostCalls, profiles[1].HostCalls)
+}
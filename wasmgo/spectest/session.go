@@ -0,0 +1,343 @@
+package spectest
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/eosspark/eos-go/wasmgo"
+	"github.com/eosspark/eos-go/wasmgo/utils"
+	"github.com/eosspark/eos-go/wasmgo/wagon/validate"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wast"
+)
+
+// instance is a module of a script instantiated in its own VirtualMachine
+type instance struct {
+	vm *wasmgo.VirtualMachine
+}
+
+// session is the state of a script while it runs, the modules it defined so far
+type session struct {
+	config  wasmgo.VMConfig
+	current *instance
+	named   map[string]*instance
+	skipped error // why the current module could not be instantiated, when wasmgo lacks a feature
+}
+
+// errUnsupported marks what wasmgo does not implement, directives depending on it are skipped
+type errUnsupported string
+
+func (e errUnsupported) Error() string { return string(e) }
+
+func newSession(config wasmgo.VMConfig) *session {
+	return &session{config: config, named: make(map[string]*instance)}
+}
+
+func (s *session) run(cmd *wast.Command) Result {
+	res := Result{Directive: cmd.Kind}
+	if cmd.Action != nil {
+		res.Directive += fmt.Sprintf(" %q", cmd.Action.Field)
+	}
+
+	switch cmd.Kind {
+	case "module":
+		s.current, s.skipped = nil, nil
+		inst, err := s.instantiate(cmd.Module)
+		if err != nil {
+			if _, ok := err.(errUnsupported); ok {
+				s.skipped = err
+			}
+			return status(res, err)
+		}
+		s.current = inst
+		if cmd.Module.Name != "" {
+			s.named[cmd.Module.Name] = inst
+		}
+
+	case "register":
+		res.Status, res.Msg = Skip, "linking modules is not supported by wasmgo"
+
+	case "invoke", "get":
+		_, err := s.act(cmd.Action)
+		return status(res, err)
+
+	case "assert_return":
+		results, err := s.act(cmd.Action)
+		if err != nil {
+			return status(res, err)
+		}
+		if len(results) != len(cmd.Expected) {
+			res.Status, res.Msg = Fail, fmt.Sprintf("got %v, want %v", results, cmd.Expected)
+			return res
+		}
+		for i, expect := range cmd.Expected {
+			if !expect.Match(results[i]) {
+				res.Status, res.Msg = Fail, fmt.Sprintf("got %v, want %v", results, cmd.Expected)
+			}
+		}
+
+	case "assert_trap", "assert_exhaustion":
+		var err error
+		if cmd.Module != nil {
+			_, err = s.instantiate(cmd.Module)
+		} else {
+			_, err = s.act(cmd.Action)
+		}
+		if _, ok := err.(errUnsupported); ok {
+			return status(res, err)
+		}
+		if err == nil {
+			res.Status, res.Msg = Fail, fmt.Sprintf("expected %q", cmd.Message)
+		}
+
+	case "assert_invalid", "assert_malformed", "assert_unlinkable":
+		var err error
+		switch cmd.Kind {
+		case "assert_invalid":
+			err = verify(cmd.Module)
+		case "assert_malformed":
+			_, err = decode(cmd.Module)
+		case "assert_unlinkable":
+			_, err = s.instantiate(cmd.Module)
+		}
+		if _, ok := err.(errUnsupported); ok {
+			return status(res, err)
+		}
+		if err == nil {
+			res.Status, res.Msg = Fail, fmt.Sprintf("expected %q", cmd.Message)
+		}
+	}
+	return res
+}
+
+// status is the result of a directive which has to succeed
+func status(res Result, err error) Result {
+	switch err.(type) {
+	case nil:
+	case errUnsupported:
+		res.Status, res.Msg = Skip, err.Error()
+	default:
+		res.Status, res.Msg = Fail, err.Error()
+	}
+	return res
+}
+
+// decode reads a module the way the chain does, followed by the wagon validator
+func decode(sm *wast.ScriptModule) (*wasm.Module, error) {
+	code, err := sm.Encode()
+	if err != nil {
+		return nil, err
+	}
+	var m *wasm.Module
+	err = protect(func() error {
+		var err error
+		m, err = wasm.ReadModule(bytes.NewReader(code), nil)
+		return err
+	})
+	return m, err
+}
+
+// verify decodes a module and checks it with the wagon validator
+func verify(sm *wast.ScriptModule) error {
+	m, err := decode(sm)
+	if err != nil {
+		return err
+	}
+	return protect(func() error {
+		return validate.VerifyModule(m)
+	})
+}
+
+// instantiate creates a VirtualMachine with the config of the session for a module and runs its start function.
+// Contracts always define a memory, which wasmgo insists on, so a module without one is given an empty memory.
+func (s *session) instantiate(sm *wast.ScriptModule) (*instance, error) {
+	m, err := decode(sm)
+	if err != nil {
+		return nil, err
+	}
+	if err = protect(func() error { return validate.VerifyModule(m) }); err != nil {
+		return nil, err
+	}
+	code, err := sm.Encode()
+	if err != nil {
+		return nil, err
+	}
+	if !hasMemory(m) {
+		if m, err = wasm.DecodeModule(bytes.NewReader(code)); err != nil {
+			return nil, err
+		}
+		addMemory(m)
+		buf := new(bytes.Buffer)
+		if err = wasm.EncodeModule(buf, m); err != nil {
+			return nil, err
+		}
+		code = buf.Bytes()
+	}
+
+	inst := &instance{}
+	err = protect(func() error {
+		var err error
+		inst.vm, err = wasmgo.NewVirtualMachine(nil, code, s.config, resolver{}, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if start := inst.vm.Module.Base.Start; start != nil {
+		if _, err = inst.call(int(start.Index)); err != nil {
+			return nil, err
+		}
+	}
+	return inst, nil
+}
+
+func hasMemory(m *wasm.Module) bool {
+	if m.Memory != nil && len(m.Memory.Entries) > 0 {
+		return true
+	}
+	if m.Import != nil {
+		for _, imp := range m.Import.Entries {
+			if imp.Type.Kind() == wasm.ExternalMemory {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addMemory gives m an empty memory, its section goes before the first section with a greater id
+func addMemory(m *wasm.Module) {
+	m.Memory = &wasm.SectionMemories{Entries: []wasm.Memory{{}}}
+	i := 0
+	for i < len(m.Sections) && (m.Sections[i].SectionID() == wasm.SectionIDCustom || m.Sections[i].SectionID() < wasm.SectionIDMemory) {
+		i++
+	}
+	m.Sections = append(m.Sections[:i], append([]wasm.Section{m.Memory}, m.Sections[i:]...)...)
+}
+
+// act invokes a function or reads a global of the module the action names
+func (s *session) act(a *wast.Action) ([]wast.Value, error) {
+	inst := s.current
+	if a.Module != "" {
+		inst = s.named[a.Module]
+	}
+	if inst == nil {
+		if s.skipped != nil && a.Module == "" {
+			return nil, s.skipped
+		}
+		return nil, fmt.Errorf("no module to %s %q", a.Kind, a.Field)
+	}
+
+	if a.Kind == "get" {
+		idx, ok := inst.vm.GetGlobalExport(a.Field)
+		if !ok {
+			return nil, fmt.Errorf("unknown global %q", a.Field)
+		}
+		return []wast.Value{inst.value(inst.globalType(idx), inst.vm.Globals[idx])}, nil
+	}
+
+	id, ok := inst.vm.GetFunctionExport(a.Field)
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", a.Field)
+	}
+	args := make([]int64, len(a.Args))
+	for i, arg := range a.Args {
+		args[i] = int64(arg.Bits)
+	}
+	ret, err := inst.call(id, args...)
+	if err != nil {
+		return nil, err
+	}
+	sig := inst.signature(id)
+	if len(sig.ReturnTypes) == 0 {
+		return nil, nil
+	}
+	return []wast.Value{inst.value(sig.ReturnTypes[0], ret)}, nil
+}
+
+// call runs a function, a trap leaves the VirtualMachine ready for the next call
+func (inst *instance) call(id int, args ...int64) (ret int64, err error) {
+	defer inst.vm.Reset()
+	err = protect(func() error {
+		var err error
+		ret, err = inst.vm.Run(id, args...)
+		return err
+	})
+	return ret, err
+}
+
+// signature returns the type of a function of the VirtualMachine, imported functions come first
+func (inst *instance) signature(id int) *wasm.FunctionSig {
+	m := inst.vm.Module.Base
+	for _, imp := range m.Import.Entries {
+		if fn, ok := imp.Type.(wasm.FuncImport); ok {
+			if id == 0 {
+				return &m.Types.Entries[fn.Type]
+			}
+			id--
+		}
+	}
+	return m.FunctionIndexSpace[id].Sig
+}
+
+// globalType returns the type of a global of the VirtualMachine, imported globals come first
+func (inst *instance) globalType(idx int) wasm.ValueType {
+	m := inst.vm.Module.Base
+	for _, imp := range m.Import.Entries {
+		if g, ok := imp.Type.(wasm.GlobalVarImport); ok {
+			if idx == 0 {
+				return g.Type.Type
+			}
+			idx--
+		}
+	}
+	return m.GlobalIndexSpace[idx].Type.Type
+}
+
+// value reads a slot of the interpreter, which holds i32 and f32 in its low 32 bits
+func (inst *instance) value(t wasm.ValueType, v int64) wast.Value {
+	bits := uint64(v)
+	if t == wasm.ValueTypeI32 || t == wasm.ValueTypeF32 {
+		bits = uint64(uint32(v))
+	}
+	return wast.Value{Type: t, Bits: bits}
+}
+
+// protect turns a panic of wagon or of the interpreter into an error
+func protect(f func() error) (err error) {
+	defer utils.CatchPanic(&err)
+	return f()
+}
+
+// resolver provides the imports of the spectest module and the checktime wasmgo injects
+type resolver struct{}
+
+func (resolver) ResolveFunc(module, field string) wasmgo.FunctionImport {
+	switch {
+	case module == "env" && field == "checktime":
+		return func(vm *wasmgo.VirtualMachine) int64 { return 0 }
+	case module == "spectest" && (field == "print" || len(field) > 6 && field[:6] == "print_"):
+		return func(vm *wasmgo.VirtualMachine) int64 { return 0 }
+	case module != "spectest":
+		panic(errUnsupported(fmt.Sprintf("linking modules is not supported by wasmgo, import %s.%s", module, field)))
+	}
+	panic(fmt.Errorf("unknown import %s.%s", module, field))
+}
+
+func (resolver) ResolveGlobal(module, field string) int64 {
+	if module == "spectest" {
+		switch field {
+		case "global_i32", "global_i64":
+			return 666
+		case "global_f32":
+			return int64(math.Float32bits(666))
+		case "global_f64":
+			return int64(math.Float64bits(666))
+		}
+	}
+	if module != "spectest" {
+		panic(errUnsupported(fmt.Sprintf("linking modules is not supported by wasmgo, import %s.%s", module, field)))
+	}
+	panic(fmt.Errorf("unknown import %s.%s", module, field))
+}
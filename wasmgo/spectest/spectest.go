@@ -0,0 +1,131 @@
+// Package spectest runs the scripts of the WebAssembly spec test suite against the wasmgo interpreter.
+//
+// Modules are read with wagon, checked with its validator and executed by wasmgo.VirtualMachine
+// with the configuration contracts run with, or any other VMConfig. Every directive of a script gives a Result.
+package spectest
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/eosspark/eos-go/wasmgo"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wast"
+)
+
+// Status is the outcome of a directive.
+type Status uint8
+
+const (
+	Pass Status = iota
+	Fail
+	Skip // the directive needs a feature wasmgo does not have, such as linking modules
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "PASS"
+	case Fail:
+		return "FAIL"
+	}
+	return "SKIP"
+}
+
+// Result is the outcome of one directive of a script.
+type Result struct {
+	Line      int
+	Directive string // the directive with its action, such as `assert_return "add"`
+	Status    Status
+	Msg       string // why it failed or was skipped
+}
+
+func (r Result) String() string {
+	if r.Msg == "" {
+		return fmt.Sprintf("%s %d: %s", r.Status, r.Line, r.Directive)
+	}
+	return fmt.Sprintf("%s %d: %s: %s", r.Status, r.Line, r.Directive, r.Msg)
+}
+
+// Report holds the results of a script in the order of its directives.
+type Report struct {
+	Name    string
+	Results []Result
+}
+
+// Count returns the number of results with status s.
+func (r *Report) Count(s Status) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == s {
+			n++
+		}
+	}
+	return n
+}
+
+// Failures returns the failed results.
+func (r *Report) Failures() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.Status == Fail {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Summary returns a line counting the results by status.
+func (r *Report) Summary() string {
+	return fmt.Sprintf("%s: %d passed, %d failed, %d skipped", r.Name, r.Count(Pass), r.Count(Fail), r.Count(Skip))
+}
+
+// WriteTo writes every result, one per line, followed by the summary.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, res := range r.Results {
+		n, err := fmt.Fprintf(w, "%s:%s\n", r.Name, res)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	n, err := fmt.Fprintln(w, r.Summary())
+	return total + int64(n), err
+}
+
+// RunScript runs the directives of a script, an error is only returned when the script cannot be read.
+func RunScript(name string, src []byte) (*Report, error) {
+	return RunScriptWith(name, src, wasmgo.ContractVMConfig)
+}
+
+// RunScriptWith runs a script in VirtualMachines created with config, such as one enabling the ahead-of-time tier.
+func RunScriptWith(name string, src []byte, config wasmgo.VMConfig) (*Report, error) {
+	cmds, err := wast.ParseScript(src)
+	if err != nil {
+		return nil, err
+	}
+	s := newSession(config)
+	report := &Report{Name: name}
+	for _, cmd := range cmds {
+		res := s.run(cmd)
+		res.Line = cmd.Line
+		report.Results = append(report.Results, res)
+	}
+	return report, nil
+}
+
+// RunFile runs the script at path.
+func RunFile(path string) (*Report, error) {
+	return RunFileWith(path, wasmgo.ContractVMConfig)
+}
+
+// RunFileWith runs the script at path in VirtualMachines created with config.
+func RunFileWith(path string, config wasmgo.VMConfig) (*Report, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return RunScriptWith(filepath.Base(path), src, config)
+}
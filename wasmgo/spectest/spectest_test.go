@@ -0,0 +1,125 @@
+package spectest_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/eosspark/eos-go/wasmgo"
+	"github.com/eosspark/eos-go/wasmgo/spectest"
+	"github.com/stretchr/testify/assert"
+)
+
+// knownFailures are the directives wasmgo is known to get wrong.
+// A fix shows up as an unexpected pass, the entry has to be removed then.
+var knownFailures = []struct {
+	script string
+	lines  []int // nil for every directive of the script
+	reason string
+}{}
+
+func isKnownFailure(script string, line int) bool {
+	for _, known := range knownFailures {
+		if known.script != script {
+			continue
+		}
+		if known.lines == nil {
+			return true
+		}
+		for _, l := range known.lines {
+			if l == line {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestSpec(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.wast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			report, err := spectest.RunFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if testing.Verbose() {
+				buf := new(bytes.Buffer)
+				report.WriteTo(buf)
+				t.Log("\n" + buf.String())
+			}
+
+			for _, res := range report.Results {
+				known := isKnownFailure(report.Name, res.Line)
+				switch {
+				case res.Status == spectest.Fail && !known:
+					t.Errorf("%s", res)
+				case res.Status == spectest.Pass && known && res.Directive != "module":
+					t.Errorf("%s: listed in knownFailures but passes", res)
+				}
+			}
+		})
+	}
+}
+
+// TestSpecAOT checks the ahead-of-time tier gives the results of the interpreter, known failures included
+func TestSpecAOT(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.wast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	aot := wasmgo.ContractVMConfig
+	aot.EnableJIT = true
+
+	for _, file := range files {
+		interpreted, err := spectest.RunFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compiled, err := spectest.RunFileWith(file, aot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, interpreted.Results, compiled.Results, file)
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	report, err := spectest.RunScript("test", []byte(`
+(module
+  (func (export "add") (param i32 i32) (result i32) (i32.add (get_local 0) (get_local 1)))
+  (func (export "div") (param i32 i32) (result i32) (i32.div_u (get_local 0) (get_local 1)))
+)
+(assert_return (invoke "add" (i32.const 1) (i32.const 2)) (i32.const 3))
+(assert_return (invoke "add" (i32.const 1) (i32.const 2)) (i32.const 4))
+(assert_trap (invoke "div" (i32.const 1) (i32.const 0)) "integer divide by zero")
+(assert_trap (invoke "div" (i32.const 1) (i32.const 1)) "integer divide by zero")
+(assert_malformed (module quote "(func") "unexpected end")
+(assert_invalid (module (func (result i32) (i64.const 1) (i32.add (i32.const 1)))) "type mismatch")
+(register "M")
+(assert_return (invoke "missing"))
+`))
+	assert.NoError(t, err)
+
+	expected := []spectest.Result{
+		{Line: 2, Directive: "module", Status: spectest.Pass},
+		{Line: 6, Directive: `assert_return "add"`, Status: spectest.Pass},
+		{Line: 7, Directive: `assert_return "add"`, Status: spectest.Fail, Msg: "got [i32:3], want [i32:4]"},
+		{Line: 8, Directive: `assert_trap "div"`, Status: spectest.Pass},
+		{Line: 9, Directive: `assert_trap "div"`, Status: spectest.Fail, Msg: `expected "integer divide by zero"`},
+		{Line: 10, Directive: "assert_malformed", Status: spectest.Pass},
+		{Line: 11, Directive: "assert_invalid", Status: spectest.Pass},
+		{Line: 12, Directive: "register", Status: spectest.Skip, Msg: "linking modules is not supported by wasmgo"},
+		{Line: 13, Directive: `assert_return "missing"`, Status: spectest.Fail, Msg: `unknown function "missing"`},
+	}
+	assert.Equal(t, expected, report.Results)
+	assert.Equal(t, "test: 5 passed, 3 failed, 1 skipped", report.Summary())
+	assert.Equal(t, 3, len(report.Failures()))
+
+	_, err = spectest.RunScript("test", []byte(`(assert_return (invoke "f") (i32.const))`))
+	assert.EqualError(t, err, "wast: 1:29: expected a constant")
+}
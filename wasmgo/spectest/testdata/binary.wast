@@ -0,0 +1,25 @@
+(module binary "\00asm" "\01\00\00\00")
+(module binary
+  "\00asm" "\01\00\00\00"
+  "\01\05\01\60\00\01\7f"          ;; type section: () -> i32
+  "\03\02\01\00"                   ;; function section
+  "\07\05\01\01\66\00\00"          ;; export "f"
+  "\0a\06\01\04\00\41\2a\0b"       ;; code: i32.const 42
+)
+(assert_return (invoke "f") (i32.const 42))
+
+(assert_malformed (module binary "") "unexpected end")
+(assert_malformed (module binary "\01") "unexpected end")
+(assert_malformed (module binary "\00as") "unexpected end")
+(assert_malformed (module binary "asm\00") "magic header not detected")
+(assert_malformed (module binary "\00ASM" "\01\00\00\00") "magic header not detected")
+(assert_malformed (module binary "\00asm" "\01") "unexpected end")
+(assert_malformed (module binary "\00asm" "\01\00\00\00" "\0c\00") "malformed section id")
+(assert_malformed (module binary "\00asm" "\01\00\00\00" "\01\05\01\60\00\01") "unexpected end")
+
+(assert_malformed (module quote "(func (i32.const 1)") "unexpected end")
+(assert_malformed (module quote "(func (i32.const 0x))") "unknown operator")
+(assert_malformed (module quote "(func (drop (i32.const 4294967296)))") "constant out of range")
+(assert_malformed (module quote "(func (drop (i64.const -9223372036854775809)))") "constant out of range")
+(assert_malformed (module quote "(func (i32.frobnicate))") "unknown operator")
+(assert_malformed (module quote "(func $f) (func $f)") "duplicate func")
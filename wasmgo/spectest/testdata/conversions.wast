@@ -0,0 +1,20 @@
+(module
+  (func (export "i32.trunc_u_f32") (param f32) (result i32) (i32.trunc_u/f32 (get_local 0)))
+  (func (export "i32.trunc_u_f64") (param f64) (result i32) (i32.trunc_u/f64 (get_local 0)))
+  (func (export "i64.trunc_s_f64") (param f64) (result i64) (i64.trunc_s/f64 (get_local 0)))
+  (func (export "i64.trunc_u_f64") (param f64) (result i64) (i64.trunc_u/f64 (get_local 0)))
+  (func (export "f64.convert_s_i32") (param i32) (result f64) (f64.convert_s/i32 (get_local 0)))
+  (func (export "f64.convert_u_i32") (param i32) (result f64) (f64.convert_u/i32 (get_local 0)))
+)
+
+(assert_return (invoke "i32.trunc_u_f32" (f32.const 0x1.fffffep+31)) (i32.const -256))
+(assert_return (invoke "i32.trunc_u_f64" (f64.const 4294967295.0)) (i32.const -1))
+(assert_return (invoke "i32.trunc_u_f64" (f64.const -0x1.ccccccccccccdp-1)) (i32.const 0))
+(assert_trap (invoke "i32.trunc_u_f64" (f64.const 4294967296.0)) "integer overflow")
+(assert_trap (invoke "i32.trunc_u_f64" (f64.const -1.0)) "integer overflow")
+(assert_trap (invoke "i64.trunc_s_f64" (f64.const 9223372036854775808.0)) "integer overflow")
+(assert_return (invoke "i64.trunc_u_f64" (f64.const 1e19)) (i64.const -8446744073709551616))
+(assert_trap (invoke "i64.trunc_u_f64" (f64.const nan)) "invalid conversion to integer")
+
+(assert_return (invoke "f64.convert_s_i32" (i32.const -2147483648)) (f64.const -2147483648))
+(assert_return (invoke "f64.convert_u_i32" (i32.const -1)) (f64.const 4294967295))
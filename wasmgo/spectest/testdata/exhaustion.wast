@@ -0,0 +1,16 @@
+(module
+  (func $runaway (export "runaway") (call $runaway))
+  (func $mutual-runaway1 (export "mutual-runaway") (call $mutual-runaway2))
+  (func $mutual-runaway2 (call $mutual-runaway1))
+  (func $fac (export "fac") (param i64) (result i64)
+    (if (result i64) (i64.eqz (get_local 0))
+      (then (i64.const 1))
+      (else (i64.mul (get_local 0) (call $fac (i64.sub (get_local 0) (i64.const 1)))))
+    )
+  )
+)
+
+(assert_exhaustion (invoke "runaway") "call stack exhausted")
+(assert_exhaustion (invoke "mutual-runaway") "call stack exhausted")
+(assert_return (invoke "fac" (i64.const 20)) (i64.const 2432902008176640000))
+(assert_exhaustion (invoke "fac" (i64.const 100000)) "call stack exhausted")
@@ -0,0 +1,24 @@
+(module
+  (func (export "f32.abs") (param f32) (result f32) (f32.abs (get_local 0)))
+  (func (export "f32.copysign") (param f32 f32) (result f32) (f32.copysign (get_local 0) (get_local 1)))
+  (func (export "f32.min") (param f32 f32) (result f32) (f32.min (get_local 0) (get_local 1)))
+  (func (export "f32.max") (param f32 f32) (result f32) (f32.max (get_local 0) (get_local 1)))
+  (func (export "f64.abs") (param f64) (result f64) (f64.abs (get_local 0)))
+  (func (export "f64.min") (param f64 f64) (result f64) (f64.min (get_local 0) (get_local 1)))
+  (func (export "f64.max") (param f64 f64) (result f64) (f64.max (get_local 0) (get_local 1)))
+)
+
+;; abs and copysign are bitwise, NaN payloads go through them unchanged
+(assert_return (invoke "f32.abs" (f32.const nan:0x0f1e2)) (f32.const nan:0x0f1e2))
+(assert_return (invoke "f32.abs" (f32.const -nan:0x0f1e2)) (f32.const nan:0x0f1e2))
+(assert_return (invoke "f32.copysign" (f32.const nan:0x0f1e2) (f32.const -0x0p+0)) (f32.const -nan:0x0f1e2))
+(assert_return (invoke "f32.copysign" (f32.const -nan:0x0f1e2) (f32.const 0x0p+0)) (f32.const nan:0x0f1e2))
+(assert_return (invoke "f64.abs" (f64.const -nan:0x0f1e27a6b)) (f64.const nan:0x0f1e27a6b))
+
+;; min and max of NaN are NaN, infinities included
+(assert_return (invoke "f32.min" (f32.const -inf) (f32.const nan)) (f32.const nan:canonical))
+(assert_return (invoke "f32.max" (f32.const inf) (f32.const nan)) (f32.const nan:canonical))
+(assert_return (invoke "f64.min" (f64.const nan) (f64.const -inf)) (f64.const nan:canonical))
+(assert_return (invoke "f64.max" (f64.const nan) (f64.const inf)) (f64.const nan:canonical))
+(assert_return (invoke "f32.min" (f32.const -0x0p+0) (f32.const 0x0p+0)) (f32.const -0x0p+0))
+(assert_return (invoke "f64.max" (f64.const -0x0p+0) (f64.const 0x0p+0)) (f64.const 0x0p+0))
@@ -0,0 +1,14 @@
+(assert_invalid (module (func (result i32))) "type mismatch")
+(assert_invalid (module (func (result i32) (i64.const 0))) "type mismatch")
+(assert_invalid (module (func (drop (i32.add (i32.const 0))))) "type mismatch")
+(assert_invalid (module (func (drop (i32.add (i32.const 0) (f32.const 0))))) "type mismatch")
+(assert_invalid
+  (module (func (result i32) (select (i32.const 1) (i64.const 1) (i32.const 1))))
+  "type mismatch"
+)
+(assert_invalid (module (func (local i32) (drop (get_local 1)))) "unknown local")
+(assert_invalid (module (func (br 1))) "unknown label")
+(assert_invalid (module (func (block (br_if 2 (i32.const 1))))) "unknown label")
+(assert_invalid (module (func (result i32) (block (result i32) (i32.const 1)) (drop))) "type mismatch")
+(assert_invalid (module (func (if (i64.const 1) (then)))) "type mismatch")
+(assert_invalid (module (func (drop (i32.load (i32.const 0))))) "unknown memory")
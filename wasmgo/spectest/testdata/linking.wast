@@ -0,0 +1,24 @@
+(module $M
+  (global (export "glob") i32 (i32.const 42))
+  (func (export "f") (result i32) (i32.const 1))
+)
+(register "M" $M)
+(assert_return (invoke $M "f") (i32.const 1))
+(assert_return (get $M "glob") (i32.const 42))
+
+(module
+  (import "M" "f" (func $f (result i32)))
+  (func (export "g") (result i32) (call $f))
+)
+(assert_return (invoke "g") (i32.const 1))
+
+(module
+  (import "spectest" "global_i32" (global i32))
+  (import "spectest" "print_i32" (func $print (param i32)))
+  (global (export "copy") i32 (get_global 0))
+  (func (export "print") (call $print (i32.const 7)))
+)
+(assert_return (get "copy") (i32.const 666))
+(assert_return (invoke "print"))
+
+(assert_unlinkable (module (import "spectest" "unknown" (func))) "unknown import")
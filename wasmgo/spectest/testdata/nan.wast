@@ -0,0 +1,27 @@
+(module
+  (func (export "f32.add") (param f32 f32) (result f32) (f32.add (get_local 0) (get_local 1)))
+  (func (export "f32.div") (param f32 f32) (result f32) (f32.div (get_local 0) (get_local 1)))
+  (func (export "f32.sqrt") (param f32) (result f32) (f32.sqrt (get_local 0)))
+  (func (export "f32.neg") (param f32) (result f32) (f32.neg (get_local 0)))
+  (func (export "f64.add") (param f64 f64) (result f64) (f64.add (get_local 0) (get_local 1)))
+  (func (export "f64.mul") (param f64 f64) (result f64) (f64.mul (get_local 0) (get_local 1)))
+  (func (export "f64.sqrt") (param f64) (result f64) (f64.sqrt (get_local 0)))
+  (func (export "i32.div_s") (param i32 i32) (result i32) (i32.div_s (get_local 0) (get_local 1)))
+  (func (export "i32.trunc_s/f32") (param f32) (result i32) (i32.trunc_s/f32 (get_local 0)))
+)
+
+(assert_return (invoke "f32.add" (f32.const 0x1p-149) (f32.const 0x1p-149)) (f32.const 0x1p-148))
+(assert_return (invoke "f32.add" (f32.const 0x1.fffffep127) (f32.const 0x1.fffffep127)) (f32.const inf))
+(assert_return (invoke "f32.div" (f32.const 0) (f32.const 0)) (f32.const nan:canonical))
+(assert_return (invoke "f32.add" (f32.const inf) (f32.const -inf)) (f32.const nan:canonical))
+(assert_return_canonical_nan (invoke "f32.sqrt" (f32.const -1)))
+(assert_return_arithmetic_nan (invoke "f32.add" (f32.const nan:0x200000) (f32.const 1)))
+(assert_return (invoke "f32.neg" (f32.const nan)) (f32.const -nan))
+(assert_return (invoke "f32.neg" (f32.const -0x0p+0)) (f32.const 0x0p+0))
+(assert_return (invoke "f64.add" (f64.const 0x1p-1074) (f64.const 0x1p-1074)) (f64.const 0x1p-1073))
+(assert_return (invoke "f64.mul" (f64.const inf) (f64.const 0)) (f64.const nan:canonical))
+(assert_return_canonical_nan (invoke "f64.sqrt" (f64.const -0x1p-1022)))
+(assert_return_arithmetic_nan (invoke "f64.add" (f64.const nan:0x4000000000000) (f64.const 1)))
+(assert_trap (invoke "i32.div_s" (i32.const 0x80000000) (i32.const -1)) "integer overflow")
+(assert_trap (invoke "i32.trunc_s/f32" (f32.const nan)) "invalid conversion to integer")
+(assert_trap (invoke "i32.trunc_s/f32" (f32.const 0x1p31)) "integer overflow")
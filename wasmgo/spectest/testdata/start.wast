@@ -0,0 +1,20 @@
+(module
+  (memory 1)
+  (func $main (i32.store8 (i32.const 0) (i32.const 65)))
+  (start $main)
+  (func (export "get") (result i32) (i32.load8_u (i32.const 0)))
+)
+(assert_return (invoke "get") (i32.const 65))
+
+(module
+  (memory (data "A"))
+  (func $inc (i32.store8 (i32.const 0) (i32.add (i32.load8_u (i32.const 0)) (i32.const 1))))
+  (func (export "inc") (call $inc))
+  (func (export "get") (result i32) (i32.load8_u (i32.const 0)))
+  (start $inc)
+)
+(assert_return (invoke "get") (i32.const 66))
+(invoke "inc")
+(assert_return (invoke "get") (i32.const 67))
+
+(assert_trap (module (func $main (unreachable)) (start $main)) "unreachable")
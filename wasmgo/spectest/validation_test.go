@@ -0,0 +1,125 @@
+package spectest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/eosspark/eos-go/exception"
+	"github.com/eosspark/eos-go/exception/try"
+	"github.com/eosspark/eos-go/wasmgo"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wast"
+	"github.com/stretchr/testify/assert"
+)
+
+const applyFunc = `(func (export "apply") (param i64 i64 i64))`
+
+// validate returns the exception thrown validating the module made of fields, nil if it is valid
+func validate(t *testing.T, disableNested bool, fields ...string) (thrown exception.Exception) {
+	code, err := wast.Compile([]byte("(module " + strings.Join(fields, "\n") + ")"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	try.Try(func() {
+		assert.NoError(t, wasmgo.Validate(disableNested, code))
+	}).Catch(func(e exception.Exception) {
+		thrown = e
+	}).End()
+	return
+}
+
+func assertRejected(t *testing.T, expected exception.Exception, thrown exception.Exception) {
+	if assert.NotNil(t, thrown, "expected %s", expected.Name()) {
+		assert.Equal(t, expected.Code(), thrown.Code(), thrown.DetailMessage())
+	}
+}
+
+func TestIntrinsicsResolvable(t *testing.T) {
+	resolver := new(wasmgo.Resolver)
+	for name := range wasmgo.Intrinsics {
+		assert.NotNil(t, resolver.ResolveFunc("env", name), name)
+	}
+}
+
+func TestValidateImports(t *testing.T) {
+	assert.Nil(t, validate(t, false, `(import "env" "prints" (func (param i32)))`,
+		`(import "env" "current_receiver" (func (result i64)))`, applyFunc))
+
+	assertRejected(t, &exception.WasmImportNotAllowed{},
+		validate(t, false, `(import "env" "fopen" (func (param i32 i32) (result i32)))`, applyFunc))
+	assertRejected(t, &exception.WasmImportNotAllowed{},
+		validate(t, false, `(import "libc" "prints" (func (param i32)))`, applyFunc))
+	assertRejected(t, &exception.WasmImportNotAllowed{},
+		validate(t, false, `(import "env" "memory" (memory 1))`, applyFunc))
+
+	assertRejected(t, &exception.WasmImportSignatureMismatch{},
+		validate(t, false, `(import "env" "prints" (func (param i64)))`, applyFunc))
+	assertRejected(t, &exception.WasmImportSignatureMismatch{},
+		validate(t, false, `(import "env" "current_receiver" (func (result i32)))`, applyFunc))
+}
+
+func TestValidateMutableGlobalImport(t *testing.T) {
+	assertRejected(t, &exception.WasmMutableGlobalImport{},
+		validate(t, false, `(import "env" "counter" (global (mut i32)))`, applyFunc))
+
+	/* immutable globals are not intrinsics either */
+	assertRejected(t, &exception.WasmImportNotAllowed{},
+		validate(t, false, `(import "env" "counter" (global i32))`, applyFunc))
+}
+
+func TestValidateFunctionCount(t *testing.T) {
+	functions := func(n int) []string {
+		fields := []string{applyFunc}
+		for i := 1; i < n; i++ {
+			fields = append(fields, "(func)")
+		}
+		return fields
+	}
+
+	assert.Nil(t, validate(t, false, functions(wasmgo.MaximumSectionElements-1)...))
+	/* refused by the decoder already, like the reference serialization does */
+	assertRejected(t, &exception.WasmSerializationError{}, validate(t, false, functions(wasmgo.MaximumSectionElements)...))
+}
+
+func TestValidateLocalSets(t *testing.T) {
+	function := func(n int) string {
+		/* alternate the types so that every local is a declaration of its own */
+		locals := make([]string, n)
+		for i := range locals {
+			locals[i] = []string{"(local i32)", "(local f32)"}[i%2]
+		}
+		return "(func " + strings.Join(locals, " ") + ")"
+	}
+
+	assert.Nil(t, validate(t, false, applyFunc, function(wasmgo.MaximumLocalSets)))
+	assertRejected(t, &exception.WasmTooManyLocals{}, validate(t, false, applyFunc, function(wasmgo.MaximumLocalSets+1)))
+}
+
+func TestValidateNestedStructures(t *testing.T) {
+	function := func(depth int) string {
+		return "(func " + strings.Repeat("(block ", depth/2) + strings.Repeat("(loop ", depth-depth/2) +
+			strings.Repeat(")", depth) + ")"
+	}
+
+	assert.Nil(t, validate(t, false, applyFunc, function(wasmgo.MaximumNestedStructures-1)))
+	assertRejected(t, &exception.WasmNestedTooDeep{}, validate(t, false, applyFunc, function(wasmgo.MaximumNestedStructures)))
+
+	/* the depth is per function */
+	assert.Nil(t, validate(t, false, applyFunc, function(wasmgo.MaximumNestedStructures-1), function(wasmgo.MaximumNestedStructures-1)))
+
+	/* only checked when producing a block */
+	assert.Nil(t, validate(t, true, applyFunc, function(wasmgo.MaximumNestedStructures)))
+}
+
+func TestValidateBrTable(t *testing.T) {
+	function := func(targets int) string {
+		return fmt.Sprintf("(func (block (br_table %s0 (i32.const 0))))", strings.Repeat("0 ", targets))
+	}
+
+	assert.Nil(t, validate(t, false, applyFunc, function(wasmgo.MaximumBrTableElements)))
+	assertRejected(t, &exception.WasmBrTableTooLarge{}, validate(t, false, applyFunc, function(wasmgo.MaximumBrTableElements+1)))
+}
+
+func TestValidateApplySignature(t *testing.T) {
+	assertRejected(t, &exception.WasmExecutionError{}, validate(t, false, `(func (export "apply") (param i64 i32 i32))`))
+}
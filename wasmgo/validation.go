@@ -24,6 +24,8 @@ func Init(d bool, de uint16) {
 	depth = de
 }
 
+// Validate checks code against the rules a contract must follow to be set on chain,
+// the nesting of blocks is not checked when d is true.
 func Validate(d bool, code []byte) error {
 
 	Init(d, 0)
@@ -44,6 +46,8 @@ func ValidateModule(m *wasm.Module) error {
 		dataSegementsValidation,
 		tablesValidation,
 		globalsValidation,
+		importsValidation,
+		maximumLocalSets,
 		maximumFunctionStack,
 		ensureApplyExported,
 		maximumSerialize,
@@ -144,14 +148,76 @@ func globalsValidation(m *wasm.Module) error {
 	return nil
 }
 
+func importsValidation(m *wasm.Module) error {
+	if m.Import == nil {
+		return nil
+	}
+	for _, entry := range m.Import.Entries {
+		if global, ok := entry.Type.(wasm.GlobalVarImport); ok && global.Type.Mutable {
+			EosThrow(&WasmMutableGlobalImport{}, "Smart contract imports mutable global %s.%s", entry.ModuleName, entry.FieldName)
+		}
+
+		function, ok := entry.Type.(wasm.FuncImport)
+		if !ok {
+			EosThrow(&WasmImportNotAllowed{}, "Smart contract may only import functions, %s.%s is not one", entry.ModuleName, entry.FieldName)
+		}
+		intrinsic, ok := Intrinsics[entry.FieldName]
+		if !ok || entry.ModuleName != "env" {
+			EosThrow(&WasmImportNotAllowed{}, "Smart contract imports %s.%s which is not an intrinsic", entry.ModuleName, entry.FieldName)
+		}
+
+		if m.Types == nil || int(function.Type) >= len(m.Types.Entries) {
+			EosThrow(&WasmSerializationError{}, "Smart contract imports %s.%s with an unknown type", entry.ModuleName, entry.FieldName)
+		}
+		sig := m.Types.Entries[function.Type]
+		if !sameValueTypes(sig.ParamTypes, intrinsic.ParamTypes) || !sameValueTypes(sig.ReturnTypes, intrinsic.ReturnTypes) {
+			EosThrow(&WasmImportSignatureMismatch{}, "Smart contract imports %s.%s as %s, the intrinsic is %s",
+				entry.ModuleName, entry.FieldName, sig, intrinsic)
+		}
+	}
+
+	return nil
+}
+
+func sameValueTypes(a, b []wasm.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func maximumLocalSets(m *wasm.Module) error {
+	if m.Code == nil {
+		return nil
+	}
+	for _, body := range m.Code.Bodies {
+		if len(body.Locals) > MaximumLocalSets {
+			EosThrow(&WasmTooManyLocals{}, "Smart contract function has more than %d local declarations", MaximumLocalSets)
+		}
+	}
+
+	return nil
+}
+
 func maximumFunctionStack(m *wasm.Module) error {
+	if m.Function == nil {
+		return nil
+	}
 
 	for i, functypeIndex := range m.Function.Types {
 
 		functionStackUsage := 0
 
 		for _, local := range m.Code.Bodies[i].Locals {
-			functionStackUsage += int(local.Count * uint32(getTypeBitWidth(local.Type)/8))
+			if local.Count > MaximumFuncLocalBytes {
+				EosThrow(&WasmExecutionError{}, "Smart contract function has more than %d bytes of stack usage", MaximumFuncLocalBytes)
+			}
+			functionStackUsage += int(local.Count) * int(getTypeBitWidth(local.Type)/8)
 		}
 
 		for _, params := range m.Types.Entries[functypeIndex].ParamTypes {
@@ -210,8 +276,8 @@ func ensureApplyExported(m *wasm.Module) error {
 			functionSig := m.Types.Entries[m.Function.Types[index]]
 			if len(functionSig.ParamTypes) == 3 &&
 				functionSig.ParamTypes[0] == wasm.ValueTypeI64 &&
-				functionSig.ParamTypes[0] == wasm.ValueTypeI64 &&
-				functionSig.ParamTypes[0] == wasm.ValueTypeI64 &&
+				functionSig.ParamTypes[1] == wasm.ValueTypeI64 &&
+				functionSig.ParamTypes[2] == wasm.ValueTypeI64 &&
 				len(functionSig.ReturnTypes) == 0 {
 				return nil
 			}
@@ -240,11 +306,13 @@ func getImportFuctionNumber(m *wasm.Module) int {
 
 func ValidateBody(d *disasm.Disassembly) error {
 
-	//uint16 depth
+	depth = 0
 	for _, instr := range d.Code {
 		switch instr.Op.Code {
-		case ops.Block, ops.Loop, ops.If, ops.Else, ops.End:
+		case ops.Block, ops.Loop, ops.If, ops.End:
 			nestedValidator(&instr, &depth)
+		case ops.BrTable:
+			brTableValidator(&instr)
 		case ops.I32Load, ops.I64Load, ops.F32Load, ops.F64Load, ops.I32Load8s, ops.I32Load8u, ops.I32Load16s, ops.I32Load16u, ops.I64Load8s, ops.I64Load8u, ops.I64Load16s, ops.I64Load16u, ops.I64Load32s, ops.I64Load32u, ops.I32Store, ops.I64Store, ops.F32Store, ops.F64Store, ops.I32Store8, ops.I32Store16, ops.I64Store8, ops.I64Store16, ops.I64Store32:
 			largeOffsetValidator(&instr)
 		}
@@ -256,12 +324,22 @@ func nestedValidator(instr *disasm.Instr, depth *uint16) {
 
 	if !disabled {
 		if instr.Op.Code == ops.End {
-			*depth--
-		} else {
-			*depth++
+			if *depth > 0 { // the end of the function body closes no structure
+				*depth--
+			}
+			return
 		}
+		*depth++
+
+		EosAssert(*depth < MaximumNestedStructures, &WasmNestedTooDeep{}, "Nested depth exceeded")
+	}
+
+}
+
+func brTableValidator(instr *disasm.Instr) {
 
-		EosAssert(*depth < 1024, &WasmExecutionError{}, "Nested depth exceeded")
+	if targets := instr.Immediates[0].(uint32); targets > MaximumBrTableElements {
+		EosThrow(&WasmBrTableTooLarge{}, "Smart contract br_table has %d targets, more than %d", targets, MaximumBrTableElements)
 	}
 
 }
@@ -44,6 +44,7 @@ type VirtualMachine struct {
 	CallStack        []Frame
 	CurrentFrame     int
 	Table            []uint32
+	FunctionSigs     []*wasm.FunctionSig // the type of every function, imported ones first like FunctionCode
 	Globals          []int64
 	Memory           []byte
 	NumValueSlots    int
@@ -55,11 +56,15 @@ type VirtualMachine struct {
 	ReturnValue      int64
 	Gas              uint64
 	GasLimitExceeded bool
+	Tracer           *Tracer    // records every instruction executed by the interpreter, see debug.go
+	Profile          *VMProfile // counts the instructions and host calls executed, see profile.go
+
+	labels []string
 }
 
 // VMConfig denotes a set of options passed to a single VirtualMachine insta.ce
 type VMConfig struct {
-	EnableJIT                bool
+	EnableJIT                bool // execute the FunctionCode compiled ahead of time, see aot.go
 	MaxMemoryPages           int
 	MaxTableSize             int
 	MaxValueSlots            int
@@ -99,10 +104,6 @@ func NewVirtualMachine(
 	impResolver ImportResolver,
 	gasPolicy compiler.GasPolicy,
 ) (_retVM *VirtualMachine, retErr error) {
-	if config.EnableJIT {
-		fmt.Println("Warning: JIT support is removed.")
-	}
-
 	m, err := compiler.LoadModule(code)
 	if err != nil {
 		return nil, err
@@ -111,6 +112,13 @@ func NewVirtualMachine(
 	//inject timecheck for infinite loop
 	Inject(m.Base)
 
+	//the injected checktime import shifts the named functions as well
+	functionNames := make(map[int]string, len(m.FunctionNames))
+	for id, name := range m.FunctionNames {
+		functionNames[id+1] = name
+	}
+	m.FunctionNames = functionNames
+
 	//buf := new(bytes.Buffer)
 	//wast.WriteTo(buf, m.Base)
 	//ioutil.WriteFile("/tmp/hello.wat", buf.Bytes(), 0644)
@@ -122,6 +130,17 @@ func NewVirtualMachine(
 		return nil, err
 	}
 
+	if config.EnableJIT {
+		for i := range functionCode {
+			ops, err := compileAOT(&functionCode[i])
+			if err != nil {
+				return nil, err
+			}
+			functionCode[i].JITInfo = ops
+			functionCode[i].JITDone = true
+		}
+	}
+
 	//defer utils.CatchPanic(&retErr)
 
 	table := make([]uint32, 0)
@@ -195,7 +214,8 @@ func NewVirtualMachine(
 		}
 		if m.Base.Elements != nil && len(m.Base.Elements.Entries) > 0 {
 			for _, e := range m.Base.Elements.Entries {
-				offset := int(execInitExpr(e.Offset, globals))
+				offset := int(uint32(execInitExpr(e.Offset, globals)))
+				EosAssert(offset+len(e.Elems) <= len(table), &WasmExecutionError{}, "elements segment does not fit")
 				copy(table[offset:], e.Elems)
 			}
 		}
@@ -219,8 +239,9 @@ func NewVirtualMachine(
 
 		if m.Base.Data != nil && len(m.Base.Data.Entries) > 0 {
 			for _, e := range m.Base.Data.Entries {
-				offset := int(execInitExpr(e.Offset, globals))
-				copy(memory[int(offset):], e.Data)
+				offset := int(uint32(execInitExpr(e.Offset, globals)))
+				EosAssert(offset+len(e.Data) <= len(memory), &WasmExecutionError{}, "data segment does not fit")
+				copy(memory[offset:], e.Data)
 			}
 		}
 	}
@@ -238,6 +259,7 @@ func NewVirtualMachine(
 		CallStack:       make([]Frame, DefaultCallStackSize),
 		CurrentFrame:    -1,
 		Table:           table,
+		FunctionSigs:    functionSigs(m.Base),
 		Globals:         globals,
 		Memory:          memory,
 		Exited:          true,
@@ -270,7 +292,9 @@ func (vm *VirtualMachine) Reset() {
 
 	vm.CallStack = make([]Frame, DefaultCallStackSize)
 	vm.CurrentFrame = -1
+	vm.NumValueSlots = 0
 	vm.Exited = true
+	vm.ExitError = nil
 	vm.Delegate = nil
 	vm.InsideExecute = false
 }
@@ -346,10 +370,7 @@ func (vm *VirtualMachine) GetFunctionExport(key string) (int, bool) {
 // PrintStackTrace prints the entire VM stack trace for debugging.
 func (vm *VirtualMachine) PrintStackTrace() {
 	fmt.Println("--- Begin stack trace ---")
-	for i := vm.CurrentFrame; i >= 0; i-- {
-		functionID := vm.CallStack[i].FunctionID
-		fmt.Printf("<%d> [%d] %s\n", i, functionID, vm.Module.FunctionNames[functionID])
-	}
+	fmt.Print(vm.StackTrace())
 	fmt.Println("--- End stack trace ---")
 }
 
@@ -423,6 +444,11 @@ func (vm *VirtualMachine) Execute() {
 		}
 	}()
 
+	if vm.Config.EnableJIT {
+		vm.executeAOT()
+		return
+	}
+
 	frame := vm.GetCurrentFrame()
 
 	for {
@@ -431,6 +457,12 @@ func (vm *VirtualMachine) Execute() {
 		frame.IP += 5
 
 		//fmt.Printf("INS: [%d] %s\n", valueID, ins.String())
+		if vm.Tracer != nil {
+			vm.Tracer.step(vm, frame, ins)
+		}
+		if vm.Profile != nil {
+			vm.Profile.Instructions[frame.FunctionID]++
+		}
 
 		switch ins {
 		case opcodes.Nop:
@@ -924,12 +956,12 @@ func (vm *VirtualMachine) Execute() {
 			a := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			b := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP+4:frame.IP+8]))]))
 			frame.IP += 8
-			frame.Regs[valueID] = int64(math.Float32bits(float32(math.Min(float64(a), float64(b)))))
+			frame.Regs[valueID] = int64(math.Float32bits(float32(fmin(float64(a), float64(b)))))
 		case opcodes.F32Max:
 			a := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			b := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP+4:frame.IP+8]))]))
 			frame.IP += 8
-			frame.Regs[valueID] = int64(math.Float32bits(float32(math.Max(float64(a), float64(b)))))
+			frame.Regs[valueID] = int64(math.Float32bits(float32(fmax(float64(a), float64(b)))))
 		case opcodes.F32Ceil:
 			val := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			frame.IP += 4
@@ -949,7 +981,7 @@ func (vm *VirtualMachine) Execute() {
 		case opcodes.F32Abs:
 			val := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			frame.IP += 4
-			frame.Regs[valueID] = int64(math.Float32bits(float32(math.Abs(float64(val)))))
+			frame.Regs[valueID] = int64(math.Float32bits(val) &^ (1 << 31))
 		case opcodes.F32Neg:
 			val := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			frame.IP += 4
@@ -958,7 +990,7 @@ func (vm *VirtualMachine) Execute() {
 			a := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			b := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP+4:frame.IP+8]))]))
 			frame.IP += 8
-			frame.Regs[valueID] = int64(math.Float32bits(float32(math.Copysign(float64(a), float64(b)))))
+			frame.Regs[valueID] = int64(math.Float32bits(a)&^(1<<31) | math.Float32bits(b)&(1<<31))
 		case opcodes.F32Eq:
 			a := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			b := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP+4:frame.IP+8]))]))
@@ -1041,12 +1073,12 @@ func (vm *VirtualMachine) Execute() {
 			a := math.Float64frombits(uint64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			b := math.Float64frombits(uint64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP+4:frame.IP+8]))]))
 			frame.IP += 8
-			frame.Regs[valueID] = int64(math.Float64bits(math.Min(a, b)))
+			frame.Regs[valueID] = int64(math.Float64bits(fmin(a, b)))
 		case opcodes.F64Max:
 			a := math.Float64frombits(uint64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			b := math.Float64frombits(uint64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP+4:frame.IP+8]))]))
 			frame.IP += 8
-			frame.Regs[valueID] = int64(math.Float64bits(math.Max(a, b)))
+			frame.Regs[valueID] = int64(math.Float64bits(fmax(a, b)))
 		case opcodes.F64Ceil:
 			val := math.Float64frombits(uint64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			frame.IP += 4
@@ -1136,25 +1168,45 @@ func (vm *VirtualMachine) Execute() {
 			frame.IP += 4
 			frame.Regs[valueID] = int64(v)
 
-		case opcodes.I32TruncSF32, opcodes.I32TruncUF32:
+		case opcodes.I32TruncSF32:
 			v := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			frame.IP += 4
-			frame.Regs[valueID] = int64(int32(math.Trunc(float64(v))))
+			frame.Regs[valueID] = int64(int32(truncS(float64(v), 32)))
 
-		case opcodes.I32TruncSF64, opcodes.I32TruncUF64:
+		case opcodes.I32TruncUF32:
+			v := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
+			frame.IP += 4
+			frame.Regs[valueID] = int64(uint32(truncU(float64(v), 32)))
+
+		case opcodes.I32TruncSF64:
+			v := math.Float64frombits(uint64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
+			frame.IP += 4
+			frame.Regs[valueID] = int64(int32(truncS(v, 32)))
+
+		case opcodes.I32TruncUF64:
 			v := math.Float64frombits(uint64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			frame.IP += 4
-			frame.Regs[valueID] = int64(int32(math.Trunc(v)))
+			frame.Regs[valueID] = int64(uint32(truncU(v, 32)))
 
-		case opcodes.I64TruncSF32, opcodes.I64TruncUF32:
+		case opcodes.I64TruncSF32:
 			v := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			frame.IP += 4
-			frame.Regs[valueID] = int64(math.Trunc(float64(v)))
+			frame.Regs[valueID] = truncS(float64(v), 64)
 
-		case opcodes.I64TruncSF64, opcodes.I64TruncUF64:
+		case opcodes.I64TruncUF32:
+			v := math.Float32frombits(uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
+			frame.IP += 4
+			frame.Regs[valueID] = int64(truncU(float64(v), 64))
+
+		case opcodes.I64TruncSF64:
+			v := math.Float64frombits(uint64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
+			frame.IP += 4
+			frame.Regs[valueID] = truncS(v, 64)
+
+		case opcodes.I64TruncUF64:
 			v := math.Float64frombits(uint64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
 			frame.IP += 4
-			frame.Regs[valueID] = int64(math.Trunc(v))
+			frame.Regs[valueID] = int64(truncU(v, 64))
 
 		case opcodes.F32DemoteF64:
 			v := math.Float64frombits(uint64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]))
@@ -1189,12 +1241,12 @@ func (vm *VirtualMachine) Execute() {
 		case opcodes.F64ConvertSI32:
 			v := int32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))])
 			frame.IP += 4
-			frame.Regs[valueID] = int64(int32(math.Float64bits(float64(v))))
+			frame.Regs[valueID] = int64(math.Float64bits(float64(v)))
 
 		case opcodes.F64ConvertUI32:
 			v := uint32(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))])
 			frame.IP += 4
-			frame.Regs[valueID] = int64(int32(math.Float64bits(float64(v))))
+			frame.Regs[valueID] = int64(math.Float64bits(float64(v)))
 
 		case opcodes.F64ConvertSI64:
 			v := int64(frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))])
@@ -1445,16 +1497,9 @@ func (vm *VirtualMachine) Execute() {
 			tableItemID := frame.Regs[int(LE.Uint32(frame.Code[frame.IP:frame.IP+4]))]
 			frame.IP += 4
 
-			sig := &vm.Module.Base.Types.Entries[typeID]
-
-			functionID := int(vm.Table[tableItemID])
+			functionID := vm.indirectFunction(typeID, tableItemID)
 			code := vm.FunctionCode[functionID]
 
-			// TODO: We are only checking CC here; Do we want strict typeck?
-			if code.NumParams != len(sig.ParamTypes) || code.NumReturns != len(sig.ReturnTypes) {
-				panic("type mismatch")
-			}
-
 			oldRegs := frame.Regs
 			frame.ReturnReg = valueID
 
@@ -1469,7 +1514,7 @@ func (vm *VirtualMachine) Execute() {
 			importID := int(LE.Uint32(frame.Code[frame.IP : frame.IP+4]))
 			frame.IP += 4
 			vm.Delegate = func() {
-				frame.Regs[valueID] = vm.FunctionImports[importID](vm)
+				frame.Regs[valueID] = vm.callImport(importID)
 			}
 			return
 
@@ -1507,3 +1552,86 @@ func (vm *VirtualMachine) Execute() {
 		}
 	}
 }
+
+// functionSigs lists the type of every function of m, imported functions first
+func functionSigs(m *wasm.Module) []*wasm.FunctionSig {
+	sigs := make([]*wasm.FunctionSig, 0)
+	if m.Import != nil {
+		for _, imp := range m.Import.Entries {
+			if fn, ok := imp.Type.(wasm.FuncImport); ok {
+				sigs = append(sigs, &m.Types.Entries[fn.Type])
+			}
+		}
+	}
+	if m.Function != nil {
+		for _, typeID := range m.Function.Types {
+			sigs = append(sigs, &m.Types.Entries[typeID])
+		}
+	}
+	return sigs
+}
+
+// indirectFunction resolves the function call_indirect calls through the table,
+// it traps unless the function has exactly the type typeID the call expects
+func (vm *VirtualMachine) indirectFunction(typeID int, tableItemID int64) int {
+	if tableItemID < 0 || tableItemID >= int64(len(vm.Table)) {
+		panic("undefined element")
+	}
+	functionID := int(vm.Table[tableItemID])
+	if functionID >= len(vm.FunctionCode) {
+		panic("uninitialized element")
+	}
+
+	expected, actual := &vm.Module.Base.Types.Entries[typeID], vm.FunctionSigs[functionID]
+	same := len(expected.ParamTypes) == len(actual.ParamTypes) && len(expected.ReturnTypes) == len(actual.ReturnTypes)
+	for i := 0; same && i < len(expected.ParamTypes); i++ {
+		same = expected.ParamTypes[i] == actual.ParamTypes[i]
+	}
+	for i := 0; same && i < len(expected.ReturnTypes); i++ {
+		same = expected.ReturnTypes[i] == actual.ReturnTypes[i]
+	}
+	if !same {
+		panic("indirect call type mismatch")
+	}
+	return functionID
+}
+
+// truncS is the integer part of v as a signed integer of size bits, it traps on NaN and on a value out of range
+func truncS(v float64, size uint) int64 {
+	if math.IsNaN(v) {
+		panic("invalid conversion to integer")
+	}
+	t, limit := math.Trunc(v), math.Ldexp(1, int(size-1))
+	if t < -limit || t >= limit {
+		panic("integer overflow")
+	}
+	return int64(t)
+}
+
+// truncU is the integer part of v as an unsigned integer of size bits, it traps on NaN and on a value out of range
+func truncU(v float64, size uint) uint64 {
+	if math.IsNaN(v) {
+		panic("invalid conversion to integer")
+	}
+	t := math.Trunc(v)
+	if t <= -1 || t >= math.Ldexp(1, int(size)) {
+		panic("integer overflow")
+	}
+	return uint64(t)
+}
+
+// fmin is math.Min, except that a NaN operand gives NaN even against -Inf
+func fmin(a, b float64) float64 {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return a + b
+	}
+	return math.Min(a, b)
+}
+
+// fmax is math.Max, except that a NaN operand gives NaN even against +Inf
+func fmax(a, b float64) float64 {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return a + b
+	}
+	return math.Max(a, b)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/eosspark/eos-go/wasmgo/wagon/exec"
 	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wast"
 )
 
 func ExampleVM_add() {
@@ -115,20 +116,11 @@ func ExampleVM_add() {
 	// fct3() -> <nil>
 }
 
-// compileWast2Wasm fakes a compilation pass from WAST to WASM.
-//
-// When wagon gets a WAST parser, this function will be running an actual compilation.
-// See: https://github.com/eosspark/eos-go/wasmgo/wagon/issues/34
+// compileWast2Wasm compiles a WAST file to WASM.
 func compileWast2Wasm(fname string) ([]byte, error) {
-	switch fname {
-	case "testdata/add-ex.wast":
-		// obtained by running:
-		//  $> wat2wasm -v -o add-ex.wasm add-ex.wast
-		return ioutil.ReadFile("testdata/add-ex.wasm")
-	case "testdata/add-ex-main.wast":
-		// obtained by running:
-		//  $> wat2wasm -v -o add-ex-main.wasm add-ex-main.wast
-		return ioutil.ReadFile("testdata/add-ex-main.wasm")
+	src, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("unknown wast test file %q", fname)
+	return wast.Compile(src)
 }
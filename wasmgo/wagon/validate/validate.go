@@ -10,6 +10,7 @@ import (
 	"io"
 
 	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm/leb128"
 	ops "github.com/eosspark/eos-go/wasmgo/wagon/wasm/operators"
 )
 
@@ -237,6 +238,9 @@ func verifyBody(fn *wasm.FunctionSig, body *wasm.FunctionBody, module *wasm.Modu
 			}
 
 		case ops.I32Load, ops.I64Load, ops.F32Load, ops.F64Load, ops.I32Load8s, ops.I32Load8u, ops.I32Load16s, ops.I32Load16u, ops.I64Load8s, ops.I64Load8u, ops.I64Load16s, ops.I64Load16u, ops.I64Load32s, ops.I64Load32u, ops.I32Store, ops.I64Store, ops.F32Store, ops.F64Store, ops.I32Store8, ops.I32Store16, ops.I64Store8, ops.I64Store16, ops.I64Store32:
+			if !hasMemory(module) {
+				return vm, NoSectionError(wasm.SectionIDMemory)
+			}
 			// read memory_immediate
 			// flags
 			_, err := vm.fetchVarUint()
@@ -249,6 +253,9 @@ func verifyBody(fn *wasm.FunctionSig, body *wasm.FunctionBody, module *wasm.Modu
 				return vm, err
 			}
 		case ops.CurrentMemory, ops.GrowMemory:
+			if !hasMemory(module) {
+				return vm, NoSectionError(wasm.SectionIDMemory)
+			}
 			_, err := vm.fetchVarUint()
 			if err != nil {
 				return vm, err
@@ -349,12 +356,114 @@ func verifyBody(fn *wasm.FunctionSig, body *wasm.FunctionBody, module *wasm.Modu
 		}
 	}
 
+	// the implicit block of the function body ends with its result
+	if len(fn.ReturnTypes) != 0 && !vm.isPolymorphic() {
+		top, under := vm.topOperand()
+		if under || top.Type != fn.ReturnTypes[0] {
+			return vm, InvalidTypeError{fn.ReturnTypes[0], top.Type}
+		}
+	}
+
 	return vm, nil
 }
 
+// hasMemory reports whether the module defines or imports a linear memory
+func hasMemory(module *wasm.Module) bool {
+	if module.Memory != nil && len(module.Memory.Entries) > 0 {
+		return true
+	}
+	if module.Import != nil {
+		for _, imp := range module.Import.Entries {
+			if imp.Type.Kind() == wasm.ExternalMemory {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasTable(module *wasm.Module) bool {
+	if module.Table != nil && len(module.Table.Entries) > 0 {
+		return true
+	}
+	if module.Import != nil {
+		for _, imp := range module.Import.Entries {
+			if imp.Type.Kind() == wasm.ExternalTable {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// initExprType is the type of the value an initializer expression produces
+func initExprType(module *wasm.Module, expr []byte) (wasm.ValueType, error) {
+	if len(expr) == 0 {
+		return 0, wasm.ErrEmptyInitExpr
+	}
+	switch expr[0] {
+	case ops.I32Const:
+		return wasm.ValueTypeI32, nil
+	case ops.I64Const:
+		return wasm.ValueTypeI64, nil
+	case ops.F32Const:
+		return wasm.ValueTypeF32, nil
+	case ops.F64Const:
+		return wasm.ValueTypeF64, nil
+	case ops.GetGlobal:
+		index, err := leb128.ReadVarUint32(bytes.NewReader(expr[1:]))
+		if err != nil {
+			return 0, err
+		}
+		global := module.GetGlobal(int(index))
+		if global == nil {
+			return 0, wasm.InvalidGlobalIndexError(index)
+		}
+		return global.Type.Type, nil
+	}
+	return 0, wasm.InvalidInitExprOpError(expr[0])
+}
+
+// verifySegments checks the element and data segments have a table and a memory to go to,
+// and an i32 offset.
+func verifySegments(module *wasm.Module) error {
+	if module.Elements != nil {
+		for _, e := range module.Elements.Entries {
+			if !hasTable(module) {
+				return NoSectionError(wasm.SectionIDTable)
+			}
+			typ, err := initExprType(module, e.Offset)
+			if err != nil {
+				return err
+			}
+			if typ != wasm.ValueTypeI32 {
+				return InvalidTypeError{wasm.ValueTypeI32, typ}
+			}
+		}
+	}
+	if module.Data != nil {
+		for _, e := range module.Data.Entries {
+			if !hasMemory(module) {
+				return NoSectionError(wasm.SectionIDMemory)
+			}
+			typ, err := initExprType(module, e.Offset)
+			if err != nil {
+				return err
+			}
+			if typ != wasm.ValueTypeI32 {
+				return InvalidTypeError{wasm.ValueTypeI32, typ}
+			}
+		}
+	}
+	return nil
+}
+
 // VerifyModule verifies the given module according to WebAssembly verification
 // specs.
 func VerifyModule(module *wasm.Module) error {
+	if err := verifySegments(module); err != nil {
+		return err
+	}
 	if module.Function == nil || module.Types == nil || len(module.Types.Entries) == 0 {
 		return nil
 	}
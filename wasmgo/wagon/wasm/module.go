@@ -17,6 +17,9 @@ var ErrInvalidMagic = errors.New("wasm: Invalid magic number")
 const (
 	Magic   uint32 = 0x6d736100
 	Version uint32 = 0x1
+
+	// MaximumCodeSize bounds the code section, as wasm_constraints::maximum_code_size of the reference
+	MaximumCodeSize = 20 * 1024 * 1024
 )
 
 // Function represents an entry in the function index space of a module.
@@ -10,7 +10,10 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/eosspark/eos-go/exception"
+	"github.com/eosspark/eos-go/exception/try"
 	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm/leb128"
 )
 
 var testPaths = []string{
@@ -45,3 +48,46 @@ func TestReadModule(t *testing.T) {
 		}
 	}
 }
+
+// moduleWithCodeSize returns a module of one function whose code section payload is size bytes
+func moduleWithCodeSize(size int) []byte {
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = append(module, byte(wasm.SectionIDType), 4, 1, 0x60, 0, 0)
+	module = append(module, byte(wasm.SectionIDFunction), 2, 1, 0)
+
+	// the payload is the function count, the body size and the body: no locals, nops and end
+	bodySize := size - 1 - len(leb128.AppendUleb128(nil, uint64(size)))
+	body := append([]byte{0}, bytes.Repeat([]byte{0x01}, bodySize-2)...)
+	body = append(body, 0x0b)
+
+	module = append(module, byte(wasm.SectionIDCode))
+	module = leb128.AppendUleb128(module, uint64(size))
+	module = append(module, 1)
+	module = leb128.AppendUleb128(module, uint64(bodySize))
+	return append(module, body...)
+}
+
+func TestReadModuleCodeSize(t *testing.T) {
+	decode := func(size int) (thrown exception.Exception) {
+		try.Try(func() {
+			m, err := wasm.DecodeModule(bytes.NewReader(moduleWithCodeSize(size)))
+			if err != nil {
+				t.Fatalf("error reading module %v", err)
+			}
+			if len(m.Code.Bodies) != 1 {
+				t.Fatalf("expected one function body, got %d", len(m.Code.Bodies))
+			}
+		}).Catch(func(e exception.Exception) {
+			thrown = e
+		}).End()
+		return
+	}
+
+	if thrown := decode(wasm.MaximumCodeSize - 1); thrown != nil {
+		t.Fatalf("a code section of %d bytes is refused: %s", wasm.MaximumCodeSize-1, thrown.DetailMessage())
+	}
+	thrown := decode(wasm.MaximumCodeSize)
+	if thrown == nil || thrown.Code() != (exception.FcException{}).Code() {
+		t.Fatalf("a code section of %d bytes is accepted", wasm.MaximumCodeSize)
+	}
+}
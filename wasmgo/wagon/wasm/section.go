@@ -128,7 +128,7 @@ func (m *Module) readSection(r *readpos.ReadPos) (bool, error) {
 		return false, err
 	}
 
-	if s.ID == SectionIDCode && payloadDataLen >= 20*1024 { //MaximumCodeSize 20*1024
+	if s.ID == SectionIDCode && payloadDataLen >= MaximumCodeSize {
 		EosThrow(&FcException{}, "Function body too large")
 	}
 
@@ -623,7 +623,13 @@ func (s *SectionExports) WritePayload(w io.Writer) error {
 		entries = append(entries, e)
 	}
 	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Index < entries[j].Index
+		if entries[i].Index != entries[j].Index {
+			return entries[i].Index < entries[j].Index
+		}
+		if entries[i].Kind != entries[j].Kind {
+			return entries[i].Kind < entries[j].Kind
+		}
+		return entries[i].FieldStr < entries[j].FieldStr
 	})
 	for _, e := range entries {
 		if err := e.MarshalWASM(w); err != nil {
@@ -1193,6 +1199,9 @@ func (m NameMap) UnmarshalWASM(r io.Reader) error {
 	return nil
 }
 func (m NameMap) MarshalWASM(w io.Writer) error {
+	if _, err := leb128.WriteVarUint32(w, uint32(len(m))); err != nil {
+		return err
+	}
 	keys := make([]uint32, 0, len(m))
 	for k := range m {
 		keys = append(keys, k)
@@ -0,0 +1,410 @@
+// Copyright 2018 The go-interpreter Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wast
+
+// See https://webassembly.github.io/spec/core/text/instructions.html
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm/leb128"
+	ops "github.com/eosspark/eos-go/wasmgo/wagon/wasm/operators"
+)
+
+// opcodes maps the name of every operator to its opcode,
+// both the names wagon uses and the ones of the current specification are accepted
+var opcodes = make(map[string]byte)
+
+func init() {
+	for code := 0; code < 256; code++ {
+		op, err := ops.New(byte(code))
+		if err != nil {
+			continue
+		}
+		opcodes[op.Name] = op.Code
+
+		/* i32.trunc_s/f32 is i32.trunc_f32_s, i32.wrap/i64 is i32.wrap_i64 */
+		if slash := strings.IndexByte(op.Name, '/'); slash >= 0 {
+			name, from := op.Name[:slash], op.Name[slash+1:]
+			if strings.HasSuffix(name, "_s") || strings.HasSuffix(name, "_u") {
+				opcodes[name[:len(name)-2]+"_"+from+name[len(name)-2:]] = op.Code
+			} else {
+				opcodes[name+"_"+from] = op.Code
+			}
+		}
+	}
+	for name, code := range map[string]byte{
+		"local.get":   ops.GetLocal,
+		"local.set":   ops.SetLocal,
+		"local.tee":   ops.TeeLocal,
+		"global.get":  ops.GetGlobal,
+		"global.set":  ops.SetGlobal,
+		"memory.size": ops.CurrentMemory,
+		"memory.grow": ops.GrowMemory,
+	} {
+		opcodes[name] = code
+	}
+}
+
+// compiler encodes the instructions of a function body or of a constant expression
+type compiler struct {
+	b      *builder
+	code   bytes.Buffer
+	locals map[string]uint32
+	labels []string // names of the enclosing blocks, the innermost last
+}
+
+func newCompiler(b *builder) *compiler {
+	return &compiler{b: b, locals: make(map[string]uint32)}
+}
+
+func (c *compiler) local(name *node, idx uint32) {
+	if name == nil {
+		return
+	}
+	if _, ok := c.locals[name.text]; ok {
+		fail(name, "duplicate local $%s", name.text)
+	}
+	c.locals[name.text] = idx
+}
+
+// instrs compiles a sequence of plain and folded instructions
+func (c *compiler) instrs(seq []*node) {
+	for i := 0; i < len(seq); {
+		i = c.instr(seq, i)
+	}
+}
+
+// instr compiles the instruction starting at seq[i] and returns the index following it
+func (c *compiler) instr(seq []*node, i int) int {
+	n := seq[i]
+	if n.kind == nodeList {
+		c.folded(n)
+		return i + 1
+	}
+	if n.kind != nodeKeyword {
+		fail(n, "expected an instruction")
+	}
+
+	op := opcode(n)
+	switch op {
+	case ops.Block, ops.Loop, ops.If:
+		return c.plainBlock(op, seq, i+1)
+	case ops.Else, ops.End:
+		fail(n, "unexpected %s", n.text)
+	}
+	imm := new(bytes.Buffer)
+	i = c.immediates(n, op, seq, i+1, imm)
+	c.code.WriteByte(op)
+	imm.WriteTo(&c.code)
+	return i
+}
+
+func opcode(n *node) byte {
+	op, ok := opcodes[n.text]
+	if n.kind != nodeKeyword || !ok {
+		fail(n, "unknown operator %q", n.text)
+	}
+	return op
+}
+
+// plainBlock compiles block, loop and if written with end, seq[i] is the first token after the keyword
+func (c *compiler) plainBlock(op byte, seq []*node, i int) int {
+	label, sig, i := c.blockHeader(seq, i)
+	c.code.WriteByte(op)
+	c.code.WriteByte(byte(sig) & 0x7f)
+	c.labels = append(c.labels, label)
+
+	/* an empty else is left out */
+	pendingElse := false
+	for {
+		if i >= len(seq) {
+			fail(seq[len(seq)-1], "missing end")
+		}
+		switch n := seq[i]; {
+		case n.is("end"):
+			c.labels = c.labels[:len(c.labels)-1]
+			c.code.WriteByte(ops.End)
+			return skipLabel(seq, i+1)
+		case n.is("else") && op == ops.If:
+			pendingElse = true
+			i = skipLabel(seq, i+1)
+		default:
+			if pendingElse {
+				c.code.WriteByte(ops.Else)
+				pendingElse = false
+			}
+			i = c.instr(seq, i)
+		}
+	}
+}
+
+// skipLabel steps over the optional label repeated after else and end
+func skipLabel(seq []*node, i int) int {
+	if i < len(seq) && seq[i].kind == nodeID {
+		return i + 1
+	}
+	return i
+}
+
+// blockHeader reads the optional label and result type of a block
+func (c *compiler) blockHeader(seq []*node, i int) (string, wasm.BlockType, int) {
+	label := ""
+	if i < len(seq) && seq[i].kind == nodeID {
+		label = seq[i].text
+		i++
+	}
+	sig := wasm.BlockTypeEmpty
+	if i < len(seq) && seq[i].head() == "result" {
+		r := seq[i]
+		if len(r.list) > 2 {
+			fail(r, "blocks return a single value")
+		}
+		if len(r.list) == 2 {
+			sig = wasm.BlockType(valueType(r.list[1]))
+		}
+		i++
+	} else if i < len(seq) && seq[i].kind == nodeKeyword {
+		if t, ok := valueTypes[seq[i].text]; ok {
+			sig = wasm.BlockType(t)
+			i++
+		}
+	}
+	return label, sig, i
+}
+
+// folded compiles an instruction in s-expression form, its operands are compiled ahead of it
+func (c *compiler) folded(n *node) {
+	if len(n.list) == 0 {
+		fail(n, "empty instruction")
+	}
+	op := opcode(n.list[0])
+	switch op {
+	case ops.Block, ops.Loop:
+		label, sig, i := c.blockHeader(n.list, 1)
+		c.code.WriteByte(op)
+		c.code.WriteByte(byte(sig) & 0x7f)
+		c.labels = append(c.labels, label)
+		c.instrs(n.list[i:])
+		c.labels = c.labels[:len(c.labels)-1]
+		c.code.WriteByte(ops.End)
+		return
+	case ops.If:
+		c.foldedIf(n)
+		return
+	case ops.Else, ops.End:
+		fail(n, "unexpected %s", n.head())
+	}
+
+	imm := new(bytes.Buffer)
+	i := c.immediates(n.list[0], op, n.list, 1, imm)
+	for _, operand := range n.list[i:] {
+		if operand.kind != nodeList {
+			fail(operand, "expected a folded instruction")
+		}
+		c.folded(operand)
+	}
+	c.code.WriteByte(op)
+	imm.WriteTo(&c.code)
+}
+
+// foldedIf compiles (if label? result? condition* (then instr*) (else instr*)?)
+func (c *compiler) foldedIf(n *node) {
+	label, sig, i := c.blockHeader(n.list, 1)
+	for ; i < len(n.list) && n.list[i].head() != "then"; i++ {
+		if n.list[i].kind != nodeList {
+			fail(n.list[i], "expected a folded instruction")
+		}
+		c.folded(n.list[i])
+	}
+	if i == len(n.list) {
+		fail(n, "missing then")
+	}
+
+	c.code.WriteByte(ops.If)
+	c.code.WriteByte(byte(sig) & 0x7f)
+	c.labels = append(c.labels, label)
+	c.instrs(n.list[i].list[1:])
+	i++
+	if i < len(n.list) && n.list[i].head() == "else" {
+		if len(n.list[i].list) > 1 {
+			c.code.WriteByte(ops.Else)
+			c.instrs(n.list[i].list[1:])
+		}
+		i++
+	}
+	if i < len(n.list) {
+		fail(n.list[i], "unexpected token in if")
+	}
+	c.labels = c.labels[:len(c.labels)-1]
+	c.code.WriteByte(ops.End)
+}
+
+// immediates encodes the immediate arguments of op into imm, seq[i] is the first token after the operator
+func (c *compiler) immediates(at *node, op byte, seq []*node, i int, imm *bytes.Buffer) int {
+	arg := func() *node {
+		if i >= len(seq) || seq[i].kind == nodeList {
+			fail(at, "%s expects an argument", at.text)
+		}
+		i++
+		return seq[i-1]
+	}
+
+	switch op {
+	case ops.Br, ops.BrIf:
+		leb128.WriteVarUint32(imm, c.labelRef(arg()))
+	case ops.BrTable:
+		var targets []uint32
+		for i < len(seq) && isIndex(seq[i]) {
+			targets = append(targets, c.labelRef(arg()))
+		}
+		if len(targets) == 0 {
+			fail(at, "br_table expects a default target")
+		}
+		leb128.WriteVarUint32(imm, uint32(len(targets)-1))
+		for _, t := range targets {
+			leb128.WriteVarUint32(imm, t)
+		}
+	case ops.Call:
+		leb128.WriteVarUint32(imm, c.b.ref(c.b.funcs, arg()))
+	case ops.CallIndirect:
+		var idx uint32
+		if i < len(seq) && isIndex(seq[i]) {
+			/* the (type x) of call_indirect used to be written without its parentheses */
+			idx = c.b.typeRef(arg())
+		} else {
+			it := &fieldItems{f: at, items: seq, i: i}
+			idx, _, _ = c.b.typeUse(it)
+			i = it.i
+		}
+		leb128.WriteVarUint32(imm, idx)
+		imm.WriteByte(0)
+	case ops.GetLocal, ops.SetLocal, ops.TeeLocal:
+		leb128.WriteVarUint32(imm, c.localRef(arg()))
+	case ops.GetGlobal, ops.SetGlobal:
+		leb128.WriteVarUint32(imm, c.b.ref(c.b.globals, arg()))
+	case ops.I32Const:
+		n := arg()
+		v, err := parseInt(n.text, 32)
+		if err != nil {
+			fail(n, "malformed i32 %q", n.text)
+		}
+		writeSleb(imm, int64(int32(v)))
+	case ops.I64Const:
+		n := arg()
+		v, err := parseInt(n.text, 64)
+		if err != nil {
+			fail(n, "malformed i64 %q", n.text)
+		}
+		writeSleb(imm, int64(v))
+	case ops.F32Const:
+		n := arg()
+		v, err := parseFloat(n.text, 32)
+		if err != nil {
+			fail(n, "malformed f32 %q", n.text)
+		}
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], uint32(v))
+		imm.Write(b[:])
+	case ops.F64Const:
+		n := arg()
+		v, err := parseFloat(n.text, 64)
+		if err != nil {
+			fail(n, "malformed f64 %q", n.text)
+		}
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], v)
+		imm.Write(b[:])
+	case ops.CurrentMemory, ops.GrowMemory:
+		imm.WriteByte(0)
+	default:
+		if size := accessSize(at.text); size > 0 {
+			i = memArg(seq, i, size, imm)
+		}
+	}
+	return i
+}
+
+// isIndex reports whether n may be a reference, a number or a $name
+func isIndex(n *node) bool {
+	return n.kind == nodeID || n.kind == nodeKeyword && n.text[0] >= '0' && n.text[0] <= '9'
+}
+
+func (c *compiler) labelRef(n *node) uint32 {
+	if n.kind == nodeID {
+		for i := len(c.labels) - 1; i >= 0; i-- {
+			if c.labels[i] == n.text {
+				return uint32(len(c.labels) - 1 - i)
+			}
+		}
+		fail(n, "unknown label $%s", n.text)
+	}
+	return uint32Of(n)
+}
+
+func (c *compiler) localRef(n *node) uint32 {
+	if n.kind == nodeID {
+		idx, ok := c.locals[n.text]
+		if !ok {
+			fail(n, "unknown local $%s", n.text)
+		}
+		return idx
+	}
+	return uint32Of(n)
+}
+
+// accessSize is the number of bytes a load or a store reads or writes, 0 for other operators
+func accessSize(name string) uint32 {
+	if !strings.Contains(name, ".load") && !strings.Contains(name, ".store") {
+		return 0
+	}
+	switch {
+	case strings.HasSuffix(name, "8") || strings.HasSuffix(name, "8_s") || strings.HasSuffix(name, "8_u"):
+		return 1
+	case strings.HasSuffix(name, "16") || strings.HasSuffix(name, "16_s") || strings.HasSuffix(name, "16_u"):
+		return 2
+	case strings.HasSuffix(name, "32") || strings.HasSuffix(name, "32_s") || strings.HasSuffix(name, "32_u"):
+		return 4
+	case strings.HasPrefix(name, "i64") || strings.HasPrefix(name, "f64"):
+		return 8
+	}
+	return 4
+}
+
+// memArg encodes the optional offset=N align=N of a memory access, alignment defaults to the access size
+func memArg(seq []*node, i int, size uint32, imm *bytes.Buffer) int {
+	offset, align := uint32(0), size
+	if i < len(seq) && seq[i].kind == nodeKeyword && strings.HasPrefix(seq[i].text, "offset=") {
+		v, err := parseUint(seq[i].text[len("offset="):], 32)
+		if err != nil {
+			fail(seq[i], "malformed offset")
+		}
+		offset = uint32(v)
+		i++
+	}
+	if i < len(seq) && seq[i].kind == nodeKeyword && strings.HasPrefix(seq[i].text, "align=") {
+		v, err := parseUint(seq[i].text[len("align="):], 32)
+		if err != nil || v == 0 || v&(v-1) != 0 {
+			fail(seq[i], "alignment must be a power of two")
+		}
+		align = uint32(v)
+		i++
+	}
+
+	flags := uint32(0)
+	for ; align > 1; align >>= 1 {
+		flags++
+	}
+	leb128.WriteVarUint32(imm, flags)
+	leb128.WriteVarUint32(imm, offset)
+	return i
+}
+
+func writeSleb(buf *bytes.Buffer, v int64) {
+	leb128.WriteVarint64(buf, v)
+}
@@ -0,0 +1,279 @@
+// Copyright 2018 The go-interpreter Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wast
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// See https://webassembly.github.io/spec/core/text/lexical.html
+
+// SyntaxError is returned for a malformed text module, it points at the offending token.
+type SyntaxError struct {
+	Line, Col int
+	Msg       string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("wast: %d:%d: %s", e.Line, e.Col, e.Msg)
+}
+
+type pos struct {
+	line, col int
+}
+
+type nodeKind uint8
+
+const (
+	nodeList    nodeKind = iota // a parenthesized list
+	nodeKeyword                 // keywords, numbers and other reserved tokens, such as offset=8
+	nodeID                      // a $name, text holds it without the $
+	nodeString                  // a string literal, text holds the decoded bytes
+)
+
+// node is an s-expression, either a list or an atom
+type node struct {
+	kind nodeKind
+	pos  pos
+	text string
+	list []*node
+}
+
+// head returns the keyword a list starts with
+func (n *node) head() string {
+	if n.kind != nodeList || len(n.list) == 0 || n.list[0].kind != nodeKeyword {
+		return ""
+	}
+	return n.list[0].text
+}
+
+func (n *node) is(keyword string) bool {
+	return n.kind == nodeKeyword && n.text == keyword
+}
+
+func (n *node) errorf(format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Line: n.pos.line, Col: n.pos.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+type lexer struct {
+	src  []byte
+	off  int
+	line int
+	col  int
+}
+
+// readNodes splits src into its top level s-expressions
+func readNodes(src []byte) ([]*node, error) {
+	l := &lexer{src: src, line: 1, col: 1}
+	var nodes []*node
+	for {
+		n, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if n == nil {
+			return nodes, nil
+		}
+		if n.kind == nodeKeyword && n.text == ")" {
+			return nil, n.errorf("unexpected )")
+		}
+		nodes = append(nodes, n)
+	}
+}
+
+func (l *lexer) errorf(format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Line: l.line, Col: l.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) peek(i int) byte {
+	if l.off+i < len(l.src) {
+		return l.src[l.off+i]
+	}
+	return 0
+}
+
+func (l *lexer) advance() {
+	if l.src[l.off] == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	l.off++
+}
+
+// skip moves past white space and comments
+func (l *lexer) skip() error {
+	for l.off < len(l.src) {
+		switch c := l.peek(0); {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			l.advance()
+		case c == ';' && l.peek(1) == ';':
+			for l.off < len(l.src) && l.peek(0) != '\n' {
+				l.advance()
+			}
+		case c == '(' && l.peek(1) == ';':
+			start := *l
+			l.advance()
+			l.advance()
+			for depth := 1; depth > 0; {
+				if l.off >= len(l.src) {
+					return start.errorf("unterminated block comment")
+				}
+				switch {
+				case l.peek(0) == '(' && l.peek(1) == ';':
+					depth++
+					l.advance()
+				case l.peek(0) == ';' && l.peek(1) == ')':
+					depth--
+					l.advance()
+				}
+				l.advance()
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// next reads one s-expression, a ")" is returned as a keyword node to close the list being read
+func (l *lexer) next() (*node, error) {
+	if err := l.skip(); err != nil {
+		return nil, err
+	}
+	if l.off >= len(l.src) {
+		return nil, nil
+	}
+
+	p := pos{l.line, l.col}
+	switch c := l.peek(0); c {
+	case '(':
+		l.advance()
+		list := &node{kind: nodeList, pos: p}
+		for {
+			n, err := l.next()
+			if err != nil {
+				return nil, err
+			}
+			if n == nil {
+				return nil, list.errorf("unclosed (")
+			}
+			if n.kind == nodeKeyword && n.text == ")" {
+				return list, nil
+			}
+			list.list = append(list.list, n)
+		}
+	case ')':
+		l.advance()
+		return &node{kind: nodeKeyword, pos: p, text: ")"}, nil
+	case '"':
+		s, err := l.readString()
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nodeString, pos: p, text: s}, nil
+	}
+
+	start := l.off
+	for l.off < len(l.src) && isIDChar(l.peek(0)) {
+		l.advance()
+	}
+	if l.off == start {
+		return nil, l.errorf("unexpected character %q", l.peek(0))
+	}
+	text := string(l.src[start:l.off])
+	if text[0] == '$' {
+		if len(text) == 1 {
+			return nil, (&node{pos: p}).errorf("empty identifier")
+		}
+		return &node{kind: nodeID, pos: p, text: text[1:]}, nil
+	}
+	return &node{kind: nodeKeyword, pos: p, text: text}, nil
+}
+
+func isIDChar(c byte) bool {
+	switch {
+	case c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return true
+	}
+	switch c {
+	case '!', '#', '$', '%', '&', '\'', '*', '+', '-', '.', '/', ':', '<', '=', '>', '?', '@', '\\', '^', '_', '`', '|', '~':
+		return true
+	}
+	return false
+}
+
+func (l *lexer) readString() (string, error) {
+	start := *l
+	l.advance()
+	var buf []byte
+	for {
+		if l.off >= len(l.src) || l.peek(0) == '\n' {
+			return "", start.errorf("unterminated string")
+		}
+		c := l.peek(0)
+		if c == '"' {
+			l.advance()
+			return string(buf), nil
+		}
+		if c != '\\' {
+			buf = append(buf, c)
+			l.advance()
+			continue
+		}
+
+		l.advance()
+		switch e := l.peek(0); e {
+		case 'n':
+			buf = append(buf, '\n')
+		case 't':
+			buf = append(buf, '\t')
+		case 'r':
+			buf = append(buf, '\r')
+		case '\\', '\'', '"':
+			buf = append(buf, e)
+		case 'u':
+			end := l.off + 2
+			for end < len(l.src) && l.src[end] != '}' {
+				end++
+			}
+			if l.peek(1) != '{' || end >= len(l.src) {
+				return "", l.errorf("malformed unicode escape")
+			}
+			r, err := strconv.ParseUint(string(l.src[l.off+2:end]), 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				return "", l.errorf("malformed unicode escape")
+			}
+			buf = append(buf, string(rune(r))...)
+			for l.off < end {
+				l.advance()
+			}
+		default:
+			hi, ok1 := hexDigit(e)
+			lo, ok2 := hexDigit(l.peek(1))
+			if !ok1 || !ok2 {
+				return "", l.errorf("unknown escape \\%c", e)
+			}
+			buf = append(buf, hi<<4|lo)
+			l.advance()
+		}
+		l.advance()
+	}
+}
+
+func hexDigit(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
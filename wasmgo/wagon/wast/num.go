@@ -0,0 +1,106 @@
+// Copyright 2018 The go-interpreter Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wast
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// See https://webassembly.github.io/spec/core/text/values.html
+
+var errNumber = errors.New("malformed number")
+
+func splitSign(s string) (neg bool, rest string) {
+	if strings.HasPrefix(s, "-") {
+		return true, s[1:]
+	}
+	return false, strings.TrimPrefix(s, "+")
+}
+
+// parseUint reads an unsigned integer, decimal or hexadecimal with _ separators
+func parseUint(s string, bits int) (uint64, error) {
+	if strings.HasPrefix(s, "_") || strings.HasSuffix(s, "_") || strings.Contains(s, "__") {
+		return 0, errNumber
+	}
+	s = strings.Replace(s, "_", "", -1)
+	base := 10
+	if strings.HasPrefix(s, "0x") {
+		s, base = s[2:], 16
+	}
+	if s == "" || s[0] == '+' || s[0] == '-' {
+		return 0, errNumber
+	}
+	v, err := strconv.ParseUint(s, base, bits)
+	if err != nil {
+		return 0, errNumber
+	}
+	return v, nil
+}
+
+// parseInt reads an integer of bits wide, both the signed and the unsigned ranges are accepted
+// and the result is its two's complement bit pattern
+func parseInt(s string, bits uint) (uint64, error) {
+	neg, rest := splitSign(s)
+	v, err := parseUint(rest, int(bits))
+	if err != nil {
+		return 0, err
+	}
+	if !neg {
+		return v, nil
+	}
+	if v > 1<<(bits-1) {
+		return 0, errNumber
+	}
+	return -v & (1<<(bits-1)<<1 - 1), nil
+}
+
+// parseFloat reads a float of bits wide and returns its bit pattern
+func parseFloat(s string, bits uint) (uint64, error) {
+	neg, rest := splitSign(s)
+	mantBits := uint(52)
+	expMask := uint64(0x7ff)
+	if bits == 32 {
+		mantBits, expMask = 23, 0xff
+	}
+	sign := uint64(0)
+	if neg {
+		sign = 1 << (bits - 1)
+	}
+
+	switch {
+	case rest == "inf":
+		return sign | expMask<<mantBits, nil
+	case rest == "nan":
+		return sign | expMask<<mantBits | 1<<(mantBits-1), nil
+	case strings.HasPrefix(rest, "nan:0x"):
+		payload, err := parseUint(rest[4:], 64)
+		if err != nil || payload == 0 || payload >= 1<<mantBits {
+			return 0, errNumber
+		}
+		return sign | expMask<<mantBits | payload, nil
+	}
+
+	if rest == "" || strings.HasPrefix(rest, "_") || strings.HasSuffix(rest, "_") || strings.Contains(rest, "__") {
+		return 0, errNumber
+	}
+	rest = strings.Replace(rest, "_", "", -1)
+	if strings.HasPrefix(rest, "0x") && !strings.ContainsAny(rest, "pP") {
+		rest += "p0"
+	}
+	if strings.ContainsAny(rest, "+-") && !strings.ContainsAny(rest, "eEpP") {
+		return 0, errNumber
+	}
+	v, err := strconv.ParseFloat(rest, int(bits))
+	if err != nil {
+		return 0, errNumber
+	}
+	if bits == 32 {
+		return sign | uint64(math.Float32bits(float32(v))), nil
+	}
+	return sign | math.Float64bits(v), nil
+}
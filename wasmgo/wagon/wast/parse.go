@@ -0,0 +1,773 @@
+// Copyright 2018 The go-interpreter Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wast
+
+// See https://webassembly.github.io/spec/core/text/modules.html
+
+import (
+	"bytes"
+
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+	ops "github.com/eosspark/eos-go/wasmgo/wagon/wasm/operators"
+)
+
+// Parse reads a module in the WebAssembly text format.
+// The source may hold a single (module ...) or the bare list of module fields.
+func Parse(src []byte) (m *wasm.Module, err error) {
+	nodes, err := readNodes(src)
+	if err != nil {
+		return nil, err
+	}
+	defer catch(&err)
+
+	fields := nodes
+	if len(nodes) == 1 && nodes[0].head() == "module" {
+		_, fields = moduleFields(nodes[0])
+	}
+	return parseModule(fields), nil
+}
+
+// catch turns the *SyntaxError a parser panicked with into err
+func catch(err *error) {
+	if r := recover(); r != nil {
+		serr, ok := r.(*SyntaxError)
+		if !ok {
+			panic(r)
+		}
+		*err = serr
+	}
+}
+
+// moduleFields splits a (module $id? ...) list into its name and its fields
+func moduleFields(n *node) (name string, fields []*node) {
+	fields = n.list[1:]
+	if len(fields) > 0 && fields[0].kind == nodeID {
+		name, fields = fields[0].text, fields[1:]
+	}
+	return name, fields
+}
+
+// Compile translates a module in the WebAssembly text format to its binary encoding.
+func Compile(src []byte) ([]byte, error) {
+	m, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err = wasm.EncodeModule(buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// space is an index space of the module, imported entries come first
+type space struct {
+	kind    string
+	names   map[string]uint32
+	imports uint32
+	count   uint32
+}
+
+// builder collects the entries of a module while its fields are read
+type builder struct {
+	types     []wasm.FunctionSig
+	typeNames map[string]uint32
+
+	funcs, tables, mems, globals *space
+	index                        map[*node]uint32 // index of every field declaring an entry
+
+	imports   []wasm.ImportEntry
+	funcTypes []uint32
+	bodies    []wasm.FunctionBody
+	tabs      []wasm.Table
+	memories  []wasm.Memory
+	globalEs  []wasm.GlobalEntry
+	exports   map[string]wasm.ExportEntry
+	start     *wasm.SectionStartFunction
+	elems     []wasm.ElementSegment
+	data      []wasm.DataSegment
+}
+
+func fail(n *node, format string, args ...interface{}) {
+	panic(n.errorf(format, args...))
+}
+
+func parseModule(fields []*node) *wasm.Module {
+	b := &builder{
+		typeNames: make(map[string]uint32),
+		funcs:     &space{kind: "func", names: make(map[string]uint32)},
+		tables:    &space{kind: "table", names: make(map[string]uint32)},
+		mems:      &space{kind: "memory", names: make(map[string]uint32)},
+		globals:   &space{kind: "global", names: make(map[string]uint32)},
+		index:     make(map[*node]uint32),
+		exports:   make(map[string]wasm.ExportEntry),
+	}
+
+	/* explicit types and the indices of every entry are known before anything refers to them */
+	for _, f := range fields {
+		if f.kind != nodeList {
+			fail(f, "expected a module field")
+		}
+		if f.head() == "type" {
+			b.typeField(f)
+		}
+		if s, _, imported := b.declaration(f); s != nil && imported {
+			s.imports++
+		}
+	}
+	imported := make(map[*space]uint32)
+	for _, f := range fields {
+		s, id, isImport := b.declaration(f)
+		if s == nil {
+			continue
+		}
+		idx := s.imports + s.count
+		if isImport {
+			idx = imported[s]
+			imported[s]++
+		} else {
+			s.count++
+		}
+		b.index[f] = idx
+		if id != nil {
+			if _, ok := s.names[id.text]; ok {
+				fail(id, "duplicate %s $%s", s.kind, id.text)
+			}
+			s.names[id.text] = idx
+		}
+	}
+
+	for _, f := range fields {
+		switch f.head() {
+		case "type":
+		case "import":
+			b.importField(f)
+		case "func":
+			b.funcField(f)
+		case "table":
+			b.tableField(f)
+		case "memory":
+			b.memoryField(f)
+		case "global":
+			b.globalField(f)
+		case "export":
+			b.exportField(f)
+		case "start":
+			b.startField(f)
+		case "elem":
+			b.elemField(f)
+		case "data":
+			b.dataField(f)
+		default:
+			fail(f, "unknown module field %q", f.head())
+		}
+	}
+	return b.module()
+}
+
+// declaration tells the index space a field adds an entry to, with its name
+func (b *builder) declaration(f *node) (s *space, id *node, imported bool) {
+	decl := f
+	switch f.head() {
+	case "import":
+		if len(f.list) != 4 || f.list[3].kind != nodeList {
+			fail(f, "malformed import")
+		}
+		decl, imported = f.list[3], true
+	case "func", "table", "memory", "global":
+	default:
+		return nil, nil, false
+	}
+
+	switch decl.head() {
+	case "func":
+		s = b.funcs
+	case "table":
+		s = b.tables
+	case "memory":
+		s = b.mems
+	case "global":
+		s = b.globals
+	default:
+		fail(decl, "unknown import kind %q", decl.head())
+	}
+	if len(decl.list) > 1 && decl.list[1].kind == nodeID {
+		id = decl.list[1]
+	}
+	for _, item := range decl.list[1:] {
+		if item.head() == "import" {
+			imported = true
+		}
+	}
+	return s, id, imported
+}
+
+func (b *builder) module() *wasm.Module {
+	m := &wasm.Module{Version: wasm.Version}
+	if len(b.types) > 0 {
+		m.Types = &wasm.SectionTypes{Entries: b.types}
+		m.Sections = append(m.Sections, m.Types)
+	}
+	if len(b.imports) > 0 {
+		m.Import = &wasm.SectionImports{Entries: b.imports}
+		m.Sections = append(m.Sections, m.Import)
+	}
+	if len(b.funcTypes) > 0 {
+		m.Function = &wasm.SectionFunctions{Types: b.funcTypes}
+		m.Sections = append(m.Sections, m.Function)
+	}
+	if len(b.tabs) > 0 {
+		m.Table = &wasm.SectionTables{Entries: b.tabs}
+		m.Sections = append(m.Sections, m.Table)
+	}
+	if len(b.memories) > 0 {
+		m.Memory = &wasm.SectionMemories{Entries: b.memories}
+		m.Sections = append(m.Sections, m.Memory)
+	}
+	if len(b.globalEs) > 0 {
+		m.Global = &wasm.SectionGlobals{Globals: b.globalEs}
+		m.Sections = append(m.Sections, m.Global)
+	}
+	if len(b.exports) > 0 {
+		m.Export = &wasm.SectionExports{Entries: b.exports}
+		m.Sections = append(m.Sections, m.Export)
+	}
+	if b.start != nil {
+		m.Start = b.start
+		m.Sections = append(m.Sections, m.Start)
+	}
+	if len(b.elems) > 0 {
+		m.Elements = &wasm.SectionElements{Entries: b.elems}
+		m.Sections = append(m.Sections, m.Elements)
+	}
+	if len(b.bodies) > 0 {
+		for i := range b.bodies {
+			b.bodies[i].Module = m
+		}
+		m.Code = &wasm.SectionCode{Bodies: b.bodies}
+		m.Sections = append(m.Sections, m.Code)
+	}
+	if len(b.data) > 0 {
+		m.Data = &wasm.SectionData{Entries: b.data}
+		m.Sections = append(m.Sections, m.Data)
+	}
+	return m
+}
+
+// fieldItems walks the items of a field after its keyword
+type fieldItems struct {
+	f     *node
+	items []*node
+	i     int
+}
+
+func items(f *node) *fieldItems {
+	return &fieldItems{f: f, items: f.list[1:]}
+}
+
+func (it *fieldItems) done() bool {
+	return it.i >= len(it.items)
+}
+
+func (it *fieldItems) peek() *node {
+	if it.done() {
+		return &node{kind: nodeList, pos: it.f.pos}
+	}
+	return it.items[it.i]
+}
+
+func (it *fieldItems) next() *node {
+	if it.done() {
+		fail(it.f, "unexpected end of %s", it.f.head())
+	}
+	it.i++
+	return it.items[it.i-1]
+}
+
+func (it *fieldItems) id() *node {
+	if it.peek().kind == nodeID {
+		return it.next()
+	}
+	return nil
+}
+
+func (it *fieldItems) end() {
+	if !it.done() {
+		fail(it.peek(), "unexpected token in %s", it.f.head())
+	}
+}
+
+func (it *fieldItems) rest() []*node {
+	rest := it.items[it.i:]
+	it.i = len(it.items)
+	return rest
+}
+
+// inlineExports consumes the (export "name") abbreviations of a definition
+func (b *builder) inlineExports(it *fieldItems, kind wasm.External, idx uint32) {
+	for it.peek().head() == "export" {
+		e := it.next()
+		if len(e.list) != 2 || e.list[1].kind != nodeString {
+			fail(e, "malformed export")
+		}
+		b.export(e.list[1], kind, idx)
+	}
+}
+
+// inlineImport consumes the (import "module" "name") abbreviation of a definition
+func inlineImport(it *fieldItems) (module, name string, ok bool) {
+	if it.peek().head() != "import" {
+		return "", "", false
+	}
+	i := it.next()
+	if len(i.list) != 3 || i.list[1].kind != nodeString || i.list[2].kind != nodeString {
+		fail(i, "malformed import")
+	}
+	return i.list[1].text, i.list[2].text, true
+}
+
+func (b *builder) export(name *node, kind wasm.External, idx uint32) {
+	if _, ok := b.exports[name.text]; ok {
+		fail(name, "duplicate export %q", name.text)
+	}
+	b.exports[name.text] = wasm.ExportEntry{FieldStr: name.text, Kind: kind, Index: idx}
+}
+
+func (b *builder) typeField(f *node) {
+	it := items(f)
+	id := it.id()
+	fn := it.next()
+	if fn.head() != "func" {
+		fail(fn, "expected a func type")
+	}
+	fit := items(fn)
+	sig, _ := b.signature(fit)
+	fit.end()
+	it.end()
+
+	if id != nil {
+		if _, ok := b.typeNames[id.text]; ok {
+			fail(id, "duplicate type $%s", id.text)
+		}
+		b.typeNames[id.text] = uint32(len(b.types))
+	}
+	b.types = append(b.types, sig)
+}
+
+// signature reads the (param ...) and (result ...) lists, with the names of the parameters
+func (b *builder) signature(it *fieldItems) (wasm.FunctionSig, []*node) {
+	sig := wasm.FunctionSig{Form: int8(wasm.TypeFunc), ParamTypes: []wasm.ValueType{}, ReturnTypes: []wasm.ValueType{}}
+	var names []*node
+	for it.peek().head() == "param" {
+		p := items(it.next())
+		if id := p.id(); id != nil {
+			sig.ParamTypes = append(sig.ParamTypes, valueType(p.next()))
+			names = append(names, id)
+			p.end()
+			continue
+		}
+		for !p.done() {
+			sig.ParamTypes = append(sig.ParamTypes, valueType(p.next()))
+			names = append(names, nil)
+		}
+	}
+	for it.peek().head() == "result" {
+		r := items(it.next())
+		for !r.done() {
+			sig.ReturnTypes = append(sig.ReturnTypes, valueType(r.next()))
+		}
+	}
+	return sig, names
+}
+
+// typeUse reads a (type x) reference and the inline signature, either of which may be left out.
+// A signature without a type reference uses the first type matching it, which is added if there is none.
+func (b *builder) typeUse(it *fieldItems) (uint32, wasm.FunctionSig, []*node) {
+	var ref *node
+	if it.peek().head() == "type" {
+		t := it.next()
+		if len(t.list) != 2 {
+			fail(t, "malformed type use")
+		}
+		ref = t.list[1]
+	}
+	start := it.peek()
+	sig, names := b.signature(it)
+
+	if ref == nil {
+		return b.findType(sig), sig, names
+	}
+	idx := b.typeRef(ref)
+	if len(sig.ParamTypes) == 0 && len(sig.ReturnTypes) == 0 {
+		return idx, b.types[idx], make([]*node, len(b.types[idx].ParamTypes))
+	}
+	if !sameSignature(sig, b.types[idx]) {
+		fail(start, "inline signature does not match type %d", idx)
+	}
+	return idx, sig, names
+}
+
+func (b *builder) findType(sig wasm.FunctionSig) uint32 {
+	for i, t := range b.types {
+		if sameSignature(t, sig) {
+			return uint32(i)
+		}
+	}
+	b.types = append(b.types, sig)
+	return uint32(len(b.types) - 1)
+}
+
+func sameSignature(a, b wasm.FunctionSig) bool {
+	if len(a.ParamTypes) != len(b.ParamTypes) || len(a.ReturnTypes) != len(b.ReturnTypes) {
+		return false
+	}
+	for i := range a.ParamTypes {
+		if a.ParamTypes[i] != b.ParamTypes[i] {
+			return false
+		}
+	}
+	for i := range a.ReturnTypes {
+		if a.ReturnTypes[i] != b.ReturnTypes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *builder) typeRef(n *node) uint32 {
+	if n.kind == nodeID {
+		idx, ok := b.typeNames[n.text]
+		if !ok {
+			fail(n, "unknown type $%s", n.text)
+		}
+		return idx
+	}
+	idx := uint32Of(n)
+	if int(idx) >= len(b.types) {
+		fail(n, "unknown type %d", idx)
+	}
+	return idx
+}
+
+// ref resolves a reference into an index space
+func (b *builder) ref(s *space, n *node) uint32 {
+	if n.kind == nodeID {
+		idx, ok := s.names[n.text]
+		if !ok {
+			fail(n, "unknown %s $%s", s.kind, n.text)
+		}
+		return idx
+	}
+	idx := uint32Of(n)
+	if idx >= s.imports+s.count {
+		fail(n, "unknown %s %d", s.kind, idx)
+	}
+	return idx
+}
+
+func uint32Of(n *node) uint32 {
+	if n.kind != nodeKeyword {
+		fail(n, "expected a number")
+	}
+	v, err := parseUint(n.text, 32)
+	if err != nil {
+		fail(n, "malformed u32 %q", n.text)
+	}
+	return uint32(v)
+}
+
+var valueTypes = map[string]wasm.ValueType{
+	"i32": wasm.ValueTypeI32,
+	"i64": wasm.ValueTypeI64,
+	"f32": wasm.ValueTypeF32,
+	"f64": wasm.ValueTypeF64,
+}
+
+func valueType(n *node) wasm.ValueType {
+	t, ok := valueTypes[n.text]
+	if n.kind != nodeKeyword || !ok {
+		fail(n, "expected a value type")
+	}
+	return t
+}
+
+func (b *builder) importField(f *node) {
+	if len(f.list) != 4 || f.list[1].kind != nodeString || f.list[2].kind != nodeString {
+		fail(f, "malformed import")
+	}
+	desc := f.list[3]
+	it := items(desc)
+	it.id()
+
+	entry := wasm.ImportEntry{ModuleName: f.list[1].text, FieldName: f.list[2].text}
+	switch desc.head() {
+	case "func":
+		idx, _, _ := b.typeUse(it)
+		entry.Type = wasm.FuncImport{Type: idx}
+	case "table":
+		entry.Type = wasm.TableImport{Type: tableType(it)}
+	case "memory":
+		entry.Type = wasm.MemoryImport{Type: wasm.Memory{Limits: limits(it)}}
+	case "global":
+		entry.Type = wasm.GlobalVarImport{Type: globalType(it.next())}
+	}
+	it.end()
+	b.imports = append(b.imports, entry)
+}
+
+func limits(it *fieldItems) wasm.ResizableLimits {
+	lim := wasm.ResizableLimits{Initial: uint32Of(it.next())}
+	if it.peek().kind == nodeKeyword && it.peek().text != "anyfunc" && it.peek().text != "funcref" {
+		lim.Flags, lim.Maximum = 1, uint32Of(it.next())
+	}
+	return lim
+}
+
+func tableType(it *fieldItems) wasm.Table {
+	lim := limits(it)
+	elemType(it.next())
+	return wasm.Table{ElementType: wasm.ElemTypeAnyFunc, Limits: lim}
+}
+
+func elemType(n *node) {
+	if !n.is("anyfunc") && !n.is("funcref") {
+		fail(n, "expected anyfunc")
+	}
+}
+
+func globalType(n *node) wasm.GlobalVar {
+	if n.head() == "mut" {
+		if len(n.list) != 2 {
+			fail(n, "malformed global type")
+		}
+		return wasm.GlobalVar{Type: valueType(n.list[1]), Mutable: true}
+	}
+	return wasm.GlobalVar{Type: valueType(n)}
+}
+
+func (b *builder) funcField(f *node) {
+	idx := b.index[f]
+	it := items(f)
+	it.id()
+	b.inlineExports(it, wasm.ExternalFunction, idx)
+
+	if module, name, ok := inlineImport(it); ok {
+		typeIdx, _, _ := b.typeUse(it)
+		it.end()
+		b.imports = append(b.imports, wasm.ImportEntry{ModuleName: module, FieldName: name, Type: wasm.FuncImport{Type: typeIdx}})
+		return
+	}
+
+	typeIdx, sig, names := b.typeUse(it)
+	c := newCompiler(b)
+	for i, name := range names {
+		c.local(name, uint32(i))
+	}
+
+	body := wasm.FunctionBody{}
+	count := uint32(len(sig.ParamTypes))
+	for it.peek().head() == "local" {
+		l := items(it.next())
+		if id := l.id(); id != nil {
+			c.local(id, count)
+			body.Locals = appendLocal(body.Locals, valueType(l.next()))
+			count++
+			l.end()
+			continue
+		}
+		for !l.done() {
+			body.Locals = appendLocal(body.Locals, valueType(l.next()))
+			count++
+		}
+	}
+
+	c.instrs(it.rest())
+	body.Code = c.code.Bytes()
+	b.funcTypes = append(b.funcTypes, typeIdx)
+	b.bodies = append(b.bodies, body)
+}
+
+// appendLocal adds a local, runs of the same type share an entry
+func appendLocal(locals []wasm.LocalEntry, t wasm.ValueType) []wasm.LocalEntry {
+	if n := len(locals); n > 0 && locals[n-1].Type == t {
+		locals[n-1].Count++
+		return locals
+	}
+	return append(locals, wasm.LocalEntry{Count: 1, Type: t})
+}
+
+func (b *builder) tableField(f *node) {
+	idx := b.index[f]
+	it := items(f)
+	it.id()
+	b.inlineExports(it, wasm.ExternalTable, idx)
+
+	if module, name, ok := inlineImport(it); ok {
+		table := tableType(it)
+		it.end()
+		b.imports = append(b.imports, wasm.ImportEntry{ModuleName: module, FieldName: name, Type: wasm.TableImport{Type: table}})
+		return
+	}
+
+	if it.peek().is("anyfunc") || it.peek().is("funcref") {
+		/* (table anyfunc (elem ...)) sizes the table to its elements, which are placed at 0 */
+		it.next()
+		elem := it.next()
+		if elem.head() != "elem" {
+			fail(elem, "expected an elem list")
+		}
+		it.end()
+		var funcs []uint32
+		for _, n := range elem.list[1:] {
+			funcs = append(funcs, b.ref(b.funcs, n))
+		}
+		size := uint32(len(funcs))
+		b.tabs = append(b.tabs, wasm.Table{ElementType: wasm.ElemTypeAnyFunc, Limits: wasm.ResizableLimits{Flags: 1, Initial: size, Maximum: size}})
+		b.elems = append(b.elems, wasm.ElementSegment{Index: idx, Offset: constExpr(0), Elems: funcs})
+		return
+	}
+
+	b.tabs = append(b.tabs, tableType(it))
+	it.end()
+}
+
+func (b *builder) memoryField(f *node) {
+	idx := b.index[f]
+	it := items(f)
+	it.id()
+	b.inlineExports(it, wasm.ExternalMemory, idx)
+
+	if module, name, ok := inlineImport(it); ok {
+		lim := limits(it)
+		it.end()
+		b.imports = append(b.imports, wasm.ImportEntry{ModuleName: module, FieldName: name, Type: wasm.MemoryImport{Type: wasm.Memory{Limits: lim}}})
+		return
+	}
+
+	if it.peek().head() == "data" {
+		/* (memory (data ...)) sizes the memory to its data, which is placed at 0 */
+		data := dataBytes(items(it.next()))
+		it.end()
+		pages := uint32((len(data) + 0xffff) / 0x10000)
+		b.memories = append(b.memories, wasm.Memory{Limits: wasm.ResizableLimits{Flags: 1, Initial: pages, Maximum: pages}})
+		b.data = append(b.data, wasm.DataSegment{Index: idx, Offset: constExpr(0), Data: data})
+		return
+	}
+
+	b.memories = append(b.memories, wasm.Memory{Limits: limits(it)})
+	it.end()
+}
+
+func (b *builder) globalField(f *node) {
+	idx := b.index[f]
+	it := items(f)
+	it.id()
+	b.inlineExports(it, wasm.ExternalGlobal, idx)
+
+	if module, name, ok := inlineImport(it); ok {
+		typ := globalType(it.next())
+		it.end()
+		b.imports = append(b.imports, wasm.ImportEntry{ModuleName: module, FieldName: name, Type: wasm.GlobalVarImport{Type: typ}})
+		return
+	}
+
+	typ := globalType(it.next())
+	b.globalEs = append(b.globalEs, wasm.GlobalEntry{Type: typ, Init: b.initExpr(it.rest())})
+}
+
+func (b *builder) exportField(f *node) {
+	if len(f.list) != 3 || f.list[1].kind != nodeString || f.list[2].kind != nodeList || len(f.list[2].list) != 2 {
+		fail(f, "malformed export")
+	}
+	desc := f.list[2]
+	switch desc.head() {
+	case "func":
+		b.export(f.list[1], wasm.ExternalFunction, b.ref(b.funcs, desc.list[1]))
+	case "table":
+		b.export(f.list[1], wasm.ExternalTable, b.ref(b.tables, desc.list[1]))
+	case "memory":
+		b.export(f.list[1], wasm.ExternalMemory, b.ref(b.mems, desc.list[1]))
+	case "global":
+		b.export(f.list[1], wasm.ExternalGlobal, b.ref(b.globals, desc.list[1]))
+	default:
+		fail(desc, "unknown export kind %q", desc.head())
+	}
+}
+
+func (b *builder) startField(f *node) {
+	if len(f.list) != 2 {
+		fail(f, "malformed start")
+	}
+	if b.start != nil {
+		fail(f, "multiple start functions")
+	}
+	b.start = &wasm.SectionStartFunction{Index: b.ref(b.funcs, f.list[1])}
+}
+
+// offset reads the offset of a segment, either (offset instr*) or a single folded instruction
+func (b *builder) offset(it *fieldItems) []byte {
+	n := it.next()
+	if n.kind != nodeList {
+		fail(n, "expected an offset expression")
+	}
+	if n.head() == "offset" {
+		return b.initExpr(n.list[1:])
+	}
+	return b.initExpr([]*node{n})
+}
+
+func (b *builder) elemField(f *node) {
+	it := items(f)
+	seg := wasm.ElementSegment{}
+	if it.peek().kind != nodeList {
+		seg.Index = b.ref(b.tables, it.next())
+	}
+	seg.Offset = b.offset(it)
+	seg.Elems = []uint32{}
+	for !it.done() {
+		seg.Elems = append(seg.Elems, b.ref(b.funcs, it.next()))
+	}
+	b.elems = append(b.elems, seg)
+}
+
+func (b *builder) dataField(f *node) {
+	it := items(f)
+	seg := wasm.DataSegment{}
+	if it.peek().kind != nodeList {
+		seg.Index = b.ref(b.mems, it.next())
+	}
+	seg.Offset = b.offset(it)
+	seg.Data = dataBytes(it)
+	b.data = append(b.data, seg)
+}
+
+func dataBytes(it *fieldItems) []byte {
+	data := []byte{}
+	for !it.done() {
+		s := it.next()
+		if s.kind != nodeString {
+			fail(s, "expected a string")
+		}
+		data = append(data, s.text...)
+	}
+	return data
+}
+
+// initExpr compiles a constant expression, terminated by end
+func (b *builder) initExpr(instrs []*node) []byte {
+	c := newCompiler(b)
+	c.instrs(instrs)
+	c.code.WriteByte(ops.End)
+	return c.code.Bytes()
+}
+
+// constExpr is the i32.const expression of a segment offset
+func constExpr(v int32) []byte {
+	c := newCompiler(nil)
+	c.code.WriteByte(ops.I32Const)
+	writeSleb(&c.code, int64(v))
+	c.code.WriteByte(ops.End)
+	return c.code.Bytes()
+}
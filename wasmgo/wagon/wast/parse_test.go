@@ -0,0 +1,199 @@
+// Copyright 2018 The go-interpreter Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wast_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wast"
+)
+
+// encode re-encodes a module without its custom sections
+func encode(t *testing.T, m *wasm.Module) []byte {
+	var sections []wasm.Section
+	for _, s := range m.Sections {
+		if s.SectionID() != wasm.SectionIDCustom {
+			sections = append(sections, s)
+		}
+	}
+	m.Sections = sections
+	buf := new(bytes.Buffer)
+	if err := wasm.EncodeModule(buf, m); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompile(t *testing.T) {
+	for _, dir := range testPaths {
+		fnames, err := filepath.Glob(filepath.Join(dir, "*.wast"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, fname := range fnames {
+			name := fname
+			wname := strings.TrimSuffix(name, ".wast") + ".wasm"
+			if _, err := os.Stat(wname); err != nil {
+				continue
+			}
+			t.Run(filepath.Base(name), func(t *testing.T) {
+				src, err := ioutil.ReadFile(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				raw, err := ioutil.ReadFile(wname)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				code, err := wast.Compile(src)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if bytes.Equal(raw, code) {
+					return
+				}
+
+				/* the order of exports and custom sections may differ */
+				got, err := wasm.DecodeModule(bytes.NewReader(code))
+				if err != nil {
+					t.Fatalf("error reading compiled module %v", err)
+				}
+				exp, err := wasm.DecodeModule(bytes.NewReader(raw))
+				if err != nil {
+					t.Fatalf("error reading module %v", err)
+				}
+				if !bytes.Equal(encode(t, exp), encode(t, got)) {
+					t.Fatalf("compiled module is different")
+				}
+			})
+		}
+	}
+}
+
+func TestParseCode(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		wast string
+		code []byte
+	}{
+		{
+			name: "flat",
+			wast: `(func $f (param $x i32) (result i32) block $out loop $in get_local $x br_if $out br $in end end i32.const 1)`,
+			code: []byte{0x02, 0x40, 0x03, 0x40, 0x20, 0x00, 0x0d, 0x01, 0x0c, 0x00, 0x0b, 0x0b, 0x41, 0x01},
+		},
+		{
+			name: "folded",
+			wast: `(func (param i32) (result i32) (if (result i32) (get_local 0) (then (i32.const -1)) (else (i32.const 0xffffffff))))`,
+			code: []byte{0x20, 0x00, 0x04, 0x7f, 0x41, 0x7f, 0x05, 0x41, 0x7f, 0x0b},
+		},
+		{
+			name: "current names",
+			wast: `(memory 1) (func (local i64) (local.set 0 (i64.extend_i32_u (memory.grow (i32.const 1)))))`,
+			code: []byte{0x41, 0x01, 0x40, 0x00, 0xad, 0x21, 0x00},
+		},
+		{
+			name: "memory access",
+			wast: `(memory 1) (func (i64.store32 offset=8 align=2 (i32.const 0) (i64.load (i32.const 0))))`,
+			code: []byte{0x41, 0x00, 0x41, 0x00, 0x29, 0x03, 0x00, 0x3e, 0x01, 0x08},
+		},
+		{
+			name: "floats",
+			wast: `(func (drop (f32.const 0x1p-1)) (drop (f32.const -nan:0x200000)) (drop (f64.const -inf)))`,
+			code: []byte{
+				0x43, 0x00, 0x00, 0x00, 0x3f, 0x1a,
+				0x43, 0x00, 0x00, 0xa0, 0xff, 0x1a,
+				0x44, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf0, 0xff, 0x1a,
+			},
+		},
+		{
+			name: "br_table",
+			wast: `(func (block $a (block $b (br_table $b $a 0 (i32.const 0)))))`,
+			code: []byte{0x02, 0x40, 0x02, 0x40, 0x41, 0x00, 0x0e, 0x02, 0x00, 0x01, 0x00, 0x0b, 0x0b},
+		},
+		{
+			name: "call_indirect",
+			wast: `(type $v (func)) (table 1 anyfunc) (func (call_indirect (type $v) (i32.const 0)) (call_indirect (param i32) (i32.const 1) (i32.const 0)))`,
+			code: []byte{0x41, 0x00, 0x11, 0x00, 0x00, 0x41, 0x01, 0x41, 0x00, 0x11, 0x01, 0x00},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := wast.Parse([]byte(tc.wast))
+			if err != nil {
+				t.Fatal(err)
+			}
+			bodies := m.Code.Bodies
+			if got := bodies[len(bodies)-1].Code; !bytes.Equal(tc.code, got) {
+				t.Fatalf("got % x, want % x", got, tc.code)
+			}
+		})
+	}
+}
+
+func TestParseModule(t *testing.T) {
+	m, err := wast.Parse([]byte(`(module $m
+  (import "env" "f" (func $imported (param i64)))
+  (global $g (import "env" "g") i32)
+  (func $local (export "run") (call $imported (i64.const 0)))
+  (global $h (mut i32) (get_global $g))
+  (memory $mem (export "memory") 1 2)
+  (table anyfunc (elem $local $imported))
+  (data (i32.const 8) "a\62\u{63}")
+  (start $local))`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.Import.Entries) != 2 || m.Import.Entries[1].Type.Kind() != wasm.ExternalGlobal {
+		t.Fatalf("imports %+v", m.Import.Entries)
+	}
+	if e := m.Export.Entries["run"]; e.Kind != wasm.ExternalFunction || e.Index != 1 {
+		t.Fatalf("export %+v", e)
+	}
+	if m.Start.Index != 1 {
+		t.Fatalf("start %d", m.Start.Index)
+	}
+	if !bytes.Equal(m.Global.Globals[0].Init, []byte{0x23, 0x00, 0x0b}) {
+		t.Fatalf("global init % x", m.Global.Globals[0].Init)
+	}
+	if lim := m.Memory.Entries[0].Limits; lim.Flags != 1 || lim.Initial != 1 || lim.Maximum != 2 {
+		t.Fatalf("memory %+v", lim)
+	}
+	if lim := m.Table.Entries[0].Limits; lim.Initial != 2 || lim.Maximum != 2 {
+		t.Fatalf("table %+v", lim)
+	}
+	if elems := m.Elements.Entries[0].Elems; len(elems) != 2 || elems[0] != 1 || elems[1] != 0 {
+		t.Fatalf("elements %v", elems)
+	}
+	if string(m.Data.Entries[0].Data) != "abc" {
+		t.Fatalf("data %q", m.Data.Entries[0].Data)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		wast string
+		err  string
+	}{
+		{`(module (func (i32.const 1)`, "wast: 1:9: unclosed ("},
+		{"(module\n  (func (call $missing)))", "wast: 2:15: unknown func $missing"},
+		{`(func (br $nowhere))`, "wast: 1:11: unknown label $nowhere"},
+		{`(func (i32.const 0x1_0000_0000))`, `wast: 1:18: malformed i32 "0x1_0000_0000"`},
+		{`(func (i32.frob))`, `wast: 1:8: unknown operator "i32.frob"`},
+		{`(func $f) (func $f)`, "wast: 1:17: duplicate func $f"},
+		{`(data (i32.const 0) "\x")`, `wast: 1:23: unknown escape \x`},
+	} {
+		_, err := wast.Parse([]byte(tc.wast))
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("%s: got error %v, want %s", tc.wast, err, tc.err)
+		}
+	}
+}
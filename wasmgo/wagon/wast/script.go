@@ -0,0 +1,302 @@
+// Copyright 2018 The go-interpreter Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wast
+
+// See https://github.com/WebAssembly/spec/tree/master/interpreter#scripts
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+)
+
+// ModuleFormat tells how a module of a script is written.
+type ModuleFormat uint8
+
+const (
+	ModuleText   ModuleFormat = iota // (module ...)
+	ModuleBinary                     // (module binary "...")
+	ModuleQuote                      // (module quote "...")
+)
+
+// ScriptModule is a module defined by a script.
+type ScriptModule struct {
+	Name   string // the $name without $, empty when the module has none
+	Format ModuleFormat
+	Module *wasm.Module // the parsed text module, nil when Err is set
+	Err    error        // why a text module could not be read
+	Source []byte       // the bytes of a binary module or the fields of a quoted one
+}
+
+// Encode returns the binary encoding of the module.
+// Quoted modules are only parsed here, so that assert_malformed can see the error.
+func (sm *ScriptModule) Encode() ([]byte, error) {
+	switch sm.Format {
+	case ModuleBinary:
+		return sm.Source, nil
+	case ModuleQuote:
+		return Compile(sm.Source)
+	}
+	if sm.Err != nil {
+		return nil, sm.Err
+	}
+	buf := new(bytes.Buffer)
+	if err := wasm.EncodeModule(buf, sm.Module); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NaNKind is the kind of NaN an assertion expects instead of an exact value.
+type NaNKind uint8
+
+const (
+	NaNNone       NaNKind = iota
+	NaNCanonical          // nan:canonical, only the sign may vary
+	NaNArithmetic         // nan:arithmetic, the quiet bit is set
+)
+
+// Value is a constant of a script, such as (i32.const 1).
+// Bits holds the two's complement or IEEE 754 bit pattern.
+// The older assert_return_*_nan directives do not write the type of the NaN they expect, it is left 0.
+type Value struct {
+	Type wasm.ValueType
+	Bits uint64
+	NaN  NaNKind
+}
+
+// Match reports whether v is the result an assertion expects.
+func (expect Value) Match(v Value) bool {
+	if expect.Type != v.Type && (expect.Type != 0 || expect.NaN == NaNNone) {
+		return false
+	}
+	switch expect.NaN {
+	case NaNCanonical:
+		if v.Type == wasm.ValueTypeF32 {
+			return uint32(v.Bits)&0x7fffffff == 0x7fc00000
+		}
+		return v.Type == wasm.ValueTypeF64 && v.Bits&0x7fffffffffffffff == 0x7ff8000000000000
+	case NaNArithmetic:
+		if v.Type == wasm.ValueTypeF32 {
+			return uint32(v.Bits)&0x7fc00000 == 0x7fc00000
+		}
+		return v.Type == wasm.ValueTypeF64 && v.Bits&0x7ff8000000000000 == 0x7ff8000000000000
+	}
+	return expect.Bits == v.Bits
+}
+
+func (v Value) String() string {
+	t := "f"
+	if v.Type != 0 {
+		t = v.Type.String()
+	}
+	switch {
+	case v.NaN == NaNCanonical:
+		return t + ":nan:canonical"
+	case v.NaN == NaNArithmetic:
+		return t + ":nan:arithmetic"
+	case v.Type == wasm.ValueTypeI32:
+		return fmt.Sprintf("%s:%d", t, int32(v.Bits))
+	case v.Type == wasm.ValueTypeI64:
+		return fmt.Sprintf("%s:%d", t, int64(v.Bits))
+	case v.Type == wasm.ValueTypeF32:
+		return fmt.Sprintf("%s:%g (0x%08x)", t, math.Float32frombits(uint32(v.Bits)), uint32(v.Bits))
+	}
+	return fmt.Sprintf("%s:%g (0x%016x)", t, math.Float64frombits(v.Bits), v.Bits)
+}
+
+// Action is an invoke of an exported function or a get of an exported global.
+type Action struct {
+	Kind   string // "invoke" or "get"
+	Module string // the module acted on, empty for the last one defined
+	Field  string
+	Args   []Value
+}
+
+// Command is a directive of a script.
+type Command struct {
+	Line, Col int
+	Kind      string // the keyword of the directive, such as "assert_return"
+
+	Module   *ScriptModule // for module, assert_invalid, assert_malformed, assert_unlinkable and assert_trap on a module
+	Name     string        // the module a register refers to, empty for the last one defined
+	As       string        // the name a register makes the module visible under
+	Action   *Action
+	Expected []Value
+	Message  string // the failure an assertion expects
+}
+
+// ParseScript reads a script of the WebAssembly spec test suite.
+// Modules which cannot be read are kept with their error, since assertions may expect it.
+func ParseScript(src []byte) (cmds []*Command, err error) {
+	nodes, err := readNodes(src)
+	if err != nil {
+		return nil, err
+	}
+	defer catch(&err)
+
+	for _, n := range nodes {
+		cmds = append(cmds, command(n))
+	}
+	return cmds, nil
+}
+
+func command(n *node) *Command {
+	cmd := &Command{Line: n.pos.line, Col: n.pos.col, Kind: n.head()}
+	it := items(n)
+	switch cmd.Kind {
+	case "module":
+		cmd.Module = scriptModule(n)
+		return cmd
+	case "register":
+		name := it.next()
+		if name.kind != nodeString {
+			fail(name, "expected a string")
+		}
+		cmd.As = name.text
+		cmd.Name = optionalID(it)
+	case "invoke", "get":
+		cmd.Action = action(n)
+		return cmd
+	case "assert_return", "assert_return_canonical_nan", "assert_return_arithmetic_nan":
+		cmd.Action = action(it.next())
+		for !it.done() {
+			cmd.Expected = append(cmd.Expected, constant(it.next()))
+		}
+		if cmd.Kind != "assert_return" {
+			if len(cmd.Expected) > 0 {
+				fail(n, "unexpected result of %s", cmd.Kind)
+			}
+			cmd.Expected = []Value{{NaN: NaNCanonical}}
+			if cmd.Kind == "assert_return_arithmetic_nan" {
+				cmd.Expected[0].NaN = NaNArithmetic
+			}
+			cmd.Kind = "assert_return"
+		}
+		return cmd
+	case "assert_trap":
+		if a := it.next(); a.head() == "module" {
+			cmd.Module = scriptModule(a)
+		} else {
+			cmd.Action = action(a)
+		}
+		cmd.Message = message(it)
+	case "assert_exhaustion":
+		cmd.Action = action(it.next())
+		cmd.Message = message(it)
+	case "assert_invalid", "assert_malformed", "assert_unlinkable":
+		m := it.next()
+		if m.head() != "module" {
+			fail(m, "expected a module")
+		}
+		cmd.Module = scriptModule(m)
+		cmd.Message = message(it)
+	default:
+		fail(n, "unknown directive %q", cmd.Kind)
+	}
+	it.end()
+	return cmd
+}
+
+func scriptModule(n *node) *ScriptModule {
+	name, fields := moduleFields(n)
+	sm := &ScriptModule{Name: name}
+	if len(fields) > 0 && (fields[0].is("binary") || fields[0].is("quote")) {
+		sm.Format = ModuleBinary
+		if fields[0].is("quote") {
+			sm.Format = ModuleQuote
+		}
+		for _, s := range fields[1:] {
+			if s.kind != nodeString {
+				fail(s, "expected a string")
+			}
+			sm.Source = append(sm.Source, s.text...)
+		}
+		return sm
+	}
+
+	func() {
+		defer catch(&sm.Err)
+		sm.Module = parseModule(fields)
+	}()
+	return sm
+}
+
+func action(n *node) *Action {
+	a := &Action{Kind: n.head()}
+	if a.Kind != "invoke" && a.Kind != "get" {
+		fail(n, "expected an action")
+	}
+	it := items(n)
+	a.Module = optionalID(it)
+	field := it.next()
+	if field.kind != nodeString {
+		fail(field, "expected a string")
+	}
+	a.Field = field.text
+	for a.Kind == "invoke" && !it.done() {
+		a.Args = append(a.Args, constant(it.next()))
+	}
+	it.end()
+	return a
+}
+
+func optionalID(it *fieldItems) string {
+	if id := it.id(); id != nil {
+		return id.text
+	}
+	return ""
+}
+
+func message(it *fieldItems) string {
+	s := it.next()
+	if s.kind != nodeString {
+		fail(s, "expected a string")
+	}
+	return s.text
+}
+
+var constTypes = map[string]wasm.ValueType{
+	"i32.const": wasm.ValueTypeI32,
+	"i64.const": wasm.ValueTypeI64,
+	"f32.const": wasm.ValueTypeF32,
+	"f64.const": wasm.ValueTypeF64,
+}
+
+// constant reads a value such as (i32.const 1) or (f32.const nan:canonical)
+func constant(n *node) Value {
+	t, ok := constTypes[n.head()]
+	if !ok || len(n.list) != 2 || n.list[1].kind != nodeKeyword {
+		fail(n, "expected a constant")
+	}
+	v := Value{Type: t}
+	text := n.list[1].text
+	switch {
+	case text == "nan:canonical" && (t == wasm.ValueTypeF32 || t == wasm.ValueTypeF64):
+		v.NaN = NaNCanonical
+		return v
+	case text == "nan:arithmetic" && (t == wasm.ValueTypeF32 || t == wasm.ValueTypeF64):
+		v.NaN = NaNArithmetic
+		return v
+	}
+
+	var err error
+	switch t {
+	case wasm.ValueTypeI32:
+		v.Bits, err = parseInt(text, 32)
+	case wasm.ValueTypeI64:
+		v.Bits, err = parseInt(text, 64)
+	case wasm.ValueTypeF32:
+		v.Bits, err = parseFloat(text, 32)
+	case wasm.ValueTypeF64:
+		v.Bits, err = parseFloat(text, 64)
+	}
+	if err != nil {
+		fail(n.list[1], "malformed %s %q", t, text)
+	}
+	return v
+}
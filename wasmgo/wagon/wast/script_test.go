@@ -0,0 +1,102 @@
+// Copyright 2018 The go-interpreter Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wast_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wast"
+)
+
+const script = `(module $M (func (export "f") (param i32) (result i32) (get_local 0)))
+(register "M" $M)
+(assert_return (invoke $M "f" (i32.const -1)) (i32.const 0xffffffff))
+(assert_return (invoke "nan") (f64.const nan:canonical))
+(assert_return_arithmetic_nan (invoke "nan"))
+(assert_trap (invoke "f" (i64.const 1)) "type mismatch")
+(assert_exhaustion (invoke "run") "call stack exhausted")
+(assert_malformed (module binary "\00asm") "unexpected end")
+(assert_malformed (module quote "(func") "unexpected end")
+(assert_invalid (module (func (call 9))) "unknown function")
+(get "g")
+`
+
+func TestParseScript(t *testing.T) {
+	cmds, err := wast.ParseScript([]byte(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := []string{"module", "register", "assert_return", "assert_return", "assert_return", "assert_trap",
+		"assert_exhaustion", "assert_malformed", "assert_malformed", "assert_invalid", "get"}
+	if len(cmds) != len(kinds) {
+		t.Fatalf("got %d commands, want %d", len(cmds), len(kinds))
+	}
+	for i, cmd := range cmds {
+		if cmd.Kind != kinds[i] || cmd.Line != i+1 {
+			t.Errorf("command %d: got %s at line %d, want %s at line %d", i, cmd.Kind, cmd.Line, kinds[i], i+1)
+		}
+	}
+
+	if m := cmds[0].Module; m.Name != "M" || m.Format != wast.ModuleText || m.Err != nil || len(m.Module.Code.Bodies) != 1 {
+		t.Errorf("module: got %+v", m)
+	}
+	if cmds[1].As != "M" || cmds[1].Name != "M" {
+		t.Errorf("register: got %+v", cmds[1])
+	}
+
+	action := &wast.Action{Kind: "invoke", Module: "M", Field: "f", Args: []wast.Value{{Type: wasm.ValueTypeI32, Bits: 0xffffffff}}}
+	if !reflect.DeepEqual(cmds[2].Action, action) {
+		t.Errorf("action: got %+v, want %+v", cmds[2].Action, action)
+	}
+	if want := []wast.Value{{Type: wasm.ValueTypeI32, Bits: 0xffffffff}}; !reflect.DeepEqual(cmds[2].Expected, want) {
+		t.Errorf("expected: got %v, want %v", cmds[2].Expected, want)
+	}
+	if want := []wast.Value{{Type: wasm.ValueTypeF64, NaN: wast.NaNCanonical}}; !reflect.DeepEqual(cmds[3].Expected, want) {
+		t.Errorf("expected: got %v, want %v", cmds[3].Expected, want)
+	}
+	if want := []wast.Value{{NaN: wast.NaNArithmetic}}; !reflect.DeepEqual(cmds[4].Expected, want) {
+		t.Errorf("expected: got %v, want %v", cmds[4].Expected, want)
+	}
+	if cmds[5].Message != "type mismatch" || cmds[6].Message != "call stack exhausted" {
+		t.Errorf("messages: got %q and %q", cmds[5].Message, cmds[6].Message)
+	}
+
+	if m := cmds[7].Module; m.Format != wast.ModuleBinary || !bytes.Equal(m.Source, []byte("\x00asm")) {
+		t.Errorf("binary module: got %+v", m)
+	}
+	if _, err := cmds[8].Module.Encode(); err == nil || err.Error() != "wast: 1:1: unclosed (" {
+		t.Errorf("quoted module: got error %v", err)
+	}
+	if _, err := cmds[9].Module.Encode(); err == nil || err.Error() != "wast: 10:37: unknown func 9" {
+		t.Errorf("invalid module: got error %v", err)
+	}
+}
+
+func TestValueMatch(t *testing.T) {
+	f32 := func(bits uint64) wast.Value { return wast.Value{Type: wasm.ValueTypeF32, Bits: bits} }
+	f64 := func(bits uint64) wast.Value { return wast.Value{Type: wasm.ValueTypeF64, Bits: bits} }
+
+	for _, tc := range []struct {
+		expect, got wast.Value
+		match       bool
+	}{
+		{f32(0x3f800000), f32(0x3f800000), true},
+		{f32(0x3f800000), f64(0x3f800000), false},
+		{wast.Value{Type: wasm.ValueTypeF32, NaN: wast.NaNCanonical}, f32(0xffc00000), true},
+		{wast.Value{Type: wasm.ValueTypeF32, NaN: wast.NaNCanonical}, f32(0x7fc00001), false},
+		{wast.Value{Type: wasm.ValueTypeF32, NaN: wast.NaNArithmetic}, f32(0x7fc00001), true},
+		{wast.Value{Type: wasm.ValueTypeF32, NaN: wast.NaNArithmetic}, f32(0x7f800001), false},
+		{wast.Value{NaN: wast.NaNCanonical}, f64(0x7ff8000000000000), true},
+		{wast.Value{NaN: wast.NaNCanonical}, wast.Value{Type: wasm.ValueTypeI64, Bits: 0x7ff8000000000000}, false},
+	} {
+		if tc.expect.Match(tc.got) != tc.match {
+			t.Errorf("%v matching %v: want %v", tc.expect, tc.got, tc.match)
+		}
+	}
+}
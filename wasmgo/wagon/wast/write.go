@@ -2,7 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Package wast implements a WebAssembly text format.
+// Package wast implements the WebAssembly text format.
+// Parse and Compile read text modules, WriteTo writes a module as text.
 package wast
 
 // See https://webassembly.github.io/spec/core/text/
@@ -12,8 +12,8 @@ import (
 	"strings"
 	"testing"
 
-	//"github.com/eosspark/eos-go/wasmgo/wasm"
-	//"github.com/eosspark/eos-go/wasmgo/wast"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wast"
 )
 
 var testPaths = []string{
@@ -2,12 +2,14 @@ package wasmgo
 
 import (
 	"fmt"
+	. "github.com/eosspark/eos-go/chain/types/generated_containers"
 	"github.com/eosspark/eos-go/common/eos_math"
 	"github.com/eosspark/eos-go/crypto"
 	"github.com/eosspark/eos-go/crypto/rlp"
 	"github.com/eosspark/eos-go/exception"
 	"github.com/eosspark/eos-go/exception/try"
 	"github.com/eosspark/eos-go/log"
+	"github.com/eosspark/eos-go/wasmgo/wagon/wasm"
 	"os"
 	//"time"
 	//"github.com/eosspark/eos-go/wasmgo/wasm"
@@ -21,7 +23,9 @@ const (
 	MaximumLinearMemoryInit = 64 * 1024        //bytes
 	MaximumFuncLocalBytes   = 8192             //bytes
 	MaximumCallDepth        = 250              //nested calls
-	MaximumCodeSize         = 1024 * 1024      //bytes 20 * 1024  *1024
+	MaximumLocalSets        = 1023             //local declarations per function
+	MaximumNestedStructures = 1024             //nested blocks, loops and ifs
+	MaximumBrTableElements  = 8191             //br_table targets
 	WasmPageSize            = 64 * 1024        //bytes
 
 	// Assert(MaximumLinearMemory%WasmPageSize == 0, "MaximumLinearMemory must be mulitple of wasm page size")
@@ -32,13 +36,61 @@ const (
 	// Assert(MaximumFuncLocalBytes > 32, "MaximumFuncLocalBytes must be greater than 32")
 )
 
+// MaximumCodeSize bounds the code section of a contract, it is checked by the decoder
+const MaximumCodeSize = wasm.MaximumCodeSize //bytes
+
 var wasmGo *WasmGo
 
+// ContractVMConfig is the VMConfig contracts are executed with
+var ContractVMConfig = VMConfig{
+	EnableJIT:          false,
+	MaxMemoryPages:     MaximumLinearMemory / WasmPageSize,
+	DefaultMemoryPages: 1,
+	DefaultTableSize:   65536,
+}
+
+// VmType selects how WasmGo executes contracts, it is set with the wasm-runtime option of chain_plugin
+type VmType uint8
+
+const (
+	Interpreter VmType = iota // the bytecode interpreter of VirtualMachine.Execute
+	AOT                       // the bytecode compiled ahead of time into Go closures, see aot.go
+)
+
+var vmTypeNames = map[VmType]string{
+	Interpreter: "wasmgo",
+	AOT:         "wasmgo-aot",
+}
+
+func (t VmType) String() string {
+	if name, ok := vmTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("VmType(%d)", uint8(t))
+}
+
+// ParseVmType returns the VmType named s, as in the wasm-runtime option
+func ParseVmType(s string) (VmType, error) {
+	for t, name := range vmTypeNames {
+		if name == s {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown wasm runtime %q, expected %s or %s", s, Interpreter, AOT)
+}
+
 //type size_t int
 
 type WasmGo struct {
 	context EnvContext
-	vmCache map[crypto.Sha256]*VirtualMachine
+	vmType  VmType
+	vmCache map[crypto.Sha256]*VirtualMachine // per code hash, built for vmType
+
+	debugAccounts *AccountNameSet // contracts run in debug mode, see debug.go
+	traceDir      string
+
+	profile   *Profile // see profile.go
+	profiling bool
 
 	ilog log.Logger
 }
@@ -60,6 +112,19 @@ func NewWasmGo() *WasmGo {
 	return wasmGo
 }
 
+// SetVmType switches the runtime contracts are executed with, the VMs cached for the previous one are dropped
+func (w *WasmGo) SetVmType(t VmType) {
+	if w.vmType != t {
+		w.vmType = t
+		w.vmCache = make(map[crypto.Sha256]*VirtualMachine)
+	}
+}
+
+// VmType returns the runtime contracts are executed with
+func (w *WasmGo) VmType() VmType {
+	return w.vmType
+}
+
 func (w *WasmGo) Apply(codeId *crypto.Sha256, code []byte, context EnvContext) {
 	w.context = context
 
@@ -74,13 +139,11 @@ func (w *WasmGo) Apply(codeId *crypto.Sha256, code []byte, context EnvContext) {
 		//	MaxMemoryPages: MaximumLinearMemory / WasmPageSize,
 		//	DefaultMemoryPages: 1,
 		//	DefaultTableSize:   65536}, new(Resolver), nil)
+		config := ContractVMConfig
+		config.EnableJIT = w.vmType == AOT
+
 		var err error
-		vm, err = NewVirtualMachine(w, code, VMConfig{
-			EnableJIT:          false,
-			MaxMemoryPages:     MaximumLinearMemory / WasmPageSize,
-			DefaultMemoryPages: 1,
-			DefaultTableSize:   65536,
-		}, new(Resolver), nil)
+		vm, err = NewVirtualMachine(w, code, config, new(Resolver), nil)
 
 		if err != nil {
 			w.ilog.Error("could not create VM: %v", err)
@@ -96,14 +159,18 @@ func (w *WasmGo) Apply(codeId *crypto.Sha256, code []byte, context EnvContext) {
 		w.ilog.Info("Entry function %s not found", "apply")
 	}
 
+	if w.profiling {
+		defer w.profile.begin(vm, context)()
+	}
+
+	debug := w.debugging(context.GetReceiver())
+	if debug && w.traceDir != "" {
+		defer w.startTrace(vm)()
+	}
+
 	if vm.Module.Base.Start != nil {
 		startID := int(vm.Module.Base.Start.Index)
-		_, err := vm.Run(startID)
-		if err != nil {
-			// vm.PrintStackTrace()
-			// panic(err)
-			w.ilog.Error("vm execute err: %v", err)
-		}
+		w.run(vm, debug, startID)
 	}
 
 	args := make([]int64, 3)
@@ -112,12 +179,7 @@ func (w *WasmGo) Apply(codeId *crypto.Sha256, code []byte, context EnvContext) {
 	args[2] = int64(context.GetAct())
 
 	// Run the WebAssembly module's entry function.
-	_, err := vm.Run(entryID, args...)
-	if err != nil {
-		// vm.PrintStackTrace()
-		// panic(err)
-		w.ilog.Error("vm execute err: %v", err)
-	}
+	w.run(vm, debug, entryID, args...)
 	//end := time.Now()
 	//w.ilog.Info("return value = %d, duration = %v", ret, end.Sub(start))
 